@@ -75,6 +75,12 @@ const (
 
 	maxPlatformInfoBit = 1
 
+	signerInfoAuthorKeyEnBit = 0
+	signerInfoMaskChipKeyBit = 1
+	signerInfoSigningKeyBit  = 2
+	signerInfoSigningKeyMask = 0x7
+	signerInfoMaxBit         = 4
+
 	signatureOffset = 0x2A0
 	ecdsaRSsize     = 72 // From the ECDSA-P384-SHA384 format in SEV SNP API specification.
 
@@ -110,12 +116,22 @@ const (
 	// ExpectedReportVersion is set by the SNP API specification
 	// https://www.amd.com/system/files/TechDocs/56860.pdf
 	ExpectedReportVersion = 2
+	// MinReportVersion is the lowest attestation report version this package understands.
+	MinReportVersion = 2
+	// MaxReportVersion is the highest attestation report version this package understands.
+	MaxReportVersion = 5
+	// CPUIDReportVersion is the first attestation report version that contains the CPUID_FAM_ID,
+	// CPUID_MOD_ID, and CPUID_STEP fields.
+	CPUIDReportVersion = 3
+	// MitigationReportVersion is the first attestation report version that contains the
+	// LAUNCH_MIT_VECTOR and CURRENT_MIT_VECTOR fields.
+	MitigationReportVersion = 5
 )
 
 // CertTableHeaderEntry defines an entry of the beginning of an extended attestation report which
 // points to a specific key's certificate.
 type CertTableHeaderEntry struct {
-	// GUID is one of VcekGUID, AskGUID, or ArkGUID to identify which key an offset/length corresponds
+	// GUID is one of VcekGUID, VlekGUID, AskGUID, or ArkGUID to identify which key an offset/length corresponds
 	// to.
 	GUID uuid.UUID
 	// Offset is the offset into the data pages passed to the extended get_report where the specified
@@ -178,6 +194,73 @@ type SnpPolicy struct {
 	SingleSocket bool
 }
 
+// ReportSigner represents which kind of key is expected to have signed the attestation report,
+// as selected by the SIGNING_KEY field of an attestation report.
+type ReportSigner uint8
+
+const (
+	// VcekReportSigner is the SIGNING_KEY value for if the VCEK signed the attestation report.
+	VcekReportSigner ReportSigner = iota
+	// VlekReportSigner is the SIGNING_KEY value for if the VLEK signed the attestation report.
+	VlekReportSigner
+	// NoneReportSigner is the SIGNING_KEY value for if the attestation report is not signed.
+	NoneReportSigner ReportSigner = 7
+)
+
+// String returns a printable representation of the report signer kind.
+func (k ReportSigner) String() string {
+	switch k {
+	case VcekReportSigner:
+		return "VCEK"
+	case VlekReportSigner:
+		return "VLEK"
+	case NoneReportSigner:
+		return "None"
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", uint8(k))
+}
+
+// SignerInfo represents an interpretation of the AUTHOR_KEY_EN, MASK_CHIP_KEY, and SIGNING_KEY
+// fields of an attestation report.
+type SignerInfo struct {
+	// SigningKey is the kind of key that signed the attestation report.
+	SigningKey ReportSigner
+	// MaskChipKey is true if the CHIP_ID field of the report is masked to zeros.
+	MaskChipKey bool
+	// AuthorKeyEn is true if the digest of the author key is present in the report.
+	AuthorKeyEn bool
+}
+
+// ParseSignerInfo interprets the 32-bit word at offset 0x48 of an attestation report into a
+// SignerInfo, or returns an error for reserved bits or reserved SIGNING_KEY values.
+func ParseSignerInfo(signerInfo uint32) (SignerInfo, error) {
+	result := SignerInfo{
+		SigningKey:  ReportSigner((signerInfo >> signerInfoSigningKeyBit) & signerInfoSigningKeyMask),
+		MaskChipKey: (signerInfo & (1 << signerInfoMaskChipKeyBit)) != 0,
+		AuthorKeyEn: (signerInfo & (1 << signerInfoAuthorKeyEnBit)) != 0,
+	}
+	reserved := signerInfo & ^uint32((1<<(signerInfoMaxBit+1))-1)
+	if reserved != 0 {
+		return result, fmt.Errorf("signer info bits 31:5 are reserved mbz, got 0x%x", signerInfo)
+	}
+	if result.SigningKey > VlekReportSigner && result.SigningKey < NoneReportSigner {
+		return result, fmt.Errorf("signing key values 2-6 are reserved, got %d", uint8(result.SigningKey))
+	}
+	return result, nil
+}
+
+// ComposeSignerInfo returns the 32-bit ABI representation of the given SignerInfo.
+func ComposeSignerInfo(info SignerInfo) uint32 {
+	result := uint32(info.SigningKey&signerInfoSigningKeyMask) << signerInfoSigningKeyBit
+	if info.MaskChipKey {
+		result |= 1 << signerInfoMaskChipKeyBit
+	}
+	if info.AuthorKeyEn {
+		result |= 1 << signerInfoAuthorKeyEnBit
+	}
+	return result
+}
+
 // ParseSnpPolicy interprets the SEV SNP API's guest policy bitmask into an SnpPolicy struct type.
 func ParseSnpPolicy(guestPolicy uint64) (SnpPolicy, error) {
 	result := SnpPolicy{}
@@ -357,11 +440,14 @@ func ReportToProto(data []uint8) (*pb.Report, error) {
 	r.CurrentTcb = binary.LittleEndian.Uint64(data[0x38:0x40])
 	r.PlatformInfo = binary.LittleEndian.Uint64(data[0x40:0x48])
 
-	reservedAuthor := binary.LittleEndian.Uint32(data[0x48:0x4C])
-	if reservedAuthor&0xffffffe0 != 0 {
-		return nil, fmt.Errorf("mbz bits at offset 0x48 not zero: 0x%08x", reservedAuthor&0xffffffe0)
+	signerInfo := binary.LittleEndian.Uint32(data[0x48:0x4C])
+	if signerInfo&0xffffffe0 != 0 {
+		return nil, fmt.Errorf("mbz bits at offset 0x48 not zero: 0x%08x", signerInfo&0xffffffe0)
+	}
+	if _, err := ParseSignerInfo(signerInfo); err != nil {
+		return nil, fmt.Errorf("malformed signer info: %v", err)
 	}
-	r.AuthorKeyEn = reservedAuthor
+	r.SignerInfo = signerInfo
 	if err := mbz(data, 0x4C, 0x50); err != nil {
 		return nil, err
 	}
@@ -373,7 +459,14 @@ func ReportToProto(data []uint8) (*pb.Report, error) {
 	r.ReportId = clone(data[0x140:0x160])
 	r.ReportIdMa = clone(data[0x160:0x180])
 	r.ReportedTcb = binary.LittleEndian.Uint64(data[0x180:0x188])
-	if err := mbz(data, 0x188, 0x1A0); err != nil {
+	mbzLo := 0x188
+	if r.Version >= CPUIDReportVersion {
+		mbzLo = 0x18B
+		r.CpuidFamId = uint32(data[0x188])
+		r.CpuidModId = uint32(data[0x189])
+		r.CpuidStep = uint32(data[0x18A])
+	}
+	if err := mbz(data, mbzLo, 0x1A0); err != nil {
 		return nil, err
 	}
 	r.ChipId = clone(data[0x1A0:0x1E0])
@@ -391,7 +484,13 @@ func ReportToProto(data []uint8) (*pb.Report, error) {
 		return nil, err
 	}
 	r.LaunchTcb = binary.LittleEndian.Uint64(data[0x1F0:0x1F8])
-	if err := mbz(data, 0x1F8, signatureOffset); err != nil {
+	mbzLo = 0x1F8
+	if r.Version >= MitigationReportVersion {
+		mbzLo = 0x208
+		r.LaunchMitVector = binary.LittleEndian.Uint64(data[0x1F8:0x200])
+		r.CurrentMitVector = binary.LittleEndian.Uint64(data[0x200:0x208])
+	}
+	if err := mbz(data, mbzLo, signatureOffset); err != nil {
 		return nil, err
 	}
 	if r.SignatureAlgo == SignEcdsaP384Sha384 {
@@ -448,8 +547,8 @@ func ValidateReportFormat(r []byte) error {
 	}
 
 	version := binary.LittleEndian.Uint32(r[0x00:0x04])
-	if version != ExpectedReportVersion {
-		return fmt.Errorf("report version is: %d. Expected %d", version, ExpectedReportVersion)
+	if version < MinReportVersion || version > MaxReportVersion {
+		return fmt.Errorf("report version is: %d. Expected %d-%d", version, MinReportVersion, MaxReportVersion)
 	}
 
 	policy := binary.LittleEndian.Uint64(r[0x08:0x10])
@@ -480,11 +579,10 @@ func ReportToAbiBytes(r *pb.Report) ([]byte, error) {
 	binary.LittleEndian.PutUint64(data[0x38:0x40], r.CurrentTcb)
 	binary.LittleEndian.PutUint64(data[0x40:0x48], r.PlatformInfo)
 
-	var reservedAuthor uint32
-	if r.AuthorKeyEn == 1 {
-		reservedAuthor |= 0x01
+	if _, err := ParseSignerInfo(r.SignerInfo); err != nil {
+		return nil, fmt.Errorf("malformed signer info: %v", err)
 	}
-	binary.LittleEndian.PutUint32(data[0x48:0x4C], reservedAuthor)
+	binary.LittleEndian.PutUint32(data[0x48:0x4C], r.SignerInfo)
 	copy(data[0x50:0x90], r.ReportData[:])
 	copy(data[0x90:0xC0], r.Measurement[:])
 	copy(data[0xC0:0xE0], r.HostData[:])
@@ -493,6 +591,18 @@ func ReportToAbiBytes(r *pb.Report) ([]byte, error) {
 	copy(data[0x140:0x160], r.ReportId[:])
 	copy(data[0x160:0x180], r.ReportIdMa[:])
 	binary.LittleEndian.PutUint64(data[0x180:0x188], r.ReportedTcb)
+	if r.Version >= CPUIDReportVersion {
+		if r.CpuidFamId >= (1<<8) || r.CpuidModId >= (1<<8) || r.CpuidStep >= (1<<8) {
+			return nil, fmt.Errorf("cpuid_fam_id, cpuid_mod_id, and cpuid_step fields must each fit in a byte, got %d, %d, %d",
+				r.CpuidFamId, r.CpuidModId, r.CpuidStep)
+		}
+		data[0x188] = byte(r.CpuidFamId)
+		data[0x189] = byte(r.CpuidModId)
+		data[0x18A] = byte(r.CpuidStep)
+	} else if r.CpuidFamId != 0 || r.CpuidModId != 0 || r.CpuidStep != 0 {
+		return nil, fmt.Errorf("report version %d cannot have CPUID fields, which require version %d",
+			r.Version, CPUIDReportVersion)
+	}
 	copy(data[0x1A0:0x1E0], r.ChipId[:])
 	binary.LittleEndian.PutUint64(data[0x1E0:0x1E8], r.CommittedTcb)
 	if r.CurrentBuild >= (1 << 8) {
@@ -520,6 +630,13 @@ func ReportToAbiBytes(r *pb.Report) ([]byte, error) {
 	data[0x1ED] = byte(r.CommittedMinor)
 	data[0x1EE] = byte(r.CommittedMajor)
 	binary.LittleEndian.PutUint64(data[0x1F0:0x1F8], r.LaunchTcb)
+	if r.Version >= MitigationReportVersion {
+		binary.LittleEndian.PutUint64(data[0x1F8:0x200], r.LaunchMitVector)
+		binary.LittleEndian.PutUint64(data[0x200:0x208], r.CurrentMitVector)
+	} else if r.LaunchMitVector != 0 || r.CurrentMitVector != 0 {
+		return nil, fmt.Errorf("report version %d cannot have mitigation vector fields, which require version %d",
+			r.Version, MitigationReportVersion)
+	}
 
 	copy(data[signatureOffset:ReportSize], r.Signature[:])
 	return data, nil
@@ -680,10 +797,12 @@ func (c *CertTable) GetByGUIDString(guid string) ([]byte, error) {
 // so missing certificates aren't an error. If certificates are missing, you can
 // choose to fetch them yourself by calling verify.GetAttestationFromReport.
 func (c *CertTable) Proto() *pb.CertificateChain {
-	var vcek, ask, ark []byte
-	var err error
+	var vcek, vlek, ask, ark []byte
+	var err, vlekErr error
+	vlek, vlekErr = c.GetByGUIDString(VlekGUID)
 	vcek, err = c.GetByGUIDString(VcekGUID)
-	if err != nil {
+	// Only one of the VCEK or VLEK is expected to be present.
+	if err != nil && vlekErr != nil {
 		logger.Warningf("Warning: VCEK certificate not found in data pages: %v", err)
 	}
 	ask, err = c.GetByGUIDString(AskGUID)
@@ -697,6 +816,7 @@ func (c *CertTable) Proto() *pb.CertificateChain {
 	firmware, _ := c.GetByGUIDString(gce.FirmwareCertGUID)
 	return &pb.CertificateChain{
 		VcekCert:     vcek,
+		VlekCert:     vlek,
 		AskCert:      ask,
 		ArkCert:      ark,
 		FirmwareCert: firmware,
@@ -15,12 +15,15 @@
 package abi
 
 import (
+	"errors"
+	"fmt"
 	"math/rand"
 	"strings"
 	"testing"
 
 	spb "github.com/google/go-sev-guest/proto/sevsnp"
 	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 )
 
 var emptyReport = `
@@ -170,3 +173,165 @@ func TestSnpPlatformInfo(t *testing.T) {
 		}
 	}
 }
+
+func TestSignerInfo(t *testing.T) {
+	tests := []struct {
+		input   uint32
+		want    SignerInfo
+		wantErr string
+	}{
+		{
+			input: 0,
+		},
+		{
+			input: 1,
+			want:  SignerInfo{AuthorKeyEn: true},
+		},
+		{
+			input: 0x6,
+			want:  SignerInfo{SigningKey: VlekReportSigner, MaskChipKey: true},
+		},
+		{
+			input: 0x1c,
+			want:  SignerInfo{SigningKey: NoneReportSigner},
+		},
+		{
+			input:   0x8,
+			wantErr: "signing key values 2-6 are reserved, got 2",
+		},
+		{
+			input:   0x20,
+			wantErr: "signer info bits 31:5 are reserved mbz, got 0x20",
+		},
+	}
+	for _, tc := range tests {
+		got, err := ParseSignerInfo(tc.input)
+		if (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) ||
+			(err == nil && tc.wantErr != "") {
+			t.Errorf("ParseSignerInfo(%x) errored unexpectedly. Got %v, want %v",
+				tc.input, err, tc.wantErr)
+		}
+		if err == nil {
+			if tc.want != got {
+				t.Errorf("ParseSignerInfo(%x) = %v, want %v", tc.input, got, tc.want)
+			}
+			if composed := ComposeSignerInfo(got); composed != tc.input {
+				t.Errorf("ComposeSignerInfo(%v) = %x, want %x", got, composed, tc.input)
+			}
+		}
+	}
+}
+
+func TestReportVersions(t *testing.T) {
+	tests := []struct {
+		name        string
+		version     uint32
+		change      func(*spb.Report)
+		changeIndex int
+		wantErr     string
+		wantAbiErr  string
+	}{
+		{
+			name:    "v3 CPUID fields",
+			version: 3,
+			change: func(r *spb.Report) {
+				r.CpuidFamId = 0x19
+				r.CpuidModId = 0x11
+				r.CpuidStep = 0x1
+			},
+		},
+		{
+			name:        "v3 pre-chip id",
+			version:     3,
+			changeIndex: 0x18B,
+			wantErr:     "mbz range [0x18b:0x1a0] not all zero: cc",
+		},
+		{
+			name:        "v3 pre-signature reserved",
+			version:     3,
+			changeIndex: 0x1f9,
+			wantErr:     "mbz range [0x1f8:0x2a0] not all zero: 00cc",
+		},
+		{
+			name:    "v5 mitigation vectors",
+			version: 5,
+			change: func(r *spb.Report) {
+				r.CpuidFamId = 0x1a
+				r.LaunchMitVector = 0x3
+				r.CurrentMitVector = 0x7
+			},
+		},
+		{
+			name:        "v5 pre-signature reserved",
+			version:     5,
+			changeIndex: 0x209,
+			wantErr:     "mbz range [0x208:0x2a0] not all zero: 00cc",
+		},
+		{
+			name:       "v2 CPUID fields",
+			version:    2,
+			change:     func(r *spb.Report) { r.CpuidModId = 1 },
+			wantAbiErr: "report version 2 cannot have CPUID fields, which require version 3",
+		},
+		{
+			name:       "v3 mitigation vectors",
+			version:    3,
+			change:     func(r *spb.Report) { r.CurrentMitVector = 1 },
+			wantAbiErr: "report version 3 cannot have mitigation vector fields, which require version 5",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			reportProto := &spb.Report{}
+			if err := prototext.Unmarshal([]byte(emptyReport), reportProto); err != nil {
+				t.Fatalf("test failure: %v", err)
+			}
+			reportProto.Version = tc.version
+			if tc.change != nil {
+				tc.change(reportProto)
+			}
+			raw, err := ReportToAbiBytes(reportProto)
+			if (err == nil && tc.wantAbiErr != "") || (err != nil && (tc.wantAbiErr == "" || !strings.Contains(err.Error(), tc.wantAbiErr))) {
+				t.Fatalf("ReportToAbiBytes(%v) = _, %v. Want error %q", reportProto, err, tc.wantAbiErr)
+			}
+			if err != nil {
+				return
+			}
+			if tc.changeIndex != 0 {
+				raw[tc.changeIndex] = 0xcc
+			}
+			got, err := ReportToProto(raw)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("ReportToProto(%v) = _, %v. Want error %q", raw, err, tc.wantErr)
+			}
+			if err == nil && !proto.Equal(got, reportProto) {
+				t.Errorf("ReportToProto(ReportToAbiBytes(%v)) = %v, want the original", reportProto, got)
+			}
+		})
+	}
+	raw := make([]byte, ReportSize)
+	raw[0] = 6
+	wantErr := "report version is: 6. Expected 2-5"
+	if err := ValidateReportFormat(raw); err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("ValidateReportFormat(version 6) = %v. Want error %q", err, wantErr)
+	}
+}
+
+func TestSevFirmwareErrIs(t *testing.T) {
+	wrapped := fmt.Errorf("get report: %w", &SevFirmwareErr{Status: ResourceLimit})
+	if !errors.Is(wrapped, &SevFirmwareErr{Status: ResourceLimit}) {
+		t.Errorf("errors.Is(%v, ResourceLimit) = false, want true", wrapped)
+	}
+	if errors.Is(wrapped, &SevFirmwareErr{Status: InvalidParam}) || errors.Is(wrapped, ErrThrottled) {
+		t.Errorf("errors.Is(%v) matches another status", wrapped)
+	}
+	if !errors.Is(&SevFirmwareErr{Status: GuestRequestBusy}, ErrThrottled) {
+		t.Error("errors.Is(GuestRequestBusy, ErrThrottled) = false, want true")
+	}
+	if !errors.Is(&SevFirmwareErr{Status: GuestRequestInvalidLength}, ErrInvalidLength) {
+		t.Error("errors.Is(GuestRequestInvalidLength, ErrInvalidLength) = false, want true")
+	}
+	if got := (&SevFirmwareErr{Status: BadSignature}).Error(); !strings.Contains(got, "unexpected for a guest request") {
+		t.Errorf("BadSignature error is %q, want it described as unexpected", got)
+	}
+}
@@ -14,101 +14,162 @@
 
 package abi
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 // SevFirmwareStatus is the type of all AMD-SP firmware status codes, as documented in the SEV API
 // https://www.amd.com/system/files/TechDocs/55766_SEV-KM_API_Specification.pdf
 type SevFirmwareStatus int
 
-// Unexported errors are not expected to leave the kernel.
+// Status codes from Table 24 of the SEV SNP API specification
+// https://www.amd.com/system/files/TechDocs/56860.pdf. Many are platform owner or kernel errors that
+// a guest request is not expected to produce, but all are named for error reporting.
 const (
 	// Success denotes successful completion of a firmware command.
 	Success SevFirmwareStatus = 0
 	// InvalidPlatformState is the code for the platform to be in the wrong state for a given command.
-	InvalidPlatformState = 1
+	InvalidPlatformState SevFirmwareStatus = 1
 	// InvalidGuestState is the code for the guest to be in the wrong state for a given command.
-	InvalidGuestState = 2
-	// Platform owner error unexpected by guest command.
-	// invalidConfig = 3
+	InvalidGuestState SevFirmwareStatus = 2
+	// InvalidConfig is the code for an invalid platform configuration.
+	InvalidConfig SevFirmwareStatus = 3
 	// InvalidLength is the code for a provided buffer size is too small to complete the command.
-	InvalidLength = 4
-	// Platform owner error unexpected by guest command.
-	// alreadyOwned = 5
-	// Platform owner error unexpected by guest command.
-	// invalidCertificate = 6
+	InvalidLength SevFirmwareStatus = 4
+	// AlreadyOwned is the code for when the platform is already owned.
+	AlreadyOwned SevFirmwareStatus = 5
+	// InvalidCertificate is the code for when a certificate is invalid.
+	InvalidCertificate SevFirmwareStatus = 6
 	// PolicyFailure is the code for when the guest policy disallows the command.
-	PolicyFailure = 7
+	PolicyFailure SevFirmwareStatus = 7
 	// Inactive is the code for when a command is sent for a guest, but the guest is inactive.
-	Inactive = 8
+	Inactive SevFirmwareStatus = 8
 	// InvalidAddress is the code for when a provided address is invalid.
-	InvalidAddress = 9
-	// User error expected at launch, unexpected here.
-	// badSignature = 10
-	// User error expected at launch, unexpected here.
-	// badMeasurement = 11
-	// Kernel error, unexpected.
-	// asidOwned = 12
-	// Kernel error, unexpected.
-	// invalidAsid = 13
-	// Kernel error, unexpected.
-	// wbinvdRequired = 14
-	// Kernel error, unexpected.
-	// dfFlushRequired = 15
-	// Kernel error, unexpected.
-	// invalidGuest = 16
+	InvalidAddress SevFirmwareStatus = 9
+	// BadSignature is the code for when a signature is invalid, e.g., the ID block's at launch.
+	BadSignature SevFirmwareStatus = 10
+	// BadMeasurement is the code for when a launch digest does not match its expected value.
+	BadMeasurement SevFirmwareStatus = 11
+	// AsidOwned is the code for when an ASID is already owned.
+	AsidOwned SevFirmwareStatus = 12
+	// InvalidAsid is the code for when an ASID is invalid.
+	InvalidAsid SevFirmwareStatus = 13
+	// WbinvdRequired is the code for when WBINVD must be executed first.
+	WbinvdRequired SevFirmwareStatus = 14
+	// DfFlushRequired is the code for when DF_FLUSH must be invoked first.
+	DfFlushRequired SevFirmwareStatus = 15
+	// InvalidGuest is the code for when a guest handle is invalid.
+	InvalidGuest SevFirmwareStatus = 16
 	// InvalidCommand is the code for when the command code is invalid.
-	InvalidCommand = 17
-	// Kernel error, unexpected.
-	// active = 18
+	InvalidCommand SevFirmwareStatus = 17
+	// Active is the code for when a guest is already active.
+	Active SevFirmwareStatus = 18
 	// HwErrorPlatform is the code for when the hardware failed but it's okay to update its buffers.
-	HwErrorPlatform = 19
+	HwErrorPlatform SevFirmwareStatus = 19
 	// HwErrorUnsafe is the code for when the hardware failed and it's unsafe to update its buffers.
-	HwErrorUnsafe = 20
+	HwErrorUnsafe SevFirmwareStatus = 20
 	// Unsupported is for an unsupported feature.
-	Unsupported = 21
+	Unsupported SevFirmwareStatus = 21
 	// InvalidParam is the code for an invalid parameter in a command.
-	InvalidParam = 22
+	InvalidParam SevFirmwareStatus = 22
 	// ResourceLimit is the code for when the firmware has reached a resource limit and can't complete the command.
-	ResourceLimit = 23
+	ResourceLimit SevFirmwareStatus = 23
 	// SecureDataInvalid is the code for when a hardware integrity check has failed.
-	SecureDataInvalid = 24
+	SecureDataInvalid SevFirmwareStatus = 24
 	// InvalidPageSize indicates an RMP error with the recorded page size.
-	InvalidPageSize = 25
+	InvalidPageSize SevFirmwareStatus = 25
 	// InvalidPageState indicates an RMP error with the recorded page state.
-	InvalidPageState = 26
+	InvalidPageState SevFirmwareStatus = 26
 	// InvalidMdataEntry indicates an RMP error with the recorded metadata.
-	InvalidMdataEntry = 27
+	InvalidMdataEntry SevFirmwareStatus = 27
 	// InvalidPageOwner indicates an RMP error with ASID mismatch between accessors.
-	InvalidPageOwner = 28
+	InvalidPageOwner SevFirmwareStatus = 28
 	// AeadOflow indicates that firmware memory capacity is reached in the AEAD cryptographic algorithm.
-	AeadOflow = 29
-	// Skip code 0x1E since AeaedOflow is 0x1D and rbModeExited is 0x1F.
-	// reserved1e = 30
-	// Kernel error, unexpected.
-	// rbModeExited = 31
-	// Kernel error, unexpected.
-	// rmpInitRequired = 32
-	// Platform management error, unexpected.
-	// badSvn = 33
-	// Platform management error, unexpected.
-	// badVersion = 34
-	// Platform management error, unexpected.
-	// shutdownRequired = 35
-	// Platform management error, unexpected.
-	// updateFailed = 36
-	// Platform management error, unexpected.
-	// restoreRequired = 37
+	AeadOflow SevFirmwareStatus = 29
+	// Code 0x1E is reserved.
+	// RbModeExited is the code for when the RMP build mode has exited.
+	RbModeExited SevFirmwareStatus = 31
+	// RmpInitRequired is the code for when the RMP must be initialized first.
+	RmpInitRequired SevFirmwareStatus = 32
+	// BadSvn is the code for a firmware SVN that is not allowed.
+	BadSvn SevFirmwareStatus = 33
+	// BadVersion is the code for a firmware version that is not allowed.
+	BadVersion SevFirmwareStatus = 34
+	// ShutdownRequired is the code for when SHUTDOWN must be invoked first.
+	ShutdownRequired SevFirmwareStatus = 35
+	// UpdateFailed is the code for a failed firmware update.
+	UpdateFailed SevFirmwareStatus = 36
+	// RestoreRequired is the code for when a DOWNLOAD_FIRMWARE_EX must be undone first.
+	RestoreRequired SevFirmwareStatus = 37
+	// RmpInitFailed is the code for a failed RMP initialization.
+	RmpInitFailed SevFirmwareStatus = 38
+	// InvalidKey is the code for an invalid key.
+	InvalidKey SevFirmwareStatus = 39
+)
+
+// The ccp driver reports errors from the hypervisor rather than the AMD-SP in the upper 32 bits of
+// the firmware error, as the GHCB specification's SNP guest request exit information does.
+const (
+	// GuestRequestInvalidLength is set by the ccp driver and not the AMD-SP when an guest extended
+	// request provides too few pages for the firmware to populate with data.
+	GuestRequestInvalidLength SevFirmwareStatus = 0x100000000
+	// GuestRequestBusy is set when the hypervisor throttles guest requests.
+	GuestRequestBusy SevFirmwareStatus = 0x200000000
 )
 
-// GuestRequestInvalidLength is set by the ccp driver and not the AMD-SP when an guest extended
-// request provides too few pages for the firmware to populate with data.
-const GuestRequestInvalidLength SevFirmwareStatus = 0x100000000
+var (
+	// ErrThrottled matches errors for guest requests that the host rate-limited, e.g., the kernel's
+	// EAGAIN or a GuestRequestBusy firmware error. Such requests may be retried.
+	ErrThrottled = errors.New("guest request throttled by the host")
+	// ErrVmpckDisabled matches errors for guest requests that cannot be sent because the VMPCK is
+	// disabled, e.g., after a sequence number overflow or a failed message exchange. The VMPCK
+	// stays disabled until the guest reboots.
+	ErrVmpckDisabled = errors.New("VMPCK is disabled")
+	// ErrInvalidLength matches errors for extended guest requests with too small a certificate
+	// buffer.
+	ErrInvalidLength = &SevFirmwareErr{Status: GuestRequestInvalidLength}
+)
 
 // SevFirmwareErr is an error that interprets firmware status codes from the AMD secure processor.
 type SevFirmwareErr struct {
 	Status SevFirmwareStatus
 }
 
+// unexpectedStatusMessages describes the status codes of platform owner and kernel commands.
+var unexpectedStatusMessages = map[SevFirmwareStatus]string{
+	InvalidConfig:      "platform configuration is invalid",
+	AlreadyOwned:       "platform is already owned",
+	InvalidCertificate: "certificate is invalid",
+	BadSignature:       "signature is invalid",
+	BadMeasurement:     "measurement does not match",
+	AsidOwned:          "ASID is already owned",
+	InvalidAsid:        "ASID is invalid",
+	WbinvdRequired:     "WBINVD instruction required",
+	DfFlushRequired:    "DF_FLUSH required",
+	InvalidGuest:       "guest handle is invalid",
+	Active:             "guest is already active",
+	RbModeExited:       "RMP build mode has exited",
+	RmpInitRequired:    "RMP initialization required",
+	BadSvn:             "firmware SVN is not allowed",
+	BadVersion:         "firmware version is not allowed",
+	ShutdownRequired:   "platform shutdown required",
+	UpdateFailed:       "firmware update failed",
+	RestoreRequired:    "firmware restore required",
+	RmpInitFailed:      "RMP initialization failed",
+	InvalidKey:         "key is invalid",
+}
+
+// Is returns true iff target is a *SevFirmwareErr with the same status, or target is ErrThrottled
+// and the status is GuestRequestBusy.
+func (e *SevFirmwareErr) Is(target error) bool {
+	if target == ErrThrottled {
+		return e.Status == GuestRequestBusy
+	}
+	other, ok := target.(*SevFirmwareErr)
+	return ok && other.Status == e.Status
+}
+
 func (e *SevFirmwareErr) Error() string {
 	if e.Status == Success {
 		return "success"
@@ -170,5 +231,11 @@ func (e *SevFirmwareErr) Error() string {
 	if e.Status == GuestRequestInvalidLength {
 		return "too few extended guest request data pages"
 	}
+	if e.Status == GuestRequestBusy {
+		return "host is throttling guest requests"
+	}
+	if msg, ok := unexpectedStatusMessages[e.Status]; ok {
+		return fmt.Sprintf("%s (unexpected for a guest request)", msg)
+	}
 	return fmt.Sprintf("unexpected firmware status (see SEV API spec): %x", uint64(e.Status))
 }
@@ -0,0 +1,525 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Guest messages are defined in Chapter 7 of the SEV SNP API specification
+// https://www.amd.com/system/files/TechDocs/56860.pdf. A guest message is a header followed by a
+// payload that is encrypted with one of the guest's VMPCKs.
+const (
+	// GuestMessageHeaderSize is the ABI size of the guest message header.
+	GuestMessageHeaderSize = 0x60
+	// GuestMessageHeaderVersion is the only HDR_VERSION the firmware accepts.
+	GuestMessageHeaderVersion = 1
+	// GuestMessageMaxSize is the size of the shared page that holds a whole guest message.
+	GuestMessageMaxSize = 0x1000
+	// GuestMessageMaxPayloadSize is the largest payload that fits in a guest message.
+	GuestMessageMaxPayloadSize = GuestMessageMaxSize - GuestMessageHeaderSize
+	// VmpckSize is the size of a VM platform communication key.
+	VmpckSize = 32
+
+	// ReportRequestSize is the ABI size of MSG_REPORT_REQ.
+	ReportRequestSize = 0x60
+	// KeyRequestSize is the ABI size of MSG_KEY_REQ with the LAUNCH_MIT_VECTOR field.
+	KeyRequestSize = 0x28
+	// keyRequestSizeV1 is the ABI size of MSG_KEY_REQ before firmware API version 1.58 added
+	// LAUNCH_MIT_VECTOR.
+	keyRequestSizeV1 = 0x20
+	// keyFieldLaunchMitVector is the GUEST_FIELD_SELECT bit of LAUNCH_MIT_VECTOR.
+	keyFieldLaunchMitVector = 1 << 6
+	// ExportRequestSize is the ABI size of MSG_EXPORT_REQ.
+	ExportRequestSize = 0x10
+
+	msgAuthTagSize      = 0x20
+	msgSeqnoOffset      = 0x20
+	msgAlgoOffset       = 0x30
+	msgHdrVersionOffset = 0x31
+	msgHdrSizeOffset    = 0x32
+	msgTypeOffset       = 0x34
+	msgVersionOffset    = 0x35
+	msgSizeOffset       = 0x36
+	msgVmpckOffset      = 0x3C
+	// The authenticated data is the header from ALGO onwards.
+	msgAADOffset = msgAlgoOffset
+
+	gcmTagSize   = 16
+	gcmNonceSize = 12
+
+	reportResponseHeaderSize = 0x20
+	keyResponseSize          = 0x40
+	exportResponseHeaderSize = 0x20
+)
+
+// GuestMessageType is the MSG_TYPE of a guest message.
+type GuestMessageType uint8
+
+// Message types from Table 100 of the SEV SNP API specification. Each response type is its
+// request type plus one.
+const (
+	MsgCPUIDReq GuestMessageType = iota + 1
+	MsgCPUIDRsp
+	MsgKeyReq
+	MsgKeyRsp
+	MsgReportReq
+	MsgReportRsp
+	MsgExportReq
+	MsgExportRsp
+	MsgImportReq
+	MsgImportRsp
+	MsgAbsorbReq
+	MsgAbsorbRsp
+	MsgVMRKReq
+	MsgVMRKRsp
+)
+
+// GuestMessageHeader represents the header of an SNP guest message.
+type GuestMessageHeader struct {
+	// AuthTag is the AES-GCM authentication tag, of which only the first 16 bytes are used.
+	AuthTag        [msgAuthTagSize]byte
+	SequenceNumber uint64
+	Algo           uint8
+	HeaderVersion  uint8
+	HeaderSize     uint16
+	MessageType    GuestMessageType
+	MessageVersion uint8
+	MessageSize    uint16
+	// Vmpck is the index of the VMPCK the payload is encrypted with.
+	Vmpck uint8
+}
+
+// Marshal returns the ABI representation of the header.
+func (h *GuestMessageHeader) Marshal() []byte {
+	data := make([]byte, GuestMessageHeaderSize)
+	copy(data, h.AuthTag[:])
+	binary.LittleEndian.PutUint64(data[msgSeqnoOffset:], h.SequenceNumber)
+	data[msgAlgoOffset] = h.Algo
+	data[msgHdrVersionOffset] = h.HeaderVersion
+	binary.LittleEndian.PutUint16(data[msgHdrSizeOffset:], h.HeaderSize)
+	data[msgTypeOffset] = byte(h.MessageType)
+	data[msgVersionOffset] = h.MessageVersion
+	binary.LittleEndian.PutUint16(data[msgSizeOffset:], h.MessageSize)
+	data[msgVmpckOffset] = h.Vmpck
+	return data
+}
+
+// ParseGuestMessageHeader returns the header at the start of a guest message and checks that the
+// message is well-formed.
+func ParseGuestMessageHeader(data []byte) (*GuestMessageHeader, error) {
+	if len(data) < GuestMessageHeaderSize {
+		return nil, fmt.Errorf("guest message size %d is smaller than its header size %d", len(data), GuestMessageHeaderSize)
+	}
+	h := &GuestMessageHeader{
+		SequenceNumber: binary.LittleEndian.Uint64(data[msgSeqnoOffset:]),
+		Algo:           data[msgAlgoOffset],
+		HeaderVersion:  data[msgHdrVersionOffset],
+		HeaderSize:     binary.LittleEndian.Uint16(data[msgHdrSizeOffset:]),
+		MessageType:    GuestMessageType(data[msgTypeOffset]),
+		MessageVersion: data[msgVersionOffset],
+		MessageSize:    binary.LittleEndian.Uint16(data[msgSizeOffset:]),
+		Vmpck:          data[msgVmpckOffset],
+	}
+	copy(h.AuthTag[:], data[:msgAuthTagSize])
+	if h.Algo != AeadAes256Gcm {
+		return nil, fmt.Errorf("guest message algorithm %d is not AES-256-GCM (%d)", h.Algo, AeadAes256Gcm)
+	}
+	if h.HeaderVersion != GuestMessageHeaderVersion {
+		return nil, fmt.Errorf("guest message header version %d is not %d", h.HeaderVersion, GuestMessageHeaderVersion)
+	}
+	if h.HeaderSize != GuestMessageHeaderSize {
+		return nil, fmt.Errorf("guest message header size 0x%x is not 0x%x", h.HeaderSize, GuestMessageHeaderSize)
+	}
+	if int(h.MessageSize) != len(data)-GuestMessageHeaderSize {
+		return nil, fmt.Errorf("guest message size %d does not match its payload size %d", h.MessageSize, len(data)-GuestMessageHeaderSize)
+	}
+	return h, nil
+}
+
+func newVmpckAEAD(vmpck []byte) (cipher.AEAD, error) {
+	if len(vmpck) != VmpckSize {
+		return nil, fmt.Errorf("VMPCK size is %d, expected %d", len(vmpck), VmpckSize)
+	}
+	block, err := aes.NewCipher(vmpck)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// gcmNonce returns the IV of a guest message, which is its sequence number zero-extended.
+func gcmNonce(seqno uint64) []byte {
+	nonce := make([]byte, gcmNonceSize)
+	binary.LittleEndian.PutUint64(nonce, seqno)
+	return nonce
+}
+
+func sealGuestMessage(aead cipher.AEAD, h *GuestMessageHeader, payload []byte) ([]byte, error) {
+	if len(payload) > GuestMessageMaxPayloadSize {
+		return nil, fmt.Errorf("guest message payload size %d exceeds %d", len(payload), GuestMessageMaxPayloadSize)
+	}
+	h.Algo = AeadAes256Gcm
+	h.HeaderVersion = GuestMessageHeaderVersion
+	h.HeaderSize = GuestMessageHeaderSize
+	h.MessageSize = uint16(len(payload))
+	h.AuthTag = [msgAuthTagSize]byte{}
+	header := h.Marshal()
+	sealed := aead.Seal(nil, gcmNonce(h.SequenceNumber), payload, header[msgAADOffset:])
+	copy(h.AuthTag[:], sealed[len(payload):])
+	copy(header, h.AuthTag[:])
+	return append(header, sealed[:len(payload)]...), nil
+}
+
+func openGuestMessage(aead cipher.AEAD, msg []byte) (*GuestMessageHeader, []byte, error) {
+	h, err := ParseGuestMessageHeader(msg)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext := append(append([]byte{}, msg[GuestMessageHeaderSize:]...), h.AuthTag[:gcmTagSize]...)
+	payload, err := aead.Open(nil, gcmNonce(h.SequenceNumber), ciphertext, msg[msgAADOffset:GuestMessageHeaderSize])
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not authenticate guest message: %v", err)
+	}
+	return h, payload, nil
+}
+
+// SealGuestMessage returns the guest message that carries payload encrypted with the given VMPCK.
+// The header's Algo, HeaderVersion, HeaderSize, MessageSize, and AuthTag are filled in.
+func SealGuestMessage(vmpck []byte, h *GuestMessageHeader, payload []byte) ([]byte, error) {
+	aead, err := newVmpckAEAD(vmpck)
+	if err != nil {
+		return nil, err
+	}
+	return sealGuestMessage(aead, h, payload)
+}
+
+// OpenGuestMessage authenticates and decrypts a guest message with the given VMPCK. It does not
+// check the sequence number.
+func OpenGuestMessage(vmpck []byte, msg []byte) (*GuestMessageHeader, []byte, error) {
+	aead, err := newVmpckAEAD(vmpck)
+	if err != nil {
+		return nil, nil, err
+	}
+	return openGuestMessage(aead, msg)
+}
+
+// GuestMessageSession wraps requests to and unwraps responses from the AMD-SP with one VMPCK,
+// tracking sequence numbers the way the firmware expects. Each request uses the sequence number
+// one past the last response, and its response must use the request's sequence number plus one.
+// A sequence number is never reused with a different message. A request that gets no response
+// may only be sent again unchanged, e.g., after the host throttled it, and a response that fails
+// its checks disables the session.
+type GuestMessageSession struct {
+	aead    cipher.AEAD
+	vmpck   uint8
+	seqno   uint64
+	pending *GuestMessageHeader
+	// request is the pending request message, resent as-is if the same payload is wrapped again.
+	request []byte
+	payload []byte
+	failed  bool
+}
+
+// NewGuestMessageSession returns a session for the VMPCK with the given key and index. The
+// sequence number is the number of messages already exchanged with the key, 0 for a fresh guest.
+func NewGuestMessageSession(key []byte, vmpck uint8, seqno uint64) (*GuestMessageSession, error) {
+	if vmpck > 3 {
+		return nil, fmt.Errorf("VMPCK index %d must be 0 to 3", vmpck)
+	}
+	aead, err := newVmpckAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	return &GuestMessageSession{aead: aead, vmpck: vmpck, seqno: seqno}, nil
+}
+
+// SequenceNumber returns the sequence number of the last message the session wrapped or unwrapped.
+func (s *GuestMessageSession) SequenceNumber() uint64 { return s.seqno }
+
+// Wrap returns the request message that carries payload with the given type and version. If the
+// previous request has no response yet, only that same request may be wrapped again, and the
+// result is the identical message.
+func (s *GuestMessageSession) Wrap(msgType GuestMessageType, msgVersion uint8, payload []byte) ([]byte, error) {
+	if s.failed {
+		return nil, fmt.Errorf("%w: guest message session failed a response check", ErrVmpckDisabled)
+	}
+	if s.pending != nil {
+		if s.pending.MessageType == msgType && s.pending.MessageVersion == msgVersion && bytes.Equal(s.payload, payload) {
+			return s.request, nil
+		}
+		return nil, fmt.Errorf("%w: guest message request with sequence number %d has no response",
+			ErrVmpckDisabled, s.pending.SequenceNumber)
+	}
+	// The response takes the sequence number after the request's.
+	if s.seqno >= math.MaxUint64-1 {
+		return nil, fmt.Errorf("%w: guest message sequence numbers are exhausted", ErrVmpckDisabled)
+	}
+	h := &GuestMessageHeader{
+		SequenceNumber: s.seqno + 1,
+		MessageType:    msgType,
+		MessageVersion: msgVersion,
+		Vmpck:          s.vmpck,
+	}
+	msg, err := sealGuestMessage(s.aead, h, payload)
+	if err != nil {
+		return nil, err
+	}
+	s.seqno = h.SequenceNumber + 1
+	s.pending = h
+	s.request = msg
+	s.payload = append([]byte{}, payload...)
+	return msg, nil
+}
+
+// Unwrap returns the payload of the response to the last wrapped request. Any failure disables the
+// session, since the firmware's sequence number can no longer be known.
+func (s *GuestMessageSession) Unwrap(msg []byte) ([]byte, error) {
+	if s.pending == nil {
+		return nil, errors.New("no guest message request awaits a response")
+	}
+	req := s.pending
+	s.pending = nil
+	s.request = nil
+	s.payload = nil
+	h, payload, err := openGuestMessage(s.aead, msg)
+	if err == nil {
+		err = checkResponseHeader(req, h)
+	}
+	if err != nil {
+		s.failed = true
+		return nil, err
+	}
+	return payload, nil
+}
+
+func checkResponseHeader(req, rsp *GuestMessageHeader) error {
+	if rsp.SequenceNumber != req.SequenceNumber+1 {
+		return fmt.Errorf("response sequence number %d is not the request's %d plus one", rsp.SequenceNumber, req.SequenceNumber)
+	}
+	if rsp.MessageType != req.MessageType+1 {
+		return fmt.Errorf("response message type %d does not answer request type %d", rsp.MessageType, req.MessageType)
+	}
+	if rsp.MessageVersion != req.MessageVersion {
+		return fmt.Errorf("response message version %d is not the request's %d", rsp.MessageVersion, req.MessageVersion)
+	}
+	if rsp.Vmpck != req.Vmpck {
+		return fmt.Errorf("response VMPCK %d is not the request's %d", rsp.Vmpck, req.Vmpck)
+	}
+	return nil
+}
+
+// ReportRequest represents the MSG_REPORT_REQ payload.
+type ReportRequest struct {
+	ReportData [ReportDataSize]byte
+	Vmpl       uint32
+}
+
+// Marshal returns the ABI representation of the request.
+func (r *ReportRequest) Marshal() []byte {
+	data := make([]byte, ReportRequestSize)
+	copy(data, r.ReportData[:])
+	binary.LittleEndian.PutUint32(data[0x40:], r.Vmpl)
+	return data
+}
+
+// ParseReportRequest returns the MSG_REPORT_REQ payload in data.
+func ParseReportRequest(data []byte) (*ReportRequest, error) {
+	if len(data) != ReportRequestSize {
+		return nil, fmt.Errorf("MSG_REPORT_REQ size is %d, expected %d", len(data), ReportRequestSize)
+	}
+	if err := mbz(data, 0x44, ReportRequestSize); err != nil {
+		return nil, err
+	}
+	r := &ReportRequest{Vmpl: binary.LittleEndian.Uint32(data[0x40:])}
+	copy(r.ReportData[:], data)
+	return r, nil
+}
+
+// ReportResponse represents the MSG_REPORT_RSP payload.
+type ReportResponse struct {
+	Status uint32
+	// Report is the attestation report, empty unless Status is 0.
+	Report []byte
+}
+
+// Marshal returns the ABI representation of the response.
+func (r *ReportResponse) Marshal() []byte {
+	data := make([]byte, reportResponseHeaderSize+len(r.Report))
+	binary.LittleEndian.PutUint32(data[0x00:], r.Status)
+	binary.LittleEndian.PutUint32(data[0x04:], uint32(len(r.Report)))
+	copy(data[reportResponseHeaderSize:], r.Report)
+	return data
+}
+
+// ParseReportResponse returns the MSG_REPORT_RSP payload in data.
+func ParseReportResponse(data []byte) (*ReportResponse, error) {
+	if len(data) < reportResponseHeaderSize {
+		return nil, fmt.Errorf("MSG_REPORT_RSP size %d is smaller than %d", len(data), reportResponseHeaderSize)
+	}
+	r := &ReportResponse{Status: binary.LittleEndian.Uint32(data[0x00:])}
+	size := binary.LittleEndian.Uint32(data[0x04:])
+	if uint64(size) > uint64(len(data)-reportResponseHeaderSize) {
+		return nil, fmt.Errorf("MSG_REPORT_RSP REPORT_SIZE %d exceeds its payload", size)
+	}
+	r.Report = data[reportResponseHeaderSize : reportResponseHeaderSize+int(size)]
+	return r, nil
+}
+
+// KeyRequest represents the MSG_KEY_REQ payload.
+type KeyRequest struct {
+	// RootKeySelect is 0 to derive from the VCEK and 1 to derive from the VMRK in bit 0. Bits 2:1
+	// are KEY_SEL, which selects between the VCEK and the VLEK.
+	RootKeySelect    uint32
+	GuestFieldSelect uint64
+	Vmpl             uint32
+	GuestSVN         uint32
+	TCBVersion       uint64
+	LaunchMitVector  uint64
+}
+
+// Marshal returns the ABI representation of the request. Requests that do not use
+// LAUNCH_MIT_VECTOR have the shorter layout that all firmware accepts.
+func (r *KeyRequest) Marshal() []byte {
+	size := keyRequestSizeV1
+	if r.GuestFieldSelect&keyFieldLaunchMitVector != 0 || r.LaunchMitVector != 0 {
+		size = KeyRequestSize
+	}
+	data := make([]byte, size)
+	binary.LittleEndian.PutUint32(data[0x00:], r.RootKeySelect)
+	binary.LittleEndian.PutUint64(data[0x08:], r.GuestFieldSelect)
+	binary.LittleEndian.PutUint32(data[0x10:], r.Vmpl)
+	binary.LittleEndian.PutUint32(data[0x14:], r.GuestSVN)
+	binary.LittleEndian.PutUint64(data[0x18:], r.TCBVersion)
+	if size == KeyRequestSize {
+		binary.LittleEndian.PutUint64(data[0x20:], r.LaunchMitVector)
+	}
+	return data
+}
+
+// ParseKeyRequest returns the MSG_KEY_REQ payload in data, in either the layout with or without
+// LAUNCH_MIT_VECTOR.
+func ParseKeyRequest(data []byte) (*KeyRequest, error) {
+	if len(data) != KeyRequestSize && len(data) != keyRequestSizeV1 {
+		return nil, fmt.Errorf("MSG_KEY_REQ size is %d, expected %d or %d", len(data), keyRequestSizeV1, KeyRequestSize)
+	}
+	if err := mbz(data, 0x04, 0x08); err != nil {
+		return nil, err
+	}
+	r := &KeyRequest{
+		RootKeySelect:    binary.LittleEndian.Uint32(data[0x00:]),
+		GuestFieldSelect: binary.LittleEndian.Uint64(data[0x08:]),
+		Vmpl:             binary.LittleEndian.Uint32(data[0x10:]),
+		GuestSVN:         binary.LittleEndian.Uint32(data[0x14:]),
+		TCBVersion:       binary.LittleEndian.Uint64(data[0x18:]),
+	}
+	if len(data) == KeyRequestSize {
+		r.LaunchMitVector = binary.LittleEndian.Uint64(data[0x20:])
+	} else if r.GuestFieldSelect&keyFieldLaunchMitVector != 0 {
+		return nil, fmt.Errorf("MSG_KEY_REQ of size %d selects LAUNCH_MIT_VECTOR but does not have it", len(data))
+	}
+	return r, nil
+}
+
+// KeyResponse represents the MSG_KEY_RSP payload.
+type KeyResponse struct {
+	Status     uint32
+	DerivedKey [32]byte
+}
+
+// Marshal returns the ABI representation of the response.
+func (r *KeyResponse) Marshal() []byte {
+	data := make([]byte, keyResponseSize)
+	binary.LittleEndian.PutUint32(data[0x00:], r.Status)
+	copy(data[0x20:], r.DerivedKey[:])
+	return data
+}
+
+// ParseKeyResponse returns the MSG_KEY_RSP payload in data.
+func ParseKeyResponse(data []byte) (*KeyResponse, error) {
+	if len(data) != keyResponseSize {
+		return nil, fmt.Errorf("MSG_KEY_RSP size is %d, expected %d", len(data), keyResponseSize)
+	}
+	r := &KeyResponse{Status: binary.LittleEndian.Uint32(data[0x00:])}
+	copy(r.DerivedKey[:], data[0x20:])
+	return r, nil
+}
+
+// ExportRequest represents the MSG_EXPORT_REQ payload, with which a migration agent asks the
+// firmware to export a guest page.
+type ExportRequest struct {
+	GPA uint64
+	// IMIEn requests that the page be exported for intra-machine migration.
+	IMIEn bool
+}
+
+// Marshal returns the ABI representation of the request.
+func (r *ExportRequest) Marshal() []byte {
+	data := make([]byte, ExportRequestSize)
+	binary.LittleEndian.PutUint64(data[0x00:], r.GPA)
+	if r.IMIEn {
+		data[0x08] = 1
+	}
+	return data
+}
+
+// ParseExportRequest returns the MSG_EXPORT_REQ payload in data.
+func ParseExportRequest(data []byte) (*ExportRequest, error) {
+	if len(data) != ExportRequestSize {
+		return nil, fmt.Errorf("MSG_EXPORT_REQ size is %d, expected %d", len(data), ExportRequestSize)
+	}
+	if data[0x08]&^1 != 0 {
+		return nil, fmt.Errorf("MSG_EXPORT_REQ reserved bits of 0x%x are set", data[0x08])
+	}
+	if err := mbz(data, 0x09, ExportRequestSize); err != nil {
+		return nil, err
+	}
+	return &ExportRequest{GPA: binary.LittleEndian.Uint64(data[0x00:]), IMIEn: data[0x08] == 1}, nil
+}
+
+// ExportResponse represents the MSG_EXPORT_RSP payload.
+type ExportResponse struct {
+	GPA    uint64
+	Status uint32
+	// Page is the remainder of the response that describes the exported page.
+	Page []byte
+}
+
+// Marshal returns the ABI representation of the response.
+func (r *ExportResponse) Marshal() []byte {
+	data := make([]byte, exportResponseHeaderSize+len(r.Page))
+	binary.LittleEndian.PutUint64(data[0x00:], r.GPA)
+	binary.LittleEndian.PutUint32(data[0x08:], r.Status)
+	copy(data[exportResponseHeaderSize:], r.Page)
+	return data
+}
+
+// ParseExportResponse returns the MSG_EXPORT_RSP payload in data.
+func ParseExportResponse(data []byte) (*ExportResponse, error) {
+	if len(data) < exportResponseHeaderSize {
+		return nil, fmt.Errorf("MSG_EXPORT_RSP size %d is smaller than %d", len(data), exportResponseHeaderSize)
+	}
+	return &ExportResponse{
+		GPA:    binary.LittleEndian.Uint64(data[0x00:]),
+		Status: binary.LittleEndian.Uint32(data[0x08:]),
+		Page:   data[exportResponseHeaderSize:],
+	}, nil
+}
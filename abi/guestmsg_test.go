@@ -0,0 +1,210 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var testVmpck = bytes.Repeat([]byte{0x42}, VmpckSize)
+
+// respond plays the firmware's part for a request wrapped by a session.
+func respond(t *testing.T, request []byte, modify func(h *GuestMessageHeader), payload []byte) []byte {
+	t.Helper()
+	h, _, err := OpenGuestMessage(testVmpck, request)
+	if err != nil {
+		t.Fatalf("OpenGuestMessage(request) = _, _, %v, want nil", err)
+	}
+	rsp := &GuestMessageHeader{
+		SequenceNumber: h.SequenceNumber + 1,
+		MessageType:    h.MessageType + 1,
+		MessageVersion: h.MessageVersion,
+		Vmpck:          h.Vmpck,
+	}
+	if modify != nil {
+		modify(rsp)
+	}
+	msg, err := SealGuestMessage(testVmpck, rsp, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func TestGuestMessageSealOpen(t *testing.T) {
+	payload := (&ReportRequest{ReportData: [64]byte{1, 2, 3}, Vmpl: 2}).Marshal()
+	msg, err := SealGuestMessage(testVmpck, &GuestMessageHeader{SequenceNumber: 7, MessageType: MsgReportReq, MessageVersion: 1, Vmpck: 1}, payload)
+	if err != nil {
+		t.Fatalf("SealGuestMessage() = _, %v, want nil", err)
+	}
+	if len(msg) != GuestMessageHeaderSize+ReportRequestSize {
+		t.Errorf("sealed message size is %d, want %d", len(msg), GuestMessageHeaderSize+ReportRequestSize)
+	}
+	if bytes.Contains(msg, payload[:3]) {
+		t.Error("sealed message holds the plaintext payload")
+	}
+	h, got, err := OpenGuestMessage(testVmpck, msg)
+	if err != nil {
+		t.Fatalf("OpenGuestMessage() = _, _, %v, want nil", err)
+	}
+	if h.SequenceNumber != 7 || h.MessageType != MsgReportReq || h.Vmpck != 1 || h.MessageSize != ReportRequestSize {
+		t.Errorf("OpenGuestMessage() header = %+v, want sequence 7 type %d VMPCK 1 size %d", h, MsgReportReq, ReportRequestSize)
+	}
+	req, err := ParseReportRequest(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Vmpl != 2 || req.ReportData[2] != 3 {
+		t.Errorf("ParseReportRequest() = %+v, want the sealed request", req)
+	}
+
+	tamper := []struct {
+		name   string
+		offset int
+	}{
+		{name: "tag", offset: 0},
+		{name: "sequence number", offset: msgSeqnoOffset},
+		{name: "vmpck", offset: msgVmpckOffset},
+		{name: "payload", offset: GuestMessageHeaderSize},
+	}
+	for _, tc := range tamper {
+		bad := append([]byte{}, msg...)
+		bad[tc.offset] ^= 1
+		if _, _, err := OpenGuestMessage(testVmpck, bad); err == nil || !strings.Contains(err.Error(), "authenticate") {
+			t.Errorf("OpenGuestMessage(%s tampered) = _, _, %v, want authentication error", tc.name, err)
+		}
+	}
+	if _, _, err := OpenGuestMessage(testVmpck, msg[:len(msg)-1]); err == nil {
+		t.Error("OpenGuestMessage(truncated) = _, _, nil, want error")
+	}
+	if _, err := SealGuestMessage(testVmpck[1:], &GuestMessageHeader{}, nil); err == nil {
+		t.Error("SealGuestMessage(short key) = _, nil, want error")
+	}
+}
+
+func TestGuestMessageSession(t *testing.T) {
+	s, err := NewGuestMessageSession(testVmpck, 0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := &KeyResponse{DerivedKey: [32]byte{0xaa}}
+	for i := 0; i < 2; i++ {
+		req, err := s.Wrap(MsgKeyReq, 1, (&KeyRequest{GuestFieldSelect: 1}).Marshal())
+		if err != nil {
+			t.Fatalf("Wrap() = _, %v, want nil", err)
+		}
+		h, _ := ParseGuestMessageHeader(req)
+		if want := uint64(5 + 2*i); h.SequenceNumber != want {
+			t.Errorf("request %d sequence number is %d, want %d", i, h.SequenceNumber, want)
+		}
+		payload, err := s.Unwrap(respond(t, req, nil, key.Marshal()))
+		if err != nil {
+			t.Fatalf("Unwrap() = _, %v, want nil", err)
+		}
+		got, err := ParseKeyResponse(payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(key, got); diff != "" {
+			t.Errorf("ParseKeyResponse() returned unexpected diff (-want +got):\n%s", diff)
+		}
+	}
+	if s.SequenceNumber() != 8 {
+		t.Errorf("SequenceNumber() = %d, want 8", s.SequenceNumber())
+	}
+
+	if _, err := s.Unwrap(nil); err == nil {
+		t.Error("Unwrap() without a request = _, nil, want error")
+	}
+	first, err := s.Wrap(MsgReportReq, 1, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again, err := s.Wrap(MsgReportReq, 1, nil); err != nil || !bytes.Equal(again, first) {
+		t.Errorf("Wrap(same request) = %v, %v, want the pending message %v", again, err, first)
+	}
+	if _, err := s.Wrap(MsgReportReq, 1, []byte{1}); !errors.Is(err, ErrVmpckDisabled) || !strings.Contains(err.Error(), "no response") {
+		t.Errorf("Wrap(other request) with a pending request = _, %v, want no response error", err)
+	}
+
+	bad := []struct {
+		name    string
+		modify  func(h *GuestMessageHeader)
+		wantErr string
+	}{
+		{name: "replay", modify: func(h *GuestMessageHeader) { h.SequenceNumber -= 2 }, wantErr: "sequence number"},
+		{name: "type", modify: func(h *GuestMessageHeader) { h.MessageType = MsgKeyRsp }, wantErr: "message type"},
+		{name: "vmpck", modify: func(h *GuestMessageHeader) { h.Vmpck = 1 }, wantErr: "VMPCK"},
+	}
+	for _, tc := range bad {
+		s, _ := NewGuestMessageSession(testVmpck, 0, 0)
+		req, err := s.Wrap(MsgReportReq, 1, (&ReportRequest{}).Marshal())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.Unwrap(respond(t, req, tc.modify, nil)); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: Unwrap() = _, %v, want %q", tc.name, err, tc.wantErr)
+		}
+		if _, err := s.Wrap(MsgReportReq, 1, nil); err == nil || !strings.Contains(err.Error(), "disabled") {
+			t.Errorf("%s: Wrap() after failure = _, %v, want disabled error", tc.name, err)
+		}
+	}
+	if _, err := NewGuestMessageSession(testVmpck, 4, 0); err == nil {
+		t.Error("NewGuestMessageSession(VMPCK4) = _, nil, want error")
+	}
+}
+
+func TestGuestMessagePayloads(t *testing.T) {
+	export := &ExportRequest{GPA: 0x1000, IMIEn: true}
+	if got, err := ParseExportRequest(export.Marshal()); err != nil || *got != *export {
+		t.Errorf("ParseExportRequest(Marshal()) = %+v, %v, want %+v, nil", got, err, export)
+	}
+	exportRsp := &ExportResponse{GPA: 0x1000, Status: 0x16, Page: []byte{1, 2}}
+	if got, err := ParseExportResponse(exportRsp.Marshal()); err != nil || !cmp.Equal(got, exportRsp) {
+		t.Errorf("ParseExportResponse(Marshal()) = %+v, %v, want %+v, nil", got, err, exportRsp)
+	}
+	keyReq := &KeyRequest{RootKeySelect: 1, GuestFieldSelect: 0x3f, Vmpl: 1, GuestSVN: 2, TCBVersion: 3}
+	if got, err := ParseKeyRequest(keyReq.Marshal()); err != nil || *got != *keyReq {
+		t.Errorf("ParseKeyRequest(Marshal()) = %+v, %v, want %+v, nil", got, err, keyReq)
+	}
+	if size := len(keyReq.Marshal()); size != keyRequestSizeV1 {
+		t.Errorf("len(Marshal()) = %d without LAUNCH_MIT_VECTOR, want %d", size, keyRequestSizeV1)
+	}
+	mitKeyReq := &KeyRequest{RootKeySelect: 4, GuestFieldSelect: 0x7f, LaunchMitVector: 5}
+	if got, err := ParseKeyRequest(mitKeyReq.Marshal()); err != nil || *got != *mitKeyReq || len(mitKeyReq.Marshal()) != KeyRequestSize {
+		t.Errorf("ParseKeyRequest(Marshal()) = %+v, %v, want %+v, nil from %d bytes", got, err, mitKeyReq, KeyRequestSize)
+	}
+	if _, err := ParseKeyRequest(mitKeyReq.Marshal()[:keyRequestSizeV1]); err == nil {
+		t.Error("ParseKeyRequest(short request selecting LAUNCH_MIT_VECTOR) = _, nil, want error")
+	}
+	report := &ReportResponse{Report: make([]byte, ReportSize)}
+	if got, err := ParseReportResponse(report.Marshal()); err != nil || len(got.Report) != ReportSize {
+		t.Errorf("ParseReportResponse(Marshal()) = %+v, %v, want a %d byte report", got, err, ReportSize)
+	}
+	truncated := report.Marshal()[:reportResponseHeaderSize+1]
+	if _, err := ParseReportResponse(truncated); err == nil {
+		t.Error("ParseReportResponse(truncated) = _, nil, want error")
+	}
+	reserved := (&ReportRequest{}).Marshal()
+	reserved[0x50] = 1
+	if _, err := ParseReportRequest(reserved); err == nil {
+		t.Error("ParseReportRequest(reserved set) = _, nil, want error")
+	}
+}
@@ -0,0 +1,266 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// The ID_BLOCK and ID_AUTH_INFO structures are defined in Tables 74 and 75 of the SEV SNP API
+// specification https://www.amd.com/system/files/TechDocs/56860.pdf for SNP_LAUNCH_FINISH.
+const (
+	// IDBlockSize is the ABI size of the ID_BLOCK structure.
+	IDBlockSize = 0x60
+	// IDBlockVersion is the only VERSION value of an ID_BLOCK that the firmware accepts.
+	IDBlockVersion = 1
+	// IDAuthInfoSize is the ABI size of the ID_AUTH_INFO structure.
+	IDAuthInfoSize = 0x1000
+
+	idBlockFamilyIDOffset = 0x30
+	idBlockImageIDOffset  = 0x40
+	idBlockVersionOffset  = 0x50
+	idBlockGuestSvnOffset = 0x54
+	idBlockPolicyOffset   = 0x58
+
+	idAuthIDKeyAlgoOffset     = 0x00
+	idAuthAuthorKeyAlgoOffset = 0x04
+	idAuthIDBlockSigOffset    = 0x40
+	idAuthIDKeyOffset         = 0x240
+	idAuthAuthorKeySigOffset  = 0x680
+	idAuthAuthorKeyOffset     = 0x880
+	idAuthAuthorKeyEnd        = 0xC84
+)
+
+// IDBlock represents the ID_BLOCK structure the guest owner provides at launch to have the
+// firmware check the launch digest and to bind the FAMILY_ID and IMAGE_ID to the guest.
+type IDBlock struct {
+	// LD is the expected launch digest, i.e., the report's MEASUREMENT.
+	LD       [MeasurementSize]byte
+	FamilyID [FamilyIDSize]byte
+	ImageID  [ImageIDSize]byte
+	// Version must be IDBlockVersion.
+	Version  uint32
+	GuestSvn uint32
+	// Policy must equal the guest policy given at launch.
+	Policy uint64
+}
+
+// IDAuthInfo represents the ID_AUTH_INFO structure that authenticates an ID_BLOCK with an ID key
+// and optionally authenticates the ID key with an author key.
+type IDAuthInfo struct {
+	IDKeyAlgo     uint32
+	AuthorKeyAlgo uint32
+	// IDBlockSig is the ID key's signature over the ID_BLOCK in AMD ABI format.
+	IDBlockSig [SignatureSize]byte
+	// IDKey is the ID public key in AMD ABI format.
+	IDKey [EcsdaPublicKeySize]byte
+	// AuthorKeySig is the author key's signature over IDKey in AMD ABI format. All zeros if there is
+	// no author key.
+	AuthorKeySig [SignatureSize]byte
+	// AuthorKey is the author public key in AMD ABI format. All zeros if there is no author key.
+	AuthorKey [EcsdaPublicKeySize]byte
+}
+
+// Marshal returns the ABI format of the ID_BLOCK.
+func (b *IDBlock) Marshal() []byte {
+	data := make([]byte, IDBlockSize)
+	copy(data[0:idBlockFamilyIDOffset], b.LD[:])
+	copy(data[idBlockFamilyIDOffset:idBlockImageIDOffset], b.FamilyID[:])
+	copy(data[idBlockImageIDOffset:idBlockVersionOffset], b.ImageID[:])
+	binary.LittleEndian.PutUint32(data[idBlockVersionOffset:idBlockGuestSvnOffset], b.Version)
+	binary.LittleEndian.PutUint32(data[idBlockGuestSvnOffset:idBlockPolicyOffset], b.GuestSvn)
+	binary.LittleEndian.PutUint64(data[idBlockPolicyOffset:IDBlockSize], b.Policy)
+	return data
+}
+
+// ParseIDBlock returns the ID_BLOCK represented by its ABI format in data.
+func ParseIDBlock(data []byte) (*IDBlock, error) {
+	if len(data) != IDBlockSize {
+		return nil, fmt.Errorf("ID_BLOCK size is 0x%x, expected 0x%x", len(data), IDBlockSize)
+	}
+	b := &IDBlock{
+		Version:  binary.LittleEndian.Uint32(data[idBlockVersionOffset:idBlockGuestSvnOffset]),
+		GuestSvn: binary.LittleEndian.Uint32(data[idBlockGuestSvnOffset:idBlockPolicyOffset]),
+		Policy:   binary.LittleEndian.Uint64(data[idBlockPolicyOffset:IDBlockSize]),
+	}
+	copy(b.LD[:], data[0:idBlockFamilyIDOffset])
+	copy(b.FamilyID[:], data[idBlockFamilyIDOffset:idBlockImageIDOffset])
+	copy(b.ImageID[:], data[idBlockImageIDOffset:idBlockVersionOffset])
+	if b.Version != IDBlockVersion {
+		return nil, fmt.Errorf("ID_BLOCK version is %d, expected %d", b.Version, IDBlockVersion)
+	}
+	return b, nil
+}
+
+// Marshal returns the ABI format of the ID_AUTH_INFO.
+func (a *IDAuthInfo) Marshal() []byte {
+	data := make([]byte, IDAuthInfoSize)
+	binary.LittleEndian.PutUint32(data[idAuthIDKeyAlgoOffset:idAuthAuthorKeyAlgoOffset], a.IDKeyAlgo)
+	binary.LittleEndian.PutUint32(data[idAuthAuthorKeyAlgoOffset:0x08], a.AuthorKeyAlgo)
+	copy(data[idAuthIDBlockSigOffset:idAuthIDKeyOffset], a.IDBlockSig[:])
+	copy(data[idAuthIDKeyOffset:idAuthIDKeyOffset+EcsdaPublicKeySize], a.IDKey[:])
+	copy(data[idAuthAuthorKeySigOffset:idAuthAuthorKeyOffset], a.AuthorKeySig[:])
+	copy(data[idAuthAuthorKeyOffset:idAuthAuthorKeyEnd], a.AuthorKey[:])
+	return data
+}
+
+// ParseIDAuthInfo returns the ID_AUTH_INFO represented by its ABI format in data.
+func ParseIDAuthInfo(data []byte) (*IDAuthInfo, error) {
+	if len(data) != IDAuthInfoSize {
+		return nil, fmt.Errorf("ID_AUTH_INFO size is 0x%x, expected 0x%x", len(data), IDAuthInfoSize)
+	}
+	if err := mbz(data, 0x08, idAuthIDBlockSigOffset); err != nil {
+		return nil, err
+	}
+	if err := mbz(data, idAuthIDKeyOffset+EcsdaPublicKeySize, idAuthAuthorKeySigOffset); err != nil {
+		return nil, err
+	}
+	if err := mbz(data, idAuthAuthorKeyEnd, IDAuthInfoSize); err != nil {
+		return nil, err
+	}
+	a := &IDAuthInfo{
+		IDKeyAlgo:     binary.LittleEndian.Uint32(data[idAuthIDKeyAlgoOffset:idAuthAuthorKeyAlgoOffset]),
+		AuthorKeyAlgo: binary.LittleEndian.Uint32(data[idAuthAuthorKeyAlgoOffset:0x08]),
+	}
+	copy(a.IDBlockSig[:], data[idAuthIDBlockSigOffset:idAuthIDKeyOffset])
+	copy(a.IDKey[:], data[idAuthIDKeyOffset:idAuthIDKeyOffset+EcsdaPublicKeySize])
+	copy(a.AuthorKeySig[:], data[idAuthAuthorKeySigOffset:idAuthAuthorKeyOffset])
+	copy(a.AuthorKey[:], data[idAuthAuthorKeyOffset:idAuthAuthorKeyEnd])
+	return a, nil
+}
+
+// HasAuthorKey returns whether the ID_AUTH_INFO authenticates its ID key with an author key.
+func (a *IDAuthInfo) HasAuthorKey() bool {
+	return a.AuthorKeyAlgo != 0 || findNonZero(a.AuthorKey[:], 0, EcsdaPublicKeySize) != EcsdaPublicKeySize
+}
+
+// IDKeyDigest returns the SHA-384 digest of the ID key, as the report's ID_KEY_DIGEST shows.
+func (a *IDAuthInfo) IDKeyDigest() []byte {
+	digest := sha512.Sum384(a.IDKey[:])
+	return digest[:]
+}
+
+// AuthorKeyDigest returns the SHA-384 digest of the author key, as the report's AUTHOR_KEY_DIGEST
+// shows.
+func (a *IDAuthInfo) AuthorKeyDigest() []byte {
+	digest := sha512.Sum384(a.AuthorKey[:])
+	return digest[:]
+}
+
+// EcdsaPublicKeyFromBytes returns the ECDSA P-384 public key represented by its AMD SEV ABI format.
+func EcdsaPublicKeyFromBytes(data []byte) (*ecdsa.PublicKey, error) {
+	if len(data) != EcsdaPublicKeySize {
+		return nil, fmt.Errorf("public key size is 0x%x, expected 0x%x", len(data), EcsdaPublicKeySize)
+	}
+	if curve := binary.LittleEndian.Uint32(data[0:ecdsaQXoffset]); curve != EccP384 {
+		return nil, fmt.Errorf("public key curve is %d, expected %d (P-384)", curve, EccP384)
+	}
+	if err := mbz(data, ecdsaQYend, EcsdaPublicKeySize); err != nil {
+		return nil, err
+	}
+	key := &ecdsa.PublicKey{
+		Curve: elliptic.P384(),
+		X:     AmdBigInt(data[ecdsaQXoffset:ecdsaQYoffset]),
+		Y:     AmdBigInt(data[ecdsaQYoffset:ecdsaQYend]),
+	}
+	if !key.Curve.IsOnCurve(key.X, key.Y) {
+		return nil, errors.New("public key is not a point on P-384")
+	}
+	return key, nil
+}
+
+// signEcdsaP384Sha384 returns the ECDSA-P384-SHA384 signature of data in AMD ABI format.
+func signEcdsaP384Sha384(key *ecdsa.PrivateKey, data []byte) ([SignatureSize]byte, error) {
+	var result [SignatureSize]byte
+	digest := sha512.Sum384(data)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		return result, err
+	}
+	copy(ecdsaGetR(result[:]), bigIntToAMDRS(r))
+	copy(ecdsaGetS(result[:]), bigIntToAMDRS(s))
+	return result, nil
+}
+
+// verifyEcdsaP384Sha384 checks the ECDSA-P384-SHA384 signature in AMD ABI format of data against
+// the public key in AMD ABI format.
+func verifyEcdsaP384Sha384(key []byte, signature []byte, data []byte) error {
+	pub, err := EcdsaPublicKeyFromBytes(key)
+	if err != nil {
+		return err
+	}
+	digest := sha512.Sum384(data)
+	if !ecdsa.Verify(pub, digest[:], AmdBigInt(ecdsaGetR(signature)), AmdBigInt(ecdsaGetS(signature))) {
+		return errors.New("signature is invalid")
+	}
+	return nil
+}
+
+// SignIDBlock returns the ID_AUTH_INFO that authenticates the ID block with idKey, and idKey with
+// authorKey if authorKey is not nil. Both keys must be on curve P-384.
+func SignIDBlock(block *IDBlock, idKey, authorKey *ecdsa.PrivateKey) (*IDAuthInfo, error) {
+	if idKey == nil {
+		return nil, errors.New("ID key cannot be nil")
+	}
+	auth := &IDAuthInfo{IDKeyAlgo: SignEcdsaP384Sha384}
+	idPub, err := EcdsaPublicKeyToBytes(&idKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("bad ID key: %v", err)
+	}
+	copy(auth.IDKey[:], idPub)
+	if auth.IDBlockSig, err = signEcdsaP384Sha384(idKey, block.Marshal()); err != nil {
+		return nil, fmt.Errorf("could not sign ID block: %v", err)
+	}
+	if authorKey == nil {
+		return auth, nil
+	}
+	authorPub, err := EcdsaPublicKeyToBytes(&authorKey.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("bad author key: %v", err)
+	}
+	auth.AuthorKeyAlgo = SignEcdsaP384Sha384
+	copy(auth.AuthorKey[:], authorPub)
+	if auth.AuthorKeySig, err = signEcdsaP384Sha384(authorKey, auth.IDKey[:]); err != nil {
+		return nil, fmt.Errorf("could not sign ID key: %v", err)
+	}
+	return auth, nil
+}
+
+// VerifyIDBlock checks that the ID_AUTH_INFO's ID key signed the ID block and, if present, that
+// its author key signed the ID key.
+func VerifyIDBlock(block *IDBlock, auth *IDAuthInfo) error {
+	if auth.IDKeyAlgo != SignEcdsaP384Sha384 {
+		return fmt.Errorf("unknown ID key algorithm %d", auth.IDKeyAlgo)
+	}
+	if err := verifyEcdsaP384Sha384(auth.IDKey[:], auth.IDBlockSig[:], block.Marshal()); err != nil {
+		return fmt.Errorf("ID block signature: %v", err)
+	}
+	if !auth.HasAuthorKey() {
+		return nil
+	}
+	if auth.AuthorKeyAlgo != SignEcdsaP384Sha384 {
+		return fmt.Errorf("unknown author key algorithm %d", auth.AuthorKeyAlgo)
+	}
+	if err := verifyEcdsaP384Sha384(auth.AuthorKey[:], auth.AuthorKeySig[:], auth.IDKey[:]); err != nil {
+		return fmt.Errorf("ID key signature: %v", err)
+	}
+	return nil
+}
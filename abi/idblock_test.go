@@ -0,0 +1,135 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+func TestIDBlock(t *testing.T) {
+	idKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256Key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &IDBlock{Version: IDBlockVersion, GuestSvn: 3, Policy: 0x30000}
+	block.LD[0] = 0x11
+	block.FamilyID[0] = 0x22
+	block.ImageID[0] = 0x33
+
+	data := block.Marshal()
+	if len(data) != IDBlockSize {
+		t.Fatalf("IDBlock.Marshal() size is 0x%x, want 0x%x", len(data), IDBlockSize)
+	}
+	got, err := ParseIDBlock(data)
+	if err != nil {
+		t.Fatalf("ParseIDBlock(%v) = _, %v, want nil", data, err)
+	}
+	if *got != *block {
+		t.Errorf("ParseIDBlock(Marshal(%+v)) = %+v", block, got)
+	}
+	data[idBlockVersionOffset] = 2
+	if _, err := ParseIDBlock(data); err == nil || !strings.Contains(err.Error(), "version is 2") {
+		t.Errorf("ParseIDBlock(version 2) = _, %v, want version error", err)
+	}
+
+	tcs := []struct {
+		name       string
+		authorKey  *ecdsa.PrivateKey
+		modify     func(a *IDAuthInfo)
+		wantAuthor bool
+		wantErr    string
+	}{
+		{name: "id key only"},
+		{name: "author key", authorKey: authorKey, wantAuthor: true},
+		{
+			name:    "bad id block signature",
+			modify:  func(a *IDAuthInfo) { a.IDBlockSig[0] ^= 1 },
+			wantErr: "ID block signature: signature is invalid",
+		},
+		{
+			name:       "bad author signature",
+			authorKey:  authorKey,
+			modify:     func(a *IDAuthInfo) { a.AuthorKeySig[0] ^= 1 },
+			wantAuthor: true,
+			wantErr:    "ID key signature: signature is invalid",
+		},
+		{
+			name:    "bad algorithm",
+			modify:  func(a *IDAuthInfo) { a.IDKeyAlgo = 2 },
+			wantErr: "unknown ID key algorithm 2",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			auth, err := SignIDBlock(block, idKey, tc.authorKey)
+			if err != nil {
+				t.Fatalf("SignIDBlock() = _, %v, want nil", err)
+			}
+			if tc.modify != nil {
+				tc.modify(auth)
+			}
+			parsed, err := ParseIDAuthInfo(auth.Marshal())
+			if err != nil {
+				t.Fatalf("ParseIDAuthInfo(Marshal()) = _, %v, want nil", err)
+			}
+			if *parsed != *auth {
+				t.Errorf("ParseIDAuthInfo(Marshal()) did not round trip")
+			}
+			if parsed.HasAuthorKey() != tc.wantAuthor {
+				t.Errorf("HasAuthorKey() = %v, want %v", parsed.HasAuthorKey(), tc.wantAuthor)
+			}
+			err = VerifyIDBlock(block, parsed)
+			if (err == nil) != (tc.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("VerifyIDBlock() = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+
+	if _, err := SignIDBlock(block, p256Key, nil); err == nil || !strings.Contains(err.Error(), "not on curve P-384") {
+		t.Errorf("SignIDBlock(P-256 key) = _, %v, want curve error", err)
+	}
+	pub, err := EcdsaPublicKeyToBytes(&idKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := EcdsaPublicKeyFromBytes(pub)
+	if err != nil || !key.Equal(&idKey.PublicKey) {
+		t.Errorf("EcdsaPublicKeyFromBytes(EcdsaPublicKeyToBytes(k)) = %v, %v, want k", key, err)
+	}
+	auth, err := SignIDBlock(block, idKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad := auth.Marshal()
+	bad[IDAuthInfoSize-1] = 1
+	if _, err := ParseIDAuthInfo(bad); err == nil || !strings.Contains(err.Error(), "mbz range") {
+		t.Errorf("ParseIDAuthInfo(nonzero reserved) = _, %v, want mbz error", err)
+	}
+	if len(auth.IDKeyDigest()) != IDKeyDigestSize {
+		t.Errorf("IDKeyDigest() size is %d, want %d", len(auth.IDKeyDigest()), IDKeyDigestSize)
+	}
+}
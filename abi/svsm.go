@@ -0,0 +1,145 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pborman/uuid"
+)
+
+// The services manifest of the SVSM attestation protocol is defined in Table 12 of the Secure VM
+// Service Module for SEV-SNP Guests specification
+// https://www.amd.com/system/files/TechDocs/58019.pdf. GUIDs in the manifest are in the mixed-endian
+// byte order of UEFI, not the RFC 4122 byte order of the certificate table.
+const (
+	// SvsmServicesManifestGUID identifies the services manifest that SVSM_ATTEST_SERVICES returns.
+	SvsmServicesManifestGUID = "63849ebb-3d92-4670-a1ff-58f9c94b87bb"
+	// SvsmVtpmServiceGUID identifies the SVSM vTPM service. Its version 0 manifest is the
+	// TPMT_PUBLIC structure of the vTPM's endorsement key.
+	SvsmVtpmServiceGUID = "c476f1eb-0123-45a5-9641-b4e7dde5bfe3"
+
+	svsmManifestSizeOffset  = 0x10
+	svsmManifestCountOffset = 0x14
+	svsmManifestHeaderSize  = 0x18
+	svsmServiceEntrySize    = 0x18
+)
+
+// SvsmService is a service entry of an SVSM services manifest.
+type SvsmService struct {
+	GUID uuid.UUID
+	// Manifest is the service-specific manifest data.
+	Manifest []byte
+}
+
+// SvsmServicesManifest represents the services manifest that an SVSM binds to an attestation
+// report when it attests all of its services.
+type SvsmServicesManifest struct {
+	Services []SvsmService
+}
+
+// guidToUEFI returns the UEFI byte order of an RFC 4122 GUID.
+func guidToUEFI(g uuid.UUID) []byte {
+	b := clone(g)
+	reverse(b[0:4])
+	reverse(b[4:6])
+	reverse(b[6:8])
+	return b
+}
+
+// guidFromUEFI returns the RFC 4122 GUID of a GUID in UEFI byte order.
+func guidFromUEFI(b []byte) uuid.UUID {
+	return uuid.UUID(guidToUEFI(uuid.UUID(b)))
+}
+
+// Marshal returns the ABI format of the services manifest. Service manifests follow the entry
+// table in order.
+func (m *SvsmServicesManifest) Marshal() ([]byte, error) {
+	size := svsmManifestHeaderSize + svsmServiceEntrySize*len(m.Services)
+	data := make([]byte, size)
+	copy(data[0:svsmManifestSizeOffset], guidToUEFI(uuid.Parse(SvsmServicesManifestGUID)))
+	binary.LittleEndian.PutUint32(data[svsmManifestCountOffset:svsmManifestHeaderSize], uint32(len(m.Services)))
+	for i, s := range m.Services {
+		if len(s.GUID) != 16 {
+			return nil, fmt.Errorf("service %d GUID size is %d, expected 16", i, len(s.GUID))
+		}
+		entry := data[svsmManifestHeaderSize+i*svsmServiceEntrySize:]
+		copy(entry[0:0x10], guidToUEFI(s.GUID))
+		binary.LittleEndian.PutUint32(entry[0x10:0x14], uint32(len(data)))
+		binary.LittleEndian.PutUint32(entry[0x14:0x18], uint32(len(s.Manifest)))
+		data = append(data, s.Manifest...)
+	}
+	binary.LittleEndian.PutUint32(data[svsmManifestSizeOffset:svsmManifestCountOffset], uint32(len(data)))
+	return data, nil
+}
+
+// ParseSvsmServicesManifest returns the services manifest represented by its ABI format in data.
+func ParseSvsmServicesManifest(data []byte) (*SvsmServicesManifest, error) {
+	if len(data) < svsmManifestHeaderSize {
+		return nil, fmt.Errorf("services manifest size is 0x%x, expected at least 0x%x", len(data), svsmManifestHeaderSize)
+	}
+	if g := guidFromUEFI(data[0:svsmManifestSizeOffset]); !uuid.Equal(g, uuid.Parse(SvsmServicesManifestGUID)) {
+		return nil, fmt.Errorf("services manifest GUID is %v, expected %s", g, SvsmServicesManifestGUID)
+	}
+	if size := binary.LittleEndian.Uint32(data[svsmManifestSizeOffset:svsmManifestCountOffset]); uint64(size) != uint64(len(data)) {
+		return nil, fmt.Errorf("services manifest size field is 0x%x, but the manifest is 0x%x bytes", size, len(data))
+	}
+	count := binary.LittleEndian.Uint32(data[svsmManifestCountOffset:svsmManifestHeaderSize])
+	if uint64(count) > uint64(len(data)-svsmManifestHeaderSize)/svsmServiceEntrySize {
+		return nil, fmt.Errorf("services manifest has %d service entries, which exceed its size 0x%x", count, len(data))
+	}
+	m := &SvsmServicesManifest{Services: make([]SvsmService, count)}
+	for i := range m.Services {
+		entry := data[svsmManifestHeaderSize+i*svsmServiceEntrySize:]
+		offset := binary.LittleEndian.Uint32(entry[0x10:0x14])
+		length := binary.LittleEndian.Uint32(entry[0x14:0x18])
+		if uint64(offset)+uint64(length) > uint64(len(data)) {
+			return nil, fmt.Errorf("service entry %d specifies a byte range outside the services manifest (size 0x%x): offset=0x%x, length=0x%x",
+				i, len(data), offset, length)
+		}
+		m.Services[i] = SvsmService{
+			GUID:     guidFromUEFI(entry[0:0x10]),
+			Manifest: clone(data[offset : offset+length]),
+		}
+	}
+	return m, nil
+}
+
+// Service returns the manifest of the service identified by the given GUID string.
+func (m *SvsmServicesManifest) Service(guid string) ([]byte, error) {
+	g := uuid.Parse(guid)
+	if g == nil {
+		return nil, fmt.Errorf("GUID string format is XXXXXXXX-XXXX-XXXX-XXXXXXXXXXXXXXXX, got %s", guid)
+	}
+	for _, s := range m.Services {
+		if uuid.Equal(s.GUID, g) {
+			return s.Manifest, nil
+		}
+	}
+	return nil, fmt.Errorf("services manifest has no service %s", guid)
+}
+
+// SvsmReportData returns the REPORT_DATA with which an SVSM binds a nonce and a manifest to an
+// attestation report: the SHA-512 digest of the nonce followed by the manifest.
+func SvsmReportData(nonce, manifest []byte) [ReportDataSize]byte {
+	h := sha512.New()
+	h.Write(nonce)
+	h.Write(manifest)
+	var result [ReportDataSize]byte
+	copy(result[:], h.Sum(nil))
+	return result
+}
@@ -0,0 +1,87 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package abi
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pborman/uuid"
+)
+
+func TestSvsmServicesManifest(t *testing.T) {
+	m := &SvsmServicesManifest{Services: []SvsmService{
+		{GUID: uuid.Parse(SvsmVtpmServiceGUID), Manifest: []byte("ek")},
+		{GUID: uuid.Parse("00000000-0000-0000-0000-000000000001"), Manifest: []byte{}},
+	}}
+	data, err := m.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The header GUID is in UEFI byte order.
+	if got, want := hex.EncodeToString(data[0:0x10]), "bb9e8463923d7046a1ff58f9c94b87bb"; got != want {
+		t.Errorf("services manifest GUID bytes = %s, want %s", got, want)
+	}
+	got, err := ParseSvsmServicesManifest(data)
+	if err != nil {
+		t.Fatalf("ParseSvsmServicesManifest(%x) = _, %v. Want nil", data, err)
+	}
+	if diff := cmp.Diff(m, got); diff != "" {
+		t.Errorf("ParseSvsmServicesManifest(m.Marshal()) differs (-want +got):\n%s", diff)
+	}
+	ek, err := got.Service(SvsmVtpmServiceGUID)
+	if err != nil || !bytes.Equal(ek, []byte("ek")) {
+		t.Errorf("Service(%q) = %q, %v. Want \"ek\", nil", SvsmVtpmServiceGUID, ek, err)
+	}
+	if _, err := got.Service("c476f1eb-0123-45a5-9641-000000000000"); err == nil {
+		t.Error("Service(unknown) = _, nil. Want error")
+	}
+
+	with := func(offset int, value uint32) []byte {
+		result := append([]byte{}, data...)
+		binary.LittleEndian.PutUint32(result[offset:], value)
+		return result
+	}
+	tcs := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "short", data: data[:0x10], wantErr: "expected at least 0x18"},
+		{name: "guid", data: append([]byte{0}, data[1:]...), wantErr: "services manifest GUID is"},
+		{name: "size", data: with(svsmManifestSizeOffset, 0x10), wantErr: "size field is 0x10"},
+		{name: "count", data: with(svsmManifestCountOffset, 3), wantErr: "3 service entries"},
+		{name: "range", data: with(svsmManifestHeaderSize+0x14, 3), wantErr: "outside the services manifest"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParseSvsmServicesManifest(tc.data); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ParseSvsmServicesManifest() = _, %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestSvsmReportData(t *testing.T) {
+	want := sha512.Sum512([]byte("noncemanifest"))
+	if got := SvsmReportData([]byte("nonce"), []byte("manifest")); got != want {
+		t.Errorf("SvsmReportData() = %x, want %x", got, want)
+	}
+}
@@ -0,0 +1,319 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bundle collects attestations into self-contained bundles and checks them offline.
+//
+// A bundle holds the attestation with its full certificate chain, the CRL of its signing key, the
+// collector's check.Config, the collection time, and the verdict at that time. Check verifies and
+// validates the bundle's attestation without network access as of the collection time, so that an
+// auditor can reproduce the verdict long after the certificates and CRL expire. The auditor's own
+// trusted roots anchor the check, never the bundle's.
+//
+// The collection time and the CRL are the collector's claims. The ARK signs the CRL, and Check
+// rejects a CRL that was not current at the collection time, but a collector can still pick an
+// earlier collection time with an earlier, genuine CRL that predates a revocation. An auditor who
+// does not trust the collector's clock should compare the collection time with when the bundle
+// was received, or check the attestation again against a current CRL.
+package bundle
+
+import (
+	"bytes"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/google/go-sev-guest/checks"
+	bpb "github.com/google/go-sev-guest/proto/bundle"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/google/go-sev-guest/verify"
+	"github.com/google/go-sev-guest/verify/trust"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// ErrUntrustedRoots is returned by Check when the bundle records product roots that the verifier
+// does not trust.
+var ErrUntrustedRoots = errors.New("the bundle's product roots are not the verifier's trusted roots")
+
+// Options configures how Collect gathers a bundle.
+type Options struct {
+	// Getter fetches missing certificates and the CRL. If nil, uses the AMD KDS.
+	Getter trust.HTTPSGetter
+	// Now is the collection time. If zero, uses time.Now().
+	Now time.Time
+}
+
+// recordingGetter remembers the last CRL that it fetched.
+type recordingGetter struct {
+	getter trust.HTTPSGetter
+	crl    []byte
+}
+
+// Get fetches url and records the response if it is a CRL.
+func (r *recordingGetter) Get(url string) ([]byte, error) {
+	body, err := r.getter.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := x509.ParseRevocationList(body); err == nil {
+		r.crl = body
+	}
+	return body, nil
+}
+
+// crlGetter serves a bundle's CRL in place of the network.
+type crlGetter struct {
+	crl []byte
+}
+
+// Get returns the bundle's CRL for any URL. The CRL's signature is checked against the ARK.
+func (c *crlGetter) Get(url string) ([]byte, error) {
+	if len(c.crl) == 0 {
+		return nil, fmt.Errorf("bundle has no CRL for %s", url)
+	}
+	return c.crl, nil
+}
+
+// inlineRootOfTrust returns a copy of rot that has the contents of its CA bundle files in its
+// cabundles, and that checks the CRL without network access.
+func inlineRootOfTrust(rot *cpb.RootOfTrust) (*cpb.RootOfTrust, error) {
+	result := &cpb.RootOfTrust{
+		Product:         rot.GetProduct(),
+		Cabundles:       append([]string{}, rot.GetCabundles()...),
+		CheckCrl:        true,
+		DisallowNetwork: true,
+	}
+	for _, path := range rot.GetCabundlePaths() {
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("could not read CA bundle %q: %v", path, err)
+		}
+		result.Cabundles = append(result.Cabundles, string(contents))
+	}
+	return result, nil
+}
+
+// sameCert returns whether the certificates are both absent or have the same DER bytes.
+func sameCert(a, b *x509.Certificate) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return bytes.Equal(a.Raw, b.Raw)
+}
+
+// isTrustedRoot returns whether the product certificates are those of one of the roots that opts
+// trusts. Without trusted roots, the certificates must match AMD's embedded root certificates.
+func isTrustedRoot(certs *trust.ProductCerts, opts *verify.Options) bool {
+	if len(opts.TrustedRoots) == 0 {
+		for product, embedded := range trust.DefaultRootCerts {
+			root := &trust.AMDRootCerts{
+				Product:      product,
+				ProductCerts: certs,
+				AskSev:       embedded.AskSev,
+				ArkSev:       embedded.ArkSev,
+			}
+			if verify.ValidateX509(root) == nil {
+				return true
+			}
+		}
+		return false
+	}
+	for _, roots := range opts.TrustedRoots {
+		for _, root := range roots {
+			trusted := root.ProductCerts
+			if trusted != nil && sameCert(certs.Ask, trusted.Ask) && sameCert(certs.Asvk, trusted.Asvk) &&
+				sameCert(certs.Ark, trusted.Ark) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkBundledRoots returns an error unless every product root recorded in the bundle is one that
+// opts trusts. The bundle's roots are never trusted on their own, since whoever made the bundle
+// chose them.
+func checkBundledRoots(b *bpb.Bundle, opts *verify.Options) error {
+	for _, cabundle := range b.GetConfig().GetRootOfTrust().GetCabundles() {
+		root := &trust.AMDRootCerts{}
+		if err := root.FromKDSCertBytes([]byte(cabundle)); err != nil {
+			return fmt.Errorf("could not parse the bundle's CA bundle: %v", err)
+		}
+		if !isTrustedRoot(root.ProductCerts, opts) {
+			return ErrUntrustedRoots
+		}
+	}
+	return nil
+}
+
+// networkErr returns the error of the first check that failed for want of the network, if any.
+func networkErr(result *checks.Result) error {
+	for _, c := range result.Failures() {
+		var certNetworkErr *trust.AttestationRecreationErr
+		var crlNetworkErr verify.CRLUnavailableErr
+		if errors.As(c.Err, &certNetworkErr) || errors.As(c.Err, &crlNetworkErr) {
+			return c.Err
+		}
+	}
+	return nil
+}
+
+// Collect returns a bundle of the attestation and everything needed to check it against config
+// offline. Collect fetches missing certificates and the CRL with opts.Getter whatever config's
+// root of trust says. The bundle's config reads its CA bundle files into cabundles, and the
+// verdict is that of config's root of trust. Returns an error if the certificates or CRL cannot be
+// fetched, but not if the attestation fails a check, since the verdict is recorded in the bundle.
+func Collect(attestation *spb.Attestation, config *cpb.Config, opts *Options) (*bpb.Bundle, error) {
+	if config == nil {
+		return nil, errors.New("config cannot be nil")
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	rot, err := inlineRootOfTrust(config.GetRootOfTrust())
+	if err != nil {
+		return nil, err
+	}
+	verifyOpts, err := verify.RootOfTrustToOptions(rot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root_of_trust: %v", err)
+	}
+	getter := opts.Getter
+	if getter == nil {
+		getter = trust.DefaultHTTPSGetter()
+	}
+	recorder := &recordingGetter{getter: getter}
+	verifyOpts.Getter = recorder
+	verifyOpts.DisableCertFetching = false
+	verifyOpts.Now = opts.Now
+	if verifyOpts.Now.IsZero() {
+		verifyOpts.Now = time.Now()
+	}
+	attestation = proto.Clone(attestation).(*spb.Attestation)
+	if err := networkErr(verify.SnpAttestationResult(attestation, verifyOpts)); err != nil {
+		return nil, fmt.Errorf("could not collect the certificates and CRL: %v", err)
+	}
+	b := &bpb.Bundle{
+		Attestation: attestation,
+		Crl:         recorder.crl,
+		Config: &cpb.Config{
+			RootOfTrust: rot,
+			Policy:      proto.Clone(config.GetPolicy()).(*cpb.Policy),
+		},
+		// Verification may move the time forward to a fresh certificate's NotBefore.
+		Collected: timestamppb.New(verifyOpts.Now),
+	}
+	result, err := Check(b, b.Config)
+	if err != nil {
+		return nil, err
+	}
+	b.Result = result.Proto()
+	return b, nil
+}
+
+// checkCRLTime returns an error unless the CRL, if any, was current at the collection time. Both
+// are the collector's claims, so they must at least agree with each other.
+func checkCRLTime(crlBytes []byte, collected time.Time) error {
+	if len(crlBytes) == 0 {
+		return nil
+	}
+	crl, err := x509.ParseRevocationList(crlBytes)
+	if err != nil {
+		return fmt.Errorf("could not parse the bundle's CRL: %v", err)
+	}
+	if crl.ThisUpdate.After(collected) {
+		return fmt.Errorf("bundle's CRL was issued at %v, after its collection time %v", crl.ThisUpdate, collected)
+	}
+	if !crl.NextUpdate.IsZero() && crl.NextUpdate.Before(collected) {
+		return fmt.Errorf("bundle's CRL expired at %v, before its collection time %v", crl.NextUpdate, collected)
+	}
+	return nil
+}
+
+// VerifyOptions returns options that verify the bundle's attestation against the given root of
+// trust without network access as of the bundle's collection time. Only the bundle's certificates
+// and CRL are used. Returns an error if the CRL was not current at the collection time.
+func VerifyOptions(b *bpb.Bundle, rot *cpb.RootOfTrust) (*verify.Options, error) {
+	if b.GetCollected() == nil {
+		return nil, errors.New("bundle has no collection time")
+	}
+	if err := checkCRLTime(b.GetCrl(), b.GetCollected().AsTime()); err != nil {
+		return nil, err
+	}
+	opts, err := verify.RootOfTrustToOptions(rot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root_of_trust: %v", err)
+	}
+	opts.DisableCertFetching = true
+	opts.Getter = &crlGetter{crl: b.GetCrl()}
+	opts.Now = b.GetCollected().AsTime()
+	return opts, nil
+}
+
+// Check verifies the bundle's attestation offline as of its collection time against config's root
+// of trust and validates it against config's policy, and returns the outcome of every check. If
+// config is nil, verifies against the embedded AMD root certificates and validates against the
+// bundle's policy. The product roots recorded in the bundle are never trusted: Check returns
+// ErrUntrustedRoots if they are not config's trusted roots. Validation is skipped if verification
+// fails.
+// Returns an error if the bundle or config cannot be used for checking.
+func Check(b *bpb.Bundle, config *cpb.Config) (*checks.Result, error) {
+	if config == nil {
+		config = &cpb.Config{Policy: b.GetConfig().GetPolicy()}
+	}
+	rot, err := inlineRootOfTrust(config.GetRootOfTrust())
+	if err != nil {
+		return nil, err
+	}
+	verifyOpts, err := VerifyOptions(b, rot)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBundledRoots(b, verifyOpts); err != nil {
+		return nil, err
+	}
+	attestation := proto.Clone(b.GetAttestation()).(*spb.Attestation)
+	all := &checks.Result{}
+	result := verify.SnpAttestationResult(attestation, verifyOpts)
+	all.Merge(result)
+	if result.Err() != nil {
+		return all, nil
+	}
+	validateOpts, err := validate.PolicyToOptions(config.GetPolicy())
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
+	validateOpts.Now = verifyOpts.Now
+	all.Merge(validate.SnpAttestationResult(attestation, validateOpts))
+	return all, nil
+}
+
+// Reproduces returns whether result has the same checks with the same statuses as the verdict
+// recorded in the bundle.
+func Reproduces(b *bpb.Bundle, result *checks.Result) bool {
+	recorded := b.GetResult().GetChecks()
+	if len(recorded) != len(result.Checks) {
+		return false
+	}
+	for i, c := range result.Proto().GetChecks() {
+		if c.GetName() != recorded[i].GetName() || c.GetStatus() != recorded[i].GetStatus() {
+			return false
+		}
+	}
+	return true
+}
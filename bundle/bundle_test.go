@@ -0,0 +1,249 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bundle
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/x509"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/kds"
+	bpb "github.com/google/go-sev-guest/proto/bundle"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	test "github.com/google/go-sev-guest/testing"
+	"github.com/google/go-sev-guest/verify"
+	"google.golang.org/protobuf/proto"
+)
+
+const debugPolicy = 0xa0000
+
+// The certificates are valid for years, but the CRL only for a month after collection.
+var collected = time.Date(2023, time.March, 1, 9, 0, 0, 0, time.UTC)
+
+type fixture struct {
+	signer      *test.AmdSigner
+	attestation *spb.Attestation
+	config      *cpb.Config
+	crl         []byte
+	getter      *test.Getter
+}
+
+// newFixture returns an attestation and a CRL that revokes the ASK if revokeAsk is true.
+func newFixture(t *testing.T, revokeAsk bool) *fixture {
+	t.Helper()
+	signer, err := test.DefaultCertChain("Milan", collected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report := test.SignedAttestation(t, signer, &test.AttestationOptions{ReportData: [64]byte{1, 2, 3}}).GetReport()
+	template := &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: collected.Add(-time.Hour),
+		NextUpdate: collected.Add(30 * 24 * time.Hour),
+	}
+	if revokeAsk {
+		template.RevokedCertificateEntries = []x509.RevocationListEntry{
+			{SerialNumber: signer.Ask.SerialNumber, RevocationTime: collected.Add(-time.Hour)},
+		}
+	}
+	crl, err := x509.CreateRevocationList(rand.Reader, template, signer.Ark, signer.Keys.Ark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain := &spb.CertificateChain{AskCert: signer.Ask.Raw, ArkCert: signer.Ark.Raw}
+	return &fixture{
+		signer: signer,
+		// The VCEK certificate is missing, as if the host did not provide it.
+		attestation: &spb.Attestation{Report: report, CertificateChain: chain},
+		config: &cpb.Config{
+			RootOfTrust: &cpb.RootOfTrust{Product: "Milan", Cabundles: []string{string(test.ProductRootsPEM(signer))}},
+			Policy:      &cpb.Policy{Policy: debugPolicy, MinimumVersion: "0.0"},
+		},
+		crl: crl,
+		getter: &test.Getter{Responses: map[string][]byte{
+			kds.CrlURL("Milan", abi.VcekReportSigner):                                            crl,
+			kds.VCEKCertURL("Milan", report.GetChipId(), kds.TCBVersion(report.GetCurrentTcb())): signer.Vcek.Raw,
+		}},
+	}
+}
+
+func TestCollectAndCheck(t *testing.T) {
+	f := newFixture(t, false)
+	b, err := Collect(f.attestation, f.config, &Options{Getter: f.getter, Now: collected})
+	if err != nil {
+		t.Fatalf("Collect() = _, %v. Want nil", err)
+	}
+	if !bytes.Equal(b.GetAttestation().GetCertificateChain().GetVcekCert(), f.signer.Vcek.Raw) {
+		t.Error("Collect() did not fill in the VCEK certificate")
+	}
+	if !bytes.Equal(b.GetCrl(), f.crl) {
+		t.Error("Collect() did not record the CRL")
+	}
+	if rot := b.GetConfig().GetRootOfTrust(); !rot.GetCheckCrl() || !rot.GetDisallowNetwork() {
+		t.Errorf("Collect() root of trust is %v, want check_crl and disallow_network", rot)
+	}
+	if !b.GetCollected().AsTime().Equal(collected) {
+		t.Errorf("Collect() collection time is %v, want %v", b.GetCollected().AsTime(), collected)
+	}
+	for _, c := range b.GetResult().GetChecks() {
+		if c.GetStatus() == cpb.CheckResult_FAILED {
+			t.Errorf("Collect() recorded failed check %v", c)
+		}
+	}
+
+	// Check long after the CRL's NextUpdate, from a serialized bundle.
+	data, err := proto.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offline := &bpb.Bundle{}
+	if err := proto.Unmarshal(data, offline); err != nil {
+		t.Fatal(err)
+	}
+	result, err := Check(offline, f.config)
+	if err != nil {
+		t.Fatalf("Check() = _, %v. Want nil", err)
+	}
+	if err := result.Err(); err != nil {
+		t.Errorf("Check() failed: %v", err)
+	}
+	if c := result.Get(verify.CRLCheck); c == nil || c.Err != nil {
+		t.Errorf("Check() CRL check is %+v, want passed", c)
+	}
+	if !Reproduces(offline, result) {
+		t.Errorf("Check() = %v, which does not reproduce %v", result.Proto(), offline.GetResult())
+	}
+
+	stricter := proto.Clone(f.config).(*cpb.Config)
+	stricter.Policy.Measurement = bytes.Repeat([]byte{1}, abi.MeasurementSize)
+	result, err = Check(offline, stricter)
+	if err != nil {
+		t.Fatalf("Check(stricter) = _, %v. Want nil", err)
+	}
+	if result.Err() == nil || Reproduces(offline, result) {
+		t.Errorf("Check(stricter) = %v, want a failure that differs from the recorded verdict", result.Proto())
+	}
+}
+
+func TestCollectErrors(t *testing.T) {
+	f := newFixture(t, false)
+	noCrl := &test.Getter{Responses: map[string][]byte{}}
+	for url, body := range f.getter.Responses {
+		if url != kds.CrlURL("Milan", abi.VcekReportSigner) {
+			noCrl.Responses[url] = body
+		}
+	}
+	if _, err := Collect(f.attestation, f.config, &Options{Getter: noCrl, Now: collected}); err == nil || !strings.Contains(err.Error(), "could not collect") {
+		t.Errorf("Collect() without a CRL = _, %v. Want error %q", err, "could not collect")
+	}
+	if _, err := Collect(f.attestation, nil, nil); err == nil {
+		t.Error("Collect(nil config) = _, nil. Want error")
+	}
+	if _, err := Check(&bpb.Bundle{Attestation: f.attestation, Config: f.config}, nil); err == nil || !strings.Contains(err.Error(), "no collection time") {
+		t.Errorf("Check() without a collection time = _, %v. Want error %q", err, "no collection time")
+	}
+}
+
+func TestCheckCRLTime(t *testing.T) {
+	f := newFixture(t, false)
+	b, err := Collect(f.attestation, f.config, &Options{Getter: f.getter, Now: collected})
+	if err != nil {
+		t.Fatalf("Collect() = _, %v. Want nil", err)
+	}
+	tcs := []struct {
+		name       string
+		thisUpdate time.Time
+		nextUpdate time.Time
+		wantErr    string
+	}{
+		{name: "current", thisUpdate: collected, nextUpdate: collected},
+		{name: "issued after collection", thisUpdate: collected.Add(time.Hour), nextUpdate: collected.Add(48 * time.Hour), wantErr: "after its collection time"},
+		{name: "expired before collection", thisUpdate: collected.Add(-48 * time.Hour), nextUpdate: collected.Add(-time.Hour), wantErr: "before its collection time"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+				Number:     big.NewInt(2),
+				ThisUpdate: tc.thisUpdate,
+				NextUpdate: tc.nextUpdate,
+			}, f.signer.Ark, f.signer.Keys.Ark)
+			if err != nil {
+				t.Fatal(err)
+			}
+			other := proto.Clone(b).(*bpb.Bundle)
+			other.Crl = crl
+			_, err = Check(other, f.config)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Errorf("Check() = _, %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRevokedVerdict(t *testing.T) {
+	f := newFixture(t, true)
+	b, err := Collect(f.attestation, f.config, &Options{Getter: f.getter, Now: collected})
+	if err != nil {
+		t.Fatalf("Collect() = _, %v. Want nil", err)
+	}
+	result, err := Check(b, f.config)
+	if err != nil {
+		t.Fatalf("Check() = _, %v. Want nil", err)
+	}
+	if c := result.Get(verify.CRLCheck); c == nil || c.Err == nil || !strings.Contains(c.Err.Error(), "ASK was revoked") {
+		t.Errorf("Check() CRL check is %+v, want ASK revoked", c)
+	}
+	if !Reproduces(b, result) {
+		t.Errorf("Check() = %v, which does not reproduce %v", result.Proto(), b.GetResult())
+	}
+}
+
+func TestCheckTrustsOnlyVerifierRoots(t *testing.T) {
+	trusted := newFixture(t, false)
+	foreign := newFixture(t, false)
+	if bytes.Equal(trusted.signer.Ark.Raw, foreign.signer.Ark.Raw) {
+		t.Fatal("fixtures share an ARK")
+	}
+	b, err := Collect(foreign.attestation, foreign.config, &Options{Getter: foreign.getter, Now: collected})
+	if err != nil {
+		t.Fatalf("Collect() = _, %v. Want nil", err)
+	}
+	// The bundle's own ASK and ARK chain its attestation, but the verifier does not trust them.
+	if _, err := Check(b, trusted.config); err == nil || !strings.Contains(err.Error(), "not the verifier's trusted roots") {
+		t.Errorf("Check(foreign ARK) = _, %v. Want error %q", err, "not the verifier's trusted roots")
+	}
+	if _, err := Check(b, nil); err == nil || !strings.Contains(err.Error(), "not the verifier's trusted roots") {
+		t.Errorf("Check(foreign ARK, embedded roots) = _, %v. Want error %q", err, "not the verifier's trusted roots")
+	}
+	// Without recorded roots, the attestation's own chain must still lead to the verifier's roots.
+	noRoots := proto.Clone(b).(*bpb.Bundle)
+	noRoots.Config.RootOfTrust.Cabundles = nil
+	result, err := Check(noRoots, trusted.config)
+	if err != nil {
+		t.Fatalf("Check(foreign attestation) = _, %v. Want nil", err)
+	}
+	if c := result.Get(verify.CertificateChainCheck); c == nil || c.Err == nil {
+		t.Errorf("Check(foreign attestation) certificate chain check is %+v, want failed", c)
+	}
+	if _, err := Check(b, foreign.config); err != nil {
+		t.Errorf("Check(own roots) = _, %v. Want nil", err)
+	}
+}
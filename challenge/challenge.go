@@ -0,0 +1,185 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package challenge implements a nonce-based challenge/response exchange for SEV-SNP attestation.
+//
+// The verifier issues a Challenge that carries a random nonce and an expiry. The guest binds the
+// nonce and a hash of its own public key into REPORT_DATA with ReportData and returns the resulting
+// attestation together with the public key. The verifier then checks that the nonce is one it
+// issued, has not expired, has not been used before, and is bound to the public key in the report.
+//
+// REPORT_DATA is derived as
+//
+//	SHA-512("sev-snp-challenge-v1" || 0x00 || nonce || SHA-384(publicKey))
+//
+// where the public key is the caller's serialization of its key, e.g., a DER-encoded
+// SubjectPublicKeyInfo.
+package challenge
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/client"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+)
+
+const (
+	// NonceSize is the size in bytes of an issued nonce.
+	NonceSize = 32
+	// DefaultTTL is the time a challenge remains redeemable if the Issuer does not specify one.
+	DefaultTTL = 5 * time.Minute
+
+	// label separates this derivation from any other use of REPORT_DATA.
+	label = "sev-snp-challenge-v1"
+)
+
+var (
+	// ErrUnknownNonce is returned when redeeming a nonce that was never issued or was already used.
+	ErrUnknownNonce = errors.New("nonce was not issued or was already used")
+	// ErrExpiredNonce is returned when redeeming a nonce after its challenge's expiry.
+	ErrExpiredNonce = errors.New("nonce has expired")
+)
+
+// Challenge is a verifier-issued nonce that a guest must bind into an attestation report before
+// the expiry.
+type Challenge struct {
+	Nonce  []byte
+	Expiry time.Time
+}
+
+// ReportData returns the REPORT_DATA value that binds the nonce to the caller's public key.
+func ReportData(nonce, publicKey []byte) [abi.ReportDataSize]byte {
+	keyDigest := sha512.Sum384(publicKey)
+	h := sha512.New()
+	h.Write([]byte(label))
+	h.Write([]byte{0})
+	h.Write(nonce)
+	h.Write(keyDigest[:])
+	var result [abi.ReportDataSize]byte
+	copy(result[:], h.Sum(nil))
+	return result
+}
+
+// Respond returns an attestation from the given provider at the given VMPL that binds the
+// challenge to the caller's public key.
+func Respond(p client.ReportProvider, c *Challenge, publicKey []byte, vmpl int) (*spb.Attestation, error) {
+	if len(c.Nonce) != NonceSize {
+		return nil, fmt.Errorf("challenge nonce size is %d. Expected %d", len(c.Nonce), NonceSize)
+	}
+	return client.GetExtendedReportFromProvider(p, ReportData(c.Nonce, publicKey), vmpl)
+}
+
+// Issuer issues challenges and redeems each at most once before it expires. It is safe for
+// concurrent use.
+type Issuer struct {
+	// TTL is how long an issued challenge remains redeemable. If zero, uses DefaultTTL.
+	TTL time.Duration
+	// Now returns the current time. If nil, uses time.Now.
+	Now func() time.Time
+	// Rand is the source of nonces. If nil, uses crypto/rand.Reader.
+	Rand io.Reader
+
+	mu          sync.Mutex
+	outstanding map[string]time.Time
+}
+
+func (i *Issuer) now() time.Time {
+	if i.Now == nil {
+		return time.Now()
+	}
+	return i.Now()
+}
+
+func (i *Issuer) ttl() time.Duration {
+	if i.TTL == 0 {
+		return DefaultTTL
+	}
+	return i.TTL
+}
+
+// prune forgets expired nonces. Must be called with mu held.
+func (i *Issuer) prune(now time.Time) {
+	for nonce, expiry := range i.outstanding {
+		if now.After(expiry) {
+			delete(i.outstanding, nonce)
+		}
+	}
+}
+
+// Issue returns a fresh challenge.
+func (i *Issuer) Issue() (*Challenge, error) {
+	r := i.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	nonce := make([]byte, NonceSize)
+	if _, err := io.ReadFull(r, nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %v", err)
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	now := i.now()
+	i.prune(now)
+	if i.outstanding == nil {
+		i.outstanding = make(map[string]time.Time)
+	}
+	key := hex.EncodeToString(nonce)
+	if _, ok := i.outstanding[key]; ok {
+		return nil, errors.New("generated nonce is already outstanding")
+	}
+	expiry := now.Add(i.ttl())
+	i.outstanding[key] = expiry
+	return &Challenge{Nonce: nonce, Expiry: expiry}, nil
+}
+
+// Redeem consumes the nonce. It returns ErrUnknownNonce if the nonce is not outstanding, and
+// ErrExpiredNonce if its challenge has expired.
+func (i *Issuer) Redeem(nonce []byte) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	key := hex.EncodeToString(nonce)
+	expiry, ok := i.outstanding[key]
+	if !ok {
+		return ErrUnknownNonce
+	}
+	delete(i.outstanding, key)
+	if i.now().After(expiry) {
+		return ErrExpiredNonce
+	}
+	return nil
+}
+
+// Verify redeems the nonce and checks that the report's REPORT_DATA binds it to the given public
+// key. The nonce is consumed even if the binding does not match. Verify does not check the report's
+// signature, which is the job of the verify package.
+func (i *Issuer) Verify(report *spb.Report, nonce, publicKey []byte) error {
+	if err := i.Redeem(nonce); err != nil {
+		return err
+	}
+	want := ReportData(nonce, publicKey)
+	if !bytes.Equal(report.GetReportData(), want[:]) {
+		return fmt.Errorf("report field REPORT_DATA is %s. Expect %s, which binds the nonce to the public key",
+			hex.EncodeToString(report.GetReportData()), hex.EncodeToString(want[:]))
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package challenge
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/client"
+	test "github.com/google/go-sev-guest/testing"
+)
+
+func TestReportData(t *testing.T) {
+	nonce := make([]byte, NonceSize)
+	key1 := []byte("key1")
+	key2 := []byte("key2")
+	a := ReportData(nonce, key1)
+	if b := ReportData(nonce, key1); a != b {
+		t.Errorf("ReportData(%v, %v) is not deterministic: %v != %v", nonce, key1, a, b)
+	}
+	if b := ReportData(nonce, key2); a == b {
+		t.Errorf("ReportData(%v, %v) = ReportData(%v, %v). Want different values", nonce, key1, nonce, key2)
+	}
+	nonce2 := make([]byte, NonceSize)
+	nonce2[0] = 1
+	if b := ReportData(nonce2, key1); a == b {
+		t.Errorf("ReportData(%v, %v) = ReportData(%v, %v). Want different values", nonce, key1, nonce2, key1)
+	}
+}
+
+func TestChallengeResponse(t *testing.T) {
+	now := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	issuer := &Issuer{TTL: time.Minute, Now: func() time.Time { return now }}
+	c, err := issuer.Issue()
+	if err != nil {
+		t.Fatalf("Issue() errored unexpectedly: %v", err)
+	}
+	if len(c.Nonce) != NonceSize || !c.Expiry.Equal(now.Add(time.Minute)) {
+		t.Fatalf("Issue() = %v, want a %d-byte nonce expiring at %v", c, NonceSize, now.Add(time.Minute))
+	}
+	publicKey := []byte("public key")
+	reportData := ReportData(c.Nonce, publicKey)
+	tcs := []test.TestCase{{Input: reportData, Output: test.TestRawReport(reportData)}}
+	device, err := test.TcDevice(tcs, &test.DeviceOptions{Now: now})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := device.Open("/dev/sev-guest"); err != nil {
+		t.Fatal(err)
+	}
+	provider := &client.DeviceReportProvider{Device: device}
+	defer provider.Close()
+	attestation, err := Respond(provider, c, publicKey, 0)
+	if err != nil {
+		t.Fatalf("Respond() errored unexpectedly: %v", err)
+	}
+	if !bytes.Equal(attestation.GetReport().GetReportData(), reportData[:]) {
+		t.Fatalf("Respond() report data = %v, want %v", attestation.GetReport().GetReportData(), reportData)
+	}
+	if err := issuer.Verify(attestation.GetReport(), c.Nonce, publicKey); err != nil {
+		t.Errorf("Verify() errored unexpectedly: %v", err)
+	}
+	if err := issuer.Verify(attestation.GetReport(), c.Nonce, publicKey); !errors.Is(err, ErrUnknownNonce) {
+		t.Errorf("Verify() of a used nonce = %v, want %v", err, ErrUnknownNonce)
+	}
+
+	if _, err := Respond(provider, &Challenge{Nonce: []byte{1}}, publicKey, 0); err == nil {
+		t.Error("Respond() with a 1-byte nonce succeeded unexpectedly")
+	}
+
+	c2, err := issuer.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantErr := "report field REPORT_DATA"
+	if err := issuer.Verify(attestation.GetReport(), c2.Nonce, publicKey); err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("Verify() of an unbound nonce = %v, want error %q", err, wantErr)
+	}
+
+	c3, err := issuer.Issue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(2 * time.Minute)
+	if err := issuer.Redeem(c3.Nonce); !errors.Is(err, ErrExpiredNonce) {
+		t.Errorf("Redeem() of an expired nonce = %v, want %v", err, ErrExpiredNonce)
+	}
+	if err := issuer.Redeem(make([]byte, NonceSize)); !errors.Is(err, ErrUnknownNonce) {
+		t.Errorf("Redeem() of an unissued nonce = %v, want %v", err, ErrUnknownNonce)
+	}
+}
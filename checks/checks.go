@@ -0,0 +1,151 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package checks records the outcome of each individual check that the verify and validate
+// packages perform on an attestation.
+package checks
+
+import (
+	"fmt"
+
+	cpb "github.com/google/go-sev-guest/proto/check"
+	"go.uber.org/multierr"
+)
+
+// Status is the outcome of a single check.
+type Status int
+
+const (
+	// Passed means the attestation satisfied the check.
+	Passed Status = iota + 1
+	// Skipped means the check was not configured, or could not run because an earlier check failed.
+	Skipped
+	// Failed means the attestation did not satisfy the check.
+	Failed
+)
+
+// String returns a human-readable name for the status.
+func (s Status) String() string {
+	switch s {
+	case Passed:
+		return "PASSED"
+	case Skipped:
+		return "SKIPPED"
+	case Failed:
+		return "FAILED"
+	}
+	return fmt.Sprintf("UNKNOWN(%d)", int(s))
+}
+
+// Check is the outcome of a single named check of an attestation.
+type Check struct {
+	Name   string
+	Status Status
+	// Expected and Actual are human-readable values the check compared. Empty if not applicable.
+	Expected string
+	Actual   string
+	// Err is the reason for failure if Status is Failed.
+	Err error
+}
+
+// Proto returns the protobuf representation of the check.
+func (c *Check) Proto() *cpb.CheckResult {
+	result := &cpb.CheckResult{
+		Name:     c.Name,
+		Expected: c.Expected,
+		Actual:   c.Actual,
+	}
+	switch c.Status {
+	case Passed:
+		result.Status = cpb.CheckResult_PASSED
+	case Skipped:
+		result.Status = cpb.CheckResult_SKIPPED
+	case Failed:
+		result.Status = cpb.CheckResult_FAILED
+	}
+	if c.Err != nil {
+		result.Error = c.Err.Error()
+	}
+	return result
+}
+
+// Result is the outcome of every check performed on an attestation, in order.
+type Result struct {
+	Checks []*Check
+}
+
+// Record adds a check that passed if err is nil and failed otherwise.
+func (r *Result) Record(name, expected, actual string, err error) {
+	status := Passed
+	if err != nil {
+		status = Failed
+	}
+	r.Checks = append(r.Checks, &Check{
+		Name:     name,
+		Status:   status,
+		Expected: expected,
+		Actual:   actual,
+		Err:      err,
+	})
+}
+
+// Skip adds a check that did not run.
+func (r *Result) Skip(name string) {
+	r.Checks = append(r.Checks, &Check{Name: name, Status: Skipped})
+}
+
+// Merge appends the checks of other to r.
+func (r *Result) Merge(other *Result) {
+	r.Checks = append(r.Checks, other.Checks...)
+}
+
+// Get returns the first check with the given name, or nil if there is none.
+func (r *Result) Get(name string) *Check {
+	for _, c := range r.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Failures returns the failed checks in order.
+func (r *Result) Failures() []*Check {
+	var result []*Check
+	for _, c := range r.Checks {
+		if c.Status == Failed {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// Err returns the combined errors of all failed checks, or nil if no check failed. A single
+// failure's error is returned as is, so it may be inspected with errors.As.
+func (r *Result) Err() error {
+	var errs []error
+	for _, c := range r.Failures() {
+		errs = append(errs, c.Err)
+	}
+	return multierr.Combine(errs...)
+}
+
+// Proto returns the protobuf representation of the result.
+func (r *Result) Proto() *cpb.Result {
+	result := &cpb.Result{}
+	for _, c := range r.Checks {
+		result.Checks = append(result.Checks, c.Proto())
+	}
+	return result
+}
@@ -0,0 +1,71 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package checks
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+type testErr struct{}
+
+func (testErr) Error() string { return "test error" }
+
+func TestResult(t *testing.T) {
+	r := &Result{}
+	if err := r.Err(); err != nil {
+		t.Errorf("empty Result.Err() = %v, want nil", err)
+	}
+	r.Record("a", "1", "1", nil)
+	r.Skip("b")
+	r.Record("c", "1", "2", testErr{})
+	var te testErr
+	if err := r.Err(); !errors.As(err, &te) {
+		t.Errorf("Result.Err() = %v, want the single failure's error", err)
+	}
+	r.Merge(&Result{Checks: []*Check{{Name: "d", Status: Failed, Err: errors.New("second")}}})
+	if err := r.Err(); err == nil || !strings.Contains(err.Error(), "test error") || !strings.Contains(err.Error(), "second") {
+		t.Errorf("Result.Err() = %v, want both failures", err)
+	}
+	if got := r.Get("b"); got == nil || got.Status != Skipped {
+		t.Errorf("Result.Get(\"b\") = %v, want a skipped check", got)
+	}
+	if got := r.Get("e"); got != nil {
+		t.Errorf("Result.Get(\"e\") = %v, want nil", got)
+	}
+	if got := len(r.Failures()); got != 2 {
+		t.Errorf("len(Result.Failures()) = %d, want 2", got)
+	}
+
+	want := &cpb.Result{Checks: []*cpb.CheckResult{
+		{Name: "a", Status: cpb.CheckResult_PASSED, Expected: "1", Actual: "1"},
+		{Name: "b", Status: cpb.CheckResult_SKIPPED},
+		{Name: "c", Status: cpb.CheckResult_FAILED, Expected: "1", Actual: "2", Error: "test error"},
+		{Name: "d", Status: cpb.CheckResult_FAILED, Error: "second"},
+	}}
+	got := r.Proto()
+	if diff := cmp.Diff(got, want, protocmp.Transform()); diff != "" {
+		t.Errorf("Result.Proto() = %v, want %v. Diff: %s", got, want, diff)
+	}
+	if _, err := prototext.Marshal(got); err != nil {
+		t.Errorf("prototext.Marshal(%v) errored unexpectedly: %v", got, err)
+	}
+}
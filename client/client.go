@@ -17,6 +17,7 @@ package client
 import (
 	"flag"
 	"fmt"
+	"syscall"
 
 	"github.com/google/go-sev-guest/abi"
 	labi "github.com/google/go-sev-guest/client/linuxabi"
@@ -39,6 +40,37 @@ func UseDefaultSevGuest() bool {
 	return *sevGuestPath == "default"
 }
 
+// deviceErr is an error from a device that errors.Is also matches to a sentinel error in abi.
+type deviceErr struct {
+	err      error
+	sentinel error
+}
+
+func (e *deviceErr) Error() string { return fmt.Sprintf("%v: %v", e.sentinel, e.err) }
+
+func (e *deviceErr) Unwrap() error { return e.err }
+
+func (e *deviceErr) Is(target error) bool { return target == e.sentinel }
+
+// classifyErr matches the sev-guest driver's error numbers to abi's sentinel errors.
+func classifyErr(err error) error {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return err
+	}
+	switch errno {
+	// The driver itself retries requests that the host reports busy. Devices that do not, e.g.,
+	// proxies, return EAGAIN or EBUSY for a busy host.
+	case syscall.EAGAIN, syscall.EBUSY:
+		return &deviceErr{err: err, sentinel: abi.ErrThrottled}
+	// The driver returns ETIMEDOUT once it gives up retrying, and disables the VMPCK on that path.
+	// It returns ENOTTY for every request after it has disabled the VMPCK.
+	case syscall.ETIMEDOUT, syscall.ENOTTY:
+		return &deviceErr{err: err, sentinel: abi.ErrVmpckDisabled}
+	}
+	return err
+}
+
 func message(d Device, command uintptr, req *labi.SnpUserGuestRequest) error {
 	result, err := d.Ioctl(command, req)
 	if err != nil {
@@ -48,7 +80,7 @@ func message(d Device, command uintptr, req *labi.SnpUserGuestRequest) error {
 		if req.FwErr != 0 {
 			return &abi.SevFirmwareErr{Status: abi.SevFirmwareStatus(req.FwErr)}
 		}
-		return err
+		return classifyErr(err)
 	}
 	if result != uintptr(labi.EsOk) {
 		return &labi.SevEsErr{Result: labi.EsResult(result)}
@@ -113,8 +145,7 @@ func getExtendedReportIn(d Device, reportData [64]byte, vmpl int, certs []byte)
 	}
 	// Query the length required for certs.
 	if err := message(d, labi.IocSnpGetExtendedReport, &userGuestReq); err != nil {
-		var fwErr *abi.SevFirmwareErr
-		if errors.As(err, &fwErr) && fwErr.Status == abi.GuestRequestInvalidLength {
+		if errors.Is(err, abi.ErrInvalidLength) {
 			return nil, snpExtReportReq.CertsLength, nil
 		}
 		return nil, 0, err
@@ -137,7 +168,7 @@ func queryCertificateLength(d Device, vmpl int) (uint32, error) {
 func GetRawExtendedReportAtVmpl(d Device, reportData [64]byte, vmpl int) ([]byte, []byte, error) {
 	length, err := queryCertificateLength(d, vmpl)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error querying certificate length: %v", err)
+		return nil, nil, fmt.Errorf("error querying certificate length: %w", err)
 	}
 	certs := make([]byte, length)
 	report, _, err := getExtendedReportIn(d, reportData, vmpl, certs)
@@ -159,7 +190,12 @@ func GetExtendedReportAtVmpl(d Device, reportData [64]byte, vmpl int) (*pb.Attes
 	if err != nil {
 		return nil, err
 	}
+	return extendedReportToProto(reportBytes, certBytes)
+}
 
+// extendedReportToProto translates a raw attestation report and certificate table into a
+// structured type.
+func extendedReportToProto(reportBytes, certBytes []byte) (*pb.Attestation, error) {
 	report, err := abi.ReportToProto(reportBytes)
 	if err != nil {
 		return nil, err
@@ -185,6 +221,47 @@ type GuestFieldSelect struct {
 	FamilyID    bool
 	ImageID     bool
 	GuestPolicy bool
+	// LaunchMitVector mixes the request's LaunchMitVector into the key. Requires firmware API
+	// version 1.58 or later.
+	LaunchMitVector bool
+}
+
+// DerivedKeySelect selects the chip key that a derived key with UseVCEK comes from. It is the
+// KEY_SEL field of MSG_KEY_REQ.
+type DerivedKeySelect uint32
+
+const (
+	// KeySelectDefault derives from the VLEK if the host installed one, and from the VCEK otherwise.
+	// This is the only behavior of firmware that predates KEY_SEL.
+	KeySelectDefault DerivedKeySelect = 0
+	// KeySelectVCEK derives from the VCEK even if a VLEK is installed.
+	KeySelectVCEK DerivedKeySelect = 1
+	// KeySelectVLEK derives from the VLEK, and fails if none is installed.
+	KeySelectVLEK DerivedKeySelect = 2
+)
+
+// The firmware API versions, as major << 8 | minor, that introduced MSG_KEY_REQ fields.
+const (
+	keySelectMinimumVersion       = 1<<8 | 54
+	launchMitVectorMinimumVersion = 1<<8 | 58
+)
+
+// ErrUnsupportedKeyRequest matches errors for derived key requests that use fields the firmware
+// does not support.
+var ErrUnsupportedKeyRequest = errors.New("derived key request is not supported by the firmware")
+
+// launchMitVectorSender is a Device that can say whether it sends the LAUNCH_MIT_VECTOR field of
+// MSG_KEY_REQ.
+type launchMitVectorSender interface {
+	sendsLaunchMitVector() bool
+}
+
+// sendsLaunchMitVector returns whether d sends MSG_KEY_REQ with its LAUNCH_MIT_VECTOR field. The
+// Linux driver copies only the fields before it, so only devices that build the guest message
+// themselves do.
+func sendsLaunchMitVector(d Device) bool {
+	s, ok := d.(launchMitVectorSender)
+	return ok && s.sendsLaunchMitVector()
 }
 
 // SnpDerivedKeyReq represents a request to the SEV guest device to derive a key from specified
@@ -193,7 +270,10 @@ type SnpDerivedKeyReq struct {
 	// UseVCEK determines if the derived key will be based on VCEK or VMRK. This is opposite from the
 	// ABI's ROOT_KEY_SELECT to avoid accidentally making an unsafe choice in a multitenant
 	// environment.
-	UseVCEK          bool
+	UseVCEK bool
+	// KeySelect selects between the VCEK and VLEK when UseVCEK is true. Must be KeySelectDefault if
+	// UseVCEK is false. Other values require firmware API version 1.54 or later.
+	KeySelect        DerivedKeySelect
 	GuestFieldSelect GuestFieldSelect
 	// Vmpl to mix into the key. Must be greater than or equal to current Vmpl.
 	Vmpl uint32
@@ -201,11 +281,17 @@ type SnpDerivedKeyReq struct {
 	GuestSVN uint32
 	// TCBVersion to mix into the key. Must be less than or equal to the CommittedTcb.
 	TCBVersion uint64
+	// LaunchMitVector to mix into the key if GuestFieldSelect.LaunchMitVector is set. Must be a
+	// subset of the LAUNCH_MIT_VECTOR at launch.
+	LaunchMitVector uint64
 }
 
 // ABI returns the SNP ABI-specified uint64 bitmask of guest field selection.
 func (g GuestFieldSelect) ABI() uint64 {
 	var value uint64
+	if g.LaunchMitVector {
+		value |= uint64(1 << 6)
+	}
 	if g.TCBVersion {
 		value |= uint64(1 << 5)
 	}
@@ -227,22 +313,106 @@ func (g GuestFieldSelect) ABI() uint64 {
 	return value
 }
 
+// ParseGuestFieldSelect returns the guest field selection that the SNP ABI-specified bitmask
+// represents.
+func ParseGuestFieldSelect(value uint64) (GuestFieldSelect, error) {
+	if value>>7 != 0 {
+		return GuestFieldSelect{}, fmt.Errorf("guest field select 0x%x has unknown bits", value)
+	}
+	return GuestFieldSelect{
+		GuestPolicy:     value&(1<<0) != 0,
+		ImageID:         value&(1<<1) != 0,
+		FamilyID:        value&(1<<2) != 0,
+		Measurement:     value&(1<<3) != 0,
+		GuestSVN:        value&(1<<4) != 0,
+		TCBVersion:      value&(1<<5) != 0,
+		LaunchMitVector: value&(1<<6) != 0,
+	}, nil
+}
+
+// RootKeySelect returns the ROOT_KEY_SELECT field of MSG_KEY_REQ for the request, which holds the
+// root key in bit 0 and KEY_SEL in bits 2:1.
+func (r *SnpDerivedKeyReq) RootKeySelect() uint32 {
+	if !r.UseVCEK {
+		return 1
+	}
+	return uint32(r.KeySelect) << 1
+}
+
+// minimumVersion returns the firmware API version that the request needs, or 0 if all firmware
+// supports it.
+func (r *SnpDerivedKeyReq) minimumVersion() uint16 {
+	if r.GuestFieldSelect.LaunchMitVector {
+		return launchMitVectorMinimumVersion
+	}
+	if r.KeySelect != KeySelectDefault {
+		return keySelectMinimumVersion
+	}
+	return 0
+}
+
+// checkSelections returns an error that wraps ErrUnsupportedKeyRequest if no firmware supports
+// the request's selections.
+func (r *SnpDerivedKeyReq) checkSelections() error {
+	if r.KeySelect > KeySelectVLEK {
+		return fmt.Errorf("%w: key select %d is reserved", ErrUnsupportedKeyRequest, r.KeySelect)
+	}
+	if !r.UseVCEK && r.KeySelect != KeySelectDefault {
+		return fmt.Errorf("%w: key select %d requires UseVCEK", ErrUnsupportedKeyRequest, r.KeySelect)
+	}
+	return nil
+}
+
+// CheckFirmwareVersion returns an error that wraps ErrUnsupportedKeyRequest if firmware with the
+// given API version does not support the request's selections.
+func (r *SnpDerivedKeyReq) CheckFirmwareVersion(major, minor uint32) error {
+	if err := r.checkSelections(); err != nil {
+		return err
+	}
+	want := r.minimumVersion()
+	if major > 0xff || minor > 0xff || uint16(major<<8|minor) < want {
+		what := "key select"
+		if r.GuestFieldSelect.LaunchMitVector {
+			what = "the launch mitigation vector"
+		}
+		return fmt.Errorf("%w: %s requires firmware API version %d.%d, but the firmware is %d.%d",
+			ErrUnsupportedKeyRequest, what, want>>8, want&0xff, major, minor)
+	}
+	return nil
+}
+
 // GetDerivedKeyAcknowledgingItsLimitations returns 32 bytes of key material that the AMD security
 // processor derives from the given parameters. Security limitations of this command are described
 // more in the project README.
+//
+// Requests that use KeySelect or GuestFieldSelect.LaunchMitVector first get an attestation report
+// at the request's VMPL to check that the firmware's API version supports them, since the caller
+// may not run at VMPL0. Only a MessageDevice can send the launch mitigation vector.
 func GetDerivedKeyAcknowledgingItsLimitations(d Device, request *SnpDerivedKeyReq) (*labi.SnpDerivedKeyRespABI, error) {
-	response := &labi.SnpDerivedKeyRespABI{}
-	rootKeySelect := uint32(1)
-	if request.UseVCEK {
-		rootKeySelect = 0
+	if err := request.checkSelections(); err != nil {
+		return nil, err
+	}
+	if (request.GuestFieldSelect.LaunchMitVector || request.LaunchMitVector != 0) && !sendsLaunchMitVector(d) {
+		return nil, fmt.Errorf("%w: the device cannot send the launch mitigation vector", ErrUnsupportedKeyRequest)
+	}
+	if request.minimumVersion() != 0 {
+		report, err := GetReportAtVmpl(d, [64]byte{}, int(request.Vmpl))
+		if err != nil {
+			return nil, fmt.Errorf("could not get the firmware version for the derived key request: %v", err)
+		}
+		if err := request.CheckFirmwareVersion(report.GetCurrentMajor(), report.GetCurrentMinor()); err != nil {
+			return nil, err
+		}
 	}
+	response := &labi.SnpDerivedKeyRespABI{}
 	guestRequest := &labi.SnpUserGuestRequest{
 		ReqData: &labi.SnpDerivedKeyReqABI{
-			RootKeySelect:    rootKeySelect,
+			RootKeySelect:    request.RootKeySelect(),
 			GuestFieldSelect: request.GuestFieldSelect.ABI(),
 			Vmpl:             request.Vmpl,
 			GuestSVN:         request.GuestSVN,
 			TCBVersion:       request.TCBVersion,
+			LaunchMitVector:  request.LaunchMitVector,
 		},
 		RespData: response,
 	}
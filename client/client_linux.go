@@ -69,6 +69,19 @@ func OpenDevice() (*LinuxDevice, error) {
 	return result, nil
 }
 
+// OpenSharedDevice opens the SEV-SNP guest device for use by many goroutines at once.
+func OpenSharedDevice() (*SharedDevice, error) {
+	result := NewSharedDevice(func() Device { return &LinuxDevice{} })
+	path := *sevGuestPath
+	if UseDefaultSevGuest() {
+		path = defaultSevGuestDevicePath
+	}
+	if err := result.Open(path); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // Close closes the SEV-SNP guest device.
 func (d *LinuxDevice) Close() error {
 	if d.fd == -1 { // Not open
@@ -16,9 +16,12 @@ package client
 
 import (
 	"bytes"
+	"context"
 	"crypto/x509"
+	"errors"
 	"flag"
 	"fmt"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -256,3 +259,179 @@ func TestGetDerivedKey(t *testing.T) {
 		t.Errorf("GetDerivedKey...(nothing) = %v and %v. Expected equality", key1.Data, key3.Data)
 	}
 }
+
+func TestDerivedKeyFirmwareVersion(t *testing.T) {
+	tcs := []struct {
+		name         string
+		req          *SnpDerivedKeyReq
+		major, minor uint32
+		wantErr      string
+	}{
+		{name: "default", req: &SnpDerivedKeyReq{UseVCEK: true}},
+		{name: "vlek", req: &SnpDerivedKeyReq{UseVCEK: true, KeySelect: KeySelectVLEK}, major: 1, minor: 54},
+		{
+			name:    "vlek on old firmware",
+			req:     &SnpDerivedKeyReq{UseVCEK: true, KeySelect: KeySelectVLEK},
+			major:   1,
+			minor:   51,
+			wantErr: "key select requires firmware API version 1.54, but the firmware is 1.51",
+		},
+		{
+			name:  "launch mitigation vector",
+			req:   &SnpDerivedKeyReq{GuestFieldSelect: GuestFieldSelect{LaunchMitVector: true}, LaunchMitVector: 1},
+			major: 1,
+			minor: 58,
+		},
+		{
+			name:    "launch mitigation vector on old firmware",
+			req:     &SnpDerivedKeyReq{GuestFieldSelect: GuestFieldSelect{LaunchMitVector: true}},
+			major:   1,
+			minor:   55,
+			wantErr: "the launch mitigation vector requires firmware API version 1.58",
+		},
+		{
+			name:    "vmrk key select",
+			req:     &SnpDerivedKeyReq{KeySelect: KeySelectVCEK},
+			major:   1,
+			minor:   58,
+			wantErr: "key select 1 requires UseVCEK",
+		},
+		{
+			name:    "reserved key select",
+			req:     &SnpDerivedKeyReq{UseVCEK: true, KeySelect: 3},
+			major:   1,
+			minor:   58,
+			wantErr: "key select 3 is reserved",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.req.CheckFirmwareVersion(tc.major, tc.minor)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Errorf("CheckFirmwareVersion(%d, %d) = %v. Want error %q", tc.major, tc.minor, err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrUnsupportedKeyRequest) {
+				t.Errorf("CheckFirmwareVersion(%d, %d) = %v. Want ErrUnsupportedKeyRequest", tc.major, tc.minor, err)
+			}
+		})
+	}
+
+	wantRoot := map[DerivedKeySelect]uint32{KeySelectDefault: 0, KeySelectVCEK: 2, KeySelectVLEK: 4}
+	for sel, want := range wantRoot {
+		if got := (&SnpDerivedKeyReq{UseVCEK: true, KeySelect: sel}).RootKeySelect(); got != want {
+			t.Errorf("RootKeySelect() with key select %d = %d, want %d", sel, got, want)
+		}
+	}
+	fields := GuestFieldSelect{Measurement: true, LaunchMitVector: true}
+	if got, err := ParseGuestFieldSelect(fields.ABI()); err != nil || got != fields {
+		t.Errorf("ParseGuestFieldSelect(0x%x) = %+v, %v. Want %+v, nil", fields.ABI(), got, err, fields)
+	}
+	if _, err := ParseGuestFieldSelect(1 << 7); err == nil {
+		t.Error("ParseGuestFieldSelect(1 << 7) = _, nil. Want error")
+	}
+
+	// The fake device's firmware reports API version 0.0.
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		return
+	}
+	recorder := &reportVmplDevice{Device: device}
+	_, err := GetDerivedKeyAcknowledgingItsLimitations(recorder, &SnpDerivedKeyReq{UseVCEK: true, KeySelect: KeySelectVCEK, Vmpl: 2})
+	if !errors.Is(err, ErrUnsupportedKeyRequest) {
+		t.Errorf("GetDerivedKeyAcknowledgingItsLimitations(KeySelectVCEK) = _, %v. Want ErrUnsupportedKeyRequest", err)
+	}
+	// A guest at VMPL2 cannot get a report at VMPL0.
+	if len(recorder.vmpls) != 1 || recorder.vmpls[0] != 2 {
+		t.Errorf("firmware version reports were at VMPLs %v, want [2]", recorder.vmpls)
+	}
+}
+
+// reportVmplDevice records the VMPL of each attestation report request.
+type reportVmplDevice struct {
+	Device
+	vmpls []uint32
+}
+
+func (d *reportVmplDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	if sreq, ok := req.(*labi.SnpUserGuestRequest); ok && command == labi.IocSnpGetReport {
+		if r, ok := sreq.ReqData.(*labi.SnpReportReqABI); ok {
+			d.vmpls = append(d.vmpls, r.Vmpl)
+		}
+	}
+	return d.Device.Ioctl(command, req)
+}
+
+func TestDerivedKeyLaunchMitVectorDevice(t *testing.T) {
+	devMu.Do(initDevice)
+	// Like the Linux driver, the fake device takes the request ABI, which drops the vector.
+	for _, req := range []*SnpDerivedKeyReq{
+		{GuestFieldSelect: GuestFieldSelect{LaunchMitVector: true}},
+		{LaunchMitVector: 1},
+	} {
+		_, err := GetDerivedKeyAcknowledgingItsLimitations(device, req)
+		if !errors.Is(err, ErrUnsupportedKeyRequest) || !strings.Contains(err.Error(), "cannot send the launch mitigation vector") {
+			t.Errorf("GetDerivedKeyAcknowledgingItsLimitations(%+v) = _, %v. Want ErrUnsupportedKeyRequest", req, err)
+		}
+	}
+	tcs := []struct {
+		name string
+		d    Device
+		want bool
+	}{
+		{name: "device", d: device},
+		{name: "message device", d: &MessageDevice{}, want: true},
+		{name: "retrying message device", d: DefaultRetryDevice(&MessageDevice{}), want: true},
+		{name: "retrying device", d: DefaultRetryDevice(device)},
+		{name: "checked message device", d: &checkedDevice{Device: &MessageDevice{}, ctx: context.Background()}, want: true},
+		{name: "closed shared device", d: NewSharedDevice(func() Device { return &MessageDevice{} })},
+	}
+	for _, tc := range tcs {
+		if got := sendsLaunchMitVector(tc.d); got != tc.want {
+			t.Errorf("%s: sendsLaunchMitVector() = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestConfigfsReportProvider(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("the fake configfs-tsm tree requires the fake sev-guest device")
+	}
+	tcdev := device.(*test.Device)
+	p := &ConfigfsReportProvider{Root: "/fake/tsm/report", FS: &test.FakeConfigfs{Device: tcdev}}
+	if !p.IsSupported() {
+		t.Fatal("ConfigfsReportProvider.IsSupported() = false, want true")
+	}
+	for _, tc := range tests {
+		if tc.WantErr != "" {
+			continue
+		}
+		raw, certs, err := p.GetRawExtendedReportAtVmpl(tc.Input, 0)
+		if err != nil {
+			t.Fatalf("%s: GetRawExtendedReportAtVmpl(%v, 0) errored unexpectedly: %v", tc.Name, tc.Input, err)
+		}
+		wantRaw, wantCerts, err := GetRawExtendedReport(device, tc.Input)
+		if err != nil {
+			t.Fatalf("%s: GetRawExtendedReport(device, %v) errored unexpectedly: %v", tc.Name, tc.Input, err)
+		}
+		if !bytes.Equal(abi.SignedComponent(raw), abi.SignedComponent(wantRaw)) {
+			t.Errorf("%s: configfs report %v, want %v", tc.Name, raw, wantRaw)
+		}
+		if !bytes.Equal(certs, wantCerts) {
+			t.Errorf("%s: configfs certs %v, want %v", tc.Name, certs, wantCerts)
+		}
+	}
+
+	if _, _, err := p.GetRawExtendedReportAtVmpl([64]byte{}, 4); err == nil || !strings.Contains(err.Error(), "could not write configfs-tsm privlevel 4") {
+		t.Errorf("GetRawExtendedReportAtVmpl(_, 4) = %v, want privlevel error", err)
+	}
+
+	tdx := &ConfigfsReportProvider{Root: "/fake/tsm/report", FS: &test.FakeConfigfs{Device: tcdev, Provider: "tdx_guest"}}
+	if tdx.IsSupported() {
+		t.Error("ConfigfsReportProvider.IsSupported() = true for a tdx_guest provider, want false")
+	}
+	wantErr := `configfs-tsm provider is "tdx_guest", expected "sev_guest"`
+	if _, _, err := tdx.GetRawExtendedReportAtVmpl([64]byte{}, 0); err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("GetRawExtendedReportAtVmpl() = %v, want error %q", err, wantErr)
+	}
+}
@@ -0,0 +1,232 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/go-sev-guest/abi"
+	pb "github.com/google/go-sev-guest/proto/sevsnp"
+)
+
+const (
+	// DefaultTsmReportPath is the usual mount location of the configfs-tsm report subsystem.
+	DefaultTsmReportPath = "/sys/kernel/config/tsm/report"
+	// sevGuestTsmProvider is the provider attribute value the sev-guest driver reports.
+	sevGuestTsmProvider = "sev_guest"
+	// tsmReportWrites is the number of attribute writes a report request makes, which the entry's
+	// generation attribute must match if no other process wrote to the entry concurrently.
+	tsmReportWrites = 2
+)
+
+// ReportProvider encapsulates a kernel interface that produces extended attestation reports.
+type ReportProvider interface {
+	// IsSupported returns whether the provider can produce reports on this machine.
+	IsSupported() bool
+	// GetRawExtendedReportAtVmpl returns the raw attestation report that incorporates the given
+	// user data at the given VMPL, and the raw certificate table for the report's signing key.
+	GetRawExtendedReportAtVmpl(reportData [64]byte, vmpl int) ([]byte, []byte, error)
+	// Close releases any resources the provider holds.
+	Close() error
+}
+
+// DeviceReportProvider implements ReportProvider with commands to a SEV guest Device.
+type DeviceReportProvider struct {
+	Device Device
+}
+
+// IsSupported returns whether the provider has a device.
+func (p *DeviceReportProvider) IsSupported() bool {
+	return p.Device != nil
+}
+
+// GetRawExtendedReportAtVmpl requests an extended attestation report from the device.
+func (p *DeviceReportProvider) GetRawExtendedReportAtVmpl(reportData [64]byte, vmpl int) ([]byte, []byte, error) {
+	return GetRawExtendedReportAtVmpl(p.Device, reportData, vmpl)
+}
+
+// Close closes the device.
+func (p *DeviceReportProvider) Close() error {
+	return p.Device.Close()
+}
+
+// TsmFS is the set of file operations the configfs-tsm report provider performs. Tests may
+// substitute a fake configfs tree.
+type TsmFS interface {
+	MkdirTemp(dir, pattern string) (string, error)
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte) error
+	Remove(name string) error
+}
+
+// osTsmFS implements TsmFS with the host filesystem.
+type osTsmFS struct{}
+
+func (osTsmFS) MkdirTemp(dir, pattern string) (string, error) {
+	return os.MkdirTemp(dir, pattern)
+}
+
+func (osTsmFS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+func (osTsmFS) WriteFile(name string, data []byte) error {
+	return os.WriteFile(name, data, 0200)
+}
+
+func (osTsmFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// ConfigfsReportProvider implements ReportProvider with the Linux configfs-tsm report interface.
+// Each request creates a fresh report entry, so the provider holds no state between requests.
+type ConfigfsReportProvider struct {
+	// Root is the configfs-tsm report directory. If empty, uses DefaultTsmReportPath.
+	Root string
+	// FS performs the file operations. If nil, uses the host filesystem.
+	FS TsmFS
+}
+
+func (p *ConfigfsReportProvider) root() string {
+	if p.Root == "" {
+		return DefaultTsmReportPath
+	}
+	return p.Root
+}
+
+func (p *ConfigfsReportProvider) fs() TsmFS {
+	if p.FS == nil {
+		return osTsmFS{}
+	}
+	return p.FS
+}
+
+// withEntry creates a report entry, checks that the SEV guest driver provides it, and calls f
+// with the entry's directory before removing the entry.
+func (p *ConfigfsReportProvider) withEntry(f func(entry string) error) (err error) {
+	fs := p.fs()
+	entry, err := fs.MkdirTemp(p.root(), "entry")
+	if err != nil {
+		return fmt.Errorf("could not create configfs-tsm report entry: %v", err)
+	}
+	defer func() {
+		if rerr := fs.Remove(entry); rerr != nil && err == nil {
+			err = fmt.Errorf("could not remove configfs-tsm report entry %s: %v", entry, rerr)
+		}
+	}()
+	provider, err := fs.ReadFile(path.Join(entry, "provider"))
+	if err != nil {
+		return fmt.Errorf("could not read configfs-tsm provider: %v", err)
+	}
+	if got := strings.TrimSpace(string(provider)); got != sevGuestTsmProvider {
+		return fmt.Errorf("configfs-tsm provider is %q, expected %q", got, sevGuestTsmProvider)
+	}
+	return f(entry)
+}
+
+// IsSupported returns whether the configfs-tsm report interface exists and is backed by the SEV
+// guest driver.
+func (p *ConfigfsReportProvider) IsSupported() bool {
+	return p.withEntry(func(string) error { return nil }) == nil
+}
+
+// GetRawExtendedReportAtVmpl requests an attestation report and its certificate table through a
+// configfs-tsm report entry. The results match those of the package-level
+// GetRawExtendedReportAtVmpl.
+func (p *ConfigfsReportProvider) GetRawExtendedReportAtVmpl(reportData [64]byte, vmpl int) ([]byte, []byte, error) {
+	var report, certs []byte
+	err := p.withEntry(func(entry string) error {
+		fs := p.fs()
+		if err := fs.WriteFile(path.Join(entry, "privlevel"), []byte(strconv.Itoa(vmpl))); err != nil {
+			return fmt.Errorf("could not write configfs-tsm privlevel %d: %v", vmpl, err)
+		}
+		if err := fs.WriteFile(path.Join(entry, "inblob"), reportData[:]); err != nil {
+			return fmt.Errorf("could not write configfs-tsm inblob: %v", err)
+		}
+		var err error
+		report, certs, err = p.readReport(entry, tsmReportWrites)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return report, certs, nil
+}
+
+// readReport reads the attestation report and certificate table of a report entry and checks
+// that the entry's generation matches the given number of attribute writes.
+func (p *ConfigfsReportProvider) readReport(entry string, writes int) ([]byte, []byte, error) {
+	fs := p.fs()
+	outblob, err := fs.ReadFile(path.Join(entry, "outblob"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read configfs-tsm outblob: %v", err)
+	}
+	if len(outblob) < abi.ReportSize {
+		return nil, nil, fmt.Errorf("configfs-tsm outblob size is 0x%x, expected at least 0x%x", len(outblob), abi.ReportSize)
+	}
+	auxblob, err := fs.ReadFile(path.Join(entry, "auxblob"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read configfs-tsm auxblob: %v", err)
+	}
+	generation, err := fs.ReadFile(path.Join(entry, "generation"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not read configfs-tsm generation: %v", err)
+	}
+	gen, err := strconv.Atoi(strings.TrimSpace(string(generation)))
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not parse configfs-tsm generation %q: %v", generation, err)
+	}
+	if gen != writes {
+		return nil, nil, fmt.Errorf("configfs-tsm report entry %s generation is %d, expected %d. The entry was written concurrently",
+			entry, gen, writes)
+	}
+	return outblob[:abi.ReportSize], auxblob, nil
+}
+
+// Close is a no-op since the provider holds no resources between requests.
+func (p *ConfigfsReportProvider) Close() error {
+	return nil
+}
+
+// OpenReportProvider returns a configfs-tsm report provider if the kernel supports it and
+// -sev_guest_device_path is "default". Otherwise it returns a provider for the opened SEV guest
+// device.
+func OpenReportProvider() (ReportProvider, error) {
+	if UseDefaultSevGuest() {
+		p := &ConfigfsReportProvider{}
+		if p.IsSupported() {
+			return p, nil
+		}
+	}
+	d, err := OpenDevice()
+	if err != nil {
+		return nil, err
+	}
+	return &DeviceReportProvider{Device: d}, nil
+}
+
+// GetExtendedReportFromProvider gets an extended attestation report at the given VMPL from the
+// given provider into a structured type.
+func GetExtendedReportFromProvider(p ReportProvider, reportData [64]byte, vmpl int) (*pb.Attestation, error) {
+	reportBytes, certBytes, err := p.GetRawExtendedReportAtVmpl(reportData, vmpl)
+	if err != nil {
+		return nil, err
+	}
+	return extendedReportToProto(reportBytes, certBytes)
+}
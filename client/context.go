@@ -0,0 +1,68 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+
+	pb "github.com/google/go-sev-guest/proto/sevsnp"
+)
+
+// ContextDevice is a Device whose commands can be bound to a context.
+type ContextDevice interface {
+	Device
+	// WithContext returns a view of the device whose commands give up waiting once ctx is done.
+	WithContext(ctx context.Context) Device
+}
+
+// checkedDevice checks its context before each command of a device that cannot bind its commands
+// to a context.
+type checkedDevice struct {
+	Device
+	ctx context.Context
+}
+
+func (d *checkedDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	if err := d.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return d.Device.Ioctl(command, req)
+}
+
+func (d *checkedDevice) sendsLaunchMitVector() bool { return sendsLaunchMitVector(d.Device) }
+
+// withContext returns a view of d whose commands are bound to ctx. A command to a device that is
+// not a ContextDevice cannot be interrupted, so ctx is only checked before each command.
+func withContext(ctx context.Context, d Device) Device {
+	if cd, ok := d.(ContextDevice); ok {
+		return cd.WithContext(ctx)
+	}
+	return &checkedDevice{Device: d, ctx: ctx}
+}
+
+// GetReportContext is GetReport, but gives up once ctx is done.
+func GetReportContext(ctx context.Context, d Device, reportData [64]byte) (*pb.Report, error) {
+	return GetReport(withContext(ctx, d), reportData)
+}
+
+// GetRawExtendedReportContext is GetRawExtendedReport, but gives up once ctx is done.
+func GetRawExtendedReportContext(ctx context.Context, d Device, reportData [64]byte) ([]byte, []byte, error) {
+	return GetRawExtendedReport(withContext(ctx, d), reportData)
+}
+
+// GetExtendedReportContext is GetExtendedReport, but gives up once ctx is done.
+func GetExtendedReportContext(ctx context.Context, d Device, reportData [64]byte) (*pb.Attestation, error) {
+	return GetExtendedReport(withContext(ctx, d), reportData)
+}
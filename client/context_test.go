@@ -0,0 +1,84 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestGetExtendedReportContext(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("context cancellation is tested with the fake sev-guest device")
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	f := &sharedFixture{}
+	shared := NewSharedDevice(f.newDevice(t))
+	if err := shared.Open("/dev/sev-guest"); err != nil {
+		t.Fatal(err)
+	}
+	defer shared.Close()
+	tcs := []struct {
+		name    string
+		ctx     context.Context
+		d       Device
+		wantErr error
+	}{
+		{name: "device", ctx: context.Background(), d: device},
+		{name: "device cancelled", ctx: cancelled, d: device, wantErr: context.Canceled},
+		{name: "shared", ctx: context.Background(), d: shared},
+		{name: "shared cancelled", ctx: cancelled, d: shared, wantErr: context.Canceled},
+		{
+			name:    "retry cancelled",
+			ctx:     cancelled,
+			d:       &RetryDevice{Device: &throttlingDevice{Device: device}, Timeout: time.Minute, MaxRetryDelay: time.Second},
+			wantErr: context.Canceled,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := GetExtendedReportContext(tc.ctx, tc.d, tests[0].Input)
+			if (tc.wantErr == nil && err != nil) || !errors.Is(err, tc.wantErr) {
+				t.Errorf("GetExtendedReportContext() = _, %v, want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryDeviceContext(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("throttling is simulated with the fake sev-guest device")
+	}
+	d := &throttlingDevice{Device: device, throttles: 100, err: syscall.EAGAIN}
+	r := &RetryDevice{Device: d, Timeout: time.Minute, MaxRetryDelay: time.Second}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := GetReportContext(ctx, r, tests[0].Input); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetReportContext() = _, %v, want DeadlineExceeded", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("GetReportContext() retried for %v after its context's deadline", waited)
+	}
+	if d.calls != 1 {
+		t.Errorf("GetReportContext() made %d device calls, want 1", d.calls)
+	}
+}
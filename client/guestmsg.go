@@ -0,0 +1,221 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-sev-guest/abi"
+	labi "github.com/google/go-sev-guest/client/linuxabi"
+)
+
+// The message version of MSG_REPORT_REQ, MSG_KEY_REQ, and MSG_EXPORT_REQ that MessageDevice sends.
+const guestMessageVersion = 1
+
+// GuestMessageTransport delivers encrypted SNP guest messages to the AMD-SP, e.g., with the GHCB's
+// SNP guest request event or through an SVSM.
+type GuestMessageTransport interface {
+	// Send delivers a request message and returns the response message. If the host reports a
+	// firmware error, the error is an *abi.SevFirmwareErr.
+	Send(request []byte) ([]byte, error)
+}
+
+// CertificateTransport is a GuestMessageTransport that also provides the certificate table the
+// host returns with extended guest requests.
+type CertificateTransport interface {
+	GuestMessageTransport
+	Certificates() ([]byte, error)
+}
+
+// MessageDevice implements the Device interface by wrapping guest messages itself with a VMPCK
+// instead of relying on the Linux sev-guest driver.
+type MessageDevice struct {
+	transport GuestMessageTransport
+	session   *abi.GuestMessageSession
+	isOpen    bool
+}
+
+// NewMessageDevice returns an open device that sends guest messages encrypted with the VMPCK with
+// the given key and index over transport. The sequence number is the number of messages already
+// exchanged with the VMPCK.
+func NewMessageDevice(transport GuestMessageTransport, vmpck []byte, vmpckIndex uint8, seqno uint64) (*MessageDevice, error) {
+	if transport == nil {
+		return nil, errors.New("guest message transport is nil")
+	}
+	session, err := abi.NewGuestMessageSession(vmpck, vmpckIndex, seqno)
+	if err != nil {
+		return nil, err
+	}
+	return &MessageDevice{transport: transport, session: session, isOpen: true}, nil
+}
+
+// Open reopens a closed device. The path is ignored.
+func (d *MessageDevice) Open(_ string) error {
+	if d.isOpen {
+		return errors.New("device already open")
+	}
+	d.isOpen = true
+	return nil
+}
+
+// Close closes the device.
+func (d *MessageDevice) Close() error {
+	d.isOpen = false
+	return nil
+}
+
+// exchange sends the request payload and returns the response payload.
+func (d *MessageDevice) exchange(msgType abi.GuestMessageType, payload []byte) ([]byte, error) {
+	if !d.isOpen {
+		return nil, errors.New("device is not open")
+	}
+	request, err := d.session.Wrap(msgType, guestMessageVersion, payload)
+	if err != nil {
+		return nil, err
+	}
+	response, err := d.transport.Send(request)
+	if err != nil {
+		return nil, err
+	}
+	return d.session.Unwrap(response)
+}
+
+// setFwErr records a firmware error the way the Linux driver does for message to interpret.
+func setFwErr(err error, fwErr *uint64) error {
+	var sevErr *abi.SevFirmwareErr
+	if errors.As(err, &sevErr) {
+		*fwErr = uint64(sevErr.Status)
+	}
+	return err
+}
+
+func (d *MessageDevice) getReport(req *labi.SnpReportReqABI, rsp *labi.SnpReportRespABI, fwErr *uint64) error {
+	payload, err := d.exchange(abi.MsgReportReq, (&abi.ReportRequest{ReportData: req.ReportData, Vmpl: req.Vmpl}).Marshal())
+	if err != nil {
+		return setFwErr(err, fwErr)
+	}
+	report, err := abi.ParseReportResponse(payload)
+	if err != nil {
+		return err
+	}
+	if len(report.Report) > len(rsp.Data) {
+		return fmt.Errorf("report size %d exceeds %d", len(report.Report), len(rsp.Data))
+	}
+	rsp.Status = report.Status
+	rsp.ReportSize = uint32(len(report.Report))
+	copy(rsp.Data[:], report.Report)
+	return rsp.Finish(nil)
+}
+
+func (d *MessageDevice) getExtReport(req *labi.SnpExtendedReportReq, rsp *labi.SnpReportRespABI, fwErr *uint64) error {
+	transport, ok := d.transport.(CertificateTransport)
+	if !ok {
+		return errors.New("guest message transport does not provide certificates")
+	}
+	certs, err := transport.Certificates()
+	if err != nil {
+		return err
+	}
+	// Like the host, report the needed certificate buffer size without issuing the request.
+	if int(req.CertsLength) < len(certs) {
+		req.CertsLength = uint32(len(certs))
+		*fwErr = uint64(abi.GuestRequestInvalidLength)
+		return &abi.SevFirmwareErr{Status: abi.GuestRequestInvalidLength}
+	}
+	if err := d.getReport(&req.Data, rsp, fwErr); err != nil {
+		return err
+	}
+	copy(req.Certs, certs)
+	req.CertsLength = uint32(len(certs))
+	return nil
+}
+
+func (d *MessageDevice) getDerivedKey(req *labi.SnpDerivedKeyReqABI, rsp *labi.SnpDerivedKeyRespABI, fwErr *uint64) error {
+	payload, err := d.exchange(abi.MsgKeyReq, (&abi.KeyRequest{
+		RootKeySelect:    req.RootKeySelect,
+		GuestFieldSelect: req.GuestFieldSelect,
+		Vmpl:             req.Vmpl,
+		GuestSVN:         req.GuestSVN,
+		TCBVersion:       req.TCBVersion,
+		LaunchMitVector:  req.LaunchMitVector,
+	}).Marshal())
+	if err != nil {
+		return setFwErr(err, fwErr)
+	}
+	key, err := abi.ParseKeyResponse(payload)
+	if err != nil {
+		return err
+	}
+	rsp.Status = key.Status
+	rsp.Data = key.DerivedKey
+	return rsp.Finish(nil)
+}
+
+func (d *MessageDevice) sendsLaunchMitVector() bool { return true }
+
+// Ioctl performs the sev-guest driver command by sending the equivalent guest message.
+func (d *MessageDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	sreq, ok := req.(*labi.SnpUserGuestRequest)
+	if !ok {
+		return 0, fmt.Errorf("unexpected request value: %v", req)
+	}
+	var err error
+	switch command {
+	case labi.IocSnpGetReport:
+		reqData, reqOk := sreq.ReqData.(*labi.SnpReportReqABI)
+		rspData, rspOk := sreq.RespData.(*labi.SnpReportRespABI)
+		if !reqOk || !rspOk {
+			return 0, fmt.Errorf("unexpected get_report request types %T and %T", sreq.ReqData, sreq.RespData)
+		}
+		err = d.getReport(reqData, rspData, &sreq.FwErr)
+	case labi.IocSnpGetExtendedReport:
+		reqData, reqOk := sreq.ReqData.(*labi.SnpExtendedReportReq)
+		rspData, rspOk := sreq.RespData.(*labi.SnpReportRespABI)
+		if !reqOk || !rspOk {
+			return 0, fmt.Errorf("unexpected get_ext_report request types %T and %T", sreq.ReqData, sreq.RespData)
+		}
+		err = d.getExtReport(reqData, rspData, &sreq.FwErr)
+	case labi.IocSnpGetDerivedKey:
+		reqData, reqOk := sreq.ReqData.(*labi.SnpDerivedKeyReqABI)
+		rspData, rspOk := sreq.RespData.(*labi.SnpDerivedKeyRespABI)
+		if !reqOk || !rspOk {
+			return 0, fmt.Errorf("unexpected get_derived_key request types %T and %T", sreq.ReqData, sreq.RespData)
+		}
+		err = d.getDerivedKey(reqData, rspData, &sreq.FwErr)
+	default:
+		return 0, fmt.Errorf("invalid command 0x%x", command)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return uintptr(labi.EsOk), nil
+}
+
+// Export asks the firmware to export the guest page at gpa with MSG_EXPORT_REQ.
+func (d *MessageDevice) Export(gpa uint64, imiEn bool) (*abi.ExportResponse, error) {
+	payload, err := d.exchange(abi.MsgExportReq, (&abi.ExportRequest{GPA: gpa, IMIEn: imiEn}).Marshal())
+	if err != nil {
+		return nil, err
+	}
+	rsp, err := abi.ParseExportResponse(payload)
+	if err != nil {
+		return nil, err
+	}
+	if rsp.Status != 0 {
+		return nil, fmt.Errorf("msg_export_req status code: 0x%x", rsp.Status)
+	}
+	return rsp, nil
+}
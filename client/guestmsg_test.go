@@ -0,0 +1,102 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/google/go-sev-guest/abi"
+	test "github.com/google/go-sev-guest/testing"
+)
+
+func TestMessageDevice(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("the fake AMD-SP requires the fake sev-guest device")
+	}
+	tcdev := device.(*test.Device)
+	vmpck := bytes.Repeat([]byte{0x5a}, abi.VmpckSize)
+	sp := &test.AmdSP{Device: tcdev, Exports: map[uint64][]byte{0x1000: {1, 2, 3}}}
+	sp.Vmpcks[2] = vmpck
+	d, err := NewMessageDevice(sp, vmpck, 2, 0)
+	if err != nil {
+		t.Fatalf("NewMessageDevice() = _, %v, want nil", err)
+	}
+
+	exchanges := 0
+	for _, tc := range tests {
+		if tc.WantErr != "" {
+			continue
+		}
+		exchanges++
+		raw, certs, err := GetRawExtendedReport(d, tc.Input)
+		if err != nil {
+			t.Fatalf("%s: GetRawExtendedReport(message device, %v) errored unexpectedly: %v", tc.Name, tc.Input, err)
+		}
+		wantRaw, wantCerts, err := GetRawExtendedReport(device, tc.Input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(abi.SignedComponent(raw), abi.SignedComponent(wantRaw)) {
+			t.Errorf("%s: message device report %v, want %v", tc.Name, raw, wantRaw)
+		}
+		if !bytes.Equal(certs, wantCerts) {
+			t.Errorf("%s: message device certs %v, want %v", tc.Name, certs, wantCerts)
+		}
+	}
+	key, err := GetDerivedKeyAcknowledgingItsLimitations(d, &SnpDerivedKeyReq{UseVCEK: true, GuestFieldSelect: GuestFieldSelect{GuestPolicy: true}})
+	if err != nil {
+		t.Fatalf("GetDerivedKeyAcknowledgingItsLimitations(message device) = _, %v, want nil", err)
+	}
+	if !bytes.Equal(key.Data[:], bytes.Repeat([]byte{1}, 32)) {
+		t.Errorf("derived key is %v, want all ones", key.Data)
+	}
+	export, err := d.Export(0x1000, false)
+	if err != nil || !bytes.Equal(export.Page, []byte{1, 2, 3}) {
+		t.Errorf("Export(0x1000) = %v, %v, want page [1 2 3]", export, err)
+	}
+	if _, err := d.Export(0x2000, false); err == nil || !strings.Contains(err.Error(), "0x16") {
+		t.Errorf("Export(0x2000) = _, %v, want status 0x16", err)
+	}
+	// Each exchange takes a request and a response sequence number. The derived key and both
+	// exports are the exchanges besides the reports.
+	if got, want := sp.SequenceNumber(2), uint64(2*(exchanges+3)); got != want {
+		t.Errorf("AMD-SP sequence number is %d, want %d", got, want)
+	}
+
+	// A firmware error leaves the request without a response, so the device refuses to continue
+	// rather than reuse its sequence number.
+	for _, tc := range tests {
+		if tc.FwErr == 0 {
+			continue
+		}
+		if _, err := GetReport(d, tc.Input); !test.Match(err, tc.WantErr) {
+			t.Errorf("%s: GetReport(message device) = _, %v, want %q", tc.Name, err, tc.WantErr)
+		}
+		if _, err := GetReport(d, [64]byte{}); err == nil || !strings.Contains(err.Error(), "no response") {
+			t.Errorf("GetReport() after a firmware error = _, %v, want no response error", err)
+		}
+	}
+
+	wrongKey, err := NewMessageDevice(sp, bytes.Repeat([]byte{1}, abi.VmpckSize), 2, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetReport(wrongKey, [64]byte{}); err == nil || !strings.Contains(err.Error(), "authenticate") {
+		t.Errorf("GetReport(wrong VMPCK) = _, %v, want authentication error", err)
+	}
+}
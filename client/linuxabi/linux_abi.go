@@ -162,7 +162,8 @@ func (r *SnpReportRespABI) Finish(_ BinaryConvertible) error {
 // SnpDerivedKeyReqABI is the ABI representation of a request to the SEV guest device to derive a
 // key from specified information.
 type SnpDerivedKeyReqABI struct {
-	// RootKeySelect is all reserved bits except bit 0 for UseVMRK (1) or UseVCEK (0).
+	// RootKeySelect has bit 0 for UseVMRK (1) or UseVCEK (0), and bits 2:1 for KEY_SEL, which
+	// selects between the VCEK and the VLEK when bit 0 is 0. Other bits are reserved.
 	RootKeySelect    uint32
 	reserved         uint32
 	GuestFieldSelect uint64
@@ -172,6 +173,9 @@ type SnpDerivedKeyReqABI struct {
 	GuestSVN uint32
 	// TCBVersion to mix into the key. Must be less than or equal to the CommittedTcb.
 	TCBVersion uint64
+	// LaunchMitVector to mix into the key if GuestFieldSelect bit 6 is set. The Linux driver copies
+	// only the fields before it into MSG_KEY_REQ, so only a client.MessageDevice sends it.
+	LaunchMitVector uint64
 }
 
 // Pointer returns a pointer to the object.
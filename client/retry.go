@@ -0,0 +1,115 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	labi "github.com/google/go-sev-guest/client/linuxabi"
+)
+
+const initialRetryDelay = 250 * time.Millisecond
+
+// RetryDevice is a meta-device that retries commands the host throttled, doubling the delay between
+// attempts. Other errors are returned immediately.
+type RetryDevice struct {
+	// Device is the non-retrying device.
+	Device Device
+	// Timeout is how long to retry before failure.
+	Timeout time.Duration
+	// MaxRetryDelay is the maximum amount of time to wait between retries.
+	MaxRetryDelay time.Duration
+}
+
+// DefaultRetryDevice returns a device that retries throttled commands to d for up to 2 minutes.
+func DefaultRetryDevice(d Device) *RetryDevice {
+	return &RetryDevice{
+		Device:        d,
+		Timeout:       2 * time.Minute,
+		MaxRetryDelay: 30 * time.Second,
+	}
+}
+
+// Open opens the underlying device.
+func (d *RetryDevice) Open(path string) error {
+	return d.Device.Open(path)
+}
+
+// Close closes the underlying device.
+func (d *RetryDevice) Close() error {
+	return d.Device.Close()
+}
+
+// throttled returns whether a command failed because the host throttled it.
+func throttled(req any, err error) bool {
+	if sreq, ok := req.(*labi.SnpUserGuestRequest); ok && abi.SevFirmwareStatus(sreq.FwErr) == abi.GuestRequestBusy {
+		return true
+	}
+	return errors.Is(classifyErr(err), abi.ErrThrottled)
+}
+
+// Ioctl sends the command to the underlying device, retrying while the host throttles it. If the
+// timeout passes, returns the last attempt's result.
+func (d *RetryDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	return d.ioctl(context.Background(), command, req)
+}
+
+func (d *RetryDevice) ioctl(ctx context.Context, command uintptr, req any) (uintptr, error) {
+	deadline := time.Now().Add(d.Timeout)
+	delay := initialRetryDelay
+	dev := withContext(ctx, d.Device)
+	for {
+		result, err := dev.Ioctl(command, req)
+		if err == nil || !throttled(req, err) {
+			return result, err
+		}
+		if delay > d.MaxRetryDelay {
+			delay = d.MaxRetryDelay
+		}
+		if time.Now().Add(delay).After(deadline) {
+			return result, err
+		}
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay += delay
+		// The next attempt reports its own firmware error.
+		if sreq, ok := req.(*labi.SnpUserGuestRequest); ok {
+			sreq.FwErr = 0
+		}
+	}
+}
+
+func (d *RetryDevice) sendsLaunchMitVector() bool { return sendsLaunchMitVector(d.Device) }
+
+// WithContext returns a view of the device that stops retrying once ctx is done, and whose
+// underlying device commands are bound to ctx.
+func (d *RetryDevice) WithContext(ctx context.Context) Device {
+	return &retryDeviceContext{RetryDevice: d, ctx: ctx}
+}
+
+type retryDeviceContext struct {
+	*RetryDevice
+	ctx context.Context
+}
+
+func (d *retryDeviceContext) Ioctl(command uintptr, req any) (uintptr, error) {
+	return d.ioctl(d.ctx, command, req)
+}
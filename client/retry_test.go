@@ -0,0 +1,114 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	test "github.com/google/go-sev-guest/testing"
+)
+
+// throttlingDevice fails its first `throttles` commands with err, and passes the rest to Device.
+type throttlingDevice struct {
+	Device
+	throttles int
+	err       error
+	calls     int
+}
+
+func (d *throttlingDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	d.calls++
+	if d.calls <= d.throttles {
+		return 0, d.err
+	}
+	return d.Device.Ioctl(command, req)
+}
+
+// busyTransport reports the host as busy for its first `throttles` messages.
+type busyTransport struct {
+	*test.AmdSP
+	throttles int
+}
+
+func (t *busyTransport) Send(request []byte) ([]byte, error) {
+	if t.throttles > 0 {
+		t.throttles--
+		return nil, &abi.SevFirmwareErr{Status: abi.GuestRequestBusy}
+	}
+	return t.AmdSP.Send(request)
+}
+
+func TestRetryDevice(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("throttling is simulated with the fake sev-guest device")
+	}
+	tcs := []struct {
+		name      string
+		throttles int
+		err       error
+		timeout   time.Duration
+		// wantCalls is the number of device calls to expect, or -1 if timing dependent.
+		wantCalls int
+		wantErr   error
+	}{
+		{name: "success", timeout: time.Second, wantCalls: 1},
+		{name: "throttled", throttles: 2, err: syscall.EAGAIN, timeout: time.Second, wantCalls: 3},
+		{name: "timeout", throttles: 100, err: syscall.EAGAIN, timeout: 10 * time.Millisecond, wantCalls: -1, wantErr: abi.ErrThrottled},
+		{name: "vmpck disabled", throttles: 100, err: syscall.ENOTTY, timeout: time.Second, wantCalls: 1, wantErr: abi.ErrVmpckDisabled},
+		{name: "driver timeout", throttles: 100, err: syscall.ETIMEDOUT, timeout: time.Second, wantCalls: 1, wantErr: abi.ErrVmpckDisabled},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &throttlingDevice{Device: device, throttles: tc.throttles, err: tc.err}
+			r := &RetryDevice{Device: d, Timeout: tc.timeout, MaxRetryDelay: 5 * time.Millisecond}
+			_, err := GetReport(r, tests[0].Input)
+			if (tc.wantErr == nil && err != nil) || !errors.Is(err, tc.wantErr) {
+				t.Errorf("GetReport() = _, %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantCalls >= 0 && d.calls != tc.wantCalls {
+				t.Errorf("GetReport() made %d device calls, want %d", d.calls, tc.wantCalls)
+			}
+		})
+	}
+
+	// A throttled guest message is resent unchanged, so the VMPCK stays usable.
+	tcdev := device.(*test.Device)
+	vmpck := bytes.Repeat([]byte{0x5a}, abi.VmpckSize)
+	sp := &test.AmdSP{Device: tcdev}
+	sp.Vmpcks[0] = vmpck
+	transport := &busyTransport{AmdSP: sp, throttles: 2}
+	md, err := NewMessageDevice(transport, vmpck, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetReport(md, tests[0].Input); !errors.Is(err, abi.ErrThrottled) {
+		t.Errorf("GetReport(busy message device) = _, %v, want ErrThrottled", err)
+	}
+	r := &RetryDevice{Device: md, Timeout: time.Second, MaxRetryDelay: 5 * time.Millisecond}
+	for i := 0; i < 2; i++ {
+		if _, err := GetReport(r, tests[0].Input); err != nil {
+			t.Fatalf("GetReport(retrying message device) = _, %v, want nil", err)
+		}
+	}
+	if sp.SequenceNumber(0) != 4 {
+		t.Errorf("AMD-SP sequence number is %d, want 4", sp.SequenceNumber(0))
+	}
+}
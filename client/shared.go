@@ -0,0 +1,183 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"syscall"
+
+	labi "github.com/google/go-sev-guest/client/linuxabi"
+)
+
+// SharedDevice is a Device that many goroutines may use at once. It serializes commands to one
+// underlying device, counts opens so that the underlying device closes with its last user, and
+// reopens the underlying device once when a command fails because its file descriptor became
+// unusable, e.g., after the sev-guest driver is reloaded. A disabled VMPCK stays disabled for the
+// life of the guest, so its errors are returned as is.
+//
+// If reopening fails, the device stays open for its users, and the next command tries to reopen
+// the underlying device again.
+//
+// A command that has started cannot be cancelled, so contexts bound only the wait for the device.
+type SharedDevice struct {
+	newDevice func() Device
+	// sem holds a token while a goroutine uses the fields below or the underlying device.
+	sem  chan struct{}
+	refs int
+	dev  Device
+	path string
+}
+
+// NewSharedDevice returns a closed SharedDevice whose underlying devices newDevice creates, e.g.,
+// func() Device { return &LinuxDevice{} }. Each call must return a new, unopened device.
+func NewSharedDevice(newDevice func() Device) *SharedDevice {
+	return &SharedDevice{newDevice: newDevice, sem: make(chan struct{}, 1)}
+}
+
+func (s *SharedDevice) acquire(ctx context.Context) error {
+	select {
+	case s.sem <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	// Both cases may have been ready, so don't start a command for a done context.
+	if err := ctx.Err(); err != nil {
+		s.release()
+		return err
+	}
+	return nil
+}
+
+func (s *SharedDevice) release() {
+	<-s.sem
+}
+
+// Open opens the underlying device at path if this is the first open, and otherwise counts another
+// user of the already open device.
+func (s *SharedDevice) Open(path string) error {
+	if err := s.acquire(context.Background()); err != nil {
+		return err
+	}
+	defer s.release()
+	if s.refs == 0 {
+		dev := s.newDevice()
+		if err := dev.Open(path); err != nil {
+			return err
+		}
+		s.dev = dev
+		s.path = path
+	}
+	s.refs++
+	return nil
+}
+
+// Close closes the underlying device if this is the last open.
+func (s *SharedDevice) Close() error {
+	if err := s.acquire(context.Background()); err != nil {
+		return err
+	}
+	defer s.release()
+	if s.refs == 0 {
+		return errors.New("device already closed")
+	}
+	s.refs--
+	if s.refs > 0 {
+		return nil
+	}
+	if s.dev == nil {
+		// The last reopen failed, so there is no underlying device to close.
+		return nil
+	}
+	err := s.dev.Close()
+	s.dev = nil
+	return err
+}
+
+// needsReopen returns whether err means the underlying device can no longer serve commands.
+func needsReopen(err error) bool {
+	var errno syscall.Errno
+	return errors.As(err, &errno) && (errno == syscall.EBADF || errno == syscall.ENODEV || errno == syscall.ENXIO)
+}
+
+func (s *SharedDevice) reopen() error {
+	if s.dev != nil {
+		// The old device is unusable, so its close error is uninteresting.
+		s.dev.Close()
+		s.dev = nil
+	}
+	dev := s.newDevice()
+	if err := dev.Open(s.path); err != nil {
+		return fmt.Errorf("could not reopen device at %s: %v", s.path, err)
+	}
+	s.dev = dev
+	return nil
+}
+
+// Ioctl sends a command to the underlying device once no other command is in progress.
+func (s *SharedDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	return s.IoctlContext(context.Background(), command, req)
+}
+
+// IoctlContext is Ioctl, but gives up waiting for other commands to finish when ctx is done.
+func (s *SharedDevice) IoctlContext(ctx context.Context, command uintptr, req any) (uintptr, error) {
+	if err := s.acquire(ctx); err != nil {
+		return 0, err
+	}
+	defer s.release()
+	if s.refs == 0 {
+		return 0, errors.New("device is not open")
+	}
+	if s.dev == nil {
+		if err := s.reopen(); err != nil {
+			return 0, err
+		}
+	}
+	result, err := s.dev.Ioctl(command, req)
+	if err == nil || !needsReopen(err) {
+		return result, err
+	}
+	if rerr := s.reopen(); rerr != nil {
+		return 0, fmt.Errorf("%v; %v", err, rerr)
+	}
+	if sreq, ok := req.(*labi.SnpUserGuestRequest); ok {
+		sreq.FwErr = 0
+	}
+	return s.dev.Ioctl(command, req)
+}
+
+func (s *SharedDevice) sendsLaunchMitVector() bool {
+	if err := s.acquire(context.Background()); err != nil {
+		return false
+	}
+	defer s.release()
+	return s.dev != nil && sendsLaunchMitVector(s.dev)
+}
+
+// WithContext returns a view of the device whose commands use ctx as IoctlContext does. Opening
+// and closing the view opens and closes the shared device.
+func (s *SharedDevice) WithContext(ctx context.Context) Device {
+	return &sharedDeviceContext{SharedDevice: s, ctx: ctx}
+}
+
+type sharedDeviceContext struct {
+	*SharedDevice
+	ctx context.Context
+}
+
+func (d *sharedDeviceContext) Ioctl(command uintptr, req any) (uintptr, error) {
+	return d.IoctlContext(d.ctx, command, req)
+}
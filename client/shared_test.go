@@ -0,0 +1,262 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	test "github.com/google/go-sev-guest/testing"
+)
+
+// exclusiveDevice records whether its commands ever overlap.
+type exclusiveDevice struct {
+	Device
+	inUse    *int32
+	overlaps *int32
+	// failFirst is the error of the device's first command, if any.
+	failFirst error
+	calls     int
+	// openErr is the error of opening the device, if any.
+	openErr error
+}
+
+func (d *exclusiveDevice) Open(path string) error {
+	if d.openErr != nil {
+		return d.openErr
+	}
+	return d.Device.Open(path)
+}
+
+func (d *exclusiveDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	if atomic.AddInt32(d.inUse, 1) != 1 {
+		atomic.AddInt32(d.overlaps, 1)
+	}
+	defer atomic.AddInt32(d.inUse, -1)
+	d.calls++
+	if d.calls == 1 && d.failFirst != nil {
+		return 0, d.failFirst
+	}
+	return d.Device.Ioctl(command, req)
+}
+
+// blockingDevice blocks its commands until release is closed, and closes started on its first.
+type blockingDevice struct {
+	Device
+	once    sync.Once
+	started chan struct{}
+	release chan struct{}
+}
+
+func (d *blockingDevice) Ioctl(command uintptr, req any) (uintptr, error) {
+	d.once.Do(func() { close(d.started) })
+	<-d.release
+	return d.Device.Ioctl(command, req)
+}
+
+type sharedFixture struct {
+	inUse, overlaps int32
+	created         int
+	failFirst       []error
+	openErrs        []error
+}
+
+// newDevice returns a fresh fake device that shares the test suite device's signer and responses.
+func (f *sharedFixture) newDevice(t *testing.T) func() Device {
+	tcdev := device.(*test.Device)
+	return func() Device {
+		d, err := test.TcDevice(tests, &test.DeviceOptions{Keys: tcdev.Keys, Signer: tcdev.Signer})
+		if err != nil {
+			t.Fatal(err)
+		}
+		result := &exclusiveDevice{Device: d, inUse: &f.inUse, overlaps: &f.overlaps}
+		if f.created < len(f.failFirst) {
+			result.failFirst = f.failFirst[f.created]
+		}
+		if f.created < len(f.openErrs) {
+			result.openErr = f.openErrs[f.created]
+		}
+		f.created++
+		return result
+	}
+}
+
+func TestSharedDeviceConcurrency(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("the shared device test uses fake sev-guest devices")
+	}
+	f := &sharedFixture{}
+	s := NewSharedDevice(f.newDevice(t))
+	if err := s.Open("/dev/sev-guest"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 64)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.Open("/dev/sev-guest"); err != nil {
+				errs <- err
+				return
+			}
+			defer s.Close()
+			for j := 0; j < 2; j++ {
+				tc := tests[(i+j)%len(tests)]
+				if _, err := GetReport(s, tc.Input); !test.Match(err, tc.WantErr) {
+					errs <- err
+				}
+				if _, err := GetDerivedKeyAcknowledgingItsLimitations(s, &SnpDerivedKeyReq{UseVCEK: true}); err != nil {
+					errs <- err
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if f.overlaps != 0 {
+		t.Errorf("commands overlapped %d times, want 0", f.overlaps)
+	}
+	if f.created != 1 {
+		t.Errorf("created %d underlying devices, want 1", f.created)
+	}
+
+	// The first open is still outstanding.
+	if _, err := GetReport(s, tests[0].Input); err != nil {
+		t.Errorf("GetReport() after other users closed = _, %v, want nil", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetReport(s, tests[0].Input); err == nil {
+		t.Error("GetReport() after the last close = _, nil, want error")
+	}
+	if err := s.Close(); err == nil {
+		t.Error("Close() of a closed device = nil, want error")
+	}
+}
+
+func TestSharedDeviceReopen(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("the shared device test uses fake sev-guest devices")
+	}
+	tcs := []struct {
+		name        string
+		failFirst   []error
+		wantCreated int
+		wantErr     bool
+	}{
+		{name: "vmpck disabled", failFirst: []error{syscall.ENOTTY}, wantCreated: 1, wantErr: true},
+		{name: "bad fd", failFirst: []error{syscall.EBADF}, wantCreated: 2},
+		{name: "no device", failFirst: []error{syscall.ENODEV}, wantCreated: 2},
+		{name: "no reopen", failFirst: []error{syscall.EINVAL}, wantCreated: 1, wantErr: true},
+		{name: "reopen once", failFirst: []error{syscall.EBADF, syscall.EBADF}, wantCreated: 2, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			f := &sharedFixture{failFirst: tc.failFirst}
+			s := NewSharedDevice(f.newDevice(t))
+			if err := s.Open("/dev/sev-guest"); err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			if _, err := GetReport(s, tests[0].Input); (err != nil) != tc.wantErr {
+				t.Errorf("GetReport() = _, %v, want error %v", err, tc.wantErr)
+			}
+			if f.created != tc.wantCreated {
+				t.Errorf("created %d underlying devices, want %d", f.created, tc.wantCreated)
+			}
+		})
+	}
+}
+
+func TestSharedDeviceReopenFails(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("the shared device test uses fake sev-guest devices")
+	}
+	f := &sharedFixture{failFirst: []error{syscall.EBADF}, openErrs: []error{nil, syscall.ENOENT}}
+	s := NewSharedDevice(f.newDevice(t))
+	if err := s.Open("/dev/sev-guest"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := GetReport(s, tests[0].Input); err == nil {
+		t.Error("GetReport() with a failed reopen = _, nil, want error")
+	}
+	// The device is still open, so the next command reopens it.
+	if _, err := GetReport(s, tests[0].Input); err != nil {
+		t.Errorf("GetReport() after a failed reopen = _, %v, want nil", err)
+	}
+	if f.created != 3 {
+		t.Errorf("created %d underlying devices, want 3", f.created)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close() = %v, want nil", err)
+	}
+	if err := s.Close(); err == nil {
+		t.Error("Close() of a closed device = nil, want error")
+	}
+}
+
+func TestSharedDeviceContext(t *testing.T) {
+	devMu.Do(initDevice)
+	if !UseDefaultSevGuest() {
+		t.Skip("the shared device test uses fake sev-guest devices")
+	}
+	f := &sharedFixture{}
+	newDevice := f.newDevice(t)
+	blocking := &blockingDevice{Device: newDevice(), started: make(chan struct{}), release: make(chan struct{})}
+	s := NewSharedDevice(func() Device { return blocking })
+	if err := s.Open("/dev/sev-guest"); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := GetReport(s, tests[0].Input)
+		done <- err
+	}()
+	<-blocking.started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := GetReport(s.WithContext(ctx), tests[0].Input); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetReport(WithContext(timeout)) = _, %v, want DeadlineExceeded", err)
+	}
+	cancelled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := GetReport(s.WithContext(cancelled), tests[0].Input); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetReport(WithContext(cancelled)) = _, %v, want Canceled", err)
+	}
+
+	close(blocking.release)
+	if err := <-done; err != nil {
+		t.Errorf("blocked GetReport() = _, %v, want nil", err)
+	}
+	if _, err := GetReport(s.WithContext(context.Background()), tests[0].Input); err != nil {
+		t.Errorf("GetReport(WithContext()) = _, %v, want nil", err)
+	}
+}
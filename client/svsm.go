@@ -0,0 +1,97 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	pb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/pborman/uuid"
+)
+
+// SvsmServiceProvider is the configfs-tsm service_provider value that routes a report request
+// through the attestation protocol of a Secure VM Service Module (SVSM) running at VMPL0.
+const SvsmServiceProvider = "svsm"
+
+// SvsmRequest selects what an SVSM attestation covers.
+type SvsmRequest struct {
+	// Nonce is hashed together with the returned manifest into the report's REPORT_DATA.
+	Nonce [64]byte
+	// ServiceGUID selects a single service to attest, as the SVSM_ATTEST_SINGLE_SERVICE call does.
+	// The manifest is then that service's manifest. If empty, SVSM_ATTEST_SERVICES attests all
+	// services and the manifest is the services manifest, abi.SvsmServicesManifest.
+	ServiceGUID string
+	// ManifestVersion is the version of the single service's manifest to request.
+	ManifestVersion uint32
+}
+
+// GetRawSvsmReport requests an attestation report that the SVSM produces at VMPL0 on behalf of a
+// guest that runs at a less privileged VMPL. It returns the raw report, the raw certificate table
+// for the report's signing key, and the raw manifest that the report's REPORT_DATA binds as
+// abi.SvsmReportData(req.Nonce[:], manifest).
+func (p *ConfigfsReportProvider) GetRawSvsmReport(req *SvsmRequest) ([]byte, []byte, []byte, error) {
+	var report, certs, manifest []byte
+	err := p.withEntry(func(entry string) error {
+		fs := p.fs()
+		// The service_provider and inblob writes, and the service_guid and service_manifest_version
+		// writes of a single-service request.
+		writes := 2
+		if err := fs.WriteFile(path.Join(entry, "service_provider"), []byte(SvsmServiceProvider)); err != nil {
+			return fmt.Errorf("could not write configfs-tsm service_provider %q: %v", SvsmServiceProvider, err)
+		}
+		if req.ServiceGUID != "" {
+			if uuid.Parse(req.ServiceGUID) == nil {
+				return fmt.Errorf("GUID string format is XXXXXXXX-XXXX-XXXX-XXXXXXXXXXXXXXXX, got %s", req.ServiceGUID)
+			}
+			if err := fs.WriteFile(path.Join(entry, "service_guid"), []byte(req.ServiceGUID)); err != nil {
+				return fmt.Errorf("could not write configfs-tsm service_guid %s: %v", req.ServiceGUID, err)
+			}
+			version := strconv.FormatUint(uint64(req.ManifestVersion), 10)
+			if err := fs.WriteFile(path.Join(entry, "service_manifest_version"), []byte(version)); err != nil {
+				return fmt.Errorf("could not write configfs-tsm service_manifest_version %s: %v", version, err)
+			}
+			writes += 2
+		}
+		if err := fs.WriteFile(path.Join(entry, "inblob"), req.Nonce[:]); err != nil {
+			return fmt.Errorf("could not write configfs-tsm inblob: %v", err)
+		}
+		var err error
+		if manifest, err = fs.ReadFile(path.Join(entry, "manifestblob")); err != nil {
+			return fmt.Errorf("could not read configfs-tsm manifestblob: %v", err)
+		}
+		report, certs, err = p.readReport(entry, writes)
+		return err
+	})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return report, certs, manifest, nil
+}
+
+// GetSvsmAttestation requests an SVSM attestation from the given provider and returns the
+// attestation in a structured type together with the raw manifest its REPORT_DATA binds.
+func GetSvsmAttestation(p *ConfigfsReportProvider, req *SvsmRequest) (*pb.Attestation, []byte, error) {
+	reportBytes, certBytes, manifest, err := p.GetRawSvsmReport(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	attestation, err := extendedReportToProto(reportBytes, certBytes)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attestation, manifest, nil
+}
@@ -0,0 +1,89 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	test "github.com/google/go-sev-guest/testing"
+	"github.com/pborman/uuid"
+)
+
+func TestGetSvsmAttestation(t *testing.T) {
+	if !UseDefaultSevGuest() {
+		t.Skip("the fake SVSM requires the fake sev-guest device")
+	}
+	manifest := &abi.SvsmServicesManifest{Services: []abi.SvsmService{
+		{GUID: uuid.Parse(abi.SvsmVtpmServiceGUID), Manifest: []byte("vTPM EK")},
+	}}
+	all, err := manifest.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := [64]byte{1, 2, 3}
+	var tcs []test.TestCase
+	for _, m := range [][]byte{all, []byte("vTPM EK")} {
+		reportData := abi.SvsmReportData(nonce[:], m)
+		tcs = append(tcs, test.TestCase{Input: reportData, Output: test.TestRawReport(reportData)})
+	}
+	d, err := test.TcDevice(tcs, &test.DeviceOptions{Now: time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &ConfigfsReportProvider{Root: "/fake/tsm/report", FS: &test.FakeConfigfs{Device: d, SvsmManifest: manifest}}
+
+	for _, tc := range []struct {
+		name         string
+		req          *SvsmRequest
+		wantManifest []byte
+		wantErr      string
+	}{
+		{name: "all services", req: &SvsmRequest{Nonce: nonce}, wantManifest: all},
+		{name: "vTPM", req: &SvsmRequest{Nonce: nonce, ServiceGUID: abi.SvsmVtpmServiceGUID}, wantManifest: []byte("vTPM EK")},
+		{
+			name:    "unknown manifest version",
+			req:     &SvsmRequest{Nonce: nonce, ServiceGUID: abi.SvsmVtpmServiceGUID, ManifestVersion: 1},
+			wantErr: "could not read configfs-tsm manifestblob",
+		},
+		{name: "bad GUID", req: &SvsmRequest{Nonce: nonce, ServiceGUID: "vtpm"}, wantErr: "GUID string format"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			attestation, manifest, err := GetSvsmAttestation(p, tc.req)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("GetSvsmAttestation() = _, _, %v. Want error %q", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !bytes.Equal(manifest, tc.wantManifest) {
+				t.Errorf("GetSvsmAttestation() manifest = %x, want %x", manifest, tc.wantManifest)
+			}
+			want := abi.SvsmReportData(nonce[:], tc.wantManifest)
+			if got := attestation.GetReport().GetReportData(); !bytes.Equal(got, want[:]) {
+				t.Errorf("GetSvsmAttestation() REPORT_DATA = %x, want %x", got, want)
+			}
+		})
+	}
+
+	noSvsm := &ConfigfsReportProvider{Root: "/fake/tsm/report", FS: &test.FakeConfigfs{Device: d}}
+	wantErr := "could not write configfs-tsm service_provider"
+	if _, _, _, err := noSvsm.GetRawSvsmReport(&SvsmRequest{}); err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("GetRawSvsmReport() without an SVSM = %v, want error %q", err, wantErr)
+	}
+}
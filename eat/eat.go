@@ -0,0 +1,368 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eat exports SEV-SNP attestations as IETF RATS Entity Attestation Tokens (EAT) in the
+// JSON Web Token form. Evidence tokens carry the claims of an attestation report, and attestation
+// result tokens in the EAT Attestation Result (EAR) format carry a verifier's appraisal of one, so
+// that a verifier built on this module can act as a RATS Verifier.
+package eat
+
+import (
+	"crypto"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/google/go-sev-guest/verify"
+)
+
+const (
+	// EvidenceProfile is the eat_profile of SEV-SNP evidence tokens.
+	EvidenceProfile = "tag:github.com,2026:google/go-sev-guest/eat/sevsnp"
+	// ResultProfile is the eat_profile of EAT Attestation Results.
+	ResultProfile = "tag:github.com,2023:veraison/ear"
+	// Submod is the name of the SEV-SNP guest in an attestation result's submods.
+	Submod = "sevsnp"
+)
+
+// ErrExpired is returned when a token's "exp" claim is in the past.
+var ErrExpired = errors.New("token has expired")
+
+// SnpClaims are the claims of an SEV-SNP attestation report. Byte fields are hex-encoded and
+// 64-bit fields are hex strings, since JSON numbers lose precision beyond 53 bits.
+type SnpClaims struct {
+	Version         uint32 `json:"version"`
+	GuestSvn        uint32 `json:"guest_svn"`
+	Policy          string `json:"policy"`
+	FamilyID        string `json:"family_id"`
+	ImageID         string `json:"image_id"`
+	Vmpl            uint32 `json:"vmpl"`
+	SigningKey      string `json:"signing_key"`
+	PlatformInfo    string `json:"platform_info"`
+	ReportData      string `json:"report_data"`
+	Measurement     string `json:"measurement"`
+	HostData        string `json:"host_data"`
+	IDKeyDigest     string `json:"id_key_digest"`
+	AuthorKeyDigest string `json:"author_key_digest"`
+	ReportID        string `json:"report_id"`
+	ReportIDMA      string `json:"report_id_ma"`
+	ChipID          string `json:"chip_id"`
+	CurrentTcb      string `json:"current_tcb"`
+	ReportedTcb     string `json:"reported_tcb"`
+	CommittedTcb    string `json:"committed_tcb"`
+	LaunchTcb       string `json:"launch_tcb"`
+	// FirmwareVersion is the current AMD-SP firmware version as major.minor.build.
+	FirmwareVersion string `json:"firmware_version"`
+}
+
+func hexUint64(v uint64) string {
+	return fmt.Sprintf("0x%x", v)
+}
+
+// NewSnpClaims returns the claims of an attestation report.
+func NewSnpClaims(report *spb.Report) (*SnpClaims, error) {
+	info, err := abi.ParseSignerInfo(report.GetSignerInfo())
+	if err != nil {
+		return nil, err
+	}
+	return &SnpClaims{
+		Version:         report.GetVersion(),
+		GuestSvn:        report.GetGuestSvn(),
+		Policy:          hexUint64(report.GetPolicy()),
+		FamilyID:        hex.EncodeToString(report.GetFamilyId()),
+		ImageID:         hex.EncodeToString(report.GetImageId()),
+		Vmpl:            report.GetVmpl(),
+		SigningKey:      info.SigningKey.String(),
+		PlatformInfo:    hexUint64(report.GetPlatformInfo()),
+		ReportData:      hex.EncodeToString(report.GetReportData()),
+		Measurement:     hex.EncodeToString(report.GetMeasurement()),
+		HostData:        hex.EncodeToString(report.GetHostData()),
+		IDKeyDigest:     hex.EncodeToString(report.GetIdKeyDigest()),
+		AuthorKeyDigest: hex.EncodeToString(report.GetAuthorKeyDigest()),
+		ReportID:        hex.EncodeToString(report.GetReportId()),
+		ReportIDMA:      hex.EncodeToString(report.GetReportIdMa()),
+		ChipID:          hex.EncodeToString(report.GetChipId()),
+		CurrentTcb:      hexUint64(report.GetCurrentTcb()),
+		ReportedTcb:     hexUint64(report.GetReportedTcb()),
+		CommittedTcb:    hexUint64(report.GetCommittedTcb()),
+		LaunchTcb:       hexUint64(report.GetLaunchTcb()),
+		FirmwareVersion: fmt.Sprintf("%d.%d.%d", report.GetCurrentMajor(), report.GetCurrentMinor(),
+			report.GetCurrentBuild()),
+	}, nil
+}
+
+// Evidence is the claims set of an SEV-SNP evidence token.
+type Evidence struct {
+	Profile  string `json:"eat_profile"`
+	Issuer   string `json:"iss,omitempty"`
+	IssuedAt int64  `json:"iat"`
+	// Expiry is the token's "exp" claim in seconds since the epoch. Unchecked if 0.
+	Expiry int64 `json:"exp,omitempty"`
+	// Nonce is the base64url-encoded REPORT_DATA, which binds the report to the relying party's
+	// challenge.
+	Nonce  string     `json:"eat_nonce"`
+	SevSnp *SnpClaims `json:"sevsnp"`
+	// Report is the base64url-encoded attestation report in its ABI format, so that a relying party
+	// may verify its AMD signature itself.
+	Report string `json:"sevsnp_report,omitempty"`
+}
+
+// NewEvidence returns the evidence claims of an attestation, issued at the given time.
+func NewEvidence(attestation *spb.Attestation, issuedAt time.Time) (*Evidence, error) {
+	claims, err := NewSnpClaims(attestation.GetReport())
+	if err != nil {
+		return nil, fmt.Errorf("could not convert report to claims: %v", err)
+	}
+	raw, err := abi.ReportToAbiBytes(attestation.GetReport())
+	if err != nil {
+		return nil, fmt.Errorf("could not convert report to its ABI format: %v", err)
+	}
+	return &Evidence{
+		Profile:  EvidenceProfile,
+		IssuedAt: issuedAt.Unix(),
+		Nonce:    base64.RawURLEncoding.EncodeToString(attestation.GetReport().GetReportData()),
+		SevSnp:   claims,
+		Report:   base64.RawURLEncoding.EncodeToString(raw),
+	}, nil
+}
+
+// Status is the overall appraisal of an attestation result.
+type Status string
+
+const (
+	// StatusNone means the verifier makes no claim about the attester.
+	StatusNone Status = "none"
+	// StatusAffirming means the verifier found the attester trustworthy.
+	StatusAffirming Status = "affirming"
+	// StatusWarning means the verifier found the attester trustworthy with reservations.
+	StatusWarning Status = "warning"
+	// StatusContraindicated means the verifier found the attester untrustworthy.
+	StatusContraindicated Status = "contraindicated"
+)
+
+// Trustworthiness claim values that are common to every claim of a trustworthiness vector.
+const (
+	// NoClaim means the verifier makes no claim.
+	NoClaim = 0
+	// Affirming means the verifier affirms the claim.
+	Affirming = 2
+	// Contraindicated means the verifier found the claim untrustworthy.
+	Contraindicated = 96
+)
+
+// TrustVector is the AR4SI trustworthiness vector of an appraisal.
+type TrustVector struct {
+	InstanceIdentity int `json:"instance-identity,omitempty"`
+	Configuration    int `json:"configuration,omitempty"`
+	Executables      int `json:"executables,omitempty"`
+	FileSystem       int `json:"file-system,omitempty"`
+	Hardware         int `json:"hardware,omitempty"`
+	RuntimeOpaque    int `json:"runtime-opaque,omitempty"`
+	StorageOpaque    int `json:"storage-opaque,omitempty"`
+	SourcedData      int `json:"sourced-data,omitempty"`
+}
+
+// Appraisal is a verifier's appraisal of one attester.
+type Appraisal struct {
+	Status      Status       `json:"ear.status"`
+	TrustVector *TrustVector `json:"ear.trustworthiness-vector,omitempty"`
+	PolicyID    string       `json:"ear.appraisal-policy-id,omitempty"`
+	// Evidence is the appraised report's claims.
+	Evidence *SnpClaims `json:"ear.veraison.annotated-evidence,omitempty"`
+	// Checks maps the name of each check to its status, e.g., "PASSED".
+	Checks map[string]string `json:"sevsnp.checks,omitempty"`
+}
+
+// VerifierID identifies the verifier that issued an attestation result.
+type VerifierID struct {
+	Developer string `json:"developer"`
+	Build     string `json:"build"`
+}
+
+// AttestationResult is the claims set of an EAT Attestation Result.
+type AttestationResult struct {
+	Profile  string `json:"eat_profile"`
+	Issuer   string `json:"iss,omitempty"`
+	IssuedAt int64  `json:"iat"`
+	// Expiry is the token's "exp" claim in seconds since the epoch. Unchecked if 0.
+	Expiry     int64                 `json:"exp,omitempty"`
+	VerifierID VerifierID            `json:"ear.verifier-id"`
+	Nonce      string                `json:"eat_nonce,omitempty"`
+	Submods    map[string]*Appraisal `json:"submods"`
+}
+
+// Appraisal returns the SEV-SNP appraisal of the result, or nil if there is none.
+func (r *AttestationResult) Appraisal() *Appraisal {
+	return r.Submods[Submod]
+}
+
+// trustClaims groups the names of checks by the trustworthiness claim they inform. Checks that
+// inform no claim, such as report_data, still affect the status.
+var trustClaims = []struct {
+	claim  func(*TrustVector) *int
+	checks []string
+}{
+	{
+		claim:  func(v *TrustVector) *int { return &v.InstanceIdentity },
+		checks: []string{validate.ChipIDCheck, validate.ReportIDCheck, validate.ReportIDMACheck},
+	},
+	{
+		claim: func(v *TrustVector) *int { return &v.Configuration },
+		checks: []string{validate.PolicyCheck, validate.VmplCheck, validate.PlatformInfoCheck,
+			validate.HostDataCheck, validate.GuestSvnCheck},
+	},
+	{
+		claim: func(v *TrustVector) *int { return &v.Executables },
+		checks: []string{validate.MeasurementCheck, validate.FamilyIDCheck, validate.ImageIDCheck,
+			validate.IDBlockCheck},
+	},
+	{
+		claim: func(v *TrustVector) *int { return &v.Hardware },
+		checks: []string{verify.CertificateChainCheck, verify.CRLCheck, verify.SignatureCheck,
+			validate.SignerInfoCheck, validate.EndorsementKeyCheck, validate.EndorsementHWIDCheck,
+			validate.ReportedTcbCheck, validate.CurrentTcbCheck, validate.CommittedTcbCheck,
+			validate.LaunchTcbCheck, validate.FirmwareVersionCheck, validate.CPUIDCheck,
+			validate.MitigationsCheck},
+	},
+}
+
+// claimValue is Contraindicated if any of the named checks failed, Affirming if any passed, and
+// NoClaim otherwise.
+func claimValue(result *checks.Result, names []string) int {
+	value := NoClaim
+	for _, name := range names {
+		c := result.Get(name)
+		if c == nil {
+			continue
+		}
+		switch c.Status {
+		case checks.Failed:
+			return Contraindicated
+		case checks.Passed:
+			value = Affirming
+		}
+	}
+	return value
+}
+
+// NewAppraisal returns the appraisal of a report from the outcome of its verification and
+// validation checks. Any failed check contraindicates the attester. Memory encryption is affirmed
+// once the report signature is verified.
+func NewAppraisal(report *spb.Report, result *checks.Result) (*Appraisal, error) {
+	claims, err := NewSnpClaims(report)
+	if err != nil {
+		return nil, fmt.Errorf("could not convert report to claims: %v", err)
+	}
+	vector := &TrustVector{}
+	for _, c := range trustClaims {
+		*c.claim(vector) = claimValue(result, c.checks)
+	}
+	if c := result.Get(verify.SignatureCheck); c != nil && c.Status == checks.Passed {
+		vector.RuntimeOpaque = Affirming
+	}
+	status := StatusNone
+	if len(result.Failures()) != 0 {
+		status = StatusContraindicated
+	} else if vector.Hardware == Affirming {
+		status = StatusAffirming
+	}
+	appraisal := &Appraisal{
+		Status:      status,
+		TrustVector: vector,
+		Evidence:    claims,
+		Checks:      make(map[string]string),
+	}
+	for _, c := range result.Checks {
+		appraisal.Checks[c.Name] = c.Status.String()
+	}
+	return appraisal, nil
+}
+
+// NewAttestationResult returns the attestation result claims of an attestation given the outcome
+// of its verification and validation checks, issued at the given time by the identified verifier.
+func NewAttestationResult(attestation *spb.Attestation, result *checks.Result, issuedAt time.Time, id VerifierID) (*AttestationResult, error) {
+	appraisal, err := NewAppraisal(attestation.GetReport(), result)
+	if err != nil {
+		return nil, err
+	}
+	return &AttestationResult{
+		Profile:    ResultProfile,
+		IssuedAt:   issuedAt.Unix(),
+		VerifierID: id,
+		Nonce:      base64.RawURLEncoding.EncodeToString(attestation.GetReport().GetReportData()),
+		Submods:    map[string]*Appraisal{Submod: appraisal},
+	}, nil
+}
+
+func checkClaims(profile, wantProfile string, expiry int64, now time.Time) error {
+	if profile != wantProfile {
+		return fmt.Errorf("token eat_profile is %q. Expect %q", profile, wantProfile)
+	}
+	if expiry != 0 && now.Unix() > expiry {
+		return fmt.Errorf("%w: exp %s is before %s", ErrExpired, time.Unix(expiry, 0).UTC().Format(time.RFC3339),
+			now.UTC().Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ParseEvidence verifies an evidence token with the trusted keys and returns its claims. Does not
+// verify the report's AMD signature.
+func ParseEvidence(token string, keys []crypto.PublicKey, now time.Time) (*Evidence, error) {
+	result := &Evidence{}
+	if err := Verify(token, keys, result); err != nil {
+		return nil, err
+	}
+	if err := checkClaims(result.Profile, EvidenceProfile, result.Expiry, now); err != nil {
+		return nil, err
+	}
+	if result.SevSnp == nil {
+		return nil, errors.New("evidence has no sevsnp claims")
+	}
+	return result, nil
+}
+
+// Attestation returns the attestation report that the evidence carries.
+func (e *Evidence) Attestation() (*spb.Attestation, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(e.Report)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode sevsnp_report: %v", err)
+	}
+	report, err := abi.ReportToProto(raw)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse sevsnp_report: %v", err)
+	}
+	return &spb.Attestation{Report: report}, nil
+}
+
+// ParseAttestationResult verifies an attestation result token with the trusted keys and returns
+// its claims.
+func ParseAttestationResult(token string, keys []crypto.PublicKey, now time.Time) (*AttestationResult, error) {
+	result := &AttestationResult{}
+	if err := Verify(token, keys, result); err != nil {
+		return nil, err
+	}
+	if err := checkClaims(result.Profile, ResultProfile, result.Expiry, now); err != nil {
+		return nil, err
+	}
+	if result.Appraisal() == nil {
+		return nil, fmt.Errorf("attestation result has no %q submod", Submod)
+	}
+	return result, nil
+}
@@ -0,0 +1,293 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eat
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/google/go-sev-guest/verify"
+	"google.golang.org/protobuf/proto"
+)
+
+func ecdsaKey(t *testing.T, curve elliptic.Curve) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func attestation(t *testing.T) *spb.Attestation {
+	t.Helper()
+	raw := make([]byte, abi.ReportSize)
+	raw[0] = 2       // VERSION
+	raw[0x0a] = 0x03 // POLICY 0x30000
+	for i := 0; i < abi.ReportDataSize; i++ {
+		raw[0x50+i] = byte(i)
+	}
+	raw[0x90] = 0xaa // MEASUREMENT
+	report, err := abi.ReportToProto(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &spb.Attestation{Report: report}
+}
+
+func TestSignVerify(t *testing.T) {
+	p256 := ecdsaKey(t, elliptic.P256())
+	p384 := ecdsaKey(t, elliptic.P384())
+	p521 := ecdsaKey(t, elliptic.P521())
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims := map[string]any{"eat_profile": "test", "iat": float64(1700000000)}
+	tcs := []struct {
+		name    string
+		key     crypto.Signer
+		trusted []crypto.PublicKey
+		mutate  func(string) string
+		wantErr string
+	}{
+		{name: "ES256", key: p256, trusted: []crypto.PublicKey{&p256.PublicKey}},
+		{name: "ES384", key: p384, trusted: []crypto.PublicKey{&p384.PublicKey}},
+		{name: "ES512", key: p521, trusted: []crypto.PublicKey{&p521.PublicKey}},
+		{name: "RS256", key: rsaKey, trusted: []crypto.PublicKey{&rsaKey.PublicKey}},
+		{name: "second key", key: p384, trusted: []crypto.PublicKey{&rsaKey.PublicKey, &p384.PublicKey}},
+		{
+			name:    "untrusted",
+			key:     p384,
+			trusted: []crypto.PublicKey{&ecdsaKey(t, elliptic.P384()).PublicKey},
+			wantErr: "ECDSA signature did not verify",
+		},
+		{
+			name:    "algorithm substitution",
+			key:     p256,
+			trusted: []crypto.PublicKey{&p384.PublicKey},
+			wantErr: `token algorithm "ES256" does not match key algorithm "ES384"`,
+		},
+		{
+			name:    "none",
+			key:     p384,
+			trusted: []crypto.PublicKey{&p384.PublicKey},
+			mutate: func(token string) string {
+				parts := strings.Split(token, ".")
+				return base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`)) + "." + parts[1] + "."
+			},
+			wantErr: `token algorithm "none"`,
+		},
+		{
+			name:    "tampered",
+			key:     p384,
+			trusted: []crypto.PublicKey{&p384.PublicKey},
+			mutate: func(token string) string {
+				parts := strings.Split(token, ".")
+				parts[1] = base64.RawURLEncoding.EncodeToString([]byte(`{"eat_profile":"evil"}`))
+				return strings.Join(parts, ".")
+			},
+			wantErr: "ECDSA signature did not verify",
+		},
+		{
+			name:    "malformed",
+			key:     p384,
+			trusted: []crypto.PublicKey{&p384.PublicKey},
+			mutate:  func(string) string { return "a.b" },
+			wantErr: "token has 2 parts",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := Sign(claims, tc.key, "key-1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.mutate != nil {
+				token = tc.mutate(token)
+			}
+			var got map[string]any
+			err = Verify(token, tc.trusted, &got)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("Verify() = %v. Want error %q", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(claims, got); diff != "" {
+				t.Errorf("Verify() claims differ (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEvidence(t *testing.T) {
+	key := ecdsaKey(t, elliptic.P384())
+	trusted := []crypto.PublicKey{&key.PublicKey}
+	att := attestation(t)
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	evidence, err := NewEvidence(att, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if evidence.SevSnp.Policy != "0x30000" || evidence.SevSnp.SigningKey != "VCEK" ||
+		!strings.HasPrefix(evidence.SevSnp.Measurement, "aa00") {
+		t.Errorf("NewEvidence() claims = %+v, want policy 0x30000, VCEK signer and measurement aa00...", evidence.SevSnp)
+	}
+	evidence.Expiry = now.Add(time.Hour).Unix()
+	token, err := Sign(evidence, key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := ParseEvidence(token, trusted, now)
+	if err != nil {
+		t.Fatalf("ParseEvidence() = _, %v. Want nil", err)
+	}
+	if diff := cmp.Diff(evidence, got); diff != "" {
+		t.Errorf("ParseEvidence() differs (-want +got):\n%s", diff)
+	}
+	gotAttestation, err := got.Attestation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(gotAttestation, att) {
+		t.Errorf("Attestation() = %v, want %v", gotAttestation, att)
+	}
+	if _, err := ParseEvidence(token, trusted, now.Add(2*time.Hour)); !errors.Is(err, ErrExpired) {
+		t.Errorf("ParseEvidence() after exp = _, %v. Want ErrExpired", err)
+	}
+	result, err := NewAttestationResult(att, &checks.Result{}, now, VerifierID{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resultToken, err := Sign(result, key, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseEvidence(resultToken, trusted, now); err == nil || !strings.Contains(err.Error(), "eat_profile") {
+		t.Errorf("ParseEvidence(attestation result) = _, %v. Want eat_profile error", err)
+	}
+}
+
+func TestAttestationResult(t *testing.T) {
+	key := ecdsaKey(t, elliptic.P384())
+	att := attestation(t)
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	passed := func(names ...string) *checks.Result {
+		result := &checks.Result{}
+		for _, name := range names {
+			result.Record(name, "", "", nil)
+		}
+		return result
+	}
+	failed := func(result *checks.Result, name string) *checks.Result {
+		result.Record(name, "want", "got", errors.New("mismatch"))
+		return result
+	}
+	tcs := []struct {
+		name       string
+		result     *checks.Result
+		wantStatus Status
+		wantVector *TrustVector
+	}{
+		{
+			name:       "no checks",
+			result:     &checks.Result{},
+			wantStatus: StatusNone,
+			wantVector: &TrustVector{},
+		},
+		{
+			name: "affirming",
+			result: passed(verify.SignatureCheck, verify.CertificateChainCheck, validate.MeasurementCheck,
+				validate.PolicyCheck, validate.ChipIDCheck),
+			wantStatus: StatusAffirming,
+			wantVector: &TrustVector{
+				InstanceIdentity: Affirming,
+				Configuration:    Affirming,
+				Executables:      Affirming,
+				Hardware:         Affirming,
+				RuntimeOpaque:    Affirming,
+			},
+		},
+		{
+			name:       "unknown image",
+			result:     failed(passed(verify.SignatureCheck, validate.PolicyCheck), validate.MeasurementCheck),
+			wantStatus: StatusContraindicated,
+			wantVector: &TrustVector{
+				Configuration: Affirming,
+				Executables:   Contraindicated,
+				Hardware:      Affirming,
+				RuntimeOpaque: Affirming,
+			},
+		},
+		{
+			name:       "stale nonce",
+			result:     failed(passed(verify.SignatureCheck), validate.ReportDataCheck),
+			wantStatus: StatusContraindicated,
+			wantVector: &TrustVector{Hardware: Affirming, RuntimeOpaque: Affirming},
+		},
+		{
+			name:       "bad signature",
+			result:     failed(passed(verify.CertificateChainCheck), verify.SignatureCheck),
+			wantStatus: StatusContraindicated,
+			wantVector: &TrustVector{Hardware: Contraindicated},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			id := VerifierID{Developer: "https://example.com", Build: "test 1.0"}
+			claims, err := NewAttestationResult(att, tc.result, now, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			token, err := Sign(claims, key, "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := ParseAttestationResult(token, []crypto.PublicKey{&key.PublicKey}, now)
+			if err != nil {
+				t.Fatalf("ParseAttestationResult() = _, %v. Want nil", err)
+			}
+			if got.VerifierID != id || got.IssuedAt != now.Unix() {
+				t.Errorf("ParseAttestationResult() = %+v, want verifier %v issued at %d", got, id, now.Unix())
+			}
+			appraisal := got.Appraisal()
+			if appraisal.Status != tc.wantStatus {
+				t.Errorf("appraisal status = %q, want %q", appraisal.Status, tc.wantStatus)
+			}
+			if diff := cmp.Diff(tc.wantVector, appraisal.TrustVector); diff != "" {
+				t.Errorf("appraisal trust vector differs (-want +got):\n%s", diff)
+			}
+			for _, c := range tc.result.Checks {
+				if appraisal.Checks[c.Name] != c.Status.String() {
+					t.Errorf("appraisal check %q = %q, want %q", c.Name, appraisal.Checks[c.Name], c.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eat
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256" // Register SHA-256 for RS256 and ES256.
+	_ "crypto/sha512" // Register SHA-384 and SHA-512 for ES384 and ES512.
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// header is the JOSE header of a token.
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ,omitempty"`
+	KeyID     string `json:"kid,omitempty"`
+}
+
+// ErrBadSignature is returned when no trusted key verifies a token's signature.
+var ErrBadSignature = errors.New("token signature did not verify with any trusted key")
+
+// algorithm returns the JWS algorithm name and hash for a public key.
+func algorithm(key crypto.PublicKey) (string, crypto.Hash, error) {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return "ES256", crypto.SHA256, nil
+		case elliptic.P384():
+			return "ES384", crypto.SHA384, nil
+		case elliptic.P521():
+			return "ES512", crypto.SHA512, nil
+		}
+		return "", 0, fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+	case *rsa.PublicKey:
+		return "RS256", crypto.SHA256, nil
+	}
+	return "", 0, fmt.Errorf("unsupported key type %T", key)
+}
+
+func digest(hash crypto.Hash, data string) []byte {
+	h := hash.New()
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+// ecdsaSize is the size in bytes of each of R and S in a JWS ECDSA signature.
+func ecdsaSize(key *ecdsa.PublicKey) int {
+	return (key.Curve.Params().BitSize + 7) / 8
+}
+
+// Sign returns the claims as a JSON Web Token in JWS compact serialization, signed with key. ECDSA
+// keys on P-256, P-384, or P-521 sign with ES256, ES384, or ES512, and RSA keys with RS256. The
+// keyID is the token's "kid" header if not empty.
+func Sign(claims any, key crypto.Signer, keyID string) (string, error) {
+	alg, hash, err := algorithm(key.Public())
+	if err != nil {
+		return "", err
+	}
+	headerBytes, err := json.Marshal(&header{Algorithm: alg, Type: "JWT", KeyID: keyID})
+	if err != nil {
+		return "", err
+	}
+	claimsBytes, err := json.Marshal(claims)
+	if err != nil {
+		return "", fmt.Errorf("could not marshal claims: %v", err)
+	}
+	signed := base64.RawURLEncoding.EncodeToString(headerBytes) + "." +
+		base64.RawURLEncoding.EncodeToString(claimsBytes)
+	signature, err := key.Sign(rand.Reader, digest(hash, signed), hash)
+	if err != nil {
+		return "", fmt.Errorf("could not sign token: %v", err)
+	}
+	if ecKey, ok := key.Public().(*ecdsa.PublicKey); ok {
+		// JWS ECDSA signatures are the fixed-size concatenation of R and S rather than ASN.1.
+		var sig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(signature, &sig); err != nil {
+			return "", fmt.Errorf("could not parse ECDSA signature: %v", err)
+		}
+		size := ecdsaSize(ecKey)
+		signature = make([]byte, 2*size)
+		sig.R.FillBytes(signature[:size])
+		sig.S.FillBytes(signature[size:])
+	}
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func verifySignature(key crypto.PublicKey, alg, signed string, signature []byte) error {
+	keyAlg, hash, err := algorithm(key)
+	if err != nil {
+		return err
+	}
+	if keyAlg != alg {
+		return fmt.Errorf("token algorithm %q does not match key algorithm %q", alg, keyAlg)
+	}
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		size := ecdsaSize(k)
+		if len(signature) != 2*size {
+			return fmt.Errorf("ECDSA signature is %d bytes. Expect %d", len(signature), 2*size)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest(hash, signed), r, s) {
+			return errors.New("ECDSA signature did not verify")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPKCS1v15(k, hash, digest(hash, signed), signature)
+	}
+	return fmt.Errorf("unsupported key type %T", key)
+}
+
+// Verify checks that one of the trusted keys signed the token, and unmarshals the token's claims
+// into claims. The token's algorithm must be the one Sign uses for the key, so "none" and
+// algorithm substitution are refused. Does not check any claim values.
+func Verify(token string, keys []crypto.PublicKey, claims any) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("token has %d parts. Expect 3", len(parts))
+	}
+	headerBytes, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("could not decode token header: %v", err)
+	}
+	var h header
+	if err := json.Unmarshal(headerBytes, &h); err != nil {
+		return fmt.Errorf("could not unmarshal token header: %v", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("could not decode token signature: %v", err)
+	}
+	signed := parts[0] + "." + parts[1]
+	var errs []string
+	verified := false
+	for _, key := range keys {
+		err := verifySignature(key, h.Algorithm, signed, signature)
+		if err == nil {
+			verified = true
+			break
+		}
+		errs = append(errs, err.Error())
+	}
+	if !verified {
+		return fmt.Errorf("%w: [%s]", ErrBadSignature, strings.Join(errs, "; "))
+	}
+	claimsBytes, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return fmt.Errorf("could not decode token claims: %v", err)
+	}
+	if err := json.Unmarshal(claimsBytes, claims); err != nil {
+		return fmt.Errorf("could not unmarshal token claims: %v", err)
+	}
+	return nil
+}
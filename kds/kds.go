@@ -58,7 +58,10 @@ var (
 	// OidUcodeSpl is the x509v3 extension for VCEK microcode security patch level.
 	OidUcodeSpl = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 3704, 1, 3, 8})
 	// OidHwid is the x509v3 extension for VCEK certificate associated hardware identifier.
-	OidHwid         = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 3704, 1, 4})
+	OidHwid = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 3704, 1, 4})
+	// OidCspID is the x509v3 extension for a VLEK certificate's Cloud Service Provider's
+	// origin TLS key's certificate's subject key's CommonName.
+	OidCspID        = asn1.ObjectIdentifier([]int{1, 3, 6, 1, 4, 1, 3704, 1, 5})
 	authorityKeyOid = asn1.ObjectIdentifier([]int{2, 5, 29, 35})
 	// Short forms of the asn1 Object identifiers to use in map lookups, since []int are invalid key
 	// types.
@@ -73,26 +76,36 @@ var (
 	vcekSpl7          = vcekOID{major: 3, minor: 7}
 	vcekUcodeSpl      = vcekOID{major: 3, minor: 8}
 	vcekHwid          = vcekOID{major: 4}
+	vcekCspID         = vcekOID{major: 5}
 
 	kdsHostname = "kdsintf.amd.com"
 	kdsBaseURL  = "https://" + kdsHostname
 	kdsVcekPath = "/vcek/v1/"
+	kdsVlekPath = "/vlek/v1/"
 )
 
 // TCBVersion is a 64-bit bitfield of different security patch levels of AMD firmware and microcode.
 type TCBVersion uint64
 
-// VcekExtensions represents the information stored in the KDS-specified x509 extensions of a VCEK
-// certificate.
-type VcekExtensions struct {
+// Extensions represents the information stored in the KDS-specified x509 extensions of a VCEK or
+// VLEK certificate.
+type Extensions struct {
 	StructVersion uint8
 	ProductName   string
 	// The host driver knows the difference between primary and secondary HWID.
-	// Primary vs secondary is irrelevant to verification.
+	// Primary vs secondary is irrelevant to verification. Only present in VCEK certificates.
 	HWID       [64]byte
 	TCBVersion TCBVersion
+	// CspID is the Cloud Service Provider's identifier. Only present in VLEK certificates.
+	CspID string
 }
 
+// VcekExtensions represents the information stored in the KDS-specified x509 extensions of a VCEK
+// certificate.
+//
+// Deprecated: Use Extensions.
+type VcekExtensions = Extensions
+
 func oidTovcekOID(id asn1.ObjectIdentifier) (vcekOID, error) {
 	if id.Equal(OidStructVersion) {
 		return vcekStructVersion, nil
@@ -127,6 +140,9 @@ func oidTovcekOID(id asn1.ObjectIdentifier) (vcekOID, error) {
 	if id.Equal(OidUcodeSpl) {
 		return vcekUcodeSpl, nil
 	}
+	if id.Equal(OidCspID) {
+		return vcekCspID, nil
+	}
 	return vcekOID{}, fmt.Errorf("not an AMD VCEK OID: %v", id)
 }
 
@@ -275,8 +291,8 @@ func asn1OctetString(ext *pkix.Extension, field string, size int) ([]byte, error
 	return octet, nil
 }
 
-func vcekOidMapToVcekExtensions(exts map[vcekOID]*pkix.Extension) (*VcekExtensions, error) {
-	var result VcekExtensions
+func vcekOidMapToExtensions(exts map[vcekOID]*pkix.Extension, key abi.ReportSigner) (*Extensions, error) {
+	var result Extensions
 
 	if err := asn1U8(exts[vcekStructVersion], "StructVersion", &result.StructVersion); err != nil {
 		return nil, err
@@ -284,11 +300,20 @@ func vcekOidMapToVcekExtensions(exts map[vcekOID]*pkix.Extension) (*VcekExtensio
 	if err := asn1IA5String(exts[vcekProductName1], "ProductName1", &result.ProductName); err != nil {
 		return nil, err
 	}
-	octet, err := asn1OctetString(exts[vcekHwid], "HWID", 64)
-	if err != nil {
-		return nil, err
+	switch key {
+	case abi.VcekReportSigner:
+		octet, err := asn1OctetString(exts[vcekHwid], "HWID", 64)
+		if err != nil {
+			return nil, err
+		}
+		copy(result.HWID[:], octet)
+	case abi.VlekReportSigner:
+		if err := asn1IA5String(exts[vcekCspID], "CSP_ID", &result.CspID); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unexpected endorsement key kind %v", key)
 	}
-	copy(result.HWID[:], octet)
 	var blspl, snpspl, teespl, spl4, spl5, spl6, spl7, ucodespl uint8
 	if err := asn1U8(exts[vcekBlSpl], "BlSpl", &blspl); err != nil {
 		return nil, err
@@ -331,20 +356,90 @@ func vcekOidMapToVcekExtensions(exts map[vcekOID]*pkix.Extension) (*VcekExtensio
 	return &result, nil
 }
 
-// VcekCertificateExtensions returns the x509v3 extensions from the KDS specification interpreted
-// into a struct type.
-func VcekCertificateExtensions(cert *x509.Certificate) (*VcekExtensions, error) {
+// CertificateExtensions returns the x509v3 extensions from the KDS specification interpreted
+// into a struct type for the given kind of endorsement key certificate.
+func CertificateExtensions(cert *x509.Certificate, key abi.ReportSigner) (*Extensions, error) {
 	oidMap, err := vcekOidMap(cert)
 	if err != nil {
 		return nil, err
 	}
-	extensions, err := vcekOidMapToVcekExtensions(oidMap)
+	extensions, err := vcekOidMapToExtensions(oidMap, key)
 	if err != nil {
 		return nil, err
 	}
 	return extensions, nil
 }
 
+// VcekCertificateExtensions returns the x509v3 extensions from the KDS specification interpreted
+// into a struct type.
+func VcekCertificateExtensions(cert *x509.Certificate) (*Extensions, error) {
+	return CertificateExtensions(cert, abi.VcekReportSigner)
+}
+
+// VlekCertificateExtensions returns the x509v3 extensions from the KDS specification of a VLEK
+// certificate interpreted into a struct type.
+func VlekCertificateExtensions(cert *x509.Certificate) (*Extensions, error) {
+	return CertificateExtensions(cert, abi.VlekReportSigner)
+}
+
+// Products lists the AMD product lines with SEV-SNP support that the KDS issues certificates for.
+// Turin is not supported yet, since its VCEK certificate URL takes an 8-byte hwid and its TCB
+// version has an FMC security patch level.
+var Products = []string{"Milan", "Genoa"}
+
+// cpuidProducts maps CPUID extended family to the model ranges of each product line.
+var cpuidProducts = []struct {
+	family   uint8
+	minModel uint8
+	maxModel uint8
+	product  string
+}{
+	{family: 0x19, minModel: 0x00, maxModel: 0x0f, product: "Milan"},
+	{family: 0x19, minModel: 0x10, maxModel: 0x1f, product: "Genoa"},
+	{family: 0x19, minModel: 0xa0, maxModel: 0xaf, product: "Genoa"},
+}
+
+func knownProduct(product string) bool {
+	for _, p := range Products {
+		if p == product {
+			return true
+		}
+	}
+	return false
+}
+
+// ProductLine returns the product line, e.g., "Milan", of the product name value in a VCEK or
+// VLEK certificate's product name extension, e.g., "Milan-B0".
+func ProductLine(productName string) (string, error) {
+	product, _, _ := strings.Cut(productName, "-")
+	if !knownProduct(product) {
+		return "", fmt.Errorf("unknown product name: %v", productName)
+	}
+	return product, nil
+}
+
+// ProductFromCPUID returns the product line of a processor with the given CPUID extended family
+// and extended model identifiers, e.g., as reported in CPUID_FAM_ID and CPUID_MOD_ID.
+func ProductFromCPUID(family, model uint8) (string, error) {
+	for _, p := range cpuidProducts {
+		if p.family == family && p.minModel <= model && model <= p.maxModel {
+			return p.product, nil
+		}
+	}
+	return "", fmt.Errorf("unknown product for CPUID family 0x%x model 0x%x", family, model)
+}
+
+// ProductFromSigningKey returns the product line of the given ASK or ASVK certificate according
+// to its subject common name, e.g., "SEV-Milan" or "SEV-VLEK-Milan".
+func ProductFromSigningKey(cert *x509.Certificate) (string, error) {
+	cn := cert.Subject.CommonName
+	product := strings.TrimPrefix(strings.TrimPrefix(cn, "SEV-"), "VLEK-")
+	if !strings.HasPrefix(cn, "SEV-") || !knownProduct(product) {
+		return "", fmt.Errorf("unknown product signing key common name: %v", cn)
+	}
+	return product, nil
+}
+
 // ParseProductCertChain returns the DER-formatted certificates represented by the body
 // of the ProductCertChain (cert_chain) endpoint, ASK and ARK in that order.
 func ParseProductCertChain(pems []byte) ([]byte, []byte, error) {
@@ -373,7 +468,13 @@ func ParseProductCertChain(pems []byte) ([]byte, []byte, error) {
 
 // productBaseURL returns the base URL for all certificate queries within a particular product.
 func productBaseURL(name string) string {
-	return fmt.Sprintf("%s/vcek/v1/%s", kdsBaseURL, name)
+	return fmt.Sprintf("%s%s%s", kdsBaseURL, kdsVcekPath, name)
+}
+
+// vlekProductBaseURL returns the base URL for all VLEK certificate queries within a particular
+// product.
+func vlekProductBaseURL(name string) string {
+	return fmt.Sprintf("%s%s%s", kdsBaseURL, kdsVlekPath, name)
 }
 
 // ProductCertChainURL returns the AMD KDS URL for retrieving the ARK and ASK
@@ -382,6 +483,21 @@ func ProductCertChainURL(product string) string {
 	return fmt.Sprintf("%s/cert_chain", productBaseURL(product))
 }
 
+// VlekProductCertChainURL returns the AMD KDS URL for retrieving the ARK and ASVK
+// certificates on the given product in PEM format.
+func VlekProductCertChainURL(product string) string {
+	return fmt.Sprintf("%s/cert_chain", vlekProductBaseURL(product))
+}
+
+// CrlURL returns the AMD KDS URL for retrieving the certificate revocation list for
+// the given product's VCEK or VLEK signing intermediates.
+func CrlURL(product string, key abi.ReportSigner) string {
+	if key == abi.VlekReportSigner {
+		return fmt.Sprintf("%s/crl", vlekProductBaseURL(product))
+	}
+	return fmt.Sprintf("%s/crl", productBaseURL(product))
+}
+
 // VCEKCertURL returns the AMD KDS URL for retrieving the VCEK on a given product
 // at a given TCB version. The hwid is the CHIP_ID field in an attestation report.
 func VCEKCertURL(product string, hwid []byte, tcb TCBVersion) string {
@@ -404,9 +520,9 @@ type VCEKCert struct {
 	TCB     uint64
 }
 
-// parseBaseProductURL returns the product name for a root certificate chain URL if it is one,
-// with the parsed URL that has the product prefix trimmed.
-func parseBaseProductURL(kdsurl string) (string, *url.URL, error) {
+// parseBaseProductURL returns the product name for a root certificate chain URL under the given
+// key path if it is one, with the parsed URL that has the product prefix trimmed.
+func parseBaseProductURL(kdsurl, keyPath string) (string, *url.URL, error) {
 	u, err := url.Parse(kdsurl)
 	if err != nil {
 		return "", nil, fmt.Errorf("invalid AMD KDS URL %q: %v", kdsurl, err)
@@ -417,10 +533,10 @@ func parseBaseProductURL(kdsurl string) (string, *url.URL, error) {
 	if u.Host != kdsHostname {
 		return "", nil, fmt.Errorf("unexpected AMD KDS URL host %q, want %q", u.Host, kdsHostname)
 	}
-	if !strings.HasPrefix(u.Path, kdsVcekPath) {
-		return "", nil, fmt.Errorf("unexpected AMD KDS URL path %q, want prefix %q", u.Path, kdsVcekPath)
+	if !strings.HasPrefix(u.Path, keyPath) {
+		return "", nil, fmt.Errorf("unexpected AMD KDS URL path %q, want prefix %q", u.Path, keyPath)
 	}
-	function := strings.TrimPrefix(u.Path, kdsVcekPath)
+	function := strings.TrimPrefix(u.Path, keyPath)
 
 	// The following should be product/endpoint
 	pieces := strings.Split(function, "/")
@@ -434,10 +550,8 @@ func parseBaseProductURL(kdsurl string) (string, *url.URL, error) {
 	return product, u, nil
 }
 
-// ParseProductCertChainURL returns the product name for a KDS cert_chain url, or an error if the
-// input is not a KDS cert_chain url.
-func ParseProductCertChainURL(kdsurl string) (string, error) {
-	product, u, err := parseBaseProductURL(kdsurl)
+func parseProductCertChainURL(kdsurl, keyPath string) (string, error) {
+	product, u, err := parseBaseProductURL(kdsurl, keyPath)
 	if err != nil {
 		return "", err
 	}
@@ -447,11 +561,42 @@ func ParseProductCertChainURL(kdsurl string) (string, error) {
 	return product, nil
 }
 
+// ParseProductCertChainURL returns the product name for a KDS cert_chain url, or an error if the
+// input is not a KDS cert_chain url.
+func ParseProductCertChainURL(kdsurl string) (string, error) {
+	return parseProductCertChainURL(kdsurl, kdsVcekPath)
+}
+
+// ParseVlekProductCertChainURL returns the product name for a KDS VLEK cert_chain url, or an error
+// if the input is not a KDS VLEK cert_chain url.
+func ParseVlekProductCertChainURL(kdsurl string) (string, error) {
+	return parseProductCertChainURL(kdsurl, kdsVlekPath)
+}
+
+// ParseCrlURL returns the product name and endorsement key kind for a KDS crl url, or an error if
+// the input is not a KDS crl url.
+func ParseCrlURL(kdsurl string) (string, abi.ReportSigner, error) {
+	key := abi.VcekReportSigner
+	keyPath := kdsVcekPath
+	if u, err := url.Parse(kdsurl); err == nil && strings.HasPrefix(u.Path, kdsVlekPath) {
+		key = abi.VlekReportSigner
+		keyPath = kdsVlekPath
+	}
+	product, u, err := parseBaseProductURL(kdsurl, keyPath)
+	if err != nil {
+		return "", abi.NoneReportSigner, err
+	}
+	if u.Path != "crl" {
+		return "", abi.NoneReportSigner, fmt.Errorf("unexpected AMD KDS URL path %q, want \"crl\"", u.Path)
+	}
+	return product, key, nil
+}
+
 // ParseVCEKCertURL returns the attestation report components represented in the given KDS VCEK
 // certificate request URL.
 func ParseVCEKCertURL(kdsurl string) (VCEKCert, error) {
 	result := VCEKCert{}
-	product, u, err := parseBaseProductURL(kdsurl)
+	product, u, err := parseBaseProductURL(kdsurl, kdsVcekPath)
 	if err != nil {
 		return result, err
 	}
@@ -33,6 +33,21 @@ func TestProductCertChainURL(t *testing.T) {
 	}
 }
 
+func TestVlekProductCertChainURL(t *testing.T) {
+	got := VlekProductCertChainURL("Milan")
+	want := "https://kdsintf.amd.com/vlek/v1/Milan/cert_chain"
+	if got != want {
+		t.Errorf("VlekProductCertChainURL(\"Milan\") = %q, want %q", got, want)
+	}
+	product, err := ParseVlekProductCertChainURL(got)
+	if err != nil || product != "Milan" {
+		t.Errorf("ParseVlekProductCertChainURL(%q) = %q, %v, want \"Milan\", nil", got, product, err)
+	}
+	if _, err := ParseProductCertChainURL(got); err == nil {
+		t.Errorf("ParseProductCertChainURL(%q) = _, nil, want error", got)
+	}
+}
+
 func TestVCEKCertURL(t *testing.T) {
 	hwid := make([]byte, abi.ChipIDSize)
 	hwid[0] = 0xfe
@@ -80,7 +95,7 @@ func TestParseProductBaseURL(t *testing.T) {
 	}
 	for _, tc := range tcs {
 		t.Run(tc.name, func(t *testing.T) {
-			gotProduct, gotURL, err := parseBaseProductURL(tc.url)
+			gotProduct, gotURL, err := parseBaseProductURL(tc.url, kdsVcekPath)
 			if (err == nil && tc.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
 				t.Fatalf("parseBaseProductURL(%q) = _, _, %v, want %q", tc.url, err, tc.wantErr)
 			}
@@ -156,3 +171,72 @@ func TestParseVCEKCertURL(t *testing.T) {
 		})
 	}
 }
+
+func TestProductLine(t *testing.T) {
+	tcs := []struct {
+		input   string
+		want    string
+		wantErr string
+	}{
+		{input: "Milan-B0", want: "Milan"},
+		{input: "Genoa-B1", want: "Genoa"},
+		{input: "Turin-C0", wantErr: "unknown product name: Turin-C0"},
+		{input: "Cookie-B0", wantErr: "unknown product name: Cookie-B0"},
+	}
+	for _, tc := range tcs {
+		got, err := ProductLine(tc.input)
+		if (err == nil && tc.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("ProductLine(%q) = _, %v, want %q", tc.input, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("ProductLine(%q) = %q, _ want %q", tc.input, got, tc.want)
+		}
+	}
+}
+
+func TestProductFromCPUID(t *testing.T) {
+	tcs := []struct {
+		family  uint8
+		model   uint8
+		want    string
+		wantErr string
+	}{
+		{family: 0x19, model: 0x01, want: "Milan"},
+		{family: 0x19, model: 0x11, want: "Genoa"},
+		{family: 0x1a, model: 0x02, wantErr: "unknown product for CPUID family 0x1a model 0x2"},
+		{family: 0x17, model: 0x31, wantErr: "unknown product for CPUID family 0x17 model 0x31"},
+	}
+	for _, tc := range tcs {
+		got, err := ProductFromCPUID(tc.family, tc.model)
+		if (err == nil && tc.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("ProductFromCPUID(0x%x, 0x%x) = _, %v, want %q", tc.family, tc.model, err, tc.wantErr)
+		}
+		if got != tc.want {
+			t.Errorf("ProductFromCPUID(0x%x, 0x%x) = %q, _ want %q", tc.family, tc.model, got, tc.want)
+		}
+	}
+}
+
+func TestParseCrlURL(t *testing.T) {
+	tcs := []struct {
+		url         string
+		wantProduct string
+		wantKey     abi.ReportSigner
+		wantErr     string
+	}{
+		{url: CrlURL("Milan", abi.VcekReportSigner), wantProduct: "Milan", wantKey: abi.VcekReportSigner},
+		{url: CrlURL("Genoa", abi.VlekReportSigner), wantProduct: "Genoa", wantKey: abi.VlekReportSigner},
+		{url: ProductCertChainURL("Milan"), wantErr: "want \"crl\""},
+		{url: "https://fakekds.com/vcek/v1/Milan/crl", wantErr: "unexpected AMD KDS URL host"},
+	}
+	for _, tc := range tcs {
+		product, key, err := ParseCrlURL(tc.url)
+		if (err == nil && tc.wantErr != "") || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
+			t.Errorf("ParseCrlURL(%q) = _, _, %v, want %q", tc.url, err, tc.wantErr)
+			continue
+		}
+		if err == nil && (product != tc.wantProduct || key != tc.wantKey) {
+			t.Errorf("ParseCrlURL(%q) = %q, %v, nil, want %q, %v", tc.url, product, key, tc.wantProduct, tc.wantKey)
+		}
+	}
+}
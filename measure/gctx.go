@@ -0,0 +1,134 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measure
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/google/go-sev-guest/abi"
+)
+
+// PageType is the PAGE_TYPE of an SNP_LAUNCH_UPDATE command, as in Table 67 of the SEV SNP API
+// specification https://www.amd.com/system/files/TechDocs/56860.pdf
+type PageType uint8
+
+const (
+	// PageTypeNormal is a page of measured, encrypted contents.
+	PageTypeNormal PageType = 0x01
+	// PageTypeVMSA is a vCPU's initial save area.
+	PageTypeVMSA PageType = 0x02
+	// PageTypeZero is a page the firmware fills with zeros.
+	PageTypeZero PageType = 0x03
+	// PageTypeUnmeasured is a page of encrypted contents that are not measured.
+	PageTypeUnmeasured PageType = 0x04
+	// PageTypeSecrets is the page the firmware fills with the guest's secrets.
+	PageTypeSecrets PageType = 0x05
+	// PageTypeCPUID is the page the firmware fills with the validated CPUID table.
+	PageTypeCPUID PageType = 0x06
+)
+
+const (
+	// PageSize is the size of the pages that SNP_LAUNCH_UPDATE measures.
+	PageSize = 0x1000
+	// VMSAGPA is the guest physical address that the firmware uses for every VMSA page in the
+	// launch digest, regardless of where the page resides.
+	VMSAGPA = 0xFFFFFFFFF000
+
+	// pageInfoSize is the size of the PAGE_INFO structure each launch update digests.
+	pageInfoSize = 0x70
+)
+
+// GuestContext reproduces the firmware's running launch digest over SNP_LAUNCH_UPDATE commands.
+// The zero value is the launch digest before any update.
+type GuestContext struct {
+	ld [abi.MeasurementSize]byte
+}
+
+// LaunchDigest returns the current launch digest.
+func (g *GuestContext) LaunchDigest() []byte {
+	return append([]byte{}, g.ld[:]...)
+}
+
+// Update extends the launch digest with the PAGE_INFO structure of one page at gpa whose contents
+// digest is contents. Pages without measured contents have an all-zero contents digest.
+func (g *GuestContext) Update(pageType PageType, gpa uint64, contents [abi.MeasurementSize]byte) {
+	// PAGE_INFO is defined in Table 67 of the SEV SNP API specification. The IS_IMI and VMPL
+	// permission fields are zero for launches without a migration agent or VMPLs.
+	var pageInfo [pageInfoSize]byte
+	copy(pageInfo[0x00:0x30], g.ld[:])
+	copy(pageInfo[0x30:0x60], contents[:])
+	binary.LittleEndian.PutUint16(pageInfo[0x60:0x62], pageInfoSize)
+	pageInfo[0x62] = byte(pageType)
+	binary.LittleEndian.PutUint64(pageInfo[0x68:0x70], gpa)
+	g.ld = sha512.Sum384(pageInfo[:])
+}
+
+func checkPages(gpa uint64, length int) error {
+	if gpa%PageSize != 0 {
+		return fmt.Errorf("guest physical address 0x%x is not page aligned", gpa)
+	}
+	if length%PageSize != 0 {
+		return fmt.Errorf("length 0x%x is not a multiple of the page size", length)
+	}
+	return nil
+}
+
+// UpdateNormalPages extends the launch digest with the measured pages of data starting at gpa.
+func (g *GuestContext) UpdateNormalPages(gpa uint64, data []byte) error {
+	if err := checkPages(gpa, len(data)); err != nil {
+		return err
+	}
+	for offset := 0; offset < len(data); offset += PageSize {
+		g.Update(PageTypeNormal, gpa+uint64(offset), sha512.Sum384(data[offset:offset+PageSize]))
+	}
+	return nil
+}
+
+// updateEmptyPages extends the launch digest with length bytes of pages with unmeasured contents.
+func (g *GuestContext) updateEmptyPages(pageType PageType, gpa uint64, length int) error {
+	if err := checkPages(gpa, length); err != nil {
+		return err
+	}
+	for offset := 0; offset < length; offset += PageSize {
+		g.Update(pageType, gpa+uint64(offset), [abi.MeasurementSize]byte{})
+	}
+	return nil
+}
+
+// UpdateZeroPages extends the launch digest with length bytes of zero pages starting at gpa.
+func (g *GuestContext) UpdateZeroPages(gpa uint64, length int) error {
+	return g.updateEmptyPages(PageTypeZero, gpa, length)
+}
+
+// UpdateSecretsPage extends the launch digest with the secrets page at gpa.
+func (g *GuestContext) UpdateSecretsPage(gpa uint64) error {
+	return g.updateEmptyPages(PageTypeSecrets, gpa, PageSize)
+}
+
+// UpdateCPUIDPage extends the launch digest with the CPUID page at gpa.
+func (g *GuestContext) UpdateCPUIDPage(gpa uint64) error {
+	return g.updateEmptyPages(PageTypeCPUID, gpa, PageSize)
+}
+
+// UpdateVMSAPage extends the launch digest with a vCPU's initial VMSA page.
+func (g *GuestContext) UpdateVMSAPage(page []byte) error {
+	if len(page) != PageSize {
+		return fmt.Errorf("VMSA page size is 0x%x, expected 0x%x", len(page), PageSize)
+	}
+	g.Update(PageTypeVMSA, VMSAGPA, sha512.Sum384(page))
+	return nil
+}
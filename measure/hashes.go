@@ -0,0 +1,83 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measure
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+)
+
+// GUIDs of the hashes table that QEMU fills in for measured direct boot. They are defined in QEMU's
+// target/i386/sev.c and checked by OVMF's BlobVerifierLibSevHashes.
+const (
+	sevHashTableHeaderGUID = "9438d606-4f22-4cc9-b479-a793d411fd21"
+	sevKernelEntryGUID     = "4de79437-abd2-427f-b835-d5b172d2045b"
+	sevInitrdEntryGUID     = "44baf731-3a2f-4bd7-9af1-41e29169781d"
+	sevCmdlineEntryGUID    = "97d02dd8-bd20-4c94-aa78-e7714d36ab2a"
+
+	// A table entry is a GUID, a 16-bit length, and a SHA-256 digest.
+	sevHashTableEntrySize = 16 + 2 + sha256.Size
+	// The table is a header GUID, a 16-bit length, and entries for the command line, initrd, and
+	// kernel, in that order.
+	sevHashTableSize = 16 + 2 + 3*sevHashTableEntrySize
+	// QEMU pads the table to a 16-byte boundary.
+	sevHashTablePaddedSize = (sevHashTableSize + 15) &^ 15
+)
+
+// KernelHashes are the digests of the blobs that QEMU loads for measured direct boot.
+type KernelHashes struct {
+	Kernel  [sha256.Size]byte
+	Initrd  [sha256.Size]byte
+	Cmdline [sha256.Size]byte
+}
+
+// NewKernelHashes returns the hashes QEMU computes for the given kernel, initrd, and command line.
+// An absent initrd is hashed as empty. QEMU terminates the command line with a NUL byte.
+func NewKernelHashes(kernel, initrd []byte, cmdline string) *KernelHashes {
+	return &KernelHashes{
+		Kernel:  sha256.Sum256(kernel),
+		Initrd:  sha256.Sum256(initrd),
+		Cmdline: sha256.Sum256(append([]byte(cmdline), 0)),
+	}
+}
+
+func putHashTableEntry(data []byte, guid string, digest [sha256.Size]byte) {
+	copy(data[0:16], guidBytesLE(guid))
+	binary.LittleEndian.PutUint16(data[16:18], sevHashTableEntrySize)
+	copy(data[18:sevHashTableEntrySize], digest[:])
+}
+
+// Table returns the padded hashes table as QEMU writes it into guest memory.
+func (h *KernelHashes) Table() []byte {
+	table := make([]byte, sevHashTablePaddedSize)
+	copy(table[0:16], guidBytesLE(sevHashTableHeaderGUID))
+	binary.LittleEndian.PutUint16(table[16:18], sevHashTableSize)
+	entries := table[18:]
+	putHashTableEntry(entries[0:], sevCmdlineEntryGUID, h.Cmdline)
+	putHashTableEntry(entries[sevHashTableEntrySize:], sevInitrdEntryGUID, h.Initrd)
+	putHashTableEntry(entries[2*sevHashTableEntrySize:], sevKernelEntryGUID, h.Kernel)
+	return table
+}
+
+// Page returns the guest page that holds the hashes table at the given offset within the page.
+func (h *KernelHashes) Page(offset int) ([]byte, error) {
+	if offset < 0 || offset+sevHashTablePaddedSize > PageSize {
+		return nil, fmt.Errorf("hashes table offset 0x%x does not fit in a page", offset)
+	}
+	page := make([]byte, PageSize)
+	copy(page[offset:], h.Table())
+	return page, nil
+}
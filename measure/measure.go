@@ -0,0 +1,129 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package measure reproduces the SEV-SNP launch digest, i.e., the attestation report's
+// MEASUREMENT, of a QEMU guest that boots OVMF.
+//
+// The guest policy does not contribute to the launch digest, so a MEASUREMENT is shared by every
+// policy the guest may launch with.
+package measure
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/go-sev-guest/vmsa"
+)
+
+// Options describes a guest launch.
+type Options struct {
+	// OVMF is the firmware image.
+	OVMF *OVMF
+	// VCPUs is the number of vCPUs the guest launches with.
+	VCPUs int
+	// VCPUSig is the processor signature of the guest's vCPUs. See vmsa.CPUSig and vmsa.CPUSigs.
+	VCPUSig uint32
+	// SevFeatures is the SEV_FEATURES value of every VMSA. If zero, uses vmsa.SevFeatureSNPActive.
+	SevFeatures uint64
+	// KernelHashes are the hashes of the directly booted kernel, initrd, and command line. Nil if
+	// the guest does not use measured direct boot.
+	KernelHashes *KernelHashes
+}
+
+// updateSection extends the launch digest with the pages QEMU initializes for an OVMF metadata
+// section.
+func updateSection(g *GuestContext, s MetadataSection, opts *Options) error {
+	gpa := uint64(s.GPA)
+	switch s.Type {
+	case SectionSnpSecMemory, SectionSvsmCaa:
+		return g.UpdateZeroPages(gpa, int(s.Size))
+	case SectionSnpSecrets:
+		return g.UpdateSecretsPage(gpa)
+	case SectionCPUID:
+		return g.UpdateCPUIDPage(gpa)
+	case SectionSnpKernelHashes:
+		if opts.KernelHashes == nil {
+			return g.UpdateZeroPages(gpa, int(s.Size))
+		}
+		tableGPA, err := opts.OVMF.SevHashesTableGPA()
+		if err != nil {
+			return err
+		}
+		if uint64(tableGPA)&^(PageSize-1) != gpa {
+			return fmt.Errorf("SEV hash table GPA 0x%x is not in the kernel hashes section at 0x%x", tableGPA, gpa)
+		}
+		page, err := opts.KernelHashes.Page(int(tableGPA & (PageSize - 1)))
+		if err != nil {
+			return err
+		}
+		return g.UpdateNormalPages(gpa, page)
+	}
+	return fmt.Errorf("unknown OVMF SEV metadata section type 0x%x", uint32(s.Type))
+}
+
+// VMSAPages returns the initial VMSA pages of each vCPU in launch order. The bootstrap processor
+// starts at the reset vector, and application processors at OVMF's SEV-ES reset block.
+func VMSAPages(opts *Options) ([][]byte, error) {
+	if opts.VCPUs < 1 {
+		return nil, fmt.Errorf("vCPU count %d must be at least 1", opts.VCPUs)
+	}
+	sevFeatures := opts.SevFeatures
+	if sevFeatures == 0 {
+		sevFeatures = vmsa.SevFeatureSNPActive
+	}
+	pages := [][]byte{vmsa.BSPSaveArea(sevFeatures, opts.VCPUSig).Marshal()}
+	if opts.VCPUs == 1 {
+		return pages, nil
+	}
+	apEIP, err := opts.OVMF.SevEsResetEIP()
+	if err != nil {
+		return nil, fmt.Errorf("cannot start application processors: %v", err)
+	}
+	ap := vmsa.ResetSaveArea(apEIP, sevFeatures, opts.VCPUSig).Marshal()
+	for i := 1; i < opts.VCPUs; i++ {
+		pages = append(pages, ap)
+	}
+	return pages, nil
+}
+
+// SnpLaunchDigest returns the MEASUREMENT of a guest launched as opts describes, following the
+// order in which QEMU issues SNP_LAUNCH_UPDATE commands: the OVMF image, its SEV metadata sections,
+// and then each vCPU's VMSA.
+func SnpLaunchDigest(opts *Options) ([]byte, error) {
+	if opts == nil || opts.OVMF == nil {
+		return nil, errors.New("an OVMF image is required")
+	}
+	if opts.KernelHashes != nil && !opts.OVMF.hasSection(SectionSnpKernelHashes) {
+		return nil, errors.New("kernel hashes given, but the OVMF image has no SNP kernel hashes section")
+	}
+	g := &GuestContext{}
+	if err := g.UpdateNormalPages(opts.OVMF.GPA(), opts.OVMF.Data()); err != nil {
+		return nil, err
+	}
+	for _, s := range opts.OVMF.MetadataSections() {
+		if err := updateSection(g, s, opts); err != nil {
+			return nil, err
+		}
+	}
+	pages, err := VMSAPages(opts)
+	if err != nil {
+		return nil, err
+	}
+	for _, page := range pages {
+		if err := g.UpdateVMSAPage(page); err != nil {
+			return nil, err
+		}
+	}
+	return g.LaunchDigest(), nil
+}
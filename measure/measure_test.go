@@ -0,0 +1,267 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measure
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-sev-guest/vmsa"
+)
+
+const (
+	testOVMFSize   = 4 * PageSize
+	testHashesGPA  = 0x80C00
+	testResetEIP   = 0xFFFFF000 + 0x123
+	testSecretsGPA = 0x80000
+	testCPUIDGPA   = 0x81000
+)
+
+var testSections = []MetadataSection{
+	{GPA: 0x70000, Size: 2 * PageSize, Type: SectionSnpSecMemory},
+	{GPA: testSecretsGPA, Size: PageSize, Type: SectionSnpSecrets},
+	{GPA: testCPUIDGPA, Size: PageSize, Type: SectionCPUID},
+	{GPA: testHashesGPA &^ (PageSize - 1), Size: PageSize, Type: SectionSnpKernelHashes},
+}
+
+// footerEntry returns an OVMF footer table entry, which is its data followed by its size and GUID.
+func footerEntry(guid string, data []byte) []byte {
+	entry := append([]byte{}, data...)
+	entry = binary.LittleEndian.AppendUint16(entry, uint16(len(data)+ovmfTableEntryHdrSize))
+	return append(entry, guidBytesLE(guid)...)
+}
+
+func u32(v uint32) []byte {
+	return binary.LittleEndian.AppendUint32(nil, v)
+}
+
+// fakeOVMF returns an image with the SEV metadata at its start and a footer table at its end.
+func fakeOVMF(sections []MetadataSection, withResetBlock bool) []byte {
+	data := make([]byte, testOVMFSize)
+	for i := range data {
+		data[i] = byte(i * 7)
+	}
+	metadata := append([]byte(ovmfSevMetadataMagic), u32(uint32(ovmfMetadataHdrSize+len(sections)*ovmfMetadataDescSize))...)
+	metadata = append(metadata, u32(ovmfSevMetadataVer)...)
+	metadata = append(metadata, u32(uint32(len(sections)))...)
+	for _, s := range sections {
+		metadata = append(metadata, u32(s.GPA)...)
+		metadata = append(metadata, u32(s.Size)...)
+		metadata = append(metadata, u32(uint32(s.Type))...)
+	}
+	copy(data, metadata)
+
+	table := footerEntry(ovmfSevMetadataGUID, u32(testOVMFSize))
+	table = append(table, footerEntry(sevHashTableRVGUID, append(u32(testHashesGPA), u32(0x400)...))...)
+	if withResetBlock {
+		table = append(table, footerEntry(sevEsResetBlockGUID, u32(testResetEIP))...)
+	}
+	table = append(table, footerEntry(ovmfTableFooterGUID, nil)...)
+	// The footer entry's size covers the whole table.
+	binary.LittleEndian.PutUint16(table[len(table)-ovmfTableEntryHdrSize:], uint16(len(table)))
+	copy(data[testOVMFSize-ovmfFooterOffset-len(table):], table)
+	return data
+}
+
+func TestParseOVMF(t *testing.T) {
+	o, err := ParseOVMF(fakeOVMF(testSections, true))
+	if err != nil {
+		t.Fatalf("ParseOVMF() = _, %v, want nil", err)
+	}
+	if diff := cmp.Diff(o.MetadataSections(), testSections); diff != "" {
+		t.Errorf("MetadataSections() returned unexpected diff (-want +got):\n%s", diff)
+	}
+	if got := o.GPA(); got != fourGiB-testOVMFSize {
+		t.Errorf("GPA() = 0x%x, want 0x%x", got, fourGiB-testOVMFSize)
+	}
+	if eip, err := o.SevEsResetEIP(); err != nil || eip != testResetEIP {
+		t.Errorf("SevEsResetEIP() = 0x%x, %v, want 0x%x, nil", eip, err, testResetEIP)
+	}
+	if gpa, err := o.SevHashesTableGPA(); err != nil || gpa != testHashesGPA {
+		t.Errorf("SevHashesTableGPA() = 0x%x, %v, want 0x%x, nil", gpa, err, testHashesGPA)
+	}
+
+	noFooter, err := ParseOVMF(make([]byte, testOVMFSize))
+	if err != nil {
+		t.Fatalf("ParseOVMF(zeros) = _, %v, want nil", err)
+	}
+	if len(noFooter.MetadataSections()) != 0 {
+		t.Errorf("ParseOVMF(zeros) has sections %v, want none", noFooter.MetadataSections())
+	}
+	if _, err := noFooter.SevEsResetEIP(); err == nil {
+		t.Error("SevEsResetEIP() without a footer table = nil, want error")
+	}
+
+	badMagic := fakeOVMF(testSections, true)
+	badMagic[0] = 'B'
+	if _, err := ParseOVMF(badMagic); err == nil || !strings.Contains(err.Error(), "signature") {
+		t.Errorf("ParseOVMF(bad magic) = _, %v, want signature error", err)
+	}
+	if _, err := ParseOVMF(make([]byte, PageSize+1)); err == nil {
+		t.Error("ParseOVMF(unaligned) = _, nil, want error")
+	}
+}
+
+func TestGuestContextUpdate(t *testing.T) {
+	g := &GuestContext{}
+	var contents [48]byte
+	contents[0] = 0xaa
+	g.Update(PageTypeNormal, 0x1000, contents)
+
+	// PAGE_INFO from Table 67 of the SEV SNP API specification with a zero initial digest.
+	want := make([]byte, 0x70)
+	copy(want[0x30:], contents[:])
+	want[0x60] = 0x70
+	want[0x62] = byte(PageTypeNormal)
+	want[0x69] = 0x10
+	digest := sha512.Sum384(want)
+	if !bytes.Equal(g.LaunchDigest(), digest[:]) {
+		t.Errorf("LaunchDigest() = %x, want %x", g.LaunchDigest(), digest)
+	}
+	if err := g.UpdateNormalPages(0x1001, make([]byte, PageSize)); err == nil {
+		t.Error("UpdateNormalPages(unaligned) = nil, want error")
+	}
+	if err := g.UpdateZeroPages(0x1000, PageSize+1); err == nil {
+		t.Error("UpdateZeroPages(partial page) = nil, want error")
+	}
+}
+
+func TestKernelHashes(t *testing.T) {
+	h := NewKernelHashes([]byte("kernel"), nil, "")
+	table := h.Table()
+	if len(table) != 176 {
+		t.Fatalf("Table() size is %d, want 176", len(table))
+	}
+	if got := binary.LittleEndian.Uint16(table[16:18]); got != 168 {
+		t.Errorf("table length is %d, want 168", got)
+	}
+	for i, guid := range []string{sevCmdlineEntryGUID, sevInitrdEntryGUID, sevKernelEntryGUID} {
+		entry := table[18+i*sevHashTableEntrySize:]
+		if got := guidStringLE(entry[0:16]); got != guid {
+			t.Errorf("entry %d GUID is %s, want %s", i, got, guid)
+		}
+	}
+	// An absent command line is a lone NUL byte.
+	if h.Cmdline != NewKernelHashes(nil, nil, "").Cmdline || h.Cmdline == NewKernelHashes(nil, nil, "x").Cmdline {
+		t.Error("command line hash does not depend only on the command line")
+	}
+	page, err := h.Page(testHashesGPA & (PageSize - 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(page[0xC00:0xC00+len(table)], table) {
+		t.Error("Page() does not hold the table at its offset")
+	}
+	if _, err := h.Page(PageSize - 16); err == nil {
+		t.Error("Page(near end) = _, nil, want error")
+	}
+}
+
+func TestSnpLaunchDigest(t *testing.T) {
+	ovmf, err := ParseOVMF(fakeOVMF(testSections, true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashes := NewKernelHashes([]byte("kernel"), []byte("initrd"), "console=ttyS0")
+	opts := &Options{OVMF: ovmf, VCPUs: 4, VCPUSig: vmsa.CPUSigs["EPYC-Milan"], KernelHashes: hashes}
+
+	// The expected digests were computed by a separate implementation of the launch digest written
+	// from the SEV SNP API specification, QEMU's SNP_LAUNCH_UPDATE order, and Linux's
+	// struct sev_es_save_area, which shares no code with this package.
+	knownAnswers := []struct {
+		name string
+		opts *Options
+		want string
+	}{
+		{
+			name: "4 EPYC-Milan vCPUs with kernel hashes",
+			opts: opts,
+			want: "ca2e246cd3e77532aeb5f97ebcbb0bd53c87f5279b187541667d050913632aaa94a1557d5b9c58a1420bbb62a4c00335",
+		},
+		{
+			name: "1 EPYC-Genoa vCPU",
+			opts: &Options{OVMF: ovmf, VCPUs: 1, VCPUSig: vmsa.CPUSigs["EPYC-Genoa"]},
+			want: "57df96e94fe9cc79c50c344674da8172cbc9cc30bfcf58ea204d250e86c6d404e9fbfba0a8216a4f80e6aeb73e3c1eb2",
+		},
+	}
+	for _, tc := range knownAnswers {
+		got, err := SnpLaunchDigest(tc.opts)
+		if err != nil {
+			t.Fatalf("%s: SnpLaunchDigest() = _, %v, want nil", tc.name, err)
+		}
+		if hex.EncodeToString(got) != tc.want {
+			t.Errorf("%s: SnpLaunchDigest() = %x, want %s", tc.name, got, tc.want)
+		}
+	}
+	got, err := SnpLaunchDigest(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	variants := []struct {
+		name   string
+		modify func(o *Options)
+	}{
+		{name: "vcpus", modify: func(o *Options) { o.VCPUs = 2 }},
+		{name: "cpu", modify: func(o *Options) { o.VCPUSig = vmsa.CPUSigs["EPYC-Genoa"] }},
+		{name: "no kernel", modify: func(o *Options) { o.KernelHashes = nil }},
+		{name: "sev features", modify: func(o *Options) { o.SevFeatures = 0x21 }},
+	}
+	for _, v := range variants {
+		o := *opts
+		v.modify(&o)
+		other, err := SnpLaunchDigest(&o)
+		if err != nil {
+			t.Errorf("%s: SnpLaunchDigest() = _, %v, want nil", v.name, err)
+			continue
+		}
+		if bytes.Equal(other, got) {
+			t.Errorf("%s: SnpLaunchDigest() did not change", v.name)
+		}
+	}
+
+	noHashes, err := ParseOVMF(fakeOVMF(testSections[:3], false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := []struct {
+		name    string
+		opts    *Options
+		wantErr string
+	}{
+		{name: "no ovmf", opts: &Options{VCPUs: 1}, wantErr: "OVMF image is required"},
+		{name: "no vcpus", opts: &Options{OVMF: ovmf}, wantErr: "must be at least 1"},
+		{
+			name:    "no hashes section",
+			opts:    &Options{OVMF: noHashes, VCPUs: 1, KernelHashes: hashes},
+			wantErr: "no SNP kernel hashes section",
+		},
+		{
+			name:    "no reset block",
+			opts:    &Options{OVMF: noHashes, VCPUs: 2},
+			wantErr: "cannot start application processors",
+		},
+	}
+	for _, tc := range errs {
+		if _, err := SnpLaunchDigest(tc.opts); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: SnpLaunchDigest() = _, %v, want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
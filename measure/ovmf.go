@@ -0,0 +1,218 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measure
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/pborman/uuid"
+)
+
+// GUIDs of the OVMF footer table entries that affect the launch digest. They are defined in OVMF's
+// OvmfPkg/ResetVector/Ia16/ResetVectorVtf0.asm.
+const (
+	ovmfTableFooterGUID   = "96b582de-1fb2-45f7-baea-a366c55a082d"
+	sevHashTableRVGUID    = "7255371f-3a3b-4b04-927b-1da6efa8d454"
+	sevEsResetBlockGUID   = "00f771de-1a7e-4fcb-890e-68c77e2fb44e"
+	ovmfSevMetadataGUID   = "dc886566-984a-4798-a75e-5585a7bf67cc"
+	ovmfSevMetadataMagic  = "ASEV"
+	ovmfSevMetadataVer    = 1
+	ovmfFooterOffset      = 32
+	ovmfTableEntryHdrSize = 2 + 16
+	ovmfMetadataHdrSize   = 16
+	ovmfMetadataDescSize  = 12
+	fourGiB               = 1 << 32
+)
+
+// SectionType is the type of an OVMF SEV metadata section, which determines how the VMM
+// initializes the section's pages at launch.
+type SectionType uint32
+
+const (
+	// SectionSnpSecMemory is memory the VMM pre-validates as zero pages.
+	SectionSnpSecMemory SectionType = 1
+	// SectionSnpSecrets is the SNP secrets page.
+	SectionSnpSecrets SectionType = 2
+	// SectionCPUID is the SNP CPUID page.
+	SectionCPUID SectionType = 3
+	// SectionSvsmCaa is the SVSM calling area, which the VMM pre-validates as zero pages.
+	SectionSvsmCaa SectionType = 4
+	// SectionSnpKernelHashes is the page that holds the kernel, initrd, and command line hashes
+	// table for measured direct boot.
+	SectionSnpKernelHashes SectionType = 0x10
+)
+
+// MetadataSection describes a region of guest memory that the VMM initializes at launch.
+type MetadataSection struct {
+	GPA  uint32
+	Size uint32
+	Type SectionType
+}
+
+// OVMF is a parsed OVMF firmware image.
+type OVMF struct {
+	data     []byte
+	table    map[string][]byte
+	sections []MetadataSection
+}
+
+// swapGUIDEndianness converts between the RFC 4122 binary form of a GUID and the mixed-endian form
+// that EDK2 lays out in memory. The conversion is its own inverse.
+func swapGUIDEndianness(b []byte) []byte {
+	return []byte{b[3], b[2], b[1], b[0], b[5], b[4], b[7], b[6],
+		b[8], b[9], b[10], b[11], b[12], b[13], b[14], b[15]}
+}
+
+// guidBytesLE returns the mixed-endian binary form of a GUID.
+func guidBytesLE(guid string) []byte {
+	return swapGUIDEndianness(uuid.Parse(guid))
+}
+
+// guidStringLE returns the string form of a GUID in mixed-endian binary form.
+func guidStringLE(b []byte) string {
+	return uuid.UUID(swapGUIDEndianness(b)).String()
+}
+
+// ParseOVMF parses the footer table and SEV metadata of an OVMF image.
+func ParseOVMF(data []byte) (*OVMF, error) {
+	if len(data) == 0 || len(data)%PageSize != 0 {
+		return nil, fmt.Errorf("OVMF size 0x%x is not a non-zero multiple of the page size", len(data))
+	}
+	if len(data) > fourGiB {
+		return nil, fmt.Errorf("OVMF size 0x%x does not fit below 4GiB", len(data))
+	}
+	o := &OVMF{data: data, table: make(map[string][]byte)}
+	if err := o.parseFooterTable(); err != nil {
+		return nil, err
+	}
+	if err := o.parseSevMetadata(); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// parseFooterTable reads the GUIDed table that ends 32 bytes before the end of the image. Each
+// entry's data is followed by its 16-bit size, which includes the header, and its GUID.
+func (o *OVMF) parseFooterTable() error {
+	footerStart := len(o.data) - ovmfFooterOffset - ovmfTableEntryHdrSize
+	if footerStart < 0 {
+		return errors.New("OVMF image too small for a footer table")
+	}
+	footer := o.data[footerStart : footerStart+ovmfTableEntryHdrSize]
+	if guidStringLE(footer[2:]) != ovmfTableFooterGUID {
+		// Without a footer table, the image has no SEV metadata.
+		return nil
+	}
+	tableSize := int(binary.LittleEndian.Uint16(footer[0:2])) - ovmfTableEntryHdrSize
+	if tableSize < 0 || tableSize > footerStart {
+		return fmt.Errorf("OVMF footer table size %d is invalid", tableSize)
+	}
+	table := o.data[footerStart-tableSize : footerStart]
+	for len(table) >= ovmfTableEntryHdrSize {
+		header := table[len(table)-ovmfTableEntryHdrSize:]
+		size := int(binary.LittleEndian.Uint16(header[0:2]))
+		if size < ovmfTableEntryHdrSize || size > len(table) {
+			return fmt.Errorf("OVMF footer table entry size %d is invalid", size)
+		}
+		o.table[guidStringLE(header[2:])] = table[len(table)-size : len(table)-ovmfTableEntryHdrSize]
+		table = table[:len(table)-size]
+	}
+	return nil
+}
+
+func (o *OVMF) tableUint32(guid, name string) (uint32, error) {
+	entry, ok := o.table[guid]
+	if !ok {
+		return 0, fmt.Errorf("OVMF footer table has no %s entry", name)
+	}
+	if len(entry) < 4 {
+		return 0, fmt.Errorf("OVMF footer table %s entry is too small", name)
+	}
+	return binary.LittleEndian.Uint32(entry[0:4]), nil
+}
+
+func (o *OVMF) parseSevMetadata() error {
+	if _, ok := o.table[ovmfSevMetadataGUID]; !ok {
+		return nil
+	}
+	offsetFromEnd, err := o.tableUint32(ovmfSevMetadataGUID, "SEV metadata")
+	if err != nil {
+		return err
+	}
+	start := len(o.data) - int(offsetFromEnd)
+	if offsetFromEnd > uint32(len(o.data)) || start+ovmfMetadataHdrSize > len(o.data) {
+		return fmt.Errorf("OVMF SEV metadata offset 0x%x is out of bounds", offsetFromEnd)
+	}
+	header := o.data[start : start+ovmfMetadataHdrSize]
+	if string(header[0:4]) != ovmfSevMetadataMagic {
+		return fmt.Errorf("OVMF SEV metadata signature is %q, expected %q", header[0:4], ovmfSevMetadataMagic)
+	}
+	size := binary.LittleEndian.Uint32(header[4:8])
+	if version := binary.LittleEndian.Uint32(header[8:12]); version != ovmfSevMetadataVer {
+		return fmt.Errorf("OVMF SEV metadata version is %d, expected %d", version, ovmfSevMetadataVer)
+	}
+	count := binary.LittleEndian.Uint32(header[12:16])
+	if uint64(size) < ovmfMetadataHdrSize+uint64(count)*ovmfMetadataDescSize ||
+		uint64(start)+uint64(size) > uint64(len(o.data)) {
+		return fmt.Errorf("OVMF SEV metadata size 0x%x is invalid for %d sections", size, count)
+	}
+	descs := o.data[start+ovmfMetadataHdrSize : start+int(size)]
+	for i := 0; i < int(count); i++ {
+		desc := descs[i*ovmfMetadataDescSize : (i+1)*ovmfMetadataDescSize]
+		o.sections = append(o.sections, MetadataSection{
+			GPA:  binary.LittleEndian.Uint32(desc[0:4]),
+			Size: binary.LittleEndian.Uint32(desc[4:8]),
+			Type: SectionType(binary.LittleEndian.Uint32(desc[8:12])),
+		})
+	}
+	return nil
+}
+
+// Data returns the image contents.
+func (o *OVMF) Data() []byte {
+	return o.data
+}
+
+// GPA returns the guest physical address the image is mapped at, which ends at 4GiB.
+func (o *OVMF) GPA() uint64 {
+	return fourGiB - uint64(len(o.data))
+}
+
+// MetadataSections returns the SEV metadata sections in the order the VMM initializes them.
+func (o *OVMF) MetadataSections() []MetadataSection {
+	return o.sections
+}
+
+// hasSection returns whether the image has a metadata section of the given type.
+func (o *OVMF) hasSection(t SectionType) bool {
+	for _, s := range o.sections {
+		if s.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// SevEsResetEIP returns the initial instruction pointer of application processors.
+func (o *OVMF) SevEsResetEIP() (uint32, error) {
+	return o.tableUint32(sevEsResetBlockGUID, "SEV-ES reset block")
+}
+
+// SevHashesTableGPA returns the guest physical address of the kernel hashes table.
+func (o *OVMF) SevHashesTableGPA() (uint32, error) {
+	return o.tableUint32(sevHashTableRVGUID, "SEV hash table")
+}
@@ -0,0 +1,165 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// Metric names that a Metrics observer records.
+const (
+	// KDSRequestsMetric counts KDS fetches by "class" and "result".
+	KDSRequestsMetric = "sevsnp.kds.requests"
+	// KDSLatencyMetric records the seconds each KDS fetch took by "class" and "result".
+	KDSLatencyMetric = "sevsnp.kds.request.duration"
+	// KDSRetriesMetric counts KDS fetch retries by "class".
+	KDSRetriesMetric = "sevsnp.kds.retries"
+	// CacheLookupsMetric counts KDS cache lookups by "class" and "hit".
+	CacheLookupsMetric = "sevsnp.kds.cache.lookups"
+	// CRLRefreshesMetric counts CRL downloads by "product", "signer" and "result".
+	CRLRefreshesMetric = "sevsnp.crl.refreshes"
+	// ChecksMetric counts attestation checks by "stage", "check" and "status".
+	ChecksMetric = "sevsnp.checks"
+	// StageLatencyMetric records the seconds each attestation checking stage took by "stage".
+	StageLatencyMetric = "sevsnp.stage.duration"
+)
+
+// Attribute is a key-value pair that describes a measurement.
+type Attribute struct {
+	Key   string
+	Value string
+}
+
+// Counter is a monotonically increasing metric, such as an OpenTelemetry Int64Counter.
+type Counter interface {
+	Add(ctx context.Context, incr int64, attrs ...Attribute)
+}
+
+// Histogram is a distribution metric, such as an OpenTelemetry Float64Histogram.
+type Histogram interface {
+	Record(ctx context.Context, value float64, attrs ...Attribute)
+}
+
+// Meter creates metrics, such as an OpenTelemetry Meter. A thin wrapper that converts Attributes to
+// the metrics library's own attribute type adapts most metrics libraries.
+type Meter interface {
+	Counter(name, description string) (Counter, error)
+	Histogram(name, description, unit string) (Histogram, error)
+}
+
+// Metrics is an Observer that records events as metrics.
+type Metrics struct {
+	kdsRequests  Counter
+	kdsLatency   Histogram
+	kdsRetries   Counter
+	cacheLookups Counter
+	crlRefreshes Counter
+	checks       Counter
+	stageLatency Histogram
+}
+
+// NewMetrics returns an Observer that records events with metrics created by m.
+func NewMetrics(m Meter) (*Metrics, error) {
+	result := &Metrics{}
+	counters := []struct {
+		dest        *Counter
+		name        string
+		description string
+	}{
+		{&result.kdsRequests, KDSRequestsMetric, "AMD KDS fetches"},
+		{&result.kdsRetries, KDSRetriesMetric, "AMD KDS fetch retries"},
+		{&result.cacheLookups, CacheLookupsMetric, "AMD KDS cache lookups"},
+		{&result.crlRefreshes, CRLRefreshesMetric, "CRL downloads"},
+		{&result.checks, ChecksMetric, "Attestation checks"},
+	}
+	for _, c := range counters {
+		counter, err := m.Counter(c.name, c.description)
+		if err != nil {
+			return nil, fmt.Errorf("could not create metric %q: %v", c.name, err)
+		}
+		*c.dest = counter
+	}
+	histograms := []struct {
+		dest        *Histogram
+		name        string
+		description string
+	}{
+		{&result.kdsLatency, KDSLatencyMetric, "AMD KDS fetch latency"},
+		{&result.stageLatency, StageLatencyMetric, "Attestation checking stage latency"},
+	}
+	for _, h := range histograms {
+		histogram, err := m.Histogram(h.name, h.description, "s")
+		if err != nil {
+			return nil, fmt.Errorf("could not create metric %q: %v", h.name, err)
+		}
+		*h.dest = histogram
+	}
+	return result, nil
+}
+
+// resultOf returns a low-cardinality description of the outcome of an operation.
+func resultOf(err error, statusCode int) string {
+	switch {
+	case err == nil:
+		return "ok"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "deadline_exceeded"
+	case statusCode != 0:
+		return "http_" + strconv.Itoa(statusCode)
+	}
+	return "error"
+}
+
+// KDSRequest records the fetch, its latency and its retries.
+func (m *Metrics) KDSRequest(ctx context.Context, e *KDSRequest) {
+	class := Attribute{Key: "class", Value: string(e.Class)}
+	result := Attribute{Key: "result", Value: resultOf(e.Err, e.StatusCode)}
+	m.kdsRequests.Add(ctx, 1, class, result)
+	m.kdsLatency.Record(ctx, e.Latency.Seconds(), class, result)
+	if e.Retries > 0 {
+		m.kdsRetries.Add(ctx, int64(e.Retries), class)
+	}
+}
+
+// CRLRefresh records the CRL download.
+func (m *Metrics) CRLRefresh(ctx context.Context, e *CRLRefresh) {
+	m.crlRefreshes.Add(ctx, 1,
+		Attribute{Key: "product", Value: e.Product},
+		Attribute{Key: "signer", Value: e.Signer},
+		Attribute{Key: "result", Value: resultOf(e.Err, 0)})
+}
+
+// CacheLookup records the lookup.
+func (m *Metrics) CacheLookup(ctx context.Context, e *CacheLookup) {
+	m.cacheLookups.Add(ctx, 1,
+		Attribute{Key: "class", Value: string(e.Class)},
+		Attribute{Key: "hit", Value: strconv.FormatBool(e.Hit)})
+}
+
+// Outcome records the status of every check and the stage's latency.
+func (m *Metrics) Outcome(ctx context.Context, e *Outcome) {
+	stage := Attribute{Key: "stage", Value: string(e.Stage)}
+	for _, c := range e.Result.Checks {
+		m.checks.Add(ctx, 1, stage,
+			Attribute{Key: "check", Value: c.Name},
+			Attribute{Key: "status", Value: c.Status.String()})
+	}
+	m.stageLatency.Record(ctx, e.Duration.Seconds(), stage)
+}
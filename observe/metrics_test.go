@@ -0,0 +1,123 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package observe
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-sev-guest/checks"
+)
+
+// fakeMeter sums every measurement by metric name and attributes.
+type fakeMeter struct {
+	values map[string]float64
+}
+
+type fakeMetric struct {
+	meter *fakeMeter
+	name  string
+}
+
+func (m *fakeMetric) record(value float64, attrs []Attribute) {
+	var parts []string
+	for _, a := range attrs {
+		parts = append(parts, fmt.Sprintf("%s=%s", a.Key, a.Value))
+	}
+	sort.Strings(parts)
+	m.meter.values[m.name+"{"+strings.Join(parts, ",")+"}"] += value
+}
+
+func (m *fakeMetric) Add(_ context.Context, incr int64, attrs ...Attribute) {
+	m.record(float64(incr), attrs)
+}
+
+func (m *fakeMetric) Record(_ context.Context, value float64, attrs ...Attribute) {
+	m.record(value, attrs)
+}
+
+func (m *fakeMeter) Counter(name, _ string) (Counter, error) {
+	return &fakeMetric{meter: m, name: name}, nil
+}
+
+func (m *fakeMeter) Histogram(name, _, _ string) (Histogram, error) {
+	return &fakeMetric{meter: m, name: name}, nil
+}
+
+func TestMetrics(t *testing.T) {
+	meter := &fakeMeter{values: make(map[string]float64)}
+	m, err := NewMetrics(meter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	m.KDSRequest(ctx, &KDSRequest{Class: VcekURL, Latency: time.Second})
+	m.KDSRequest(ctx, &KDSRequest{Class: VcekURL, Latency: 2 * time.Second, Retries: 3, StatusCode: 429, Err: errors.New("failed")})
+	m.KDSRequest(ctx, &KDSRequest{Class: CrlURL, Err: fmt.Errorf("gave up: %w", context.Canceled)})
+	m.CacheLookup(ctx, &CacheLookup{Class: CertChainURL, Hit: true})
+	m.CRLRefresh(ctx, &CRLRefresh{Product: "Milan", Signer: "VCEK"})
+	result := &checks.Result{}
+	result.Record("signature", "", "", nil)
+	result.Record("crl", "", "", errors.New("revoked"))
+	result.Skip("policy")
+	m.Outcome(ctx, &Outcome{Stage: StageVerify, Result: result, Duration: time.Second})
+
+	want := map[string]float64{
+		"sevsnp.kds.requests{class=vcek,result=ok}":                 1,
+		"sevsnp.kds.request.duration{class=vcek,result=ok}":         1,
+		"sevsnp.kds.requests{class=vcek,result=http_429}":           1,
+		"sevsnp.kds.request.duration{class=vcek,result=http_429}":   2,
+		"sevsnp.kds.retries{class=vcek}":                            3,
+		"sevsnp.kds.requests{class=crl,result=canceled}":            1,
+		"sevsnp.kds.request.duration{class=crl,result=canceled}":    0,
+		"sevsnp.kds.cache.lookups{class=cert_chain,hit=true}":       1,
+		"sevsnp.crl.refreshes{product=Milan,result=ok,signer=VCEK}": 1,
+		"sevsnp.checks{check=signature,stage=verify,status=PASSED}": 1,
+		"sevsnp.checks{check=crl,stage=verify,status=FAILED}":       1,
+		"sevsnp.checks{check=policy,stage=verify,status=SKIPPED}":   1,
+		"sevsnp.stage.duration{stage=verify}":                       1,
+	}
+	if diff := cmp.Diff(want, meter.values); diff != "" {
+		t.Errorf("NewMetrics() recorded unexpected metrics (-want +got):\n%s", diff)
+	}
+}
+
+func TestDefault(t *testing.T) {
+	defer SetDefault(nil)
+	if _, ok := Default().(Nop); !ok {
+		t.Errorf("Default() = %T, want Nop", Default())
+	}
+	m, err := NewMetrics(&fakeMeter{values: make(map[string]float64)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetDefault(m)
+	if got := OrDefault(nil); got != Observer(m) {
+		t.Errorf("OrDefault(nil) = %v, want the default %v", got, m)
+	}
+	if got := OrDefault(Nop{}); got != Observer(Nop{}) {
+		t.Errorf("OrDefault(Nop{}) = %v, want Nop{}", got)
+	}
+	SetDefault(nil)
+	if _, ok := Default().(Nop); !ok {
+		t.Errorf("Default() after SetDefault(nil) = %T, want Nop", Default())
+	}
+}
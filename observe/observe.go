@@ -0,0 +1,142 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package observe defines hooks through which the trust, verify and validate packages report AMD
+// KDS traffic and attestation check outcomes, e.g., for metrics and tracing.
+package observe
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/go-sev-guest/checks"
+)
+
+// URLClass is the kind of AMD KDS resource a URL names.
+type URLClass string
+
+const (
+	// VcekURL names a VCEK certificate.
+	VcekURL URLClass = "vcek"
+	// CertChainURL names a product's ASK or ASVK and ARK certificate chain.
+	CertChainURL URLClass = "cert_chain"
+	// CrlURL names a product's certificate revocation list.
+	CrlURL URLClass = "crl"
+	// OtherURL names anything else.
+	OtherURL URLClass = "other"
+)
+
+// Stage is the part of attestation checking that produced a checks.Result.
+type Stage string
+
+const (
+	// StageVerify checks the report signature and certificate chain.
+	StageVerify Stage = "verify"
+	// StageValidate checks the report against a policy.
+	StageValidate Stage = "validate"
+)
+
+// KDSRequest describes the fetch of one URL, including any retries.
+type KDSRequest struct {
+	Class URLClass
+	// Latency is the time from the first attempt to the final result.
+	Latency time.Duration
+	// StatusCode is the HTTP status code of the last attempt if it failed with one, or 0.
+	StatusCode int
+	// Retries is the number of attempts after the first.
+	Retries int
+	// Err is the error of the fetch, or nil if it succeeded.
+	Err error
+}
+
+// CRLRefresh describes the download of a CRL that was missing or past its NextUpdate time.
+type CRLRefresh struct {
+	Product string
+	// Signer is the kind of endorsement key whose CRL was refreshed, e.g., "VCEK".
+	Signer string
+	// Err is the reason the refresh failed, or nil if it succeeded.
+	Err error
+}
+
+// CacheLookup describes a CachingHTTPSGetter lookup.
+type CacheLookup struct {
+	Class URLClass
+	// Hit is true if the response was served from the cache.
+	Hit bool
+}
+
+// Outcome describes the checks one stage performed on an attestation.
+type Outcome struct {
+	Stage  Stage
+	Result *checks.Result
+	// Duration is how long the stage took.
+	Duration time.Duration
+}
+
+// Observer receives events as they happen. Implementations must be safe for concurrent use and
+// should return quickly. The context is the caller's, when one is available, so that an observer
+// may associate the event with a trace.
+type Observer interface {
+	KDSRequest(ctx context.Context, e *KDSRequest)
+	CRLRefresh(ctx context.Context, e *CRLRefresh)
+	CacheLookup(ctx context.Context, e *CacheLookup)
+	Outcome(ctx context.Context, e *Outcome)
+}
+
+// Nop is an Observer that ignores all events.
+type Nop struct{}
+
+// KDSRequest does nothing.
+func (Nop) KDSRequest(context.Context, *KDSRequest) {}
+
+// CRLRefresh does nothing.
+func (Nop) CRLRefresh(context.Context, *CRLRefresh) {}
+
+// CacheLookup does nothing.
+func (Nop) CacheLookup(context.Context, *CacheLookup) {}
+
+// Outcome does nothing.
+func (Nop) Outcome(context.Context, *Outcome) {}
+
+var (
+	defaultMu       sync.RWMutex
+	defaultObserver Observer = Nop{}
+)
+
+// SetDefault sets the Observer that receives events from anything not given its own. A nil o
+// restores the Nop default.
+func SetDefault(o Observer) {
+	if o == nil {
+		o = Nop{}
+	}
+	defaultMu.Lock()
+	defaultObserver = o
+	defaultMu.Unlock()
+}
+
+// Default returns the Observer set by SetDefault.
+func Default() Observer {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultObserver
+}
+
+// OrDefault returns o if it is not nil, and otherwise Default().
+func OrDefault(o Observer) Observer {
+	if o != nil {
+		return o
+	}
+	return Default()
+}
@@ -0,0 +1,79 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package otelmetrics adapts an OpenTelemetry metric.Meter to an observe.Meter. It is a separate
+// module so that the go-sev-guest module does not depend on OpenTelemetry.
+package otelmetrics
+
+import (
+	"context"
+
+	"github.com/google/go-sev-guest/observe"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+type meter struct {
+	meter metric.Meter
+}
+
+// NewMeter returns an observe.Meter whose metrics m creates, e.g., for observe.NewMetrics.
+func NewMeter(m metric.Meter) observe.Meter {
+	return &meter{meter: m}
+}
+
+// NewMetrics returns an observe.Metrics that records events with metrics m creates.
+func NewMetrics(m metric.Meter) (*observe.Metrics, error) {
+	return observe.NewMetrics(NewMeter(m))
+}
+
+func (m *meter) Counter(name, description string) (observe.Counter, error) {
+	c, err := m.meter.Int64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		return nil, err
+	}
+	return &counter{counter: c}, nil
+}
+
+func (m *meter) Histogram(name, description, unit string) (observe.Histogram, error) {
+	h, err := m.meter.Float64Histogram(name, metric.WithDescription(description), metric.WithUnit(unit))
+	if err != nil {
+		return nil, err
+	}
+	return &histogram{histogram: h}, nil
+}
+
+func attributes(attrs []observe.Attribute) metric.MeasurementOption {
+	kvs := make([]attribute.KeyValue, len(attrs))
+	for i, a := range attrs {
+		kvs[i] = attribute.String(a.Key, a.Value)
+	}
+	return metric.WithAttributes(kvs...)
+}
+
+type counter struct {
+	counter metric.Int64Counter
+}
+
+func (c *counter) Add(ctx context.Context, incr int64, attrs ...observe.Attribute) {
+	c.counter.Add(ctx, incr, attributes(attrs))
+}
+
+type histogram struct {
+	histogram metric.Float64Histogram
+}
+
+func (h *histogram) Record(ctx context.Context, value float64, attrs ...observe.Attribute) {
+	h.histogram.Record(ctx, value, attributes(attrs))
+}
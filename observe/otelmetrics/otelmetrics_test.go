@@ -0,0 +1,67 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otelmetrics
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/observe"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestMetrics(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	provider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))
+	m, err := NewMetrics(provider.Meter("test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	m.KDSRequest(ctx, &observe.KDSRequest{Class: observe.VcekURL, Latency: 2 * time.Second, Retries: 3})
+
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(ctx, &rm); err != nil {
+		t.Fatal(err)
+	}
+	found := make(map[string]metricdata.Aggregation)
+	for _, sm := range rm.ScopeMetrics {
+		for _, metric := range sm.Metrics {
+			found[metric.Name] = metric.Data
+		}
+	}
+	wantAttrs := attribute.NewSet(attribute.String("class", "vcek"), attribute.String("result", "ok"))
+	requests, ok := found[observe.KDSRequestsMetric].(metricdata.Sum[int64])
+	if !ok || len(requests.DataPoints) != 1 {
+		t.Fatalf("%s = %v, want one int64 sum", observe.KDSRequestsMetric, found[observe.KDSRequestsMetric])
+	}
+	if p := requests.DataPoints[0]; p.Value != 1 || !p.Attributes.Equals(&wantAttrs) {
+		t.Errorf("%s = %d with %v, want 1 with %v", observe.KDSRequestsMetric, p.Value, p.Attributes.ToSlice(), wantAttrs.ToSlice())
+	}
+	latency, ok := found[observe.KDSLatencyMetric].(metricdata.Histogram[float64])
+	if !ok || len(latency.DataPoints) != 1 {
+		t.Fatalf("%s = %v, want one float64 histogram", observe.KDSLatencyMetric, found[observe.KDSLatencyMetric])
+	}
+	if p := latency.DataPoints[0]; p.Count != 1 || p.Sum != 2 {
+		t.Errorf("%s has count %d and sum %v, want 1 and 2", observe.KDSLatencyMetric, p.Count, p.Sum)
+	}
+	retries, ok := found[observe.KDSRetriesMetric].(metricdata.Sum[int64])
+	if !ok || len(retries.DataPoints) != 1 || retries.DataPoints[0].Value != 3 {
+		t.Errorf("%s = %v, want 3", observe.KDSRetriesMetric, found[observe.KDSRetriesMetric])
+	}
+}
@@ -0,0 +1,23 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sevsnp
+
+// GetAuthorKeyEn returns the word at offset 0x48 of the report, which field 10 held under the name
+// author_key_en before it was renamed signer_info.
+//
+// Deprecated: Use GetSignerInfo, and abi.ParseSignerInfo to interpret it.
+func (x *Report) GetAuthorKeyEn() uint32 {
+	return x.GetSignerInfo()
+}
@@ -0,0 +1,294 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sealing derives keys from the AMD security processor and seals data with them.
+//
+// A Policy describes which root key and which launch and platform state the processor mixes into
+// a derived key. Subkey expands a derived key for a given purpose with HKDF-SHA256. Seal encrypts
+// data with AES-256-GCM under the "seal" subkey and stores the policy in the blob's header, so
+// Unseal can derive the same key again. Since the processor mixes the current values of the
+// selected fields into the key, a blob sealed under one measurement does not unseal under
+// another.
+//
+// A sealed blob is
+//
+//	"SNPS" || version (4 bytes) || policy (40 bytes) || nonce (12 bytes) || ciphertext
+//
+// where the header before the ciphertext is authenticated along with the caller's additional
+// data. Integers are little-endian, and the policy has the layout of the MSG_KEY_REQ structure.
+// Seal writes version 2. Version 1 blobs have a 32-byte policy without the launch mitigation
+// vector, and Unseal still reads them.
+//
+// Read LIMITATIONS.md before you choose a policy.
+package sealing
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/google/go-sev-guest/client"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// PolicySize is the size in bytes of a serialized Policy.
+	PolicySize = 0x28
+	// BlobVersion is the version of the sealed blob format that Seal produces.
+	BlobVersion = 2
+	// HeaderSize is the size in bytes of a sealed blob's header.
+	HeaderSize = 0x3c
+
+	blobPolicyOffset = 0x08
+	blobNonceOffset  = 0x30
+	blobNonceSize    = 12
+
+	// Version 1 blobs have a policy without the launch mitigation vector.
+	blobVersionV1 = 1
+	policySizeV1  = 0x20
+	headerSizeV1  = 0x34
+
+	// kdfLabel separates these derivations from any other use of a derived key.
+	kdfLabel = "sev-snp-derived-key-v1"
+	// sealPurpose is the Subkey purpose of the key that Seal uses.
+	sealPurpose = "seal"
+)
+
+var (
+	blobMagic = []byte("SNPS")
+
+	// ErrUnseal is returned when a blob does not decrypt with the key derived from its policy. This
+	// happens when the blob was modified, or when it was sealed with a different root key or
+	// under different values of the policy's selected fields.
+	ErrUnseal = errors.New("blob does not unseal under the current launch and platform state")
+)
+
+// Policy describes the inputs of a derived key. It is a serializable form of
+// client.SnpDerivedKeyReq.
+type Policy struct {
+	// UseVCEK selects the chip key as the root key if true, and the VMRK otherwise.
+	UseVCEK bool `json:"use_vcek"`
+	// KeySelect selects between the VCEK and the VLEK as the chip key.
+	KeySelect client.DerivedKeySelect `json:"key_select"`
+	// GuestFieldSelect selects the launch and platform state that is mixed into the key.
+	GuestFieldSelect client.GuestFieldSelect `json:"guest_field_select"`
+	// Vmpl to mix into the key. Must be greater than or equal to the current VMPL.
+	Vmpl uint32 `json:"vmpl"`
+	// GuestSVN to mix into the key if selected. Must be less than or equal to the GuestSVN at
+	// launch.
+	GuestSVN uint32 `json:"guest_svn"`
+	// TCBVersion to mix into the key if selected. Must be less than or equal to the committed TCB.
+	TCBVersion uint64 `json:"tcb_version"`
+	// LaunchMitVector to mix into the key if selected. Must be a subset of the launch mitigation
+	// vector.
+	LaunchMitVector uint64 `json:"launch_mit_vector"`
+}
+
+// PolicyFromReport returns a policy that selects the given fields, with the VMPL, guest SVN, and
+// committed TCB of the report, so that the key is as specific as the report allows. The policy has
+// the report's launch mitigation vector only if fields select it, since only a MessageDevice can
+// send the vector.
+func PolicyFromReport(report *spb.Report, fields client.GuestFieldSelect, useVCEK bool) *Policy {
+	p := &Policy{
+		UseVCEK:          useVCEK,
+		GuestFieldSelect: fields,
+		Vmpl:             report.GetVmpl(),
+		GuestSVN:         report.GetGuestSvn(),
+		TCBVersion:       report.GetCommittedTcb(),
+	}
+	if fields.LaunchMitVector {
+		p.LaunchMitVector = report.GetLaunchMitVector()
+	}
+	return p
+}
+
+// Request returns the derived key request that the policy describes.
+func (p *Policy) Request() *client.SnpDerivedKeyReq {
+	return &client.SnpDerivedKeyReq{
+		UseVCEK:          p.UseVCEK,
+		KeySelect:        p.KeySelect,
+		GuestFieldSelect: p.GuestFieldSelect,
+		Vmpl:             p.Vmpl,
+		GuestSVN:         p.GuestSVN,
+		TCBVersion:       p.TCBVersion,
+		LaunchMitVector:  p.LaunchMitVector,
+	}
+}
+
+// Marshal returns the binary form of the policy.
+func (p *Policy) Marshal() []byte {
+	data := make([]byte, PolicySize)
+	binary.LittleEndian.PutUint32(data[0x00:0x04], p.Request().RootKeySelect())
+	binary.LittleEndian.PutUint64(data[0x08:0x10], p.GuestFieldSelect.ABI())
+	binary.LittleEndian.PutUint32(data[0x10:0x14], p.Vmpl)
+	binary.LittleEndian.PutUint32(data[0x14:0x18], p.GuestSVN)
+	binary.LittleEndian.PutUint64(data[0x18:0x20], p.TCBVersion)
+	binary.LittleEndian.PutUint64(data[0x20:0x28], p.LaunchMitVector)
+	return data
+}
+
+// ParsePolicy returns the policy represented by its binary form in data.
+func ParsePolicy(data []byte) (*Policy, error) {
+	if len(data) != PolicySize {
+		return nil, fmt.Errorf("policy size is 0x%x, expected 0x%x", len(data), PolicySize)
+	}
+	rootKeySelect := binary.LittleEndian.Uint32(data[0x00:0x04])
+	switch rootKeySelect {
+	case 1, uint32(client.KeySelectDefault) << 1, uint32(client.KeySelectVCEK) << 1, uint32(client.KeySelectVLEK) << 1:
+	default:
+		return nil, fmt.Errorf("policy root key select is 0x%x, expected the VMRK or a valid KEY_SEL", rootKeySelect)
+	}
+	if reserved := binary.LittleEndian.Uint32(data[0x04:0x08]); reserved != 0 {
+		return nil, fmt.Errorf("policy reserved field is 0x%x, expected 0", reserved)
+	}
+	fields, err := client.ParseGuestFieldSelect(binary.LittleEndian.Uint64(data[0x08:0x10]))
+	if err != nil {
+		return nil, fmt.Errorf("policy %v", err)
+	}
+	p := &Policy{
+		UseVCEK:          rootKeySelect&1 == 0,
+		KeySelect:        client.DerivedKeySelect(rootKeySelect >> 1),
+		GuestFieldSelect: fields,
+		Vmpl:             binary.LittleEndian.Uint32(data[0x10:0x14]),
+		GuestSVN:         binary.LittleEndian.Uint32(data[0x14:0x18]),
+		TCBVersion:       binary.LittleEndian.Uint64(data[0x18:0x20]),
+		LaunchMitVector:  binary.LittleEndian.Uint64(data[0x20:0x28]),
+	}
+	if p.Vmpl > 3 {
+		return nil, fmt.Errorf("policy VMPL is %d. Expect 0-3", p.Vmpl)
+	}
+	return p, nil
+}
+
+// DeriveKey returns the 32-byte key that the device derives for the policy.
+func DeriveKey(d client.Device, p *Policy) ([]byte, error) {
+	if p.Vmpl > 3 {
+		return nil, fmt.Errorf("policy VMPL is %d. Expect 0-3", p.Vmpl)
+	}
+	resp, err := client.GetDerivedKeyAcknowledgingItsLimitations(d, p.Request())
+	if err != nil {
+		return nil, err
+	}
+	return resp.Data[:], nil
+}
+
+// Subkey returns size bytes of key material for the given purpose expanded from a derived key
+// with HKDF-SHA256. Different purposes give independent keys.
+func Subkey(key []byte, purpose string, size int) ([]byte, error) {
+	info := append([]byte(kdfLabel+"\x00"), purpose...)
+	result := make([]byte, size)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, key, nil, info), result); err != nil {
+		return nil, fmt.Errorf("could not expand key for %q: %v", purpose, err)
+	}
+	return result, nil
+}
+
+func newAEAD(d client.Device, p *Policy) (cipher.AEAD, error) {
+	key, err := DeriveKey(d, p)
+	if err != nil {
+		return nil, err
+	}
+	sealKey, err := Subkey(key, sealPurpose, 32)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(sealKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts and authenticates plaintext and additionalData with a key that the device derives
+// for the policy. The additional data is not part of the blob and must be given again to Unseal.
+func Seal(d client.Device, p *Policy, plaintext, additionalData []byte) ([]byte, error) {
+	aead, err := newAEAD(d, p)
+	if err != nil {
+		return nil, err
+	}
+	header := make([]byte, HeaderSize)
+	copy(header[0:4], blobMagic)
+	binary.LittleEndian.PutUint32(header[4:blobPolicyOffset], BlobVersion)
+	copy(header[blobPolicyOffset:blobNonceOffset], p.Marshal())
+	nonce := make([]byte, blobNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("could not generate nonce: %v", err)
+	}
+	copy(header[blobNonceOffset:HeaderSize], nonce)
+	ad := append(append([]byte{}, header...), additionalData...)
+	return aead.Seal(header, nonce, plaintext, ad), nil
+}
+
+// parseHeader returns the policy that a sealed blob's header specifies and the size of the header,
+// which ends with the nonce.
+func parseHeader(blob []byte) (*Policy, int, error) {
+	if len(blob) < blobPolicyOffset {
+		return nil, 0, fmt.Errorf("sealed blob size is 0x%x, expected at least 0x%x", len(blob), HeaderSize)
+	}
+	if string(blob[0:4]) != string(blobMagic) {
+		return nil, 0, fmt.Errorf("sealed blob magic is %q, expected %q", blob[0:4], blobMagic)
+	}
+	policySize, headerSize := PolicySize, HeaderSize
+	switch version := binary.LittleEndian.Uint32(blob[4:blobPolicyOffset]); version {
+	case BlobVersion:
+	case blobVersionV1:
+		policySize, headerSize = policySizeV1, headerSizeV1
+	default:
+		return nil, 0, fmt.Errorf("sealed blob version is %d, expected %d or %d", version, blobVersionV1, BlobVersion)
+	}
+	if len(blob) < headerSize {
+		return nil, 0, fmt.Errorf("sealed blob size is 0x%x, expected at least 0x%x", len(blob), headerSize)
+	}
+	// A version 1 policy is a policy whose launch mitigation vector is zero.
+	policy := make([]byte, PolicySize)
+	copy(policy, blob[blobPolicyOffset:blobPolicyOffset+policySize])
+	p, err := ParsePolicy(policy)
+	if err != nil {
+		return nil, 0, err
+	}
+	return p, headerSize, nil
+}
+
+// BlobPolicy returns the policy that a sealed blob's header specifies. Version 1 blobs from before
+// the launch mitigation vector are still read.
+func BlobPolicy(blob []byte) (*Policy, error) {
+	p, _, err := parseHeader(blob)
+	return p, err
+}
+
+// Unseal returns the plaintext of a blob that Seal produced, and the policy it was sealed under.
+// Returns an error that wraps ErrUnseal if the device derives a different key for the policy, or
+// if the blob or additional data differ from what was sealed.
+func Unseal(d client.Device, blob, additionalData []byte) ([]byte, *Policy, error) {
+	p, headerSize, err := parseHeader(blob)
+	if err != nil {
+		return nil, nil, err
+	}
+	aead, err := newAEAD(d, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	ad := append(append([]byte{}, blob[:headerSize]...), additionalData...)
+	plaintext, err := aead.Open(nil, blob[headerSize-blobNonceSize:headerSize], blob[headerSize:], ad)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrUnseal, err)
+	}
+	return plaintext, p, nil
+}
@@ -0,0 +1,225 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sealing
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-sev-guest/client"
+	labi "github.com/google/go-sev-guest/client/linuxabi"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	test "github.com/google/go-sev-guest/testing"
+)
+
+var measurementPolicy = &Policy{
+	GuestFieldSelect: client.GuestFieldSelect{Measurement: true, GuestSVN: true},
+	Vmpl:             1,
+	GuestSVN:         2,
+}
+
+// device returns a fake device that derives the given key for measurementPolicy, as if the
+// launch measurement were mixed into it.
+func device(t *testing.T, key byte) *test.Device {
+	t.Helper()
+	req := &labi.SnpDerivedKeyReqABI{RootKeySelect: 1, GuestFieldSelect: 0x18, Vmpl: 1, GuestSVN: 2}
+	d := &test.Device{Keys: map[string][]byte{test.DerivedKeyRequestToString(req): bytes.Repeat([]byte{key}, 32)}}
+	if err := d.Open("/dev/sev-guest"); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestPolicy(t *testing.T) {
+	p := &Policy{
+		UseVCEK:          true,
+		KeySelect:        client.KeySelectVLEK,
+		GuestFieldSelect: client.GuestFieldSelect{TCBVersion: true, FamilyID: true, GuestPolicy: true, LaunchMitVector: true},
+		Vmpl:             3,
+		GuestSVN:         7,
+		TCBVersion:       0xd315000000000004,
+		LaunchMitVector:  0x5,
+	}
+	got, err := ParsePolicy(p.Marshal())
+	if err != nil {
+		t.Fatalf("ParsePolicy(%x) = _, %v. Want nil", p.Marshal(), err)
+	}
+	if diff := cmp.Diff(p, got); diff != "" {
+		t.Errorf("ParsePolicy(p.Marshal()) differs (-want +got):\n%s", diff)
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fromJSON Policy
+	if err := json.Unmarshal(data, &fromJSON); err != nil || !cmp.Equal(p, &fromJSON) {
+		t.Errorf("json.Unmarshal(%s) = %v, %v. Want %v, nil", data, fromJSON, err, p)
+	}
+
+	with := func(offset int, value byte) []byte {
+		result := p.Marshal()
+		result[offset] = value
+		return result
+	}
+	tcs := []struct {
+		name    string
+		data    []byte
+		wantErr string
+	}{
+		{name: "short", data: make([]byte, 0x10), wantErr: "policy size is 0x10"},
+		{name: "vmrk key select", data: with(0x00, 3), wantErr: "root key select is 0x3"},
+		{name: "reserved key select", data: with(0x00, 6), wantErr: "root key select is 0x6"},
+		{name: "reserved", data: with(0x04, 1), wantErr: "reserved field is 0x1"},
+		{name: "unknown field", data: with(0x08, 0x80), wantErr: "has unknown bits"},
+		{name: "vmpl", data: with(0x10, 4), wantErr: "VMPL is 4"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := ParsePolicy(tc.data); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("ParsePolicy() = _, %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyFromReport(t *testing.T) {
+	report := &spb.Report{Vmpl: 1, GuestSvn: 2, CommittedTcb: 3, CurrentTcb: 4, LaunchMitVector: 5}
+	fields := client.GuestFieldSelect{Measurement: true}
+	want := &Policy{GuestFieldSelect: fields, Vmpl: 1, GuestSVN: 2, TCBVersion: 3}
+	if diff := cmp.Diff(want, PolicyFromReport(report, fields, false)); diff != "" {
+		t.Errorf("PolicyFromReport() differs (-want +got):\n%s", diff)
+	}
+	fields.LaunchMitVector = true
+	want = &Policy{GuestFieldSelect: fields, Vmpl: 1, GuestSVN: 2, TCBVersion: 3, LaunchMitVector: 5}
+	if diff := cmp.Diff(want, PolicyFromReport(report, fields, false)); diff != "" {
+		t.Errorf("PolicyFromReport(LaunchMitVector) differs (-want +got):\n%s", diff)
+	}
+}
+
+func TestSubkey(t *testing.T) {
+	key := bytes.Repeat([]byte{1}, 32)
+	a, err := Subkey(key, "a", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := Subkey(key, "a", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Subkey(key, "b", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a, again) {
+		t.Errorf("Subkey(key, \"a\") = %x then %x, want equal", a, again)
+	}
+	if bytes.Equal(a, b) || bytes.Equal(a, key) {
+		t.Errorf("Subkey(key, \"a\") = %x, want different from Subkey(key, \"b\") = %x and key", a, b)
+	}
+}
+
+func withVersion(blob []byte, version uint32) []byte {
+	result := append([]byte{}, blob...)
+	binary.LittleEndian.PutUint32(result[4:blobPolicyOffset], version)
+	return result
+}
+
+// sealV1 returns a blob in the version 1 format, whose header has the policy without the launch
+// mitigation vector.
+func sealV1(t *testing.T, d client.Device, p *Policy, plaintext, additionalData []byte) []byte {
+	t.Helper()
+	aead, err := newAEAD(d, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]byte, headerSizeV1)
+	copy(header[0:4], blobMagic)
+	binary.LittleEndian.PutUint32(header[4:blobPolicyOffset], blobVersionV1)
+	copy(header[blobPolicyOffset:], p.Marshal()[:policySizeV1])
+	nonce := bytes.Repeat([]byte{7}, blobNonceSize)
+	copy(header[headerSizeV1-blobNonceSize:], nonce)
+	return aead.Seal(header, nonce, plaintext, append(append([]byte{}, header...), additionalData...))
+}
+
+func TestUnsealVersion1(t *testing.T) {
+	d := device(t, 0x11)
+	blob := sealV1(t, d, measurementPolicy, []byte("secret"), []byte("disk-1"))
+	plaintext, p, err := Unseal(d, blob, []byte("disk-1"))
+	if err != nil {
+		t.Fatalf("Unseal(version 1) = _, _, %v. Want nil", err)
+	}
+	if string(plaintext) != "secret" || !cmp.Equal(p, measurementPolicy) {
+		t.Errorf("Unseal(version 1) = %q, %v. Want \"secret\", %v", plaintext, p, measurementPolicy)
+	}
+	if _, _, err := Unseal(d, withVersion(blob, BlobVersion), []byte("disk-1")); err == nil {
+		t.Error("Unseal(version 1 blob read as version 2) = _, _, nil. Want error")
+	}
+}
+
+func TestSealUnseal(t *testing.T) {
+	d := device(t, 0x11)
+	blob, err := Seal(d, measurementPolicy, []byte("secret"), []byte("disk-1"))
+	if err != nil {
+		t.Fatalf("Seal() = _, %v. Want nil", err)
+	}
+	if bytes.Contains(blob, []byte("secret")) {
+		t.Errorf("Seal() = %x, which contains the plaintext", blob)
+	}
+	plaintext, p, err := Unseal(d, blob, []byte("disk-1"))
+	if err != nil {
+		t.Fatalf("Unseal() = _, _, %v. Want nil", err)
+	}
+	if string(plaintext) != "secret" || !cmp.Equal(p, measurementPolicy) {
+		t.Errorf("Unseal() = %q, %v. Want \"secret\", %v", plaintext, p, measurementPolicy)
+	}
+
+	tampered := append([]byte{}, blob...)
+	tampered[len(tampered)-1] ^= 1
+	otherSvn := append([]byte{}, blob...)
+	otherSvn[blobPolicyOffset+0x14] = 1
+	tcs := []struct {
+		name          string
+		device        *test.Device
+		blob          []byte
+		ad            string
+		wantErr       string
+		wantErrUnseal bool
+	}{
+		{name: "other measurement", device: device(t, 0x22), blob: blob, ad: "disk-1", wantErrUnseal: true},
+		{name: "other additional data", device: d, blob: blob, ad: "disk-2", wantErrUnseal: true},
+		{name: "tampered", device: d, blob: tampered, ad: "disk-1", wantErrUnseal: true},
+		{name: "other policy", device: d, blob: otherSvn, ad: "disk-1", wantErr: "error getting derived key"},
+		{name: "magic", device: d, blob: append([]byte("SNPX"), blob[4:]...), ad: "disk-1", wantErr: "sealed blob magic"},
+		{name: "short", device: d, blob: blob[:HeaderSize-1], ad: "disk-1", wantErr: "expected at least 0x3c"},
+		{name: "version", device: d, blob: withVersion(blob, 3), ad: "disk-1", wantErr: "sealed blob version is 3, expected 1 or 2"},
+		{name: "short version 1", device: d, blob: withVersion(blob[:headerSizeV1-1], blobVersionV1), ad: "disk-1", wantErr: "expected at least 0x34"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := Unseal(tc.device, tc.blob, []byte(tc.ad))
+			if tc.wantErrUnseal && !errors.Is(err, ErrUnseal) {
+				t.Errorf("Unseal() = _, _, %v. Want ErrUnseal", err)
+			}
+			if tc.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("Unseal() = _, _, %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
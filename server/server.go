@@ -0,0 +1,338 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package server provides an HTTP service that verifies attestations and validates them against a
+// check.Config policy.
+package server
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
+	"github.com/google/go-sev-guest/eat"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/google/go-sev-guest/verify"
+	"github.com/google/go-sev-guest/verify/trust"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// FormatBin is the AMD ABI format of an attestation report immediately followed by its
+	// certificate table.
+	FormatBin = "bin"
+	// FormatProto is the binary serialization of an sevsnp.Attestation message.
+	FormatProto = "proto"
+	// FormatTextproto is the text serialization of an sevsnp.Attestation message.
+	FormatTextproto = "textproto"
+
+	// VerifyPath is the path of the attestation checking endpoint.
+	VerifyPath = "/v1/verify"
+	// ResultPath is the path of the endpoint that responds with a signed EAT Attestation Result.
+	ResultPath = "/v1/attestation-result"
+
+	// eatContentType is the media type of an EAT in the JWT form.
+	eatContentType = "application/eat+jwt"
+
+	// maxAttestationSize bounds the request body size. Certificate tables are a few KiB.
+	maxAttestationSize = 1 << 20
+)
+
+// Stages of checking an attestation, in order.
+const (
+	// StageParse is the stage of deserializing the attestation.
+	StageParse = "parse"
+	// StageVerify is the stage of checking the report signature and certificate chain.
+	StageVerify = "verify"
+	// StageValidate is the stage of checking the report against the policy.
+	StageValidate = "validate"
+)
+
+// Failure is a single failed check of an attestation.
+type Failure struct {
+	Stage string `json:"stage"`
+	// Check is the name of the failed check as recorded by the verify or validate packages.
+	// Empty for parse failures.
+	Check    string `json:"check,omitempty"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Error    string `json:"error"`
+}
+
+// Verdict is the result of checking an attestation against the server's policy.
+type Verdict struct {
+	// Verified is true iff the report signature and its certificate chain are valid.
+	Verified bool `json:"verified"`
+	// Validated is true iff the verified report satisfies the policy.
+	Validated bool `json:"validated"`
+	// Failures lists every failed check.
+	Failures []*Failure `json:"failures,omitempty"`
+}
+
+// Accepted returns true iff the attestation is verified and validated.
+func (v *Verdict) Accepted() bool {
+	return v.Verified && v.Validated
+}
+
+func (v *Verdict) addFailures(stage string, err error) {
+	for _, e := range multierr.Errors(err) {
+		v.Failures = append(v.Failures, &Failure{Stage: stage, Error: e.Error()})
+	}
+}
+
+func (v *Verdict) addResult(stage string, result *checks.Result) {
+	for _, c := range result.Failures() {
+		v.Failures = append(v.Failures, &Failure{
+			Stage:    stage,
+			Check:    c.Name,
+			Expected: c.Expected,
+			Actual:   c.Actual,
+			Error:    c.Err.Error(),
+		})
+	}
+}
+
+// ParseAttestation deserializes an attestation in the given format.
+func ParseAttestation(contents []byte, format string) (*spb.Attestation, error) {
+	result := &spb.Attestation{}
+	switch format {
+	case FormatBin:
+		if len(contents) < abi.ReportSize {
+			return nil, fmt.Errorf("attestation contents too small (0x%x bytes). Want at least 0x%x bytes", len(contents), abi.ReportSize)
+		}
+		report, err := abi.ReportToProto(contents[:abi.ReportSize])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse attestation report: %v", err)
+		}
+		certs := new(abi.CertTable)
+		if err := certs.Unmarshal(contents[abi.ReportSize:]); err != nil {
+			return nil, fmt.Errorf("could not parse certificate table: %v", err)
+		}
+		result.Report = report
+		result.CertificateChain = certs.Proto()
+	case FormatProto:
+		if err := proto.Unmarshal(contents, result); err != nil {
+			return nil, fmt.Errorf("could not parse attestation as proto: %v", err)
+		}
+	case FormatTextproto:
+		if err := prototext.Unmarshal(contents, result); err != nil {
+			return nil, fmt.Errorf("could not parse attestation as textproto: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown attestation format %q. Expect %q, %q, or %q", format, FormatBin, FormatProto, FormatTextproto)
+	}
+	if result.GetReport() == nil {
+		return nil, errors.New("attestation has no report")
+	}
+	return result, nil
+}
+
+// Server checks attestations against a fixed policy. It is safe for concurrent use.
+type Server struct {
+	config     *cpb.Config
+	verifyOpts *verify.Options
+	mux        *http.ServeMux
+
+	// resultKey signs attestation results if not nil.
+	resultKey   crypto.Signer
+	resultKeyID string
+	verifierID  eat.VerifierID
+}
+
+// New returns a server that checks attestations against the given config. If getter is not nil, it
+// is used instead of the AMD KDS to fetch missing certificates and CRLs, e.g., a testing.FakeKDS.
+func New(config *cpb.Config, getter trust.HTTPSGetter) (*Server, error) {
+	if config == nil {
+		return nil, errors.New("config cannot be nil")
+	}
+	rot := config.GetRootOfTrust()
+	if rot == nil {
+		rot = &cpb.RootOfTrust{}
+	}
+	if rot.GetCheckCrl() && rot.GetDisallowNetwork() {
+		return nil, errors.New("cannot specify both check_crl and disallow_network")
+	}
+	verifyOpts, err := verify.RootOfTrustToOptions(rot)
+	if err != nil {
+		return nil, fmt.Errorf("invalid root_of_trust: %v", err)
+	}
+	// Check the policy once up front so that every request need not fail the same way.
+	if _, err := validate.PolicyToOptions(config.GetPolicy()); err != nil {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
+	if getter == nil {
+		getter = trust.DefaultHTTPSGetter()
+	}
+	verifyOpts.Getter = getter
+	s := &Server{
+		config:     proto.Clone(config).(*cpb.Config),
+		verifyOpts: verifyOpts,
+		mux:        http.NewServeMux(),
+	}
+	s.mux.HandleFunc(VerifyPath, s.handleVerify)
+	return s, nil
+}
+
+// Check verifies the attestation and validates it against the server's policy. Missing
+// certificates are filled into the attestation.
+func (s *Server) Check(attestation *spb.Attestation) *Verdict {
+	return s.CheckContext(context.Background(), attestation)
+}
+
+// CheckContext is Check, but gives up downloading certificates and CRLs once ctx is done.
+func (s *Server) CheckContext(ctx context.Context, attestation *spb.Attestation) *Verdict {
+	verdict, _, _ := s.check(ctx, attestation)
+	return verdict
+}
+
+// check returns the verdict on the attestation and the outcome of every check that ran. The error
+// is that of an invalid policy, which fails validation without a check.
+func (s *Server) check(ctx context.Context, attestation *spb.Attestation) (*Verdict, *checks.Result, error) {
+	verdict := &Verdict{}
+	all := &checks.Result{}
+	// Copy the options since verification may adjust them.
+	verifyOpts := *s.verifyOpts
+	result := verify.SnpAttestationResultContext(ctx, attestation, &verifyOpts)
+	all.Merge(result)
+	if result.Err() != nil {
+		verdict.addResult(StageVerify, result)
+		return verdict, all, nil
+	}
+	verdict.Verified = true
+	// Validation options are rebuilt per request since validation adds key hashes to them.
+	validateOpts, err := validate.PolicyToOptions(s.config.GetPolicy())
+	if err != nil {
+		verdict.addFailures(StageValidate, err)
+		return verdict, all, err
+	}
+	result = validate.SnpAttestationResult(attestation, validateOpts)
+	all.Merge(result)
+	if result.Err() != nil {
+		verdict.addResult(StageValidate, result)
+		return verdict, all, nil
+	}
+	verdict.Validated = true
+	return verdict, all, nil
+}
+
+// EnableAttestationResults serves signed EAT Attestation Results at ResultPath, which lets the
+// server act as a RATS Verifier. The results are signed with key and identify the server as id.
+// The keyID is each result's "kid" header if not empty. Must be called before the server handles
+// requests.
+func (s *Server) EnableAttestationResults(key crypto.Signer, keyID string, id eat.VerifierID) error {
+	if key == nil {
+		return errors.New("attestation result signing key cannot be nil")
+	}
+	if s.resultKey == nil {
+		s.mux.HandleFunc(ResultPath, s.handleResult)
+	}
+	s.resultKey = key
+	s.resultKeyID = keyID
+	s.verifierID = id
+	return nil
+}
+
+// AttestationResult checks the attestation and returns its appraisal as a signed EAT Attestation
+// Result. Errors if attestation results are not enabled.
+func (s *Server) AttestationResult(ctx context.Context, attestation *spb.Attestation) (string, error) {
+	if s.resultKey == nil {
+		return "", errors.New("attestation results are not enabled")
+	}
+	_, result, err := s.check(ctx, attestation)
+	if err != nil {
+		return "", err
+	}
+	claims, err := eat.NewAttestationResult(attestation, result, time.Now(), s.verifierID)
+	if err != nil {
+		return "", err
+	}
+	return eat.Sign(claims, s.resultKey, s.resultKeyID)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func writeVerdict(w http.ResponseWriter, status int, verdict *Verdict) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(verdict)
+}
+
+// readAttestation parses the POSTed attestation in the format given by the "format" query
+// parameter, which defaults to "bin". Responds with an error and returns nil if it cannot.
+func readAttestation(w http.ResponseWriter, r *http.Request) *spb.Attestation {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = FormatBin
+	}
+	contents, err := io.ReadAll(io.LimitReader(r.Body, maxAttestationSize+1))
+	verdict := &Verdict{}
+	if err == nil && len(contents) > maxAttestationSize {
+		err = fmt.Errorf("attestation is larger than 0x%x bytes", maxAttestationSize)
+	}
+	if err != nil {
+		verdict.addFailures(StageParse, err)
+		writeVerdict(w, http.StatusBadRequest, verdict)
+		return nil
+	}
+	attestation, err := ParseAttestation(contents, format)
+	if err != nil {
+		verdict.addFailures(StageParse, err)
+		writeVerdict(w, http.StatusBadRequest, verdict)
+		return nil
+	}
+	return attestation
+}
+
+// handleVerify accepts a POSTed attestation and responds with a JSON Verdict. The status is 200
+// whenever the attestation could be parsed, even if it is not accepted.
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	if attestation := readAttestation(w, r); attestation != nil {
+		writeVerdict(w, http.StatusOK, s.CheckContext(r.Context(), attestation))
+	}
+}
+
+// handleResult accepts a POSTed attestation and responds with a signed EAT Attestation Result. The
+// status is 200 whenever the attestation could be parsed, even if the result contraindicates it.
+func (s *Server) handleResult(w http.ResponseWriter, r *http.Request) {
+	attestation := readAttestation(w, r)
+	if attestation == nil {
+		return
+	}
+	token, err := s.AttestationResult(r.Context(), attestation)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", eatContentType)
+	io.WriteString(w, token)
+}
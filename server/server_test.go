@@ -0,0 +1,320 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/client"
+	"github.com/google/go-sev-guest/eat"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	test "github.com/google/go-sev-guest/testing"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/google/go-sev-guest/verify"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+type fixture struct {
+	bin       []byte
+	report    []byte
+	textproto []byte
+	forged    []byte
+	good, bad *Server
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	device, err := test.TcDevice(test.TestCases(), &test.DeviceOptions{Now: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := device.Open("/dev/sev-guest"); err != nil {
+		t.Fatal(err)
+	}
+	defer device.Close()
+	kds, err := test.FakeKDSFromSigner(device.Signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := test.TestCases()[0].Input
+	report, certs, err := client.GetRawExtendedReport(device, input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bin := append(append([]byte{}, report...), certs...)
+	attestation, err := ParseAttestation(bin, FormatBin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	textproto, err := prototext.Marshal(attestation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Changing REPORT_DATA invalidates the signature.
+	forged := append([]byte{}, bin...)
+	forged[0x50] ^= 0xff
+
+	cabundle := test.ProductRootsPEM(device.Signer)
+	newConfig := func(reportData []byte) *cpb.Config {
+		return &cpb.Config{
+			RootOfTrust: &cpb.RootOfTrust{Cabundles: []string{string(cabundle)}},
+			Policy: &cpb.Policy{
+				Policy:         abi.SnpPolicyToBytes(abi.SnpPolicy{Debug: true}),
+				MinimumVersion: "0.0",
+				ReportData:     reportData,
+			},
+		}
+	}
+	good, err := New(newConfig(input[:]), kds)
+	if err != nil {
+		t.Fatalf("New() errored unexpectedly: %v", err)
+	}
+	otherData := make([]byte, abi.ReportDataSize)
+	otherData[0] = 0xff
+	bad, err := New(newConfig(otherData), kds)
+	if err != nil {
+		t.Fatalf("New() errored unexpectedly: %v", err)
+	}
+	return &fixture{bin: bin, report: report, textproto: textproto, forged: forged, good: good, bad: bad}
+}
+
+func TestServer(t *testing.T) {
+	f := newFixture(t)
+	good, bad, bin, textproto, forged, report := f.good, f.bad, f.bin, f.textproto, f.forged, f.report
+	tests := []struct {
+		name       string
+		server     *Server
+		method     string
+		format     string
+		body       []byte
+		wantStatus int
+		want       *Verdict
+	}{
+		{
+			name:       "bin",
+			server:     good,
+			body:       bin,
+			wantStatus: http.StatusOK,
+			want:       &Verdict{Verified: true, Validated: true},
+		},
+		{
+			name:       "textproto",
+			server:     good,
+			format:     FormatTextproto,
+			body:       textproto,
+			wantStatus: http.StatusOK,
+			want:       &Verdict{Verified: true, Validated: true},
+		},
+		{
+			name:       "policy mismatch",
+			server:     bad,
+			body:       bin,
+			wantStatus: http.StatusOK,
+			want: &Verdict{Verified: true, Failures: []*Failure{
+				{Stage: StageValidate, Check: validate.ReportDataCheck, Error: "report field REPORT_DATA"},
+			}},
+		},
+		{
+			name:       "bad signature",
+			server:     good,
+			body:       forged,
+			wantStatus: http.StatusOK,
+			want:       &Verdict{Failures: []*Failure{{Stage: StageVerify, Check: verify.SignatureCheck}}},
+		},
+		{
+			name:       "unknown format",
+			server:     good,
+			format:     "json",
+			body:       bin,
+			wantStatus: http.StatusBadRequest,
+			want: &Verdict{Failures: []*Failure{
+				{Stage: StageParse, Error: `unknown attestation format "json"`},
+			}},
+		},
+		{
+			name:       "short",
+			server:     good,
+			body:       report[:10],
+			wantStatus: http.StatusBadRequest,
+			want: &Verdict{Failures: []*Failure{
+				{Stage: StageParse, Error: "attestation contents too small"},
+			}},
+		},
+		{
+			name:       "get",
+			server:     good,
+			method:     http.MethodGet,
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.server)
+			defer srv.Close()
+			method := tc.method
+			if method == "" {
+				method = http.MethodPost
+			}
+			url := srv.URL + VerifyPath
+			if tc.format != "" {
+				url += "?format=" + tc.format
+			}
+			req, err := http.NewRequest(method, url, bytes.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tc.wantStatus {
+				t.Fatalf("%s %s status = %d, want %d", method, url, resp.StatusCode, tc.wantStatus)
+			}
+			if tc.want == nil {
+				return
+			}
+			got := &Verdict{}
+			if err := json.NewDecoder(resp.Body).Decode(got); err != nil {
+				t.Fatalf("could not decode verdict: %v", err)
+			}
+			if got.Verified != tc.want.Verified || got.Validated != tc.want.Validated {
+				t.Errorf("verdict = %+v, want verified=%v validated=%v", got, tc.want.Verified, tc.want.Validated)
+			}
+			if len(got.Failures) < len(tc.want.Failures) || (len(tc.want.Failures) == 0 && len(got.Failures) != 0) {
+				t.Fatalf("verdict failures = %v, want %v", got.Failures, tc.want.Failures)
+			}
+			for i, want := range tc.want.Failures {
+				if got.Failures[i].Stage != want.Stage || got.Failures[i].Check != want.Check ||
+					!strings.Contains(got.Failures[i].Error, want.Error) {
+					t.Errorf("verdict failure %d = %+v, want %+v", i, got.Failures[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  *cpb.Config
+		wantErr string
+	}{
+		{
+			name:    "nil",
+			wantErr: "config cannot be nil",
+		},
+		{
+			name: "crl without network",
+			config: &cpb.Config{RootOfTrust: &cpb.RootOfTrust{
+				CheckCrl:        true,
+				DisallowNetwork: true,
+			}},
+			wantErr: "cannot specify both check_crl and disallow_network",
+		},
+		{
+			name:    "bad policy",
+			config:  &cpb.Config{Policy: &cpb.Policy{MinimumVersion: "x"}},
+			wantErr: "invalid policy",
+		},
+	}
+	for _, tc := range tests {
+		if _, err := New(tc.config, nil); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s: New() = _, %v. Want error %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestAttestationResult(t *testing.T) {
+	f := newFixture(t)
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := eat.VerifierID{Developer: "https://example.com", Build: "test"}
+	for _, s := range []*Server{f.good, f.bad} {
+		if err := s.EnableAttestationResults(key, "key-1", id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tests := []struct {
+		name       string
+		server     *Server
+		body       []byte
+		wantStatus eat.Status
+	}{
+		{name: "accepted", server: f.good, body: f.bin, wantStatus: eat.StatusAffirming},
+		{name: "policy mismatch", server: f.bad, body: f.bin, wantStatus: eat.StatusContraindicated},
+		{name: "bad signature", server: f.good, body: f.forged, wantStatus: eat.StatusContraindicated},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(tc.server)
+			defer srv.Close()
+			resp, err := http.Post(srv.URL+ResultPath, "application/octet-stream", bytes.NewReader(tc.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("POST %s status = %d, want %d", ResultPath, resp.StatusCode, http.StatusOK)
+			}
+			if got := resp.Header.Get("Content-Type"); got != eatContentType {
+				t.Errorf("POST %s Content-Type = %q, want %q", ResultPath, got, eatContentType)
+			}
+			token, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := eat.ParseAttestationResult(string(token), []crypto.PublicKey{&key.PublicKey}, time.Now())
+			if err != nil {
+				t.Fatalf("ParseAttestationResult() = _, %v. Want nil", err)
+			}
+			if result.VerifierID != id {
+				t.Errorf("attestation result verifier = %v, want %v", result.VerifierID, id)
+			}
+			if got := result.Appraisal().Status; got != tc.wantStatus {
+				t.Errorf("attestation result status = %q, want %q", got, tc.wantStatus)
+			}
+		})
+	}
+
+	disabled, err := New(&cpb.Config{Policy: &cpb.Policy{Policy: abi.SnpPolicyToBytes(abi.SnpPolicy{Debug: true}), MinimumVersion: "0.0"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(disabled)
+	defer srv.Close()
+	resp, err := http.Post(srv.URL+ResultPath, "application/octet-stream", bytes.NewReader(f.bin))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("POST %s without attestation results status = %d, want %d", ResultPath, resp.StatusCode, http.StatusNotFound)
+	}
+}
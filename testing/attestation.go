@@ -0,0 +1,85 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"encoding/binary"
+	"encoding/pem"
+	"math/big"
+	"testing"
+
+	"github.com/google/go-sev-guest/abi"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+)
+
+// AttestationOptions describes the report that SignedAttestation signs.
+type AttestationOptions struct {
+	// ReportData is the report's REPORT_DATA.
+	ReportData [64]byte
+	// SignerInfo is the report's SIGNER_INFO.
+	SignerInfo uint32
+	// Sign signs the report. If nil, uses the signer's VLEK if SignerInfo selects it, and
+	// otherwise its VCEK.
+	Sign func(toSign []byte) (*big.Int, *big.Int, error)
+}
+
+// SignedAttestation returns an attestation of TestRawReport with the given options, signed by
+// signer and with its VCEK, ASK and ARK certificates.
+func SignedAttestation(t testing.TB, signer *AmdSigner, opts *AttestationOptions) *spb.Attestation {
+	t.Helper()
+	if opts == nil {
+		opts = &AttestationOptions{}
+	}
+	resp := TestRawReport(opts.ReportData)
+	raw := resp[:abi.ReportSize]
+	binary.LittleEndian.PutUint32(raw[0x48:0x4C], opts.SignerInfo)
+	sign := opts.Sign
+	if sign == nil {
+		sign = signer.Sign
+		info, err := abi.ParseSignerInfo(opts.SignerInfo)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.SigningKey == abi.VlekReportSigner {
+			sign = signer.SignVlek
+		}
+	}
+	r, s, err := sign(abi.SignedComponent(raw))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := abi.SetSignature(r, s, raw); err != nil {
+		t.Fatal(err)
+	}
+	report, err := abi.ReportToProto(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &spb.Attestation{
+		Report: report,
+		CertificateChain: &spb.CertificateChain{
+			VcekCert: signer.Vcek.Raw,
+			AskCert:  signer.Ask.Raw,
+			ArkCert:  signer.Ark.Raw,
+		},
+	}
+}
+
+// ProductRootsPEM returns the signer's ASK and ARK certificates in the PEM format of the KDS
+// cert_chain, e.g., for a RootOfTrust's cabundles.
+func ProductRootsPEM(signer *AmdSigner) []byte {
+	return append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signer.Ask.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: signer.Ark.Raw})...)
+}
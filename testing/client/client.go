@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/google/go-sev-guest/client"
+	"github.com/google/go-sev-guest/kds"
 	test "github.com/google/go-sev-guest/testing"
 	"github.com/google/go-sev-guest/verify/trust"
 )
@@ -42,10 +43,14 @@ func GetSevGuest(tcs []test.TestCase, opts *test.DeviceOptions, tb testing.TB) (
 		if err != nil {
 			tb.Fatalf("failed to create test device: %v", err)
 		}
+		product, err := kds.ProductFromSigningKey(sevTestDevice.Signer.Ask)
+		if err != nil {
+			tb.Fatalf("failed to determine test device product: %v", err)
+		}
 		goodSnpRoot := map[string][]*trust.AMDRootCerts{
-			"Milan": {
+			product: {
 				{
-					Product: "Milan",
+					Product: product,
 					ProductCerts: &trust.ProductCerts{
 						Ask: sevTestDevice.Signer.Ask,
 						Ark: sevTestDevice.Signer.Ark,
@@ -54,9 +59,9 @@ func GetSevGuest(tcs []test.TestCase, opts *test.DeviceOptions, tb testing.TB) (
 			},
 		}
 		badSnpRoot := map[string][]*trust.AMDRootCerts{
-			"Milan": {
+			product: {
 				{
-					Product: "Milan",
+					Product: product,
 					ProductCerts: &trust.ProductCerts{
 						// No ASK, oops.
 						Ask: sevTestDevice.Signer.Ark,
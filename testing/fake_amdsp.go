@@ -0,0 +1,143 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"fmt"
+
+	"github.com/google/go-sev-guest/abi"
+	labi "github.com/google/go-sev-guest/client/linuxabi"
+)
+
+// msgInvalidParameters is the guest message response status for invalid request parameters.
+const msgInvalidParameters = 0x16
+
+// AmdSP is a fake AMD secure processor that answers encrypted guest messages from a Device's
+// pre-programmed responses. It implements the client's CertificateTransport interface.
+type AmdSP struct {
+	Device *Device
+	// Vmpcks are the guest's VMPCKs by index. A nil key disables its VMPCK.
+	Vmpcks [4][]byte
+	// Exports maps a GPA to the page description that MSG_EXPORT_REQ responds with.
+	Exports map[uint64][]byte
+	seqnos  [4]uint64
+}
+
+// SequenceNumber returns the sequence number of the last message exchanged with a VMPCK.
+func (sp *AmdSP) SequenceNumber(vmpck uint8) uint64 {
+	return sp.seqnos[vmpck]
+}
+
+// Certificates returns the device's certificate table.
+func (sp *AmdSP) Certificates() ([]byte, error) {
+	return sp.Device.Certs, nil
+}
+
+func (sp *AmdSP) getReport(payload []byte) ([]byte, error) {
+	req, err := abi.ParseReportRequest(payload)
+	if err != nil {
+		return nil, err
+	}
+	var rsp labi.SnpReportRespABI
+	var fwErr uint64
+	esResult, err := sp.Device.getReport(&labi.SnpReportReqABI{ReportData: req.ReportData, Vmpl: req.Vmpl}, &rsp, &fwErr)
+	if fwErr != 0 {
+		return nil, &abi.SevFirmwareErr{Status: abi.SevFirmwareStatus(fwErr)}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if esResult != uintptr(labi.EsOk) {
+		return nil, &labi.SevEsErr{Result: labi.EsResult(esResult)}
+	}
+	return (&abi.ReportResponse{Report: rsp.Data[:abi.ReportSize]}).Marshal(), nil
+}
+
+func (sp *AmdSP) getDerivedKey(payload []byte) ([]byte, error) {
+	req, err := abi.ParseKeyRequest(payload)
+	if err != nil {
+		return nil, err
+	}
+	var rsp labi.SnpDerivedKeyRespABI
+	if _, err := sp.Device.getDerivedKey(&labi.SnpDerivedKeyReqABI{
+		RootKeySelect:    req.RootKeySelect,
+		GuestFieldSelect: req.GuestFieldSelect,
+		Vmpl:             req.Vmpl,
+		GuestSVN:         req.GuestSVN,
+		TCBVersion:       req.TCBVersion,
+		LaunchMitVector:  req.LaunchMitVector,
+	}, &rsp, nil); err != nil {
+		return nil, err
+	}
+	return (&abi.KeyResponse{DerivedKey: rsp.Data}).Marshal(), nil
+}
+
+func (sp *AmdSP) export(payload []byte) ([]byte, error) {
+	req, err := abi.ParseExportRequest(payload)
+	if err != nil {
+		return nil, err
+	}
+	page, ok := sp.Exports[req.GPA]
+	if !ok {
+		return (&abi.ExportResponse{GPA: req.GPA, Status: msgInvalidParameters}).Marshal(), nil
+	}
+	return (&abi.ExportResponse{GPA: req.GPA, Page: page}).Marshal(), nil
+}
+
+// Send decrypts a guest message request and returns its encrypted response. A firmware error
+// leaves the sequence number unchanged.
+func (sp *AmdSP) Send(request []byte) ([]byte, error) {
+	h, err := abi.ParseGuestMessageHeader(request)
+	if err != nil {
+		return nil, err
+	}
+	if h.Vmpck >= uint8(len(sp.Vmpcks)) || sp.Vmpcks[h.Vmpck] == nil {
+		return nil, fmt.Errorf("test error: VMPCK%d is disabled", h.Vmpck)
+	}
+	key := sp.Vmpcks[h.Vmpck]
+	h, payload, err := abi.OpenGuestMessage(key, request)
+	if err != nil {
+		return nil, err
+	}
+	if h.SequenceNumber != sp.seqnos[h.Vmpck]+1 {
+		return nil, fmt.Errorf("test error: sequence number %d, want %d", h.SequenceNumber, sp.seqnos[h.Vmpck]+1)
+	}
+	var response []byte
+	switch h.MessageType {
+	case abi.MsgReportReq:
+		response, err = sp.getReport(payload)
+	case abi.MsgKeyReq:
+		response, err = sp.getDerivedKey(payload)
+	case abi.MsgExportReq:
+		response, err = sp.export(payload)
+	default:
+		err = fmt.Errorf("test error: unsupported message type %d", h.MessageType)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rsp := &abi.GuestMessageHeader{
+		SequenceNumber: h.SequenceNumber + 1,
+		MessageType:    h.MessageType + 1,
+		MessageVersion: h.MessageVersion,
+		Vmpck:          h.Vmpck,
+	}
+	msg, err := abi.SealGuestMessage(key, rsp, response)
+	if err != nil {
+		return nil, err
+	}
+	sp.seqnos[h.Vmpck] = rsp.SequenceNumber
+	return msg, nil
+}
@@ -44,6 +44,7 @@ const (
 	vcekExpirationYears = 7
 	arkRsaBits          = 4096
 	askRsaBits          = 4096
+	asvkRsaBits         = 4096
 )
 
 // AmdSigner encapsulates a key and certificate chain following the format of AMD-SP's VCEK for
@@ -52,6 +53,9 @@ type AmdSigner struct {
 	Ark  *x509.Certificate
 	Ask  *x509.Certificate
 	Vcek *x509.Certificate
+	// Asvk and Vlek are only present if the keys include an ASVK and VLEK.
+	Asvk *x509.Certificate
+	Vlek *x509.Certificate
 	Keys *AmdKeys
 	// This identity does not match AMD's notion of an HWID. It is purely to combine expectations of
 	// report data -> KDS URL construction for the fake KDS implementation.
@@ -59,27 +63,43 @@ type AmdSigner struct {
 	TCB  kds.TCBVersion
 }
 
-// AmdKeys encapsulates the key chain of ARK through ASK down to VCEK.
+// AmdKeys encapsulates the key chain of ARK through ASK down to VCEK, and optionally ARK through
+// ASVK down to VLEK.
 type AmdKeys struct {
 	Ark  *rsa.PrivateKey
 	Ask  *rsa.PrivateKey
 	Vcek *ecdsa.PrivateKey
+	Asvk *rsa.PrivateKey
+	Vlek *ecdsa.PrivateKey
 }
 
 var insecureRandomness = rand.New(rand.NewSource(0xc0de))
 
-// Sign takes a chunk of bytes, signs it with VcekPriv, and returns the R, S pair for the signature
-// in little endian format.
-func (s *AmdSigner) Sign(toSign []byte) (*big.Int, *big.Int, error) {
+func sign(key *ecdsa.PrivateKey, toSign []byte) (*big.Int, *big.Int, error) {
 	h := crypto.SHA384.New()
 	h.Write(toSign)
-	R, S, err := ecdsa.Sign(insecureRandomness, s.Keys.Vcek, h.Sum(nil))
+	R, S, err := ecdsa.Sign(insecureRandomness, key, h.Sum(nil))
 	if err != nil {
 		return nil, nil, err
 	}
 	return R, S, nil
 }
 
+// Sign takes a chunk of bytes, signs it with VcekPriv, and returns the R, S pair for the signature
+// in little endian format.
+func (s *AmdSigner) Sign(toSign []byte) (*big.Int, *big.Int, error) {
+	return sign(s.Keys.Vcek, toSign)
+}
+
+// SignVlek takes a chunk of bytes, signs it with the VLEK private key, and returns the R, S pair
+// for the signature in little endian format.
+func (s *AmdSigner) SignVlek(toSign []byte) (*big.Int, *big.Int, error) {
+	if s.Keys.Vlek == nil {
+		return nil, nil, fmt.Errorf("signer has no VLEK")
+	}
+	return sign(s.Keys.Vlek, toSign)
+}
+
 // CertOverride encapsulates certificate aspects that can be overridden when creating a certificate
 // chain.
 type CertOverride struct {
@@ -108,12 +128,16 @@ type AmdSignerBuilder struct {
 	ArkCustom        CertOverride
 	AskCustom        CertOverride
 	VcekCustom       CertOverride
+	AsvkCustom       CertOverride
+	VlekCustom       CertOverride
 	HWID             [abi.ChipIDSize]byte
 	TCB              kds.TCBVersion
 	// Intermediate built certificates
 	Ark  *x509.Certificate
 	Ask  *x509.Certificate
 	Vcek *x509.Certificate
+	Asvk *x509.Certificate
+	Vlek *x509.Certificate
 }
 
 func amdPkixName(commonName string, serialNumber string) pkix.Name {
@@ -203,6 +227,20 @@ func DefaultVcek() (*ecdsa.PrivateKey, error) {
 	return privateKey, nil
 }
 
+// DefaultAsvk returns a new RSA key with the expected size for an ASVK.
+func DefaultAsvk() (*rsa.PrivateKey, error) {
+	privateKey, err := rsa.GenerateKey(insecureRandomness, asvkRsaBits)
+	if err != nil {
+		return nil, err
+	}
+	return privateKey, nil
+}
+
+// DefaultVlek returns a new ECDSA key on the expected curve for a VLEK.
+func DefaultVlek() (*ecdsa.PrivateKey, error) {
+	return DefaultVcek()
+}
+
 // DefaultAmdKeys returns a key set for ARK, ASK, and VCEK with the expected key type and size.
 func DefaultAmdKeys() (*AmdKeys, error) {
 	ark, err := DefaultArk()
@@ -257,8 +295,14 @@ func (b *AmdSignerBuilder) certifyAsk() error {
 // CustomVcekExtensions returns an array of extensions following the KDS specification
 // for the given values.
 func CustomVcekExtensions(tcb kds.TCBParts, hwid [64]byte) []pkix.Extension {
+	return ProductVcekExtensions("Milan-B0", tcb, hwid)
+}
+
+// ProductVcekExtensions returns an array of extensions following the KDS specification
+// for the given product name (e.g., "Genoa-B1") and values.
+func ProductVcekExtensions(productName string, tcb kds.TCBParts, hwid [64]byte) []pkix.Extension {
 	asn1Zero, _ := asn1.Marshal(0)
-	productName, _ := asn1.Marshal("Milan-B0")
+	asn1ProductName, _ := asn1.Marshal(productName)
 	blSpl, _ := asn1.Marshal(int(tcb.BlSpl))
 	teeSpl, _ := asn1.Marshal(int(tcb.TeeSpl))
 	snpSpl, _ := asn1.Marshal(int(tcb.SnpSpl))
@@ -270,7 +314,7 @@ func CustomVcekExtensions(tcb kds.TCBParts, hwid [64]byte) []pkix.Extension {
 	asn1Hwid, _ := asn1.Marshal(hwid[:])
 	return []pkix.Extension{
 		{Id: kds.OidStructVersion, Value: asn1Zero},
-		{Id: kds.OidProductName1, Value: productName},
+		{Id: kds.OidProductName1, Value: asn1ProductName},
 		{Id: kds.OidBlSpl, Value: blSpl},
 		{Id: kds.OidTeeSpl, Value: teeSpl},
 		{Id: kds.OidSnpSpl, Value: snpSpl},
@@ -283,6 +327,67 @@ func CustomVcekExtensions(tcb kds.TCBParts, hwid [64]byte) []pkix.Extension {
 	}
 }
 
+func (b *AmdSignerBuilder) certifyAsvk() error {
+	cert := unsignedArkOrAsk("ARK", "SEV-VLEK", b.Product, b.Ark.Subject.SerialNumber, b.AskCreationTime, askExpirationYears)
+	cert.SerialNumber = big.NewInt(0xc0dec0df)
+	cert.Subject.SerialNumber = fmt.Sprintf("%x", cert.SerialNumber)
+	cert.CRLDistributionPoints = []string{kds.CrlURL(b.Product, abi.VlekReportSigner)}
+	cert.KeyUsage = x509.KeyUsageCertSign
+
+	b.AsvkCustom.override(cert)
+
+	caBytes, err := x509.CreateCertificate(insecureRandomness, cert, b.Ark, b.Keys.Asvk.Public(), b.Keys.Ark)
+	if err != nil {
+		return fmt.Errorf("could not create a certificate from %v: %v", cert, err)
+	}
+	asvkcert, err := x509.ParseCertificate(caBytes)
+	if err != nil {
+		return err
+	}
+	b.Asvk = asvkcert
+	return nil
+}
+
+// CustomVlekExtensions returns an array of extensions following the KDS specification
+// for the given values.
+func CustomVlekExtensions(tcb kds.TCBParts, cspID string) []pkix.Extension {
+	return ProductVlekExtensions("Milan-B0", tcb, cspID)
+}
+
+// ProductVlekExtensions returns an array of VLEK extensions following the KDS specification
+// for the given product name (e.g., "Genoa-B1") and values.
+func ProductVlekExtensions(productName string, tcb kds.TCBParts, cspID string) []pkix.Extension {
+	exts := ProductVcekExtensions(productName, tcb, [64]byte{})
+	// Replace the HWID extension with the CSP_ID extension.
+	asn1CspID, _ := asn1.MarshalWithParams(cspID, "ia5")
+	exts[len(exts)-1] = pkix.Extension{Id: kds.OidCspID, Value: asn1CspID}
+	return exts
+}
+
+func (b *AmdSignerBuilder) certifyVlek() error {
+	cert := &x509.Certificate{}
+	cert.SignatureAlgorithm = x509.SHA384WithRSAPSS
+	cert.PublicKeyAlgorithm = x509.ECDSA
+	cert.Version = 3
+	cert.Issuer = amdPkixName(fmt.Sprintf("SEV-VLEK-%s", b.Product), b.Asvk.Subject.SerialNumber)
+	cert.Subject = amdPkixName("SEV-VLEK", "0")
+	cert.SerialNumber = big.NewInt(0)
+	cert.Subject.SerialNumber = fmt.Sprintf("%x", cert.SerialNumber)
+	cert.NotBefore = time.Time{}
+	cert.NotAfter = b.VcekCreationTime.Add(vcekExpirationYears * 365 * 24 * time.Hour)
+	cert.ExtraExtensions = ProductVlekExtensions(fmt.Sprintf("%s-B0", b.Product), kds.TCBParts{}, "go-sev-guest")
+
+	b.VlekCustom.override(cert)
+
+	caBytes, err := x509.CreateCertificate(insecureRandomness, cert, b.Asvk, b.Keys.Vlek.Public(), b.Keys.Asvk)
+	if err != nil {
+		return fmt.Errorf("could not create a certificate from %v: %v", cert, err)
+	}
+	signed, err := x509.ParseCertificate(caBytes)
+	b.Vlek = signed
+	return err
+}
+
 func (b *AmdSignerBuilder) certifyVcek() error {
 	cert := &x509.Certificate{}
 	cert.SignatureAlgorithm = x509.SHA384WithRSAPSS
@@ -295,7 +400,7 @@ func (b *AmdSignerBuilder) certifyVcek() error {
 	cert.NotBefore = time.Time{}
 	cert.NotAfter = b.VcekCreationTime.Add(vcekExpirationYears * 365 * 24 * time.Hour)
 	var hwid [64]byte
-	cert.ExtraExtensions = CustomVcekExtensions(kds.TCBParts{}, hwid)
+	cert.ExtraExtensions = ProductVcekExtensions(fmt.Sprintf("%s-B0", b.Product), kds.TCBParts{}, hwid)
 
 	b.VcekCustom.override(cert)
 
@@ -329,10 +434,22 @@ func (b *AmdSignerBuilder) CertChain() (*AmdSigner, error) {
 	if err := b.certifyVcek(); err != nil {
 		return nil, fmt.Errorf("vcek creation error: %v", err)
 	}
+	if b.Keys.Asvk != nil {
+		if err := b.certifyAsvk(); err != nil {
+			return nil, fmt.Errorf("asvk creation error: %v", err)
+		}
+		if b.Keys.Vlek != nil {
+			if err := b.certifyVlek(); err != nil {
+				return nil, fmt.Errorf("vlek creation error: %v", err)
+			}
+		}
+	}
 	s := &AmdSigner{
 		Ark:  b.Ark,
 		Ask:  b.Ask,
 		Vcek: b.Vcek,
+		Asvk: b.Asvk,
+		Vlek: b.Vlek,
 		Keys: b.Keys,
 		TCB:  b.TCB,
 	}
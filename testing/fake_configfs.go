@@ -0,0 +1,207 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/google/go-sev-guest/abi"
+	labi "github.com/google/go-sev-guest/client/linuxabi"
+	"github.com/pborman/uuid"
+	"golang.org/x/sys/unix"
+)
+
+// tsmPrivlevelMax is the highest privilege level the configfs-tsm interface accepts.
+const tsmPrivlevelMax = 3
+
+// fakeTsmEntry is the state of one configfs-tsm report entry directory.
+type fakeTsmEntry struct {
+	inblob          []byte
+	privlevel       uint32
+	serviceProvider string
+	serviceGUID     string
+	manifestVersion uint32
+	generation      int
+}
+
+// FakeConfigfs is an in-memory configfs-tsm report tree that serves attestation reports and
+// certificates from a fake sev-guest Device. It implements client.TsmFS.
+type FakeConfigfs struct {
+	Device *Device
+	// Provider is the value of each entry's provider attribute. If empty, uses "sev_guest".
+	Provider string
+	// SvsmManifest is the services manifest of a fake SVSM that produces reports at VMPL0 for
+	// entries whose service_provider is "svsm". If nil, the entries have no SVSM service provider.
+	// Each service has only a version 0 manifest.
+	SvsmManifest *abi.SvsmServicesManifest
+
+	mu      sync.Mutex
+	next    int
+	entries map[string]*fakeTsmEntry
+}
+
+func pathErr(op, name string, err error) error {
+	return &fs.PathError{Op: op, Path: name, Err: err}
+}
+
+func (c *FakeConfigfs) entry(op, name string) (*fakeTsmEntry, string, error) {
+	dir, attribute := path.Split(name)
+	e, ok := c.entries[path.Clean(dir)]
+	if !ok {
+		return nil, "", pathErr(op, name, fs.ErrNotExist)
+	}
+	return e, attribute, nil
+}
+
+// MkdirTemp creates a new report entry under dir.
+func (c *FakeConfigfs) MkdirTemp(dir, pattern string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]*fakeTsmEntry)
+	}
+	c.next++
+	name := path.Join(dir, fmt.Sprintf("%s%d", pattern, c.next))
+	c.entries[name] = &fakeTsmEntry{}
+	return name, nil
+}
+
+// Remove deletes a report entry.
+func (c *FakeConfigfs) Remove(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[name]; !ok {
+		return pathErr("remove", name, fs.ErrNotExist)
+	}
+	delete(c.entries, name)
+	return nil
+}
+
+// WriteFile writes a report entry attribute. Each write increments the entry's generation.
+func (c *FakeConfigfs) WriteFile(name string, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, attribute, err := c.entry("write", name)
+	if err != nil {
+		return err
+	}
+	switch attribute {
+	case "inblob":
+		if len(data) > abi.ReportDataSize {
+			return pathErr("write", name, syscall.Errno(unix.EINVAL))
+		}
+		e.inblob = make([]byte, abi.ReportDataSize)
+		copy(e.inblob, data)
+	case "privlevel":
+		level, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+		if err != nil || level > tsmPrivlevelMax {
+			return pathErr("write", name, syscall.Errno(unix.EINVAL))
+		}
+		e.privlevel = uint32(level)
+	case "service_provider":
+		if c.SvsmManifest == nil || strings.TrimSpace(string(data)) != "svsm" {
+			return pathErr("write", name, syscall.Errno(unix.EINVAL))
+		}
+		e.serviceProvider = "svsm"
+	case "service_guid":
+		if uuid.Parse(strings.TrimSpace(string(data))) == nil {
+			return pathErr("write", name, syscall.Errno(unix.EINVAL))
+		}
+		e.serviceGUID = strings.TrimSpace(string(data))
+	case "service_manifest_version":
+		version, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 32)
+		if err != nil {
+			return pathErr("write", name, syscall.Errno(unix.EINVAL))
+		}
+		e.manifestVersion = uint32(version)
+	default:
+		return pathErr("write", name, syscall.Errno(unix.EACCES))
+	}
+	e.generation++
+	return nil
+}
+
+// ReadFile reads a report entry attribute. The outblob and auxblob attributes are generated from
+// the Device at read time.
+func (c *FakeConfigfs) ReadFile(name string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, attribute, err := c.entry("read", name)
+	if err != nil {
+		return nil, err
+	}
+	switch attribute {
+	case "provider":
+		if c.Provider == "" {
+			return []byte("sev_guest\n"), nil
+		}
+		return []byte(c.Provider + "\n"), nil
+	case "generation":
+		return []byte(fmt.Sprintf("%d\n", e.generation)), nil
+	case "privlevel":
+		return []byte(fmt.Sprintf("%d\n", e.privlevel)), nil
+	case "privlevel_floor":
+		return []byte("0\n"), nil
+	case "manifestblob":
+		manifest, err := c.manifest(e)
+		if err != nil {
+			return nil, pathErr("read", name, err)
+		}
+		return manifest, nil
+	case "outblob":
+		if e.inblob == nil {
+			return nil, pathErr("read", name, syscall.Errno(unix.EINVAL))
+		}
+		req := &labi.SnpReportReqABI{Vmpl: e.privlevel}
+		copy(req.ReportData[:], e.inblob)
+		if e.serviceProvider != "" {
+			manifest, err := c.manifest(e)
+			if err != nil {
+				return nil, pathErr("read", name, err)
+			}
+			req = &labi.SnpReportReqABI{ReportData: abi.SvsmReportData(e.inblob, manifest)}
+		}
+		var rsp labi.SnpReportRespABI
+		var fwErr uint64
+		if _, err := c.Device.getReport(req, &rsp, &fwErr); err != nil {
+			return nil, pathErr("read", name, err)
+		}
+		return append([]byte{}, rsp.Data[:abi.ReportSize]...), nil
+	case "auxblob":
+		return append([]byte{}, c.Device.Certs...), nil
+	}
+	return nil, pathErr("read", name, fs.ErrNotExist)
+}
+
+// manifest returns the manifest that the fake SVSM binds to an entry's report.
+func (c *FakeConfigfs) manifest(e *fakeTsmEntry) ([]byte, error) {
+	if e.serviceProvider == "" {
+		return nil, syscall.Errno(unix.EINVAL)
+	}
+	if e.serviceGUID == "" {
+		return c.SvsmManifest.Marshal()
+	}
+	manifest, err := c.SvsmManifest.Service(e.serviceGUID)
+	if err != nil || e.manifestVersion != 0 {
+		return nil, syscall.Errno(unix.EINVAL)
+	}
+	return manifest, nil
+}
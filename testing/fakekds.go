@@ -97,9 +97,13 @@ func FakeKDSFromSigner(signer *AmdSigner) (*FakeKDS, error) {
 	); err != nil {
 		return nil, fmt.Errorf("could not encode root certificates: %v", err)
 	}
+	product, err := kds.ProductFromSigningKey(signer.Ask)
+	if err != nil {
+		return nil, err
+	}
 	return &FakeKDS{
 		Certs:       certs,
-		RootBundles: map[string]string{"Milan": b.String()},
+		RootBundles: map[string]string{product: b.String()},
 	}, nil
 }
 
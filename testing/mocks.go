@@ -104,7 +104,8 @@ func (d *Device) getExtReport(req *labi.SnpExtendedReportReq, rsp *labi.SnpRepor
 
 // DerivedKeyRequestToString translates a DerivedKeyReqABI into a map key string representation.
 func DerivedKeyRequestToString(req *labi.SnpDerivedKeyReqABI) string {
-	return fmt.Sprintf("%x %x %x %x %x", req.RootKeySelect, req.GuestFieldSelect, req.Vmpl, req.GuestSVN, req.TCBVersion)
+	return fmt.Sprintf("%x %x %x %x %x %x", req.RootKeySelect, req.GuestFieldSelect, req.Vmpl, req.GuestSVN, req.TCBVersion,
+		req.LaunchMitVector)
 }
 
 func (d *Device) getDerivedKey(req *labi.SnpDerivedKeyReqABI, rsp *labi.SnpDerivedKeyRespABI, _ *uint64) (uintptr, error) {
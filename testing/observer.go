@@ -0,0 +1,59 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testing
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/go-sev-guest/observe"
+)
+
+// Observer records every event it receives.
+type Observer struct {
+	mu           sync.Mutex
+	KDSRequests  []*observe.KDSRequest
+	CRLRefreshes []*observe.CRLRefresh
+	CacheLookups []*observe.CacheLookup
+	Outcomes     []*observe.Outcome
+}
+
+// KDSRequest records e.
+func (o *Observer) KDSRequest(_ context.Context, e *observe.KDSRequest) {
+	o.mu.Lock()
+	o.KDSRequests = append(o.KDSRequests, e)
+	o.mu.Unlock()
+}
+
+// CRLRefresh records e.
+func (o *Observer) CRLRefresh(_ context.Context, e *observe.CRLRefresh) {
+	o.mu.Lock()
+	o.CRLRefreshes = append(o.CRLRefreshes, e)
+	o.mu.Unlock()
+}
+
+// CacheLookup records e.
+func (o *Observer) CacheLookup(_ context.Context, e *observe.CacheLookup) {
+	o.mu.Lock()
+	o.CacheLookups = append(o.CacheLookups, e)
+	o.mu.Unlock()
+}
+
+// Outcome records e.
+func (o *Observer) Outcome(_ context.Context, e *observe.Outcome) {
+	o.mu.Lock()
+	o.Outcomes = append(o.Outcomes, e)
+	o.mu.Unlock()
+}
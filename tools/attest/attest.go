@@ -23,7 +23,9 @@ import (
 	"os"
 
 	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/bundle"
 	"github.com/google/go-sev-guest/client"
+	checkpb "github.com/google/go-sev-guest/proto/check"
 	"github.com/google/go-sev-guest/tools/lib/cmdline"
 	"github.com/google/logger"
 	"google.golang.org/protobuf/encoding/prototext"
@@ -55,6 +57,22 @@ var (
 	out  = flag.String("out", "", "Path to output file to write attestation report to. "+
 		"If unset, outputs to stdout.")
 	verbose = flag.Bool("v", false, "Enable verbose logging.")
+	svsm    = flag.Bool("svsm", false,
+		"Request the attestation report from the SVSM at VMPL0 through the SVSM attestation protocol. "+
+			"The REPORT_DATA input is the nonce. Implies -extended and ignores -vmpl.")
+	svsmService = flag.String("svsm_service", "",
+		"The GUID of the single service to attest with -svsm. If unset, attests all services.")
+	svsmManifestVersion = flag.Uint("svsm_manifest_version", 0,
+		"The manifest version of the -svsm_service to request.")
+	svsmManifestOut = flag.String("svsm_manifest_out", "",
+		"Path to output file to write the manifest that an -svsm attestation report binds. Required with -svsm.")
+	bundleOut = flag.Bool("bundle", false,
+		"Output a bundle.Bundle of the extended report with its certificates and CRL from the AMD KDS, "+
+			"and its verdict against -bundle_config, for checking offline with check -bundle. "+
+			"Requires -outform=proto or -outform=textproto.")
+	bundleConfig = flag.String("bundle_config", "",
+		"Path to the check.Config to record in a -bundle. Default unmarshalled as binary. Paths ending "+
+			"in .textproto will be unmarshalled as prototext. Required with -bundle.")
 )
 
 func indata() ([]byte, error) {
@@ -91,9 +109,9 @@ func nonBinOut() func(proto.Message) ([]byte, error) {
 	}
 }
 
-func outputExtendedReport(device client.Device, data [abi.ReportDataSize]byte, out io.Writer) error {
+func outputExtendedReport(provider client.ReportProvider, data [abi.ReportDataSize]byte, out io.Writer) error {
 	if *outform == "bin" {
-		report, certs, err := client.GetRawExtendedReportAtVmpl(device, data, *vmpl)
+		report, certs, err := provider.GetRawExtendedReportAtVmpl(data, *vmpl)
 		if err != nil {
 			return err
 		}
@@ -101,7 +119,7 @@ func outputExtendedReport(device client.Device, data [abi.ReportDataSize]byte, o
 		out.Write(certs)
 		return nil
 	}
-	attestation, err := client.GetExtendedReportAtVmpl(device, data, *vmpl)
+	attestation, err := client.GetExtendedReportFromProvider(provider, data, *vmpl)
 	if err != nil {
 		return err
 	}
@@ -113,6 +131,66 @@ func outputExtendedReport(device client.Device, data [abi.ReportDataSize]byte, o
 	return nil
 }
 
+func outputSvsmReport(provider *client.ConfigfsReportProvider, data [abi.ReportDataSize]byte, out io.Writer) error {
+	req := &client.SvsmRequest{
+		Nonce:           data,
+		ServiceGUID:     *svsmService,
+		ManifestVersion: uint32(*svsmManifestVersion),
+	}
+	var output, manifest []byte
+	if *outform == "bin" {
+		report, certs, m, err := provider.GetRawSvsmReport(req)
+		if err != nil {
+			return err
+		}
+		output = append(report, certs...)
+		manifest = m
+	} else {
+		attestation, m, err := client.GetSvsmAttestation(provider, req)
+		if err != nil {
+			return err
+		}
+		if output, err = nonBinOut()(attestation); err != nil {
+			return err
+		}
+		manifest = m
+	}
+	if err := os.WriteFile(*svsmManifestOut, manifest, 0644); err != nil {
+		return fmt.Errorf("could not write manifest to %q: %v", *svsmManifestOut, err)
+	}
+	out.Write(output)
+	return nil
+}
+
+func readConfig(path string) (*checkpb.Config, error) {
+	config := &checkpb.Config{}
+	if err := cmdline.ReadProto(path, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func outputBundle(provider client.ReportProvider, data [abi.ReportDataSize]byte, out io.Writer) error {
+	config, err := readConfig(*bundleConfig)
+	if err != nil {
+		return err
+	}
+	attestation, err := client.GetExtendedReportFromProvider(provider, data, *vmpl)
+	if err != nil {
+		return err
+	}
+	b, err := bundle.Collect(attestation, config, nil)
+	if err != nil {
+		return err
+	}
+	bytes, err := nonBinOut()(b)
+	if err != nil {
+		return err
+	}
+	out.Write(bytes)
+	return nil
+}
+
 func outputReport(device client.Device, data [abi.ReportDataSize]byte, out io.Writer) error {
 	if *outform == "bin" {
 		bytes, err := client.GetRawReportAtVmpl(device, data, *vmpl)
@@ -161,6 +239,14 @@ func main() {
 			*outform)
 	}
 
+	if *svsm && *svsmManifestOut == "" {
+		logger.Fatal("-svsm_manifest_out is required with -svsm")
+	}
+
+	if *bundleOut && (*outform == "bin" || *bundleConfig == "") {
+		logger.Fatal("-bundle requires -outform=proto or -outform=textproto, and -bundle_config")
+	}
+
 	if *vmpl < 0 || *vmpl > 3 {
 		logger.Fatalf("-vmpl is %d. Expect 0-3.", *vmpl)
 	}
@@ -175,20 +261,38 @@ func main() {
 		}
 	}()
 
-	device, err := client.OpenDevice()
-	if err != nil {
-		logger.Fatal(err)
-	}
-	defer device.Close()
 	var reportData64 [abi.ReportDataSize]byte
 	copy(reportData64[:], reportData)
-	if *extended {
-		if err := outputExtendedReport(device, reportData64, outwriter); err != nil {
+	if *svsm {
+		// The SVSM attestation protocol is only available through configfs-tsm.
+		provider := &client.ConfigfsReportProvider{}
+		if err := outputSvsmReport(provider, reportData64, outwriter); err != nil {
 			logger.Fatal(err)
 		}
-	} else {
-		if err := outputReport(device, reportData64, outwriter); err != nil {
+		return
+	}
+	if *extended || *bundleOut {
+		// Extended reports are available through configfs-tsm on newer kernels.
+		provider, err := client.OpenReportProvider()
+		if err != nil {
+			logger.Fatal(err)
+		}
+		defer provider.Close()
+		output := outputExtendedReport
+		if *bundleOut {
+			output = outputBundle
+		}
+		if err := output(provider, reportData64, outwriter); err != nil {
 			logger.Fatal(err)
 		}
+		return
+	}
+	device, err := client.OpenDevice()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	defer device.Close()
+	if err := outputReport(device, reportData64, outwriter); err != nil {
+		logger.Fatal(err)
 	}
 }
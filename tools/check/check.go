@@ -27,12 +27,16 @@ import (
 	"time"
 
 	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/bundle"
+	bpb "github.com/google/go-sev-guest/proto/bundle"
 	checkpb "github.com/google/go-sev-guest/proto/check"
 	kpb "github.com/google/go-sev-guest/proto/fakekds"
 	spb "github.com/google/go-sev-guest/proto/sevsnp"
 	"github.com/google/go-sev-guest/testing"
 	"github.com/google/go-sev-guest/tools/lib/cmdline"
 	"github.com/google/go-sev-guest/validate"
+	"github.com/google/go-sev-guest/validate/corim"
+	"github.com/google/go-sev-guest/validate/refvalues"
 	"github.com/google/go-sev-guest/verify"
 	"github.com/google/go-sev-guest/verify/testdata"
 	"github.com/google/go-sev-guest/verify/trust"
@@ -51,7 +55,7 @@ const (
 	defaultMinVersion                = "0.0"
 	defaultMinTcb                    = 0
 	defaultMinLaunchTcb              = 0
-	defaultProduct                   = "Milan"
+	defaultProduct                   = ""
 	defaultCheckCrl                  = false
 	defaultNetwork                   = true
 	defaultRequireAuthorKey          = false
@@ -74,6 +78,11 @@ var (
 	infile = flag.String("in", "-", "Path to the attestation report to check. Stdin is \"-\".")
 	inform = flag.String("inform", "bin", "The input format for the attestation report. One of \"bin\", \"proto\", \"textproto\".")
 
+	bundlePath = flag.String("bundle", "",
+		("A path to a serialized bundle.Bundle to check offline as of its collection time instead of -in. " +
+			"Its policy is the default for -config and the policy flags, but its product roots are only accepted if they are the trusted roots. Default unmarshalled as binary. " +
+			"Paths ending in .textproto will be unmarshalled as prototext."))
+
 	configProto = flag.String("config", "",
 		("A path to a serialized check.Config protobuf. Any individual field flags will" +
 			"overwrite the message's associated field. Default unmarshalled as binary. Paths" +
@@ -123,7 +132,22 @@ var (
 	trustedidkeys       = flag.String("trusted_id_keys", "", "Colon-separated paths to x.509 certificates of trusted author keys")
 	trustedidkeyhashes  = flag.String("trusted_id_key_hashes", "", "Comma-separated hex-encoded SHA-384 hash values of trusted identity keys in AMD public key format")
 
-	product   = flag.String("product", "", "The AMD product name for the chip that generated the attestation report.")
+	referenceValues = flag.String("reference_values", "",
+		"Path to a signed refvalues.SignedReferenceValues manifest of accepted images. If set, the "+
+			"attestation must satisfy the policy as amended by one of its reference values.")
+	referenceValuesKeys = flag.String("reference_values_keys", "",
+		"Colon-separated paths to PEM-encoded public keys or certificates trusted to sign -reference_values")
+	referenceValuesVersion = flag.Uint64("reference_values_min_version", 0,
+		"The minimum acceptable version of the -reference_values manifest")
+
+	corimPath = flag.String("corim", "",
+		"Path to a CBOR-encoded CoRIM of accepted images. If set, the attestation must satisfy the "+
+			"policy as amended by one of its AMD SEV-SNP reference triples.")
+	corimKeys = flag.String("corim_keys", "",
+		"Colon-separated paths to PEM-encoded public keys or certificates trusted to sign -corim")
+	corimUnsigned = flag.Bool("corim_allow_unsigned", false, "If true, -corim need not be signed.")
+
+	product   = flag.String("product", "", "The AMD product name for the chip that generated the attestation report. If unset, detected from the attestation. Required for a version 2 report without certificates.")
 	cabundles = flag.String("product_key_path", "",
 		"Colon-separated paths to CA bundles for the AMD product. Must be in PEM format, ASK, then ARK certificates. If unset, uses embedded root certificates.")
 	verbose     = flag.Bool("v", false, "Enable verbose logging.")
@@ -255,6 +279,50 @@ func getCertBytes(s string) (result [][]byte, err error) {
 	return result, nil
 }
 
+func corimOptions() ([]*validate.Options, error) {
+	keys, err := getCertBytes(*corimKeys)
+	if err != nil {
+		return nil, err
+	}
+	opts := &corim.Options{AllowUnsigned: *corimUnsigned}
+	for i, contents := range keys {
+		key, err := refvalues.ParsePublicKey(contents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -corim_keys[%d]: %v", i, err)
+		}
+		opts.TrustedKeys = append(opts.TrustedKeys, key)
+	}
+	contents, err := os.ReadFile(*corimPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", *corimPath, err)
+	}
+	result, err := corim.ToOptions(contents, config.Policy, opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not read reference values from %q: %v", *corimPath, err)
+	}
+	return result, nil
+}
+
+func referenceValueOptions() ([]*validate.Options, error) {
+	keys, err := getCertBytes(*referenceValuesKeys)
+	if err != nil {
+		return nil, err
+	}
+	ropts := &refvalues.Options{MinimumVersion: *referenceValuesVersion}
+	for i, contents := range keys {
+		key, err := refvalues.ParsePublicKey(contents)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -reference_values_keys[%d]: %v", i, err)
+		}
+		ropts.TrustedKeys = append(ropts.TrustedKeys, key)
+	}
+	values, err := refvalues.Load(*referenceValues, ropts)
+	if err != nil {
+		return nil, err
+	}
+	return refvalues.ToOptions(values, config.Policy)
+}
+
 func parseUint(p string, bits int) (uint64, error) {
 	base := 10
 	prepped := p
@@ -290,23 +358,8 @@ func parseConfig(path string) error {
 	if path == "" {
 		return nil
 	}
-	f, err := os.Open(path)
-	if err != nil {
-		return fmt.Errorf("could not open %q: %v", path, err)
-	}
-	defer f.Close()
-
-	contents, err := io.ReadAll(f)
-	if err != nil {
-		return fmt.Errorf("could not read %q: %v", path, err)
-	}
-	if strings.HasSuffix(path, ".textproto") {
-		err = prototext.Unmarshal(contents, config)
-	} else {
-		err = proto.Unmarshal(contents, config)
-	}
-	if err != nil {
-		return fmt.Errorf("could not deserialize %q: %v", path, err)
+	if err := cmdline.ReadProto(path, config); err != nil {
+		return err
 	}
 	// Populate fields that should not be nil
 	if config.RootOfTrust == nil {
@@ -319,7 +372,43 @@ func parseConfig(path string) error {
 }
 
 func override() bool {
-	return *configProto != ""
+	return *configProto != "" || *bundlePath != ""
+}
+
+func readBundle(path string) (*bpb.Bundle, error) {
+	result := &bpb.Bundle{}
+	if err := cmdline.ReadProto(path, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkBundle checks the bundle offline against the config and exits like the online check.
+func checkBundle(b *bpb.Bundle) {
+	if *referenceValues != "" {
+		die(errors.New("cannot specify both -bundle and -reference_values"))
+	}
+	if *corimPath != "" {
+		die(errors.New("cannot specify both -bundle and -corim"))
+	}
+	result, err := bundle.Check(b, config)
+	if errors.Is(err, bundle.ErrUntrustedRoots) {
+		dieWith(fmt.Errorf("could not verify attestation signature: %v", err), exitVerify)
+	}
+	if err != nil {
+		die(err)
+	}
+	if !bundle.Reproduces(b, result) && !*quiet {
+		fmt.Fprintln(os.Stderr, "The verdict differs from the verdict recorded in the bundle")
+	}
+	for _, name := range []string{verify.CertificateChainCheck, verify.CRLCheck, verify.SignatureCheck} {
+		if c := result.Get(name); c != nil && c.Err != nil {
+			dieWith(fmt.Errorf("could not verify attestation signature: %v", c.Err), exitVerify)
+		}
+	}
+	if err := result.Err(); err != nil {
+		dieWith(fmt.Errorf("error validating attestation: %v", err), exitPolicy)
+	}
 }
 
 func setBool(value *bool, name, flag string, defaultValue bool) error {
@@ -502,18 +591,53 @@ func main() {
 	flag.Parse()
 	cmdline.Parse("auto")
 
+	var b *bpb.Bundle
+	if *bundlePath != "" {
+		var err error
+		if b, err = readBundle(*bundlePath); err != nil {
+			die(err)
+		}
+		// Only the bundle's policy is a default. Its product roots are never trusted.
+		if policy := b.GetConfig().GetPolicy(); policy != nil {
+			config.Policy = proto.Clone(policy).(*checkpb.Policy)
+		}
+	}
 	if err := parseConfig(*configProto); err != nil {
 		die(err)
 	}
+	if config.RootOfTrust == nil {
+		config.RootOfTrust = &checkpb.RootOfTrust{}
+	}
+	if config.Policy == nil {
+		config.Policy = &checkpb.Policy{}
+	}
 
 	if err := multierr.Combine(populateRootOfTrust(),
 		populateConfig()); err != nil {
 		die(err)
 	}
+	if b != nil {
+		checkBundle(b)
+		return
+	}
 
 	if config.RootOfTrust.CheckCrl && config.RootOfTrust.DisallowNetwork {
 		die(errors.New("cannot specify both -check_crl=true and -network=false"))
 	}
+	if *referenceValues != "" && *corimPath != "" {
+		die(errors.New("cannot specify both -reference_values and -corim"))
+	}
+	// Each reference value or CoRIM reference triple is an alternative policy.
+	var alternatives []*validate.Options
+	var err error
+	if *referenceValues != "" {
+		alternatives, err = referenceValueOptions()
+	} else if *corimPath != "" {
+		alternatives, err = corimOptions()
+	}
+	if err != nil {
+		die(err)
+	}
 
 	attestation, err := getAttestation()
 	if err != nil {
@@ -568,6 +692,12 @@ func main() {
 		dieWith(fmt.Errorf("could not verify attestation signature: %v", err), exitCode)
 	}
 
+	if alternatives != nil {
+		if err := refvalues.SnpAttestation(attestation, alternatives); err != nil {
+			dieWith(fmt.Errorf("error validating attestation: %v", err), exitPolicy)
+		}
+		return
+	}
 	opts, err := validate.PolicyToOptions(config.Policy)
 	if err != nil {
 		die(err)
@@ -1,10 +1,12 @@
 package main
 
 import (
-	"bytes"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/hex"
-	"encoding/pem"
+	"errors"
 	"fmt"
+	"math/big"
 	"os"
 	"os/exec"
 	"strconv"
@@ -12,12 +14,13 @@ import (
 	"time"
 
 	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/bundle"
+	"github.com/google/go-sev-guest/kds"
 	checkpb "github.com/google/go-sev-guest/proto/check"
 	kpb "github.com/google/go-sev-guest/proto/fakekds"
 	fakesev "github.com/google/go-sev-guest/testing"
 	"github.com/google/go-sev-guest/verify/testdata"
 	"github.com/google/logger"
-	"go.uber.org/multierr"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -111,6 +114,8 @@ func withBaseArgs(config string, args ...string) []string {
 	base := []string{
 		"-in", "../../verify/testdata/attestation.bin",
 		"-kdsdatabase", kdsdatabase,
+		// A version 2 report does not identify its product.
+		"-product=Milan",
 	}
 	if config != "" {
 		base = append(base, fmt.Sprintf("-config=%s", config))
@@ -463,13 +468,6 @@ func TestCaBundles(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	fakebundle := &bytes.Buffer{}
-	if err := multierr.Combine(
-		pem.Encode(fakebundle, &pem.Block{Type: "CERTIFICATE", Bytes: signer.Ask.Raw}),
-		pem.Encode(fakebundle, &pem.Block{Type: "CERTIFICATE", Bytes: signer.Ark.Raw}),
-	); err != nil {
-		t.Fatal(err)
-	}
 	// -product only has meaning when provided with a custom product_key_path, so test together.
 	goodbad := func(n int, name string) string {
 		if n == 0 {
@@ -478,7 +476,7 @@ func TestCaBundles(t *testing.T) {
 		return fmt.Sprintf("bad%s[%d]", name, n+1)
 	}
 
-	withTempFile(fakebundle.Bytes(), t, func(fakePath string) {
+	withTempFile(fakesev.ProductRootsPEM(signer), t, func(fakePath string) {
 		products := []string{"Milan", "None"}
 		cabundles := []string{"../../verify/testdata/milan.testcer", fakePath, "doesNotExist"}
 		for i, product := range products {
@@ -498,3 +496,72 @@ func TestCaBundles(t *testing.T) {
 		}
 	})
 }
+
+// testBundle returns a bundle of a fake attestation collected a year ago, whose CRL has expired,
+// and the product roots of its fake AMD signer.
+func testBundle(t *testing.T) ([]byte, []byte) {
+	t.Helper()
+	collected := time.Now().AddDate(-1, 0, 0)
+	signer, err := fakesev.DefaultCertChain("Milan", collected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attestation := fakesev.SignedAttestation(t, signer, nil)
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: collected,
+		NextUpdate: collected.AddDate(0, 1, 0),
+	}, signer.Ark, signer.Keys.Ark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := fakesev.ProductRootsPEM(signer)
+	b, err := bundle.Collect(attestation, &checkpb.Config{
+		RootOfTrust: &checkpb.RootOfTrust{Cabundles: []string{string(roots)}},
+		Policy:      &checkpb.Policy{Policy: goodPolicy, MinimumVersion: "0.0"},
+	}, &bundle.Options{
+		Getter: &fakesev.Getter{Responses: map[string][]byte{kds.CrlURL("Milan", abi.VcekReportSigner): crl}},
+		Now:    collected,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := proto.Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out, roots
+}
+
+func TestBundle(t *testing.T) {
+	b, roots := testBundle(t)
+	withTempFile(roots, t, func(rootsPath string) {
+		withTempFile(b, t, func(path string) {
+			trusted := "-product_key_path=" + rootsPath
+			tcs := []struct {
+				name     string
+				args     []string
+				wantExit int
+			}{
+				{name: "recorded verdict", args: []string{trusted}},
+				{name: "stricter flag", args: []string{trusted, "-measurement=" + hex.EncodeToString(make([]byte, abi.MeasurementSize-1)) + "01"}, wantExit: exitPolicy},
+				{name: "embedded roots", wantExit: exitVerify},
+				{name: "other roots", args: []string{"-product_key_path=../../verify/testdata/milan.testcer"}, wantExit: exitVerify},
+			}
+			for _, tc := range tcs {
+				t.Run(tc.name, func(t *testing.T) {
+					cmd := exec.Command(check, append([]string{"-bundle", path}, tc.args...)...)
+					output, err := cmd.CombinedOutput()
+					exitCode := 0
+					var exitErr *exec.ExitError
+					if errors.As(err, &exitErr) {
+						exitCode = exitErr.ExitCode()
+					}
+					if exitCode != tc.wantExit {
+						t.Errorf("%s exited with %d, want %d: %v, %s", cmd, exitCode, tc.wantExit, err, output)
+					}
+				})
+			}
+		})
+	})
+}
@@ -0,0 +1,358 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main implements a CLI tool for explaining SEV-SNP attestation reports and the
+// differences between a report and another report or a validation policy.
+package main
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
+	"github.com/google/go-sev-guest/kds"
+	checkpb "github.com/google/go-sev-guest/proto/check"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/tools/lib/cmdline"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/google/go-sev-guest/verify"
+	"github.com/google/logger"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	// Exit code 1 - tool usage error.
+	exitTool = 1
+	// Exit code 2 - the reports differ, or the report does not satisfy the policy.
+	exitDiffer = 2
+)
+
+var (
+	infile = flag.String("in", "-", "Path to the attestation report to explain. Stdin is \"-\".")
+	inform = flag.String("inform", "bin", "The input format for the attestation reports. One of \"bin\", \"proto\", \"textproto\".")
+	diff   = flag.String("diff", "",
+		"Path to a second attestation report in the -inform format. If set, prints only the fields that differ.")
+	configProto = flag.String("config", "",
+		"Path to a check.Config whose policy the report is compared against. If set, prints only the "+
+			"checks that the report fails and why. Default unmarshalled as binary. Paths ending in "+
+			".textproto will be unmarshalled as prototext.")
+	network = flag.Bool("network", true,
+		"If true, then permitted to download the endorsement key certificate that -config needs if the "+
+			"attestation lacks it.")
+	verbose = flag.Bool("v", false, "Enable verbose logging.")
+)
+
+// field is a named, human-readable value of an attestation report.
+type field struct {
+	name  string
+	value string
+}
+
+func enabled(b bool) string {
+	if b {
+		return "enabled"
+	}
+	return "disabled"
+}
+
+func allowed(b bool) string {
+	if b {
+		return "allowed"
+	}
+	return "not allowed"
+}
+
+func explainPolicy(policy uint64) string {
+	p, err := abi.ParseSnpPolicy(policy)
+	if err != nil {
+		return fmt.Sprintf("0x%x (invalid: %v)", policy, err)
+	}
+	singleSocket := "not required"
+	if p.SingleSocket {
+		singleSocket = "required"
+	}
+	return fmt.Sprintf("0x%x (minimum ABI %d.%d, SMT %s, migration agent %s, debug %s, single socket %s)",
+		policy, p.ABIMajor, p.ABIMinor, allowed(p.SMT), allowed(p.MigrateMA), allowed(p.Debug), singleSocket)
+}
+
+func explainPlatformInfo(info uint64) string {
+	p, err := abi.ParseSnpPlatformInfo(info)
+	if err != nil {
+		return fmt.Sprintf("0x%x (invalid: %v)", info, err)
+	}
+	return fmt.Sprintf("0x%x (SMT %s, TSME %s)", info, enabled(p.SMTEnabled), enabled(p.TSMEEnabled))
+}
+
+func explainSignerInfo(signerInfo uint32) string {
+	info, err := abi.ParseSignerInfo(signerInfo)
+	if err != nil {
+		return fmt.Sprintf("0x%x (invalid: %v)", signerInfo, err)
+	}
+	masked := "not masked"
+	if info.MaskChipKey {
+		masked = "masked"
+	}
+	return fmt.Sprintf("0x%x (signed by %v, chip ID %s, author key %s)",
+		signerInfo, info.SigningKey, masked, enabled(info.AuthorKeyEn))
+}
+
+func explainTcb(tcb uint64) string {
+	p := kds.DecomposeTCBVersion(kds.TCBVersion(tcb))
+	result := fmt.Sprintf("0x%016x (bootloader %d, TEE %d, SNP %d, microcode %d", tcb, p.BlSpl, p.TeeSpl, p.SnpSpl, p.UcodeSpl)
+	if p.Spl4 != 0 || p.Spl5 != 0 || p.Spl6 != 0 || p.Spl7 != 0 {
+		result += fmt.Sprintf(", reserved SPLs %d %d %d %d", p.Spl4, p.Spl5, p.Spl6, p.Spl7)
+	}
+	return result + ")"
+}
+
+func explainSignatureAlgo(algo uint32) string {
+	if algo == abi.SignEcdsaP384Sha384 {
+		return fmt.Sprintf("%d (ECDSA P-384 with SHA-384)", algo)
+	}
+	return fmt.Sprintf("%d (unknown)", algo)
+}
+
+func explainCPUID(report *spb.Report) string {
+	if report.GetVersion() < abi.CPUIDReportVersion {
+		return fmt.Sprintf("not in version %d reports", report.GetVersion())
+	}
+	result := fmt.Sprintf("family 0x%x model 0x%x stepping 0x%x",
+		report.GetCpuidFamId(), report.GetCpuidModId(), report.GetCpuidStep())
+	if product, err := kds.ProductFromCPUID(uint8(report.GetCpuidFamId()), uint8(report.GetCpuidModId())); err == nil {
+		result += fmt.Sprintf(" (%s)", product)
+	}
+	return result
+}
+
+func explainMitVector(report *spb.Report, vector uint64) string {
+	if report.GetVersion() < abi.MitigationReportVersion {
+		return fmt.Sprintf("not in version %d reports", report.GetVersion())
+	}
+	return fmt.Sprintf("0x%x", vector)
+}
+
+// explain returns the fields of the report in ABI order with their values decoded. Fields that a
+// validate check covers share the check's name.
+func explain(report *spb.Report) []field {
+	return []field{
+		{"version", fmt.Sprintf("%d", report.GetVersion())},
+		{validate.GuestSvnCheck, fmt.Sprintf("%d", report.GetGuestSvn())},
+		{validate.PolicyCheck, explainPolicy(report.GetPolicy())},
+		{validate.FamilyIDCheck, hex.EncodeToString(report.GetFamilyId())},
+		{validate.ImageIDCheck, hex.EncodeToString(report.GetImageId())},
+		{validate.VmplCheck, fmt.Sprintf("%d", report.GetVmpl())},
+		{"signature_algo", explainSignatureAlgo(report.GetSignatureAlgo())},
+		{validate.CurrentTcbCheck, explainTcb(report.GetCurrentTcb())},
+		{validate.PlatformInfoCheck, explainPlatformInfo(report.GetPlatformInfo())},
+		{validate.SignerInfoCheck, explainSignerInfo(report.GetSignerInfo())},
+		{validate.ReportDataCheck, hex.EncodeToString(report.GetReportData())},
+		{validate.MeasurementCheck, hex.EncodeToString(report.GetMeasurement())},
+		{validate.HostDataCheck, hex.EncodeToString(report.GetHostData())},
+		{"id_key_digest", hex.EncodeToString(report.GetIdKeyDigest())},
+		{"author_key_digest", hex.EncodeToString(report.GetAuthorKeyDigest())},
+		{validate.ReportIDCheck, hex.EncodeToString(report.GetReportId())},
+		{validate.ReportIDMACheck, hex.EncodeToString(report.GetReportIdMa())},
+		{validate.ReportedTcbCheck, explainTcb(report.GetReportedTcb())},
+		{validate.CPUIDCheck, explainCPUID(report)},
+		{validate.ChipIDCheck, hex.EncodeToString(report.GetChipId())},
+		{validate.CommittedTcbCheck, explainTcb(report.GetCommittedTcb())},
+		{validate.FirmwareVersionCheck, fmt.Sprintf("%d.%d build %d",
+			report.GetCurrentMajor(), report.GetCurrentMinor(), report.GetCurrentBuild())},
+		{"committed_firmware_version", fmt.Sprintf("%d.%d build %d",
+			report.GetCommittedMajor(), report.GetCommittedMinor(), report.GetCommittedBuild())},
+		{validate.LaunchTcbCheck, explainTcb(report.GetLaunchTcb())},
+		{"launch_mit_vector", explainMitVector(report, report.GetLaunchMitVector())},
+		{"current_mit_vector", explainMitVector(report, report.GetCurrentMitVector())},
+	}
+}
+
+// fieldDiff is a field whose value differs between two reports.
+type fieldDiff struct {
+	name string
+	a, b string
+}
+
+// diffReports returns the fields that differ between the two reports in ABI order. The signature
+// is not compared, since it differs between any two reports.
+func diffReports(a, b *spb.Report) []fieldDiff {
+	var result []fieldDiff
+	bFields := explain(b)
+	for i, f := range explain(a) {
+		if f.value != bFields[i].value {
+			result = append(result, fieldDiff{name: f.name, a: f.value, b: bFields[i].value})
+		}
+	}
+	return result
+}
+
+// diffPolicy returns the failed checks of validating the attestation against the policy.
+func diffPolicy(attestation *spb.Attestation, policy *checkpb.Policy) ([]*checks.Check, error) {
+	opts, err := validate.PolicyToOptions(policy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy: %v", err)
+	}
+	return validate.SnpAttestationResult(attestation, opts).Failures(), nil
+}
+
+func writeExplanation(w io.Writer, report *spb.Report) {
+	for _, f := range explain(report) {
+		fmt.Fprintf(w, "%s: %s\n", f.name, f.value)
+	}
+}
+
+func writeReportDiffs(w io.Writer, diffs []fieldDiff) {
+	for _, d := range diffs {
+		fmt.Fprintf(w, "%s:\n  - %s\n  + %s\n", d.name, d.a, d.b)
+	}
+}
+
+func writePolicyFailures(w io.Writer, report *spb.Report, failures []*checks.Check) {
+	decoded := map[string]string{}
+	for _, f := range explain(report) {
+		decoded[f.name] = f.value
+	}
+	for _, c := range failures {
+		fmt.Fprintf(w, "%s:\n", c.Name)
+		if c.Expected != "" {
+			fmt.Fprintf(w, "  expected: %s\n", c.Expected)
+		}
+		if c.Actual != "" {
+			fmt.Fprintf(w, "  actual:   %s\n", c.Actual)
+		}
+		if value, ok := decoded[c.Name]; ok && value != c.Actual {
+			fmt.Fprintf(w, "  decoded:  %s\n", value)
+		}
+		fmt.Fprintf(w, "  reason:   %v\n", c.Err)
+	}
+}
+
+func parseAttestation(b []byte, path string) (*spb.Attestation, error) {
+	switch *inform {
+	case "bin":
+		// The attestation report in AMD's specified ABI format, optionally followed by the
+		// certificate table bytes.
+		if len(b) < abi.ReportSize {
+			return nil, fmt.Errorf("%q is too small (0x%x bytes). Want at least 0x%x bytes", path, len(b), abi.ReportSize)
+		}
+		report, err := abi.ReportToProto(b[:abi.ReportSize])
+		if err != nil {
+			return nil, fmt.Errorf("could not parse attestation report %q: %v", path, err)
+		}
+		certs := new(abi.CertTable)
+		if err := certs.Unmarshal(b[abi.ReportSize:]); err != nil {
+			return nil, fmt.Errorf("could not parse certificate table of %q: %v", path, err)
+		}
+		return &spb.Attestation{Report: report, CertificateChain: certs.Proto()}, nil
+	case "proto", "textproto":
+		unmarshal := proto.Unmarshal
+		if *inform == "textproto" {
+			unmarshal = prototext.Unmarshal
+		}
+		// Accept both an sevsnp.Attestation and a bare sevsnp.Report.
+		attestation := &spb.Attestation{}
+		if err := unmarshal(b, attestation); err == nil && attestation.GetReport() != nil {
+			return attestation, nil
+		}
+		report := &spb.Report{}
+		if err := unmarshal(b, report); err != nil {
+			return nil, fmt.Errorf("could not parse %q as an Attestation or Report %s: %v", path, *inform, err)
+		}
+		return &spb.Attestation{Report: report}, nil
+	}
+	return nil, fmt.Errorf("unknown value -inform=%s", *inform)
+}
+
+func readAttestation(path string) (*spb.Attestation, error) {
+	var contents []byte
+	var err error
+	if path == "-" {
+		contents, err = io.ReadAll(os.Stdin)
+	} else {
+		contents, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", path, err)
+	}
+	return parseAttestation(contents, path)
+}
+
+func readConfig(path string) (*checkpb.Config, error) {
+	config := &checkpb.Config{}
+	if err := cmdline.ReadProto(path, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// endorsedAttestation returns the attestation with its endorsement key certificate, which
+// validation needs, downloaded from the AMD KDS if missing and permitted.
+func endorsedAttestation(attestation *spb.Attestation) (*spb.Attestation, error) {
+	chain := attestation.GetCertificateChain()
+	if len(chain.GetVcekCert()) != 0 || len(chain.GetVlekCert()) != 0 || !*network {
+		return attestation, nil
+	}
+	result, err := verify.GetAttestationFromReport(attestation.GetReport(), verify.DefaultOptions())
+	if err != nil {
+		return nil, fmt.Errorf("could not download the endorsement key certificate: %v", err)
+	}
+	return result, nil
+}
+
+func main() {
+	logger.Init("", *verbose, false, os.Stderr)
+	flag.Parse()
+
+	if *diff != "" && *configProto != "" {
+		logger.Fatal("cannot specify both -diff and -config")
+	}
+	attestation, err := readAttestation(*infile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	switch {
+	case *diff != "":
+		other, err := readAttestation(*diff)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		diffs := diffReports(attestation.GetReport(), other.GetReport())
+		writeReportDiffs(os.Stdout, diffs)
+		if len(diffs) != 0 {
+			os.Exit(exitDiffer)
+		}
+	case *configProto != "":
+		config, err := readConfig(*configProto)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		if attestation, err = endorsedAttestation(attestation); err != nil {
+			logger.Fatal(err)
+		}
+		failures, err := diffPolicy(attestation, config.GetPolicy())
+		if err != nil {
+			logger.Fatal(err)
+		}
+		writePolicyFailures(os.Stdout, attestation.GetReport(), failures)
+		if len(failures) != 0 {
+			os.Exit(exitDiffer)
+		}
+	default:
+		writeExplanation(os.Stdout, attestation.GetReport())
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	checkpb "github.com/google/go-sev-guest/proto/check"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	test "github.com/google/go-sev-guest/testing"
+	"github.com/google/go-sev-guest/validate"
+	"google.golang.org/protobuf/proto"
+)
+
+const debugPolicy = 0xa0000
+
+func signedAttestation(t *testing.T) *spb.Attestation {
+	t.Helper()
+	signer, err := test.DefaultCertChain("Milan", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return test.SignedAttestation(t, signer, &test.AttestationOptions{ReportData: [64]byte{1, 2, 3}})
+}
+
+func TestExplainDecodes(t *testing.T) {
+	tcs := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{
+			name: "policy",
+			got:  explainPolicy(0x1b0102),
+			want: "0x1b0102 (minimum ABI 1.2, SMT allowed, migration agent not allowed, debug allowed, single socket required)",
+		},
+		{
+			name: "reserved policy bit",
+			got:  explainPolicy(0),
+			want: "0x0 (invalid:",
+		},
+		{
+			name: "tcb",
+			got:  explainTcb(0x4405000000000002),
+			want: "0x4405000000000002 (bootloader 2, TEE 0, SNP 5, microcode 68)",
+		},
+		{
+			name: "tcb reserved",
+			got:  explainTcb(0x0000000000010000),
+			want: "reserved SPLs 1 0 0 0)",
+		},
+		{
+			name: "platform info",
+			got:  explainPlatformInfo(3),
+			want: "0x3 (SMT enabled, TSME enabled)",
+		},
+		{
+			name: "signer info",
+			got:  explainSignerInfo(0x3),
+			want: "0x3 (signed by VCEK, chip ID masked, author key enabled)",
+		},
+		{
+			name: "signature algorithm",
+			got:  explainSignatureAlgo(2),
+			want: "2 (unknown)",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if !strings.Contains(tc.got, tc.want) {
+				t.Errorf("got %q, want %q", tc.got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDiffReports(t *testing.T) {
+	a := signedAttestation(t).GetReport()
+	if diffs := diffReports(a, a); len(diffs) != 0 {
+		t.Errorf("diffReports(a, a) = %v, want no differences", diffs)
+	}
+	b := proto.Clone(a).(*spb.Report)
+	b.Measurement = bytes.Repeat([]byte{1}, abi.MeasurementSize)
+	b.Policy = debugPolicy | 1<<20
+	b.Signature = make([]byte, len(a.GetSignature()))
+	diffs := diffReports(a, b)
+	var names []string
+	for _, d := range diffs {
+		names = append(names, d.name)
+	}
+	want := []string{validate.PolicyCheck, validate.MeasurementCheck}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Fatalf("diffReports() differs in %v, want %v", names, want)
+	}
+	if !strings.Contains(diffs[0].b, "single socket required") {
+		t.Errorf("diffReports() policy is %q, want the decoded single socket bit", diffs[0].b)
+	}
+}
+
+func TestDiffPolicy(t *testing.T) {
+	attestation := signedAttestation(t)
+	tcs := []struct {
+		name   string
+		policy *checkpb.Policy
+		want   []string
+	}{
+		{
+			name:   "passes",
+			policy: &checkpb.Policy{Policy: debugPolicy, MinimumVersion: "0.0"},
+		},
+		{
+			name: "measurement and debug",
+			policy: &checkpb.Policy{
+				Policy:         debugPolicy &^ (1 << 19),
+				MinimumVersion: "0.0",
+				Measurement:    bytes.Repeat([]byte{1}, abi.MeasurementSize),
+			},
+			want: []string{validate.PolicyCheck, validate.MeasurementCheck},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			failures, err := diffPolicy(attestation, tc.policy)
+			if err != nil {
+				t.Fatalf("diffPolicy() = _, %v. Want nil", err)
+			}
+			var names []string
+			for _, c := range failures {
+				names = append(names, c.Name)
+			}
+			if strings.Join(names, ",") != strings.Join(tc.want, ",") {
+				t.Fatalf("diffPolicy() failed %v, want %v", names, tc.want)
+			}
+			var out bytes.Buffer
+			writePolicyFailures(&out, attestation.GetReport(), failures)
+			for _, c := range failures {
+				if !strings.Contains(out.String(), c.Err.Error()) {
+					t.Errorf("writePolicyFailures() = %q, want reason %q", out.String(), c.Err)
+				}
+			}
+		})
+	}
+	if _, err := diffPolicy(attestation, &checkpb.Policy{MinimumVersion: "x"}); err == nil {
+		t.Error("diffPolicy(invalid) = _, nil. Want error")
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main implements a CLI tool for generating signed SEV-SNP ID blocks for VM launch.
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/tools/lib/cmdline"
+	"github.com/google/logger"
+)
+
+var (
+	inform  = flag.String("inform", "auto", "The format of the byte string flags. One of hex, base64, or auto.")
+	outform = flag.String("outform", "base64",
+		"The format of the output files. One of \"base64\" or \"bin\". "+
+			"The base64 form is what QEMU's sev-snp-guest id-block and id-auth properties expect.")
+	measurementS = flag.String("measurement", "", "The expected launch digest. Must encode 48 bytes. Required.")
+	measurement  = cmdline.Bytes("-measurement", abi.MeasurementSize, measurementS)
+	familyidS    = flag.String("family_id", "", "The FAMILY_ID to launch with. Must encode 16 bytes. Default all zeros.")
+	familyid     = cmdline.Bytes("-family_id", abi.FamilyIDSize, familyidS)
+	imageidS     = flag.String("image_id", "", "The IMAGE_ID to launch with. Must encode 16 bytes. Default all zeros.")
+	imageid      = cmdline.Bytes("-image_id", abi.ImageIDSize, imageidS)
+	guestSvn     = flag.Uint("guest_svn", 0, "The GUEST_SVN to launch with.")
+	guestPolicy  = flag.String("guest_policy", "0x30000",
+		"The guest policy to launch with in its 64-bit format. Must equal the policy QEMU is given.")
+	idKeyPath     = flag.String("id_key", "", "Path to a PEM-encoded ECDSA P-384 private key that signs the ID block. Required.")
+	authorKeyPath = flag.String("author_key", "",
+		"Path to a PEM-encoded ECDSA P-384 private key that signs the ID key. No author key if unset.")
+	idBlockOut = flag.String("id_block_out", "id_block", "Path to write the ID block to.")
+	idAuthOut  = flag.String("id_auth_out", "id_auth", "Path to write the ID authentication information to.")
+	verbose    = flag.Bool("v", false, "Enable verbose logging.")
+)
+
+func readKey(path string) (*ecdsa.PrivateKey, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", path, err)
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("%q does not contain a PEM block", path)
+	}
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		return x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse %q: %v", path, err)
+		}
+		ecKey, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%q is a %T, not an ECDSA private key", path, key)
+		}
+		return ecKey, nil
+	}
+	return nil, fmt.Errorf("%q has unexpected PEM block type %q", path, block.Type)
+}
+
+func writeOut(path string, data []byte) error {
+	if *outform == "base64" {
+		data = []byte(base64.StdEncoding.EncodeToString(data))
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func idBlock() (*abi.IDBlock, error) {
+	if len(*measurement) == 0 {
+		return nil, errors.New("-measurement is required")
+	}
+	policy, err := strconv.ParseUint(*guestPolicy, 0, 64)
+	if err != nil {
+		return nil, fmt.Errorf("-guest_policy=%q is not a 64-bit unsigned integer: %v", *guestPolicy, err)
+	}
+	if _, err := abi.ParseSnpPolicy(policy); err != nil {
+		return nil, fmt.Errorf("-guest_policy=%q: %v", *guestPolicy, err)
+	}
+	block := &abi.IDBlock{Version: abi.IDBlockVersion, GuestSvn: uint32(*guestSvn), Policy: policy}
+	copy(block.LD[:], *measurement)
+	copy(block.FamilyID[:], *familyid)
+	copy(block.ImageID[:], *imageid)
+	return block, nil
+}
+
+func main() {
+	logger.Init("", *verbose, false, os.Stderr)
+	flag.Parse()
+	// Second phase of parsing.
+	cmdline.Parse(*inform)
+
+	if *outform != "base64" && *outform != "bin" {
+		logger.Fatalf("-outform is %s. Expect \"base64\" or \"bin\"", *outform)
+	}
+	block, err := idBlock()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if *idKeyPath == "" {
+		logger.Fatal("-id_key is required")
+	}
+	idKey, err := readKey(*idKeyPath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	var authorKey *ecdsa.PrivateKey
+	if *authorKeyPath != "" {
+		if authorKey, err = readKey(*authorKeyPath); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	auth, err := abi.SignIDBlock(block, idKey, authorKey)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if err := writeOut(*idBlockOut, block.Marshal()); err != nil {
+		logger.Fatalf("could not write ID block: %v", err)
+	}
+	if err := writeOut(*idAuthOut, auth.Marshal()); err != nil {
+		logger.Fatalf("could not write ID authentication information: %v", err)
+	}
+	// The digests are what a verifier trusts through the trusted_id_key_hashes and
+	// trusted_author_key_hashes policy fields.
+	fmt.Printf("id_key_digest: %s\n", hex.EncodeToString(auth.IDKeyDigest()))
+	if authorKey != nil {
+		fmt.Printf("author_key_digest: %s\n", hex.EncodeToString(auth.AuthorKeyDigest()))
+	}
+}
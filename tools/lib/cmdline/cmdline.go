@@ -24,6 +24,9 @@ import (
 	"os"
 	"strings"
 	"unicode/utf8"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 )
 
 // InputType represents how data is coming in, either via file or string.
@@ -140,3 +143,21 @@ func Parse(inform string) {
 		}
 	}
 }
+
+// ReadProto reads the file at path into message. The file is in the textproto format if path ends
+// in ".textproto", and in the binary protobuf format otherwise.
+func ReadProto(path string, message proto.Message) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("could not read %q: %v", path, err)
+	}
+	if strings.HasSuffix(path, ".textproto") {
+		err = prototext.Unmarshal(contents, message)
+	} else {
+		err = proto.Unmarshal(contents, message)
+	}
+	if err != nil {
+		return fmt.Errorf("could not deserialize %q: %v", path, err)
+	}
+	return nil
+}
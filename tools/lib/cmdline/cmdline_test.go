@@ -16,8 +16,14 @@ package cmdline
 
 import (
 	"bytes"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	checkpb "github.com/google/go-sev-guest/proto/check"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
 )
 
 func expect(err error, wantErr string) bool {
@@ -216,3 +222,44 @@ func TestBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestReadProto(t *testing.T) {
+	want := &checkpb.Config{Policy: &checkpb.Policy{MinimumGuestSvn: 3}}
+	bin, err := proto.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text, err := prototext.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	tcs := []struct {
+		name     string
+		contents []byte
+		wantErr  string
+	}{
+		{name: "config.binarypb", contents: bin},
+		{name: "config.textproto", contents: text},
+		{name: "bad.textproto", contents: bin, wantErr: "could not deserialize"},
+		{name: "missing.binarypb", wantErr: "could not read"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, tc.name)
+			if tc.contents != nil {
+				if err := os.WriteFile(path, tc.contents, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got := &checkpb.Config{}
+			err := ReadProto(path, got)
+			if !expect(err, tc.wantErr) {
+				t.Fatalf("ReadProto(%q) = %v. Want error %q", tc.name, err, tc.wantErr)
+			}
+			if err == nil && !proto.Equal(got, want) {
+				t.Errorf("ReadProto(%q) read %v, want %v", tc.name, got, want)
+			}
+		})
+	}
+}
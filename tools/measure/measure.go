@@ -0,0 +1,139 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main implements a CLI tool for computing the expected SEV-SNP MEASUREMENT of a guest.
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/measure"
+	checkpb "github.com/google/go-sev-guest/proto/check"
+	"github.com/google/go-sev-guest/vmsa"
+	"github.com/google/logger"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+var (
+	ovmfPath = flag.String("ovmf", "", "Path to the OVMF firmware image the guest boots. Required.")
+	vcpus    = flag.Int("vcpus", 1, "The number of vCPUs the guest launches with.")
+	vcpuType = flag.String("vcpu_type", "EPYC-Milan", "The QEMU -cpu model of the guest's vCPUs, e.g., EPYC-Genoa.")
+	vcpuSig  = flag.String("vcpu_sig", "",
+		"The processor signature of the guest's vCPUs as CPUID function 1 reports it. Overrides -vcpu_type.")
+	guestFeatures = flag.String("guest_features", "0x1", "The SEV_FEATURES value of the guest's VMSAs.")
+	kernel        = flag.String("kernel", "", "Path to the kernel for measured direct boot. Unused if unset.")
+	initrd        = flag.String("initrd", "", "Path to the initrd for measured direct boot.")
+	cmdline       = flag.String("append", "", "The kernel command line for measured direct boot.")
+	guestPolicy   = flag.String("guest_policy", "0x30000",
+		"The guest policy to include in -outform=textproto output. Does not affect the MEASUREMENT.")
+	outform = flag.String("outform", "hex",
+		"The output format. One of \"hex\" for the MEASUREMENT in hex, or \"textproto\" for a check.Policy "+
+			"with the measurement and policy fields set.")
+	verbose = flag.Bool("v", false, "Enable verbose logging.")
+)
+
+func parseUint(name, value string, bits int) (uint64, error) {
+	v, err := strconv.ParseUint(value, 0, bits)
+	if err != nil {
+		return 0, fmt.Errorf("-%s=%q is not a %d-bit unsigned integer: %v", name, value, bits, err)
+	}
+	return v, nil
+}
+
+func options() (*measure.Options, error) {
+	if *ovmfPath == "" {
+		return nil, errors.New("-ovmf is required")
+	}
+	contents, err := os.ReadFile(*ovmfPath)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", *ovmfPath, err)
+	}
+	ovmf, err := measure.ParseOVMF(contents)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %q: %v", *ovmfPath, err)
+	}
+	opts := &measure.Options{OVMF: ovmf, VCPUs: *vcpus}
+	if *vcpuSig != "" {
+		sig, err := parseUint("vcpu_sig", *vcpuSig, 32)
+		if err != nil {
+			return nil, err
+		}
+		opts.VCPUSig = uint32(sig)
+	} else if opts.VCPUSig, err = vmsa.CPUSigFromName(*vcpuType); err != nil {
+		return nil, err
+	}
+	if opts.SevFeatures, err = parseUint("guest_features", *guestFeatures, 64); err != nil {
+		return nil, err
+	}
+	if *kernel == "" {
+		if *initrd != "" || *cmdline != "" {
+			return nil, errors.New("-initrd and -append require -kernel")
+		}
+		return opts, nil
+	}
+	kernelContents, err := os.ReadFile(*kernel)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", *kernel, err)
+	}
+	var initrdContents []byte
+	if *initrd != "" {
+		if initrdContents, err = os.ReadFile(*initrd); err != nil {
+			return nil, fmt.Errorf("could not read %q: %v", *initrd, err)
+		}
+	}
+	opts.KernelHashes = measure.NewKernelHashes(kernelContents, initrdContents, *cmdline)
+	return opts, nil
+}
+
+func main() {
+	logger.Init("", *verbose, false, os.Stderr)
+	flag.Parse()
+
+	if *outform != "hex" && *outform != "textproto" {
+		logger.Fatalf("-outform is %s. Expect \"hex\" or \"textproto\"", *outform)
+	}
+	opts, err := options()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	digest, err := measure.SnpLaunchDigest(opts)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if *outform == "hex" {
+		fmt.Println(hex.EncodeToString(digest))
+		return
+	}
+	policy, err := parseUint("guest_policy", *guestPolicy, 64)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if _, err := abi.ParseSnpPolicy(policy); err != nil {
+		logger.Fatalf("-guest_policy=%q: %v", *guestPolicy, err)
+	}
+	out, err := prototext.MarshalOptions{Multiline: true}.Marshal(&checkpb.Policy{
+		Measurement: digest,
+		Policy:      policy,
+	})
+	if err != nil {
+		logger.Fatal(err)
+	}
+	os.Stdout.Write(out)
+}
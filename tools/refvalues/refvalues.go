@@ -0,0 +1,122 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main implements a CLI tool for signing SEV-SNP reference value manifests.
+package main
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	rpb "github.com/google/go-sev-guest/proto/refvalues"
+	"github.com/google/go-sev-guest/validate/refvalues"
+	"github.com/google/logger"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	infile  = flag.String("in", "", "Path to a refvalues.ReferenceValues manifest in textproto format. Required.")
+	keyPath = flag.String("key", "", "Path to a PEM-encoded ECDSA or RSA private key that signs the manifest. Required.")
+	outfile = flag.String("out", "reference_values.binarypb", "Path to write the signed manifest to.")
+	outform = flag.String("outform", "bin", "The format of the signed manifest. One of \"bin\" or \"textproto\".")
+	verbose = flag.Bool("v", false, "Enable verbose logging.")
+)
+
+func readKey(path string) (crypto.Signer, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", path, err)
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("%q does not contain a PEM block", path)
+	}
+	var key any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%q has unexpected PEM block type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %q: %v", path, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%q is a %T, not a signing key", path, key)
+	}
+	return signer, nil
+}
+
+func readManifest(path string) (*rpb.ReferenceValues, error) {
+	if path == "" {
+		return nil, errors.New("-in is required")
+	}
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", path, err)
+	}
+	values := &rpb.ReferenceValues{}
+	if err := prototext.Unmarshal(contents, values); err != nil {
+		return nil, fmt.Errorf("could not unmarshal %q: %v", path, err)
+	}
+	return values, nil
+}
+
+func main() {
+	logger.Init("", *verbose, false, os.Stderr)
+	flag.Parse()
+
+	var marshal func(proto.Message) ([]byte, error)
+	switch *outform {
+	case "bin":
+		marshal = proto.Marshal
+	case "textproto":
+		marshal = prototext.MarshalOptions{Multiline: true}.Marshal
+	default:
+		logger.Fatalf("-outform is %s. Expect \"bin\" or \"textproto\"", *outform)
+	}
+	values, err := readManifest(*infile)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	if *keyPath == "" {
+		logger.Fatal("-key is required")
+	}
+	key, err := readKey(*keyPath)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	signed, err := refvalues.Sign(values, key)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	out, err := marshal(signed)
+	if err != nil {
+		logger.Fatalf("could not marshal the signed manifest: %v", err)
+	}
+	if err := os.WriteFile(*outfile, out, 0644); err != nil {
+		logger.Fatalf("could not write %q: %v", *outfile, err)
+	}
+}
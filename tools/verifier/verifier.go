@@ -0,0 +1,162 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package main implements an HTTP service for checking SEV-SNP attestations against a policy.
+package main
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/google/go-sev-guest/eat"
+	checkpb "github.com/google/go-sev-guest/proto/check"
+	kpb "github.com/google/go-sev-guest/proto/fakekds"
+	"github.com/google/go-sev-guest/server"
+	"github.com/google/go-sev-guest/testing"
+	"github.com/google/go-sev-guest/tools/lib/cmdline"
+	"github.com/google/go-sev-guest/verify/testdata"
+	"github.com/google/go-sev-guest/verify/trust"
+	"github.com/google/logger"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	configProto = flag.String("config", "",
+		("A path to a serialized check.Config protobuf that every attestation is checked against." +
+			" Default unmarshalled as binary. Paths ending in .textproto will be unmarshalled as prototext."))
+	listen        = flag.String("listen", ":8080", "The address on which to serve HTTP requests.")
+	timeout       = flag.Duration("timeout", 2*time.Minute, "Duration to continue to retry failed HTTP requests.")
+	maxRetryDelay = flag.Duration("max_retry_delay", 30*time.Second, "Maximum Duration to wait between HTTP request retries.")
+	verbose       = flag.Bool("v", false, "Enable verbose logging.")
+	testKdsFile   = flag.String("kdsdatabase", "", "Path to a fakekds.Certificates binary cache of AMD KDS")
+	cacheDir      = flag.String("cache_dir", "", "A directory in which to persist certificates and CRLs fetched from AMD KDS.")
+	offline       = flag.Bool("offline", false, "If true, only use certificates and CRLs in -cache_dir and never fetch them.")
+	resultKey     = flag.String("result_key", "", "Path to a PEM-encoded ECDSA or RSA private key. If set, serves EAT attestation results signed with it.")
+	resultKeyID   = flag.String("result_key_id", "", "The \"kid\" header of signed attestation results.")
+)
+
+func readConfig(path string) (*checkpb.Config, error) {
+	if path == "" {
+		return nil, errors.New("-config is required")
+	}
+	config := &checkpb.Config{}
+	if err := cmdline.ReadProto(path, config); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+func readKey(path string) (crypto.Signer, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", path, err)
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil {
+		return nil, fmt.Errorf("%q does not contain a PEM block", path)
+	}
+	var key any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%q has unexpected PEM block type %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %q: %v", path, err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("%q is a %T, not a signing key", path, key)
+	}
+	return signer, nil
+}
+
+func getter() (trust.HTTPSGetter, error) {
+	if *offline && *cacheDir == "" {
+		return nil, errors.New("-offline requires -cache_dir")
+	}
+	g, err := kdsGetter()
+	if err != nil {
+		return nil, err
+	}
+	if *cacheDir == "" {
+		return g, nil
+	}
+	return &trust.CachingHTTPSGetter{Dir: *cacheDir, Getter: g, Offline: *offline}, nil
+}
+
+func kdsGetter() (trust.HTTPSGetter, error) {
+	if *testKdsFile == "" {
+		return &trust.RetryHTTPSGetter{
+			Timeout:       *timeout,
+			MaxRetryDelay: *maxRetryDelay,
+			Getter:        &trust.SimpleHTTPSGetter{},
+		}, nil
+	}
+	b, err := os.ReadFile(*testKdsFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", *testKdsFile, err)
+	}
+	kds := &testing.FakeKDS{
+		Certs:       &kpb.Certificates{},
+		RootBundles: map[string]string{"Milan": string(testdata.MilanBytes)},
+	}
+	if err := proto.Unmarshal(b, kds.Certs); err != nil {
+		return nil, fmt.Errorf("could not unmarshal KDS database: %v", err)
+	}
+	return kds, nil
+}
+
+func main() {
+	logger.Init("", *verbose, false, os.Stderr)
+	flag.Parse()
+
+	config, err := readConfig(*configProto)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	g, err := getter()
+	if err != nil {
+		logger.Fatal(err)
+	}
+	s, err := server.New(config, g)
+	if err != nil {
+		logger.Fatal(err)
+	}
+	logger.Infof("Serving attestation checks at %s%s", *listen, server.VerifyPath)
+	if *resultKey != "" {
+		key, err := readKey(*resultKey)
+		if err != nil {
+			logger.Fatal(err)
+		}
+		id := eat.VerifierID{Developer: "https://github.com/google/go-sev-guest", Build: "verifier"}
+		if err := s.EnableAttestationResults(key, *resultKeyID, id); err != nil {
+			logger.Fatal(err)
+		}
+		logger.Infof("Serving attestation results at %s%s", *listen, server.ResultPath)
+	}
+	logger.Fatal(http.ListenAndServe(*listen, s))
+}
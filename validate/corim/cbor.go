@@ -0,0 +1,269 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corim
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// This file implements the subset of CBOR (RFC 8949) that CoRIM and COSE_Sign1 use: integers,
+// byte and text strings, arrays, maps, tags, and the simple values false, true, and null, all with
+// definite lengths. Decoded integers are int64 unless they only fit a uint64.
+
+const (
+	majorUint = iota
+	majorNegInt
+	majorBytes
+	majorText
+	majorArray
+	majorMap
+	majorTag
+	majorSimple
+)
+
+// maxDepth bounds the nesting of decoded arrays, maps, and tags.
+const maxDepth = 32
+
+// Tag is a CBOR tagged value.
+type Tag struct {
+	Number  uint64
+	Content any
+}
+
+type decoder struct {
+	data []byte
+	pos  int
+}
+
+// decode returns the single CBOR data item that data holds.
+func decode(data []byte) (any, error) {
+	d := &decoder{data: data}
+	v, err := d.item(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos != len(data) {
+		return nil, fmt.Errorf("%d trailing bytes after CBOR item", len(data)-d.pos)
+	}
+	return v, nil
+}
+
+func (d *decoder) head() (major byte, arg uint64, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, errors.New("unexpected end of CBOR data")
+	}
+	initial := d.data[d.pos]
+	d.pos++
+	major = initial >> 5
+	info := initial & 0x1f
+	size := 0
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == 31:
+		return 0, 0, errors.New("indefinite-length CBOR items are not supported")
+	default:
+		return 0, 0, fmt.Errorf("reserved CBOR additional information %d", info)
+	}
+	if len(d.data)-d.pos < size {
+		return 0, 0, errors.New("unexpected end of CBOR data")
+	}
+	buf := make([]byte, 8)
+	copy(buf[8-size:], d.data[d.pos:d.pos+size])
+	d.pos += size
+	return major, binary.BigEndian.Uint64(buf), nil
+}
+
+// count checks that a length of at least minSize bytes per element fits in the remaining data.
+func (d *decoder) count(arg uint64, minSize uint64) (int, error) {
+	if arg > uint64(len(d.data)-d.pos)/minSize {
+		return 0, fmt.Errorf("CBOR length %d exceeds the remaining data", arg)
+	}
+	return int(arg), nil
+}
+
+func (d *decoder) item(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("CBOR nesting deeper than %d", maxDepth)
+	}
+	major, arg, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case majorNegInt:
+		if arg > math.MaxInt64 {
+			return nil, errors.New("CBOR negative integer overflows int64")
+		}
+		return -1 - int64(arg), nil
+	case majorBytes, majorText:
+		n, err := d.count(arg, 1)
+		if err != nil {
+			return nil, err
+		}
+		b := d.data[d.pos : d.pos+n]
+		d.pos += n
+		if major == majorText {
+			return string(b), nil
+		}
+		return append([]byte{}, b...), nil
+	case majorArray:
+		n, err := d.count(arg, 1)
+		if err != nil {
+			return nil, err
+		}
+		result := make([]any, n)
+		for i := range result {
+			if result[i], err = d.item(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case majorMap:
+		n, err := d.count(arg, 2)
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[any]any, n)
+		for i := 0; i < n; i++ {
+			k, err := d.item(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch k.(type) {
+			case int64, uint64, string:
+			default:
+				return nil, fmt.Errorf("unsupported CBOR map key type %T", k)
+			}
+			if _, ok := result[k]; ok {
+				return nil, fmt.Errorf("duplicate CBOR map key %v", k)
+			}
+			if result[k], err = d.item(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return result, nil
+	case majorTag:
+		content, err := d.item(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return Tag{Number: arg, Content: content}, nil
+	}
+	switch arg {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22:
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unsupported CBOR simple value or float %d", arg)
+}
+
+func appendHead(out []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(out, major<<5|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(out, major<<5|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(out, major<<5|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(out, major<<5|26), uint32(arg))
+	}
+	return binary.BigEndian.AppendUint64(append(out, major<<5|27), arg)
+}
+
+// encode returns the deterministic CBOR encoding of a value of a type that decode returns, or of
+// an int. Map keys are sorted by their encodings.
+func encode(v any) ([]byte, error) {
+	return appendItem(nil, v)
+}
+
+func appendItem(out []byte, v any) ([]byte, error) {
+	switch x := v.(type) {
+	case int:
+		return appendItem(out, int64(x))
+	case int64:
+		if x < 0 {
+			return appendHead(out, majorNegInt, uint64(-1-x)), nil
+		}
+		return appendHead(out, majorUint, uint64(x)), nil
+	case uint64:
+		return appendHead(out, majorUint, x), nil
+	case []byte:
+		return append(appendHead(out, majorBytes, uint64(len(x))), x...), nil
+	case string:
+		return append(appendHead(out, majorText, uint64(len(x))), x...), nil
+	case []any:
+		out = appendHead(out, majorArray, uint64(len(x)))
+		for _, e := range x {
+			var err error
+			if out, err = appendItem(out, e); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	case map[any]any:
+		type entry struct{ key, value []byte }
+		entries := make([]entry, 0, len(x))
+		for k, e := range x {
+			key, err := encode(k)
+			if err != nil {
+				return nil, err
+			}
+			value, err := encode(e)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry{key, value})
+		}
+		sort.Slice(entries, func(i, j int) bool { return bytes.Compare(entries[i].key, entries[j].key) < 0 })
+		out = appendHead(out, majorMap, uint64(len(x)))
+		for _, e := range entries {
+			out = append(append(out, e.key...), e.value...)
+		}
+		return out, nil
+	case Tag:
+		return appendItem(appendHead(out, majorTag, x.Number), x.Content)
+	case bool:
+		if x {
+			return append(out, majorSimple<<5|21), nil
+		}
+		return append(out, majorSimple<<5|20), nil
+	case nil:
+		return append(out, majorSimple<<5|22), nil
+	}
+	return nil, fmt.Errorf("cannot encode %T as CBOR", v)
+}
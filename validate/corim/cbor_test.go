@@ -0,0 +1,100 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corim
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCBORRoundTrip(t *testing.T) {
+	tcs := []struct {
+		name  string
+		value any
+		// want is the RFC 8949 Appendix A encoding, if given.
+		want string
+	}{
+		{name: "zero", value: int64(0), want: "00"},
+		{name: "uint8", value: int64(24), want: "1818"},
+		{name: "uint16", value: int64(1000), want: "1903e8"},
+		{name: "uint32", value: int64(1000000), want: "1a000f4240"},
+		{name: "uint64", value: uint64(math.MaxUint64), want: "1bffffffffffffffff"},
+		{name: "negative", value: int64(-1000), want: "3903e7"},
+		{name: "bytes", value: []byte{1, 2, 3, 4}, want: "4401020304"},
+		{name: "text", value: "IETF", want: "6449455446"},
+		{name: "array", value: []any{int64(1), []any{int64(2), int64(3)}}, want: "8201820203"},
+		{name: "map", value: map[any]any{"b": int64(2), int64(1): "a"}, want: "a2016161616202"},
+		{name: "tag", value: Tag{Number: 1, Content: int64(1363896240)}, want: "c11a514b67b0"},
+		{name: "simple", value: []any{false, true, nil}, want: "83f4f5f6"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := encode(tc.value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(encoded); got != tc.want {
+				t.Errorf("encode(%v) = %s, want %s", tc.value, got, tc.want)
+			}
+			decoded, err := decode(encoded)
+			if err != nil {
+				t.Fatalf("decode(%x) = _, %v. Want nil", encoded, err)
+			}
+			if diff := cmp.Diff(tc.value, decoded); diff != "" {
+				t.Errorf("decode(encode(%v)) differs (-want +got):\n%s", tc.value, diff)
+			}
+		})
+	}
+}
+
+func TestCBORDecodeErrors(t *testing.T) {
+	deep := append(bytes.Repeat([]byte{0x81}, maxDepth+1), 0x00)
+	tcs := []struct {
+		name    string
+		data    string
+		raw     []byte
+		wantErr string
+	}{
+		{name: "empty", data: "", wantErr: "unexpected end"},
+		{name: "truncated head", data: "19", wantErr: "unexpected end"},
+		{name: "truncated bytes", data: "4401", wantErr: "exceeds the remaining data"},
+		{name: "huge array", data: "9bffffffffffffffff", wantErr: "exceeds the remaining data"},
+		{name: "indefinite", data: "9f01ff", wantErr: "indefinite-length"},
+		{name: "trailing", data: "0000", wantErr: "1 trailing bytes"},
+		{name: "duplicate key", data: "a201010102", wantErr: "duplicate CBOR map key"},
+		{name: "array key", data: "a18001", wantErr: "unsupported CBOR map key"},
+		{name: "float", data: "f93c00", wantErr: "unsupported CBOR simple value or float"},
+		{name: "deep", raw: deep, wantErr: "nesting deeper"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			data := tc.raw
+			if data == nil {
+				var err error
+				if data, err = hex.DecodeString(tc.data); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := decode(data); err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("decode(%x) = _, %v. Want error %q", data, err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,566 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package corim converts SEV-SNP reference values in a Concise Reference Integrity Manifest
+// (CoRIM) into validation policies, so that a verifier can consume the endorsements that image
+// builders publish.
+//
+// Only the reference triples of CoMID tags whose environment class has vendor "AMD" and model
+// "SEV-SNP" are considered. Each measurement names the attestation report field it describes by
+// its text mkey:
+//
+//   - MEASUREMENT: the digests, with the sha-384 algorithm, or the raw value of an accepted launch
+//     digest. This is the default if the mkey is absent.
+//   - HOST_DATA, FAMILY_ID, IMAGE_ID, CHIP_ID: the raw value of an accepted field value.
+//   - GUEST_SVN, CURRENT_TCB, LAUNCH_TCB: the minimum SVN of the field, i.e., the minimum guest
+//     SVN, the minimum TCB, and the minimum launch TCB.
+//
+// An environment instance that is 64 tagged bytes is an accepted CHIP_ID. Each reference triple
+// becomes its own policy, whose allowlist entries are the triple's accepted values bounded by the
+// CoRIM's validity period. An attestation is accepted if it satisfies any of the policies, so the
+// values of one triple are never combined with those of another.
+package corim
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	"github.com/google/go-sev-guest/validate"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/proto"
+)
+
+// CBOR tags and map keys of the CoRIM and COSE structures.
+const (
+	tagCoseSign1      = 18
+	tagCorim          = 501
+	tagComid          = 506
+	tagEpochTime      = 1
+	tagUEID           = 550
+	tagMinSvn         = 553
+	tagTaggedBytes    = 560
+	corimTags         = 1
+	corimValidity     = 4
+	validityNotBefore = 0
+	validityNotAfter  = 1
+	comidTriples      = 4
+	triplesRef        = 0
+	envClass          = 0
+	envInstance       = 1
+	classVendor       = 1
+	classModel        = 2
+	measKey           = 0
+	measValues        = 1
+	valuesSvn         = 1
+	valuesDigests     = 2
+	valuesRaw         = 4
+	coseAlg           = 1
+	namedHashSha384   = 7
+)
+
+const (
+	// Vendor is the environment class vendor of SEV-SNP reference values.
+	Vendor = "AMD"
+	// Model is the environment class model of SEV-SNP reference values.
+	Model = "SEV-SNP"
+)
+
+// The mkeys of the attestation report fields that reference values describe.
+const (
+	MeasurementKey = "MEASUREMENT"
+	HostDataKey    = "HOST_DATA"
+	FamilyIDKey    = "FAMILY_ID"
+	ImageIDKey     = "IMAGE_ID"
+	ChipIDKey      = "CHIP_ID"
+	GuestSvnKey    = "GUEST_SVN"
+	CurrentTcbKey  = "CURRENT_TCB"
+	LaunchTcbKey   = "LAUNCH_TCB"
+)
+
+// Options represents how to read a CoRIM.
+type Options struct {
+	// TrustedKeys are the keys that may sign a COSE_Sign1-signed CoRIM. A signed CoRIM is refused
+	// if empty.
+	TrustedKeys []crypto.PublicKey
+	// AllowUnsigned permits a CoRIM without a signature.
+	AllowUnsigned bool
+}
+
+// coseAlgorithm returns the hash and key check of a COSE signature algorithm.
+func coseAlgorithm(alg int64) (crypto.Hash, func(crypto.PublicKey) bool, error) {
+	ecdsaCurve := func(curve elliptic.Curve) func(crypto.PublicKey) bool {
+		return func(key crypto.PublicKey) bool {
+			k, ok := key.(*ecdsa.PublicKey)
+			return ok && k.Curve == curve
+		}
+	}
+	isRSA := func(key crypto.PublicKey) bool {
+		_, ok := key.(*rsa.PublicKey)
+		return ok
+	}
+	switch alg {
+	case -7: // ES256
+		return crypto.SHA256, ecdsaCurve(elliptic.P256()), nil
+	case -35: // ES384
+		return crypto.SHA384, ecdsaCurve(elliptic.P384()), nil
+	case -36: // ES512
+		return crypto.SHA512, ecdsaCurve(elliptic.P521()), nil
+	case -37: // PS256
+		return crypto.SHA256, isRSA, nil
+	case -38: // PS384
+		return crypto.SHA384, isRSA, nil
+	case -39: // PS512
+		return crypto.SHA512, isRSA, nil
+	}
+	return 0, nil, fmt.Errorf("unsupported COSE algorithm %d", alg)
+}
+
+func verifyCoseSignature(key crypto.PublicKey, hash crypto.Hash, message, signature []byte) error {
+	h := hash.New()
+	h.Write(message)
+	digest := h.Sum(nil)
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("ECDSA signature is %d bytes. Expect %d", len(signature), 2*size)
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(k, digest, r, s) {
+			return errors.New("ECDSA signature did not verify")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPSS(k, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+	}
+	return fmt.Errorf("unsupported key type %T", key)
+}
+
+// verifySign1 checks a COSE_Sign1 structure's signature and returns its payload.
+func verifySign1(content any, keys []crypto.PublicKey) ([]byte, error) {
+	sign1, ok := content.([]any)
+	if !ok || len(sign1) != 4 {
+		return nil, errors.New("COSE_Sign1 is not an array of 4 elements")
+	}
+	protected, ok := sign1[0].([]byte)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 protected header is not a byte string")
+	}
+	payload, ok := sign1[2].([]byte)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 payload is not a byte string")
+	}
+	signature, ok := sign1[3].([]byte)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 signature is not a byte string")
+	}
+	headers, err := decode(protected)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode COSE_Sign1 protected header: %v", err)
+	}
+	headerMap, ok := headers.(map[any]any)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 protected header is not a map")
+	}
+	alg, ok := headerMap[int64(coseAlg)].(int64)
+	if !ok {
+		return nil, errors.New("COSE_Sign1 protected header has no integer algorithm")
+	}
+	hash, keyMatches, err := coseAlgorithm(alg)
+	if err != nil {
+		return nil, err
+	}
+	message, err := encode([]any{"Signature1", protected, []byte{}, payload})
+	if err != nil {
+		return nil, err
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("CoRIM is signed but there are no trusted keys")
+	}
+	var errs error
+	for _, key := range keys {
+		if !keyMatches(key) {
+			errs = multierr.Append(errs, fmt.Errorf("key type %T does not match COSE algorithm %d", key, alg))
+			continue
+		}
+		err := verifyCoseSignature(key, hash, message, signature)
+		if err == nil {
+			return payload, nil
+		}
+		errs = multierr.Append(errs, err)
+	}
+	return nil, fmt.Errorf("CoRIM signature did not verify with any trusted key: %v", errs)
+}
+
+// unwrap returns the CoRIM map of a CoRIM, checking its signature if it has one.
+func unwrap(data []byte, opts *Options) (map[any]any, error) {
+	item, err := decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode CoRIM: %v", err)
+	}
+	signed := false
+	if tag, ok := item.(Tag); ok && tag.Number == tagCoseSign1 {
+		payload, err := verifySign1(tag.Content, opts.TrustedKeys)
+		if err != nil {
+			return nil, err
+		}
+		if item, err = decode(payload); err != nil {
+			return nil, fmt.Errorf("could not decode signed CoRIM: %v", err)
+		}
+		signed = true
+	}
+	if !signed && !opts.AllowUnsigned {
+		return nil, errors.New("CoRIM is not signed")
+	}
+	if tag, ok := item.(Tag); ok && tag.Number == tagCorim {
+		item = tag.Content
+	}
+	result, ok := item.(map[any]any)
+	if !ok {
+		return nil, errors.New("CoRIM is not a map")
+	}
+	return result, nil
+}
+
+func validityTime(v any) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	tag, ok := v.(Tag)
+	if !ok || tag.Number != tagEpochTime {
+		return "", errors.New("is not an epoch-based date-time")
+	}
+	seconds, ok := tag.Content.(int64)
+	if !ok {
+		return "", errors.New("is not an integer epoch-based date-time")
+	}
+	return time.Unix(seconds, 0).UTC().Format(time.RFC3339), nil
+}
+
+// policyBuilder accumulates the reference values of one triple into a policy.
+type policyBuilder struct {
+	policy              *cpb.Policy
+	notBefore, notAfter string
+	found               bool
+	// replaced holds the base allowlists that the triple's values have replaced.
+	replaced map[*[]*cpb.AllowedValue]bool
+}
+
+// allow adds value to the allowlist dest, in place of the base policy's entries.
+func (b *policyBuilder) allow(dest *[]*cpb.AllowedValue, value []byte) {
+	if !b.replaced[dest] {
+		*dest = nil
+		b.replaced[dest] = true
+	}
+	*dest = append(*dest, &cpb.AllowedValue{Value: value, NotBefore: b.notBefore, NotAfter: b.notAfter})
+	b.found = true
+}
+
+func taggedBytes(v any) ([]byte, bool) {
+	if tag, ok := v.(Tag); ok && tag.Number == tagTaggedBytes {
+		v = tag.Content
+	}
+	b, ok := v.([]byte)
+	return b, ok
+}
+
+func uint64Of(v any) (uint64, bool) {
+	switch x := v.(type) {
+	case int64:
+		return uint64(x), x >= 0
+	case uint64:
+		return x, true
+	}
+	return 0, false
+}
+
+// minSvn returns the minimum of a min-svn measurement value. An exact SVN cannot be expressed in a
+// validation policy, so it is an error.
+func minSvn(values map[any]any) (uint64, error) {
+	tag, ok := values[int64(valuesSvn)].(Tag)
+	if !ok || tag.Number != tagMinSvn {
+		if _, ok := values[int64(valuesSvn)]; ok {
+			return 0, fmt.Errorf("only a minimum SVN (tag %d) is supported", tagMinSvn)
+		}
+		return 0, errors.New("has no minimum SVN")
+	}
+	svn, ok := uint64Of(tag.Content)
+	if !ok {
+		return 0, errors.New("minimum SVN is not an unsigned integer")
+	}
+	return svn, nil
+}
+
+func (b *policyBuilder) setMinimum(dest *uint64, name string, value uint64) error {
+	if *dest != 0 && *dest != value {
+		return fmt.Errorf("%s minimum %#x conflicts with %#x", name, value, *dest)
+	}
+	*dest = value
+	b.found = true
+	return nil
+}
+
+func (b *policyBuilder) addMeasurement(m map[any]any) error {
+	key := MeasurementKey
+	if k, ok := m[int64(measKey)]; ok {
+		if key, ok = k.(string); !ok {
+			return fmt.Errorf("unsupported mkey %v. Expect a text mkey", k)
+		}
+	}
+	values, ok := m[int64(measValues)].(map[any]any)
+	if !ok {
+		return fmt.Errorf("%s has no measurement values", key)
+	}
+	rawField := func(dest *[]*cpb.AllowedValue, size int) error {
+		raw, ok := taggedBytes(values[int64(valuesRaw)])
+		if !ok {
+			return fmt.Errorf("%s has no raw value", key)
+		}
+		if len(raw) != size {
+			return fmt.Errorf("%s raw value is %d bytes. Expect %d", key, len(raw), size)
+		}
+		b.allow(dest, raw)
+		return nil
+	}
+	p := b.policy
+	switch key {
+	case MeasurementKey:
+		if _, ok := values[int64(valuesRaw)]; ok {
+			return rawField(&p.AllowedMeasurements, abi.MeasurementSize)
+		}
+		digests, ok := values[int64(valuesDigests)].([]any)
+		if !ok || len(digests) == 0 {
+			return fmt.Errorf("%s has no digests or raw value", key)
+		}
+		for i, d := range digests {
+			digest, ok := d.([]any)
+			if !ok || len(digest) != 2 {
+				return fmt.Errorf("%s digest %d is not an [algorithm, value] pair", key, i)
+			}
+			if alg, _ := digest[0].(int64); alg != namedHashSha384 && digest[0] != "sha-384" {
+				return fmt.Errorf("%s digest %d algorithm is %v. Expect sha-384", key, i, digest[0])
+			}
+			value, ok := digest[1].([]byte)
+			if !ok || len(value) != abi.MeasurementSize {
+				return fmt.Errorf("%s digest %d is not %d bytes", key, i, abi.MeasurementSize)
+			}
+			b.allow(&p.AllowedMeasurements, value)
+		}
+		return nil
+	case HostDataKey:
+		return rawField(&p.AllowedHostData, abi.HostDataSize)
+	case FamilyIDKey:
+		return rawField(&p.AllowedFamilyIds, abi.FamilyIDSize)
+	case ImageIDKey:
+		return rawField(&p.AllowedImageIds, abi.ImageIDSize)
+	case ChipIDKey:
+		return rawField(&p.AllowedChipIds, abi.ChipIDSize)
+	case GuestSvnKey, CurrentTcbKey, LaunchTcbKey:
+		return b.addMinimum(key, values)
+	}
+	return fmt.Errorf("unsupported mkey %q", key)
+}
+
+// addMinimum sets the policy's minimum of the field with the given mkey.
+func (b *policyBuilder) addMinimum(key string, values map[any]any) error {
+	svn, err := minSvn(values)
+	if err != nil {
+		return fmt.Errorf("%s %v", key, err)
+	}
+	p := b.policy
+	switch key {
+	case GuestSvnKey:
+		if svn > uint64(^uint32(0)) {
+			return fmt.Errorf("%s minimum SVN %d does not fit in 32 bits", key, svn)
+		}
+		if uint32(svn) > p.MinimumGuestSvn {
+			p.MinimumGuestSvn = uint32(svn)
+		}
+		b.found = true
+		return nil
+	case CurrentTcbKey:
+		return b.setMinimum(&p.MinimumTcb, key, svn)
+	}
+	return b.setMinimum(&p.MinimumLaunchTcb, key, svn)
+}
+
+// isSnpEnvironment returns whether the environment's class is SEV-SNP.
+func isSnpEnvironment(env map[any]any) bool {
+	class, ok := env[int64(envClass)].(map[any]any)
+	return ok && class[int64(classVendor)] == Vendor && class[int64(classModel)] == Model
+}
+
+// addTriple reads the reference values of an SEV-SNP reference triple into the builder's policy.
+// Returns false if the triple is not for SEV-SNP.
+func (b *policyBuilder) addTriple(triple any) (bool, error) {
+	t, ok := triple.([]any)
+	if !ok || len(t) != 2 {
+		return false, errors.New("reference triple is not an [environment, measurements] pair")
+	}
+	env, ok := t[0].(map[any]any)
+	if !ok {
+		return false, errors.New("reference triple environment is not a map")
+	}
+	if !isSnpEnvironment(env) {
+		return false, nil
+	}
+	if instance, ok := env[int64(envInstance)]; ok {
+		tag, isTag := instance.(Tag)
+		chipID, isBytes := tag.Content.([]byte)
+		if !isTag || (tag.Number != tagTaggedBytes && tag.Number != tagUEID) || !isBytes || len(chipID) != abi.ChipIDSize {
+			return false, fmt.Errorf("unsupported environment instance. Expect a %d-byte CHIP_ID", abi.ChipIDSize)
+		}
+		b.allow(&b.policy.AllowedChipIds, chipID)
+	}
+	measurements, ok := t[1].([]any)
+	if !ok {
+		return false, errors.New("reference triple measurements are not an array")
+	}
+	for i, m := range measurements {
+		measurement, ok := m.(map[any]any)
+		if !ok {
+			return false, fmt.Errorf("measurement %d is not a map", i)
+		}
+		if err := b.addMeasurement(measurement); err != nil {
+			return false, fmt.Errorf("measurement %d: %v", i, err)
+		}
+	}
+	return b.found, nil
+}
+
+// reader accumulates a policy per SEV-SNP reference triple of a CoRIM.
+type reader struct {
+	base                *cpb.Policy
+	notBefore, notAfter string
+	policies            []*cpb.Policy
+}
+
+func (r *reader) addComid(data []byte) error {
+	item, err := decode(data)
+	if err != nil {
+		return fmt.Errorf("could not decode CoMID: %v", err)
+	}
+	comid, ok := item.(map[any]any)
+	if !ok {
+		return errors.New("CoMID is not a map")
+	}
+	triples, ok := comid[int64(comidTriples)].(map[any]any)
+	if !ok {
+		return errors.New("CoMID has no triples")
+	}
+	refs, ok := triples[int64(triplesRef)]
+	if !ok {
+		return nil
+	}
+	refTriples, ok := refs.([]any)
+	if !ok {
+		return errors.New("CoMID reference triples are not an array")
+	}
+	for i, triple := range refTriples {
+		b := &policyBuilder{
+			policy:    proto.Clone(r.base).(*cpb.Policy),
+			notBefore: r.notBefore,
+			notAfter:  r.notAfter,
+			replaced:  map[*[]*cpb.AllowedValue]bool{},
+		}
+		found, err := b.addTriple(triple)
+		if err != nil {
+			return fmt.Errorf("reference triple %d: %v", i, err)
+		}
+		if found {
+			r.policies = append(r.policies, b.policy)
+		}
+	}
+	return nil
+}
+
+// ToPolicies returns a policy for each SEV-SNP reference triple of a CBOR-encoded CoRIM, in order.
+// Each is a copy of base in which the triple's accepted values replace the allowlists of their
+// fields, and the triple's minimum SVNs and TCBs are set. Base's exact field values still apply. A
+// minimum TCB that base already sets differently is an error, since TCBs are ordered per
+// component.
+func ToPolicies(data []byte, base *cpb.Policy, opts *Options) ([]*cpb.Policy, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	corim, err := unwrap(data, opts)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{base: &cpb.Policy{}}
+	if base != nil {
+		r.base = base
+	}
+	if v, ok := corim[int64(corimValidity)]; ok {
+		validity, ok := v.(map[any]any)
+		if !ok {
+			return nil, errors.New("CoRIM validity is not a map")
+		}
+		if r.notBefore, err = validityTime(validity[int64(validityNotBefore)]); err != nil {
+			return nil, fmt.Errorf("CoRIM not-before %v", err)
+		}
+		if r.notAfter, err = validityTime(validity[int64(validityNotAfter)]); err != nil {
+			return nil, fmt.Errorf("CoRIM not-after %v", err)
+		}
+	}
+	tags, ok := corim[int64(corimTags)].([]any)
+	if !ok {
+		return nil, errors.New("CoRIM has no tags")
+	}
+	for i, t := range tags {
+		tag, ok := t.(Tag)
+		if !ok || tag.Number != tagComid {
+			// Other tags, e.g., CoSWID, carry no SEV-SNP reference values.
+			continue
+		}
+		comid, ok := tag.Content.([]byte)
+		if !ok {
+			return nil, fmt.Errorf("CoRIM tag %d is not a byte string", i)
+		}
+		if err := r.addComid(comid); err != nil {
+			return nil, fmt.Errorf("CoRIM tag %d: %v", i, err)
+		}
+	}
+	if len(r.policies) == 0 {
+		return nil, fmt.Errorf("CoRIM has no %s %s reference values", Vendor, Model)
+	}
+	return r.policies, nil
+}
+
+// ToOptions returns the validation options for each of ToPolicies' policies in order. Validate an
+// attestation with refvalues.SnpAttestation, which accepts it if any of the options do.
+func ToOptions(data []byte, base *cpb.Policy, opts *Options) ([]*validate.Options, error) {
+	policies, err := ToPolicies(data, base, opts)
+	if err != nil {
+		return nil, err
+	}
+	var result []*validate.Options
+	for i, policy := range policies {
+		vopts, err := validate.PolicyToOptions(policy)
+		if err != nil {
+			return nil, fmt.Errorf("reference triple policy %d: %v", i, err)
+		}
+		result = append(result, vopts)
+	}
+	return result, nil
+}
@@ -0,0 +1,346 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package corim
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	test "github.com/google/go-sev-guest/testing"
+	"github.com/google/go-sev-guest/validate/refvalues"
+	"google.golang.org/protobuf/proto"
+)
+
+var (
+	launchDigest = bytes.Repeat([]byte{0x11}, abi.MeasurementSize)
+	otherDigest  = bytes.Repeat([]byte{0x55}, abi.MeasurementSize)
+	hostData     = bytes.Repeat([]byte{0x22}, abi.HostDataSize)
+	chipID       = bytes.Repeat([]byte{0x33}, abi.ChipIDSize)
+	notBefore    = time.Date(2026, time.June, 1, 0, 0, 0, 0, time.UTC)
+	notAfter     = time.Date(2027, time.June, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func snpEnvironment() map[any]any {
+	return map[any]any{int64(envClass): map[any]any{int64(classVendor): Vendor, int64(classModel): Model}}
+}
+
+func measurement(key string, values map[any]any) map[any]any {
+	return map[any]any{int64(measKey): key, int64(measValues): values}
+}
+
+func raw(value []byte) map[any]any {
+	return map[any]any{int64(valuesRaw): Tag{Number: tagTaggedBytes, Content: value}}
+}
+
+func minimumSvn(svn uint64) map[any]any {
+	return map[any]any{int64(valuesSvn): Tag{Number: tagMinSvn, Content: svn}}
+}
+
+// corimBytes returns an unsigned CoRIM with a single CoMID of the given reference triples.
+func corimBytes(t *testing.T, triples ...any) []byte {
+	t.Helper()
+	comid, err := encode(map[any]any{
+		int64(1):            map[any]any{int64(0): "snp-image"},
+		int64(comidTriples): map[any]any{int64(triplesRef): triples},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := encode(Tag{Number: tagCorim, Content: map[any]any{
+		int64(0): "corim-1",
+		int64(corimTags): []any{
+			Tag{Number: 505, Content: []byte("a CoSWID")},
+			Tag{Number: tagComid, Content: comid},
+		},
+		int64(corimValidity): map[any]any{
+			int64(validityNotBefore): Tag{Number: tagEpochTime, Content: notBefore.Unix()},
+			int64(validityNotAfter):  Tag{Number: tagEpochTime, Content: notAfter.Unix()},
+		},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// sign returns the CoRIM signed with key in a COSE_Sign1 structure using ES384.
+func sign(t *testing.T, corim []byte, key *ecdsa.PrivateKey) []byte {
+	t.Helper()
+	protected, err := encode(map[any]any{int64(coseAlg): int64(-35)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := encode([]any{"Signature1", protected, []byte{}, corim})
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha512.Sum384(message)
+	r, s, err := ecdsa.Sign(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := make([]byte, 96)
+	r.FillBytes(signature[:48])
+	s.FillBytes(signature[48:])
+	result, err := encode(Tag{Number: tagCoseSign1, Content: []any{protected, map[any]any{}, corim, signature}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestToPolicies(t *testing.T) {
+	window := func(value []byte) *cpb.AllowedValue {
+		return &cpb.AllowedValue{Value: value, NotBefore: "2026-06-01T00:00:00Z", NotAfter: "2027-06-01T00:00:00Z"}
+	}
+	otherEnvironment := map[any]any{int64(envClass): map[any]any{int64(classVendor): "Intel", int64(classModel): "TDX"}}
+	tcs := []struct {
+		name    string
+		triples []any
+		base    *cpb.Policy
+		want    []*cpb.Policy
+		wantErr string
+	}{
+		{
+			name: "reference values",
+			triples: []any{
+				[]any{snpEnvironment(), []any{
+					measurement(MeasurementKey, map[any]any{int64(valuesDigests): []any{[]any{int64(namedHashSha384), launchDigest}}}),
+					measurement(HostDataKey, raw(hostData)),
+					measurement(GuestSvnKey, minimumSvn(3)),
+					measurement(CurrentTcbKey, minimumSvn(0xd315000000000004)),
+				}},
+				[]any{otherEnvironment, []any{measurement("RTMR0", raw([]byte{1}))}},
+			},
+			base: &cpb.Policy{MinimumGuestSvn: 5, AllowedHostData: []*cpb.AllowedValue{{Value: make([]byte, abi.HostDataSize)}}},
+			want: []*cpb.Policy{{
+				MinimumGuestSvn:     5,
+				MinimumTcb:          0xd315000000000004,
+				AllowedMeasurements: []*cpb.AllowedValue{window(launchDigest)},
+				AllowedHostData:     []*cpb.AllowedValue{window(hostData)},
+			}},
+		},
+		{
+			name: "policy per triple",
+			triples: []any{
+				[]any{snpEnvironment(), []any{
+					measurement(MeasurementKey, raw(launchDigest)),
+					measurement(HostDataKey, raw(hostData)),
+				}},
+				[]any{snpEnvironment(), []any{measurement(MeasurementKey, raw(otherDigest))}},
+			},
+			base: &cpb.Policy{Policy: 0xa0000, AllowedHostData: []*cpb.AllowedValue{{Value: make([]byte, abi.HostDataSize)}}},
+			want: []*cpb.Policy{
+				{
+					Policy:              0xa0000,
+					AllowedMeasurements: []*cpb.AllowedValue{window(launchDigest)},
+					AllowedHostData:     []*cpb.AllowedValue{window(hostData)},
+				},
+				{
+					Policy:              0xa0000,
+					AllowedMeasurements: []*cpb.AllowedValue{window(otherDigest)},
+					AllowedHostData:     []*cpb.AllowedValue{{Value: make([]byte, abi.HostDataSize)}},
+				},
+			},
+		},
+		{
+			name: "chip instance and default mkey",
+			triples: []any{[]any{
+				map[any]any{
+					int64(envClass):    snpEnvironment()[int64(envClass)],
+					int64(envInstance): Tag{Number: tagUEID, Content: chipID},
+				},
+				[]any{map[any]any{int64(measValues): raw(launchDigest)}, measurement(LaunchTcbKey, minimumSvn(4))},
+			}},
+			want: []*cpb.Policy{{
+				MinimumLaunchTcb:    4,
+				AllowedMeasurements: []*cpb.AllowedValue{window(launchDigest)},
+				AllowedChipIds:      []*cpb.AllowedValue{window(chipID)},
+			}},
+		},
+		{
+			name:    "no snp values",
+			triples: []any{[]any{otherEnvironment, []any{measurement("RTMR0", raw([]byte{1}))}}},
+			wantErr: "CoRIM has no AMD SEV-SNP reference values",
+		},
+		{
+			name:    "unknown mkey",
+			triples: []any{[]any{snpEnvironment(), []any{measurement("POLICY", raw([]byte{1}))}}},
+			wantErr: `unsupported mkey "POLICY"`,
+		},
+		{
+			name: "exact svn",
+			triples: []any{[]any{snpEnvironment(), []any{
+				measurement(GuestSvnKey, map[any]any{int64(valuesSvn): int64(3)}),
+			}}},
+			wantErr: "only a minimum SVN (tag 553) is supported",
+		},
+		{
+			name: "sha-256 digest",
+			triples: []any{[]any{snpEnvironment(), []any{
+				measurement(MeasurementKey, map[any]any{int64(valuesDigests): []any{[]any{int64(1), launchDigest[:32]}}}),
+			}}},
+			wantErr: "algorithm is 1. Expect sha-384",
+		},
+		{
+			name:    "short host data",
+			triples: []any{[]any{snpEnvironment(), []any{measurement(HostDataKey, raw(hostData[:16]))}}},
+			wantErr: "HOST_DATA raw value is 16 bytes. Expect 32",
+		},
+		{
+			name:    "conflicting tcb",
+			triples: []any{[]any{snpEnvironment(), []any{measurement(CurrentTcbKey, minimumSvn(2))}}},
+			base:    &cpb.Policy{MinimumTcb: 1},
+			wantErr: "CURRENT_TCB minimum 0x2 conflicts with 0x1",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ToPolicies(corimBytes(t, tc.triples...), tc.base, &Options{AllowUnsigned: true})
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("ToPolicies() = _, %v. Want error %q", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("ToPolicies() = %v, want %v", got, tc.want)
+			}
+			for i := range got {
+				if !proto.Equal(got[i], tc.want[i]) {
+					t.Errorf("ToPolicies()[%d] = %v, want %v", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestToPoliciesSignature(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	unsigned := corimBytes(t, []any{snpEnvironment(), []any{measurement(MeasurementKey, raw(launchDigest))}})
+	signed := sign(t, unsigned, key)
+	tampered := append([]byte{}, signed...)
+	// The launch digest is in the payload, well before the trailing signature.
+	tampered[bytes.Index(tampered, launchDigest)] ^= 1
+	tcs := []struct {
+		name    string
+		data    []byte
+		opts    *Options
+		wantErr string
+	}{
+		{name: "signed", data: signed, opts: &Options{TrustedKeys: []crypto.PublicKey{&key.PublicKey}}},
+		{name: "second key", data: signed, opts: &Options{TrustedKeys: []crypto.PublicKey{&other.PublicKey, &key.PublicKey}}},
+		{
+			name:    "untrusted",
+			data:    signed,
+			opts:    &Options{TrustedKeys: []crypto.PublicKey{&other.PublicKey}},
+			wantErr: "CoRIM signature did not verify",
+		},
+		{
+			name:    "tampered",
+			data:    tampered,
+			opts:    &Options{TrustedKeys: []crypto.PublicKey{&key.PublicKey}},
+			wantErr: "CoRIM signature did not verify",
+		},
+		{name: "no keys", data: signed, opts: &Options{}, wantErr: "no trusted keys"},
+		{name: "unsigned", data: unsigned, opts: &Options{TrustedKeys: []crypto.PublicKey{&key.PublicKey}}, wantErr: "CoRIM is not signed"},
+		{name: "unsigned allowed", data: unsigned, opts: &Options{AllowUnsigned: true}},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ToPolicies(tc.data, nil, tc.opts)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Errorf("ToPolicies() = _, %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestToOptions(t *testing.T) {
+	sign, err := test.DefaultCertChain("Milan", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherHostData := bytes.Repeat([]byte{0x44}, abi.HostDataSize)
+	// Two images, each with its own launch digest and host data.
+	data := corimBytes(t,
+		[]any{snpEnvironment(), []any{measurement(MeasurementKey, raw(launchDigest)), measurement(HostDataKey, raw(hostData))}},
+		[]any{snpEnvironment(), []any{measurement(MeasurementKey, raw(otherDigest)), measurement(HostDataKey, raw(otherHostData))}},
+	)
+	options, err := ToOptions(data, &cpb.Policy{Policy: 0xa0000, MinimumVersion: "0.0"}, &Options{AllowUnsigned: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(options) != 2 {
+		t.Fatalf("ToOptions() = %d options, want 2", len(options))
+	}
+	if len(options[0].AllowedMeasurements) != 1 || !options[0].AllowedMeasurements[0].NotBefore.Equal(notBefore) ||
+		!options[0].AllowedMeasurements[0].NotAfter.Equal(notAfter) {
+		t.Errorf("AllowedMeasurements = %v, want one value from %v until %v", options[0].AllowedMeasurements, notBefore, notAfter)
+	}
+	tcs := []struct {
+		name        string
+		measurement []byte
+		hostData    []byte
+		wantErr     bool
+	}{
+		{name: "first image", measurement: launchDigest, hostData: hostData},
+		{name: "second image", measurement: otherDigest, hostData: otherHostData},
+		{name: "mixed images", measurement: launchDigest, hostData: otherHostData, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			attestation := &spb.Attestation{
+				Report: &spb.Report{
+					Version:     2,
+					Policy:      0xa0000,
+					Measurement: tc.measurement,
+					HostData:    tc.hostData,
+					FamilyId:    make([]byte, abi.FamilyIDSize),
+					ChipId:      make([]byte, abi.ChipIDSize),
+				},
+				CertificateChain: &spb.CertificateChain{VcekCert: sign.Vcek.Raw},
+			}
+			for _, opts := range options {
+				opts.Now = notBefore.Add(time.Hour)
+			}
+			err := refvalues.SnpAttestation(attestation, options)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("SnpAttestation() = %v, want error %v", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, refvalues.ErrNoMatch) {
+				t.Errorf("SnpAttestation() = %v, want ErrNoMatch", err)
+			}
+		})
+	}
+}
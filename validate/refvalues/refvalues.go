@@ -0,0 +1,311 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package refvalues signs, verifies, and converts manifests of accepted guest images into
+// validation options. The team that builds images publishes a signed manifest, and verifiers
+// only need to trust the publisher's public key, not the manifest's transport.
+package refvalues
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/kds"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	rpb "github.com/google/go-sev-guest/proto/refvalues"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/validate"
+	"go.uber.org/multierr"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+// ErrNoMatch is returned when an attestation satisfies none of a manifest's reference values.
+var ErrNoMatch = errors.New("attestation matches no reference value")
+
+var pssOptions = &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA384}
+
+// Options represents how to verify a signed reference value manifest.
+type Options struct {
+	// TrustedKeys are the ECDSA or RSA public keys that may sign manifests. Must be non-empty.
+	TrustedKeys []crypto.PublicKey
+	// MinimumVersion is the least manifest version to accept. A verifier that remembers the
+	// last version it accepted can refuse a rolled back manifest.
+	MinimumVersion uint64
+	// Now is the time to check the manifest's expiry against. Uses time.Now() if zero.
+	Now time.Time
+}
+
+func digest(manifest []byte) []byte {
+	h := sha512.Sum384(manifest)
+	return h[:]
+}
+
+// Sign serializes values and signs them with key, which must hold an ECDSA or RSA private key.
+func Sign(values *rpb.ReferenceValues, key crypto.Signer) (*rpb.SignedReferenceValues, error) {
+	manifest, err := proto.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("could not marshal reference values: %v", err)
+	}
+	var opts crypto.SignerOpts
+	switch key.Public().(type) {
+	case *ecdsa.PublicKey:
+		opts = crypto.SHA384
+	case *rsa.PublicKey:
+		opts = pssOptions
+	default:
+		return nil, fmt.Errorf("unsupported signing key type %T", key.Public())
+	}
+	signature, err := key.Sign(rand.Reader, digest(manifest), opts)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign reference values: %v", err)
+	}
+	return &rpb.SignedReferenceValues{Manifest: manifest, Signature: signature}, nil
+}
+
+func verifySignature(key crypto.PublicKey, manifest, signature []byte) error {
+	switch k := key.(type) {
+	case *ecdsa.PublicKey:
+		if !ecdsa.VerifyASN1(k, digest(manifest), signature) {
+			return errors.New("ECDSA signature did not verify")
+		}
+		return nil
+	case *rsa.PublicKey:
+		return rsa.VerifyPSS(k, crypto.SHA384, digest(manifest), signature, pssOptions)
+	}
+	return fmt.Errorf("unsupported trusted key type %T", key)
+}
+
+func checkValues(values *rpb.ReferenceValues, options *Options) error {
+	if values.GetVersion() < options.MinimumVersion {
+		return fmt.Errorf("manifest version %d is less than the minimum %d", values.GetVersion(),
+			options.MinimumVersion)
+	}
+	expires, err := validate.ParsePolicyTime(values.GetExpires(), true)
+	if err != nil {
+		return fmt.Errorf("invalid expires: %v", err)
+	}
+	now := options.Now
+	if now.IsZero() {
+		now = time.Now()
+	}
+	if !expires.IsZero() && now.After(expires) {
+		return fmt.Errorf("manifest expired at %s", values.GetExpires())
+	}
+	if len(values.GetValues()) == 0 {
+		return errors.New("manifest has no reference values")
+	}
+	for i, v := range values.GetValues() {
+		if len(v.GetMeasurement()) != abi.MeasurementSize {
+			return fmt.Errorf("values[%d].measurement is %d bytes. Expect %d", i,
+				len(v.GetMeasurement()), abi.MeasurementSize)
+		}
+	}
+	return nil
+}
+
+// Verify checks that one of the trusted keys signed the manifest, and that the manifest is
+// current and well-formed. Returns the manifest's reference values.
+func Verify(signed *rpb.SignedReferenceValues, options *Options) (*rpb.ReferenceValues, error) {
+	if options == nil || len(options.TrustedKeys) == 0 {
+		return nil, errors.New("no trusted keys to verify reference values with")
+	}
+	var sigErr error
+	verified := false
+	for _, key := range options.TrustedKeys {
+		err := verifySignature(key, signed.GetManifest(), signed.GetSignature())
+		if err == nil {
+			verified = true
+			break
+		}
+		sigErr = multierr.Append(sigErr, err)
+	}
+	if !verified {
+		return nil, fmt.Errorf("reference values are not signed by a trusted key: %v", sigErr)
+	}
+	values := &rpb.ReferenceValues{}
+	if err := proto.Unmarshal(signed.GetManifest(), values); err != nil {
+		return nil, fmt.Errorf("could not unmarshal reference values: %v", err)
+	}
+	if err := checkValues(values, options); err != nil {
+		return nil, fmt.Errorf("invalid reference values from %q: %v", values.GetIssuer(), err)
+	}
+	return values, nil
+}
+
+// Load reads a signed manifest from path and verifies it. The file is in the textproto format
+// if path ends in ".textproto", and in the binary protobuf format otherwise.
+func Load(path string, options *Options) (*rpb.ReferenceValues, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %q: %v", path, err)
+	}
+	signed := &rpb.SignedReferenceValues{}
+	if strings.HasSuffix(path, ".textproto") {
+		err = prototext.Unmarshal(contents, signed)
+	} else {
+		err = proto.Unmarshal(contents, signed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not unmarshal %q: %v", path, err)
+	}
+	return Verify(signed, options)
+}
+
+// ParsePublicKey parses a PEM-encoded public key or X.509 certificate for use as a trusted key.
+func ParsePublicKey(pemBytes []byte) (crypto.PublicKey, error) {
+	block, _ := pem.Decode(pemBytes)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		return cert.PublicKey, nil
+	}
+	return nil, fmt.Errorf("unexpected PEM block type %q", block.Type)
+}
+
+func maxSpl(a, b uint8) uint8 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// stricterTCB returns the TCB whose every security patch level is the greater of a's and b's.
+func stricterTCB(a, b uint64) (uint64, error) {
+	pa := kds.DecomposeTCBVersion(kds.TCBVersion(a))
+	pb := kds.DecomposeTCBVersion(kds.TCBVersion(b))
+	tcb, err := kds.ComposeTCBParts(kds.TCBParts{
+		BlSpl:    maxSpl(pa.BlSpl, pb.BlSpl),
+		TeeSpl:   maxSpl(pa.TeeSpl, pb.TeeSpl),
+		Spl4:     maxSpl(pa.Spl4, pb.Spl4),
+		Spl5:     maxSpl(pa.Spl5, pb.Spl5),
+		Spl6:     maxSpl(pa.Spl6, pb.Spl6),
+		Spl7:     maxSpl(pa.Spl7, pb.Spl7),
+		SnpSpl:   maxSpl(pa.SnpSpl, pb.SnpSpl),
+		UcodeSpl: maxSpl(pa.UcodeSpl, pb.UcodeSpl),
+	})
+	return uint64(tcb), err
+}
+
+// Policy returns a copy of base that expects the reference value in place of base's own
+// measurement, and, where the reference value sets them, its family and image IDs. The validity
+// window applies to the measurement. The reference value can only make base stricter: its guest
+// policy must not allow what base's does not, and its minimum guest SVN and minimum TCB only raise
+// base's, including the minimum TCB of each of base's product_tcbs.
+func Policy(value *rpb.ReferenceValue, base *cpb.Policy) (*cpb.Policy, error) {
+	result := &cpb.Policy{}
+	if base != nil {
+		result = proto.Clone(base).(*cpb.Policy)
+	}
+	result.Measurement = nil
+	result.AllowedMeasurements = []*cpb.AllowedValue{{
+		Value:     value.GetMeasurement(),
+		NotBefore: value.GetNotBefore(),
+		NotAfter:  value.GetNotAfter(),
+	}}
+	if value.GetPolicy() != 0 {
+		if result.GetPolicy() != 0 {
+			required, err := abi.ParseSnpPolicy(result.GetPolicy())
+			if err != nil {
+				return nil, fmt.Errorf("invalid base policy: %v", err)
+			}
+			if err := validate.GuestPolicy(value.GetPolicy(), required); err != nil {
+				return nil, fmt.Errorf("policy 0x%x is less strict than the base policy 0x%x: %v",
+					value.GetPolicy(), result.GetPolicy(), err)
+			}
+		}
+		result.Policy = value.GetPolicy()
+	}
+	if len(value.GetFamilyId()) != 0 {
+		result.FamilyId = value.GetFamilyId()
+		result.AllowedFamilyIds = nil
+	}
+	if len(value.GetImageId()) != 0 {
+		result.ImageId = value.GetImageId()
+		result.AllowedImageIds = nil
+	}
+	if value.GetMinimumGuestSvn() > result.GetMinimumGuestSvn() {
+		result.MinimumGuestSvn = value.GetMinimumGuestSvn()
+	}
+	if value.GetMinimumTcb() != 0 {
+		tcb, err := stricterTCB(result.GetMinimumTcb(), value.GetMinimumTcb())
+		if err != nil {
+			return nil, fmt.Errorf("invalid minimum_tcb: %v", err)
+		}
+		result.MinimumTcb = tcb
+		for _, p := range result.GetProductTcbs() {
+			tcb, err := stricterTCB(p.GetMinimumTcb(), value.GetMinimumTcb())
+			if err != nil {
+				return nil, fmt.Errorf("invalid minimum_tcb for product %q: %v", p.GetProduct(), err)
+			}
+			p.MinimumTcb = tcb
+		}
+	}
+	return result, nil
+}
+
+// ToOptions returns the validation options for each of the manifest's reference values in
+// order, each from the Policy of the reference value and base.
+func ToOptions(values *rpb.ReferenceValues, base *cpb.Policy) ([]*validate.Options, error) {
+	var result []*validate.Options
+	for i, v := range values.GetValues() {
+		policy, err := Policy(v, base)
+		if err != nil {
+			return nil, fmt.Errorf("reference value %d: %v", i, err)
+		}
+		opts, err := validate.PolicyToOptions(policy)
+		if err != nil {
+			return nil, fmt.Errorf("reference value %d: %v", i, err)
+		}
+		result = append(result, opts)
+	}
+	return result, nil
+}
+
+// SnpAttestation validates the attestation against each of options, and returns nil if any
+// accepts it. Otherwise returns ErrNoMatch with each option's reason. Does not check the
+// attestation certificates or signature.
+func SnpAttestation(attestation *spb.Attestation, options []*validate.Options) error {
+	var errs error
+	for i, opts := range options {
+		err := validate.SnpAttestation(attestation, opts)
+		if err == nil {
+			return nil
+		}
+		errs = multierr.Append(errs, fmt.Errorf("reference value %d: %v", i, err))
+	}
+	if errs == nil {
+		return fmt.Errorf("%w: no reference values", ErrNoMatch)
+	}
+	return fmt.Errorf("%w: %v", ErrNoMatch, errs)
+}
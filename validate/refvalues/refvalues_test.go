@@ -0,0 +1,377 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package refvalues
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/kds"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	rpb "github.com/google/go-sev-guest/proto/refvalues"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	test "github.com/google/go-sev-guest/testing"
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+)
+
+const debugPolicy = 0xa0000
+
+var (
+	oldImage = bytes.Repeat([]byte{1}, abi.MeasurementSize)
+	newImage = bytes.Repeat([]byte{2}, abi.MeasurementSize)
+)
+
+func manifest() *rpb.ReferenceValues {
+	return &rpb.ReferenceValues{
+		Issuer:  "image-builder",
+		Version: 3,
+		Expires: "2027-06-30",
+		Values: []*rpb.ReferenceValue{
+			{Measurement: oldImage, NotAfter: "2026-12-31"},
+			{Measurement: newImage, NotBefore: "2026-06-01", FamilyId: bytes.Repeat([]byte{7}, abi.FamilyIDSize)},
+		},
+	}
+}
+
+func mustEcdsaKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestVerify(t *testing.T) {
+	ecKey := mustEcdsaKey(t)
+	otherKey := mustEcdsaKey(t)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	tcs := []struct {
+		name    string
+		values  func() *rpb.ReferenceValues
+		signer  crypto.Signer
+		tamper  bool
+		options *Options
+		wantErr string
+	}{
+		{name: "ecdsa", signer: ecKey, options: &Options{TrustedKeys: []crypto.PublicKey{&ecKey.PublicKey}}},
+		{name: "rsa", signer: rsaKey, options: &Options{TrustedKeys: []crypto.PublicKey{&rsaKey.PublicKey}}},
+		{
+			name:    "second trusted key",
+			signer:  ecKey,
+			options: &Options{TrustedKeys: []crypto.PublicKey{&otherKey.PublicKey, &ecKey.PublicKey}},
+		},
+		{
+			name:    "untrusted key",
+			signer:  otherKey,
+			options: &Options{TrustedKeys: []crypto.PublicKey{&ecKey.PublicKey}},
+			wantErr: "not signed by a trusted key",
+		},
+		{
+			name:    "tampered",
+			signer:  ecKey,
+			tamper:  true,
+			options: &Options{TrustedKeys: []crypto.PublicKey{&ecKey.PublicKey}},
+			wantErr: "not signed by a trusted key",
+		},
+		{name: "no trusted keys", signer: ecKey, options: &Options{}, wantErr: "no trusted keys"},
+		{
+			name:    "rollback",
+			signer:  ecKey,
+			options: &Options{TrustedKeys: []crypto.PublicKey{&ecKey.PublicKey}, MinimumVersion: 4},
+			wantErr: "manifest version 3 is less than the minimum 4",
+		},
+		{
+			name:   "expired",
+			signer: ecKey,
+			options: &Options{
+				TrustedKeys: []crypto.PublicKey{&ecKey.PublicKey},
+				Now:         time.Date(2027, time.July, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantErr: "manifest expired at 2027-06-30",
+		},
+		{
+			name: "short measurement",
+			values: func() *rpb.ReferenceValues {
+				v := manifest()
+				v.Values[1].Measurement = newImage[:32]
+				return v
+			},
+			signer:  ecKey,
+			options: &Options{TrustedKeys: []crypto.PublicKey{&ecKey.PublicKey}},
+			wantErr: "values[1].measurement is 32 bytes. Expect 48",
+		},
+		{
+			name:    "empty",
+			values:  func() *rpb.ReferenceValues { return &rpb.ReferenceValues{} },
+			signer:  ecKey,
+			options: &Options{TrustedKeys: []crypto.PublicKey{&ecKey.PublicKey}},
+			wantErr: "manifest has no reference values",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			values := manifest()
+			if tc.values != nil {
+				values = tc.values()
+			}
+			signed, err := Sign(values, tc.signer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tc.tamper {
+				signed.Manifest[len(signed.Manifest)-1] ^= 1
+			}
+			if tc.options.Now.IsZero() {
+				tc.options.Now = now
+			}
+			got, err := Verify(signed, tc.options)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("Verify() = _, %v. Want error %q", err, tc.wantErr)
+			}
+			if err == nil && !proto.Equal(got, values) {
+				t.Errorf("Verify() = %v, want %v", got, values)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	key := mustEcdsaKey(t)
+	signed, err := Sign(manifest(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trusted, err := ParsePublicKey(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	textBytes, err := prototext.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	binBytes, err := proto.Marshal(signed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	for name, contents := range map[string][]byte{"values.textproto": textBytes, "values.binarypb": binBytes} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, contents, 0644); err != nil {
+			t.Fatal(err)
+		}
+		opts := &Options{
+			TrustedKeys: []crypto.PublicKey{trusted},
+			Now:         time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		}
+		got, err := Load(path, opts)
+		if err != nil {
+			t.Fatalf("Load(%q) = _, %v. Want nil", name, err)
+		}
+		if !proto.Equal(got, manifest()) {
+			t.Errorf("Load(%q) = %v, want %v", name, got, manifest())
+		}
+	}
+}
+
+func mustTCB(t *testing.T, parts kds.TCBParts) uint64 {
+	t.Helper()
+	tcb, err := kds.ComposeTCBParts(parts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uint64(tcb)
+}
+
+func TestPolicy(t *testing.T) {
+	base := &cpb.Policy{
+		Policy:          debugPolicy,
+		MinimumGuestSvn: 5,
+		MinimumTcb:      mustTCB(t, kds.TCBParts{BlSpl: 2, SnpSpl: 5}),
+		ProductTcbs:     []*cpb.ProductTcb{{Product: "Milan", MinimumTcb: mustTCB(t, kds.TCBParts{UcodeSpl: 9})}},
+	}
+	tcs := []struct {
+		name         string
+		value        *rpb.ReferenceValue
+		wantPolicy   uint64
+		wantSvn      uint32
+		wantTcb      uint64
+		wantMilanTcb uint64
+		wantErr      string
+	}{
+		{
+			name:         "unset",
+			value:        &rpb.ReferenceValue{Measurement: oldImage},
+			wantPolicy:   debugPolicy,
+			wantSvn:      5,
+			wantTcb:      base.GetMinimumTcb(),
+			wantMilanTcb: base.GetProductTcbs()[0].GetMinimumTcb(),
+		},
+		{
+			name: "stricter",
+			value: &rpb.ReferenceValue{
+				Measurement:     oldImage,
+				Policy:          0x20000,
+				MinimumGuestSvn: 7,
+				MinimumTcb:      mustTCB(t, kds.TCBParts{BlSpl: 3, SnpSpl: 1}),
+			},
+			wantPolicy:   0x20000,
+			wantSvn:      7,
+			wantTcb:      mustTCB(t, kds.TCBParts{BlSpl: 3, SnpSpl: 5}),
+			wantMilanTcb: mustTCB(t, kds.TCBParts{BlSpl: 3, SnpSpl: 1, UcodeSpl: 9}),
+		},
+		{
+			name:         "lower minimums",
+			value:        &rpb.ReferenceValue{Measurement: oldImage, MinimumGuestSvn: 1, MinimumTcb: mustTCB(t, kds.TCBParts{BlSpl: 1})},
+			wantPolicy:   debugPolicy,
+			wantSvn:      5,
+			wantTcb:      base.GetMinimumTcb(),
+			wantMilanTcb: mustTCB(t, kds.TCBParts{BlSpl: 1, UcodeSpl: 9}),
+		},
+		{
+			name:    "looser policy",
+			value:   &rpb.ReferenceValue{Measurement: oldImage, Policy: 0x30000},
+			wantErr: "policy 0x30000 is less strict than the base policy 0xa0000",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := Policy(tc.value, base)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("Policy() = _, %v. Want error %q", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.GetPolicy() != tc.wantPolicy {
+				t.Errorf("Policy().Policy = 0x%x, want 0x%x", got.GetPolicy(), tc.wantPolicy)
+			}
+			if got.GetMinimumGuestSvn() != tc.wantSvn {
+				t.Errorf("Policy().MinimumGuestSvn = %d, want %d", got.GetMinimumGuestSvn(), tc.wantSvn)
+			}
+			if got.GetMinimumTcb() != tc.wantTcb {
+				t.Errorf("Policy().MinimumTcb = 0x%x, want 0x%x", got.GetMinimumTcb(), tc.wantTcb)
+			}
+			if milan := got.GetProductTcbs()[0].GetMinimumTcb(); milan != tc.wantMilanTcb {
+				t.Errorf("Policy() Milan minimum TCB = 0x%x, want 0x%x", milan, tc.wantMilanTcb)
+			}
+		})
+	}
+	if base.GetProductTcbs()[0].GetMinimumTcb() != mustTCB(t, kds.TCBParts{UcodeSpl: 9}) {
+		t.Error("Policy() modified the base policy")
+	}
+}
+
+func TestSnpAttestation(t *testing.T) {
+	sign, err := test.DefaultCertChain("Milan", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := &cpb.Policy{
+		Policy:         debugPolicy,
+		MinimumVersion: "0.0",
+		Measurement:    bytes.Repeat([]byte{9}, abi.MeasurementSize),
+	}
+	options, err := ToOptions(manifest(), base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+	tcs := []struct {
+		name        string
+		measurement []byte
+		familyID    []byte
+		now         time.Time
+		wantErr     string
+	}{
+		{name: "old image", measurement: oldImage, now: day(2026, time.July, 1)},
+		{
+			name:        "old image expired",
+			measurement: oldImage,
+			now:         day(2027, time.January, 1),
+			wantErr:     "which was allowed only until 2026-12-31T23:59:59Z",
+		},
+		{
+			name:        "new image",
+			measurement: newImage,
+			familyID:    bytes.Repeat([]byte{7}, abi.FamilyIDSize),
+			now:         day(2026, time.July, 1),
+		},
+		{
+			name:        "new image wrong family",
+			measurement: newImage,
+			now:         day(2026, time.July, 1),
+			wantErr:     "FAMILY_ID",
+		},
+		{
+			name:        "base measurement replaced",
+			measurement: base.GetMeasurement(),
+			now:         day(2026, time.July, 1),
+			wantErr:     "which is not an allowed value",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			familyID := tc.familyID
+			if familyID == nil {
+				familyID = make([]byte, abi.FamilyIDSize)
+			}
+			attestation := &spb.Attestation{
+				Report: &spb.Report{
+					Version:     2,
+					Policy:      debugPolicy,
+					Measurement: tc.measurement,
+					FamilyId:    familyID,
+					ChipId:      make([]byte, abi.ChipIDSize),
+				},
+				CertificateChain: &spb.CertificateChain{VcekCert: sign.Vcek.Raw},
+			}
+			for _, opts := range options {
+				opts.Now = tc.now
+			}
+			err := SnpAttestation(attestation, options)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Errorf("SnpAttestation() = %v. Want error %q", err, tc.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrNoMatch) {
+				t.Errorf("SnpAttestation() = %v. Want ErrNoMatch", err)
+			}
+		})
+	}
+}
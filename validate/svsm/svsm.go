@@ -0,0 +1,103 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package svsm validates attestations that a Secure VM Service Module (SVSM) produces at VMPL0
+// through the SVSM attestation protocol, and extracts the endorsement key of an SVSM vTPM from
+// them.
+//
+// An SVSM attestation report binds a manifest by setting REPORT_DATA to the SHA-512 digest of the
+// relying party's nonce followed by the manifest. Since the SVSM runs at VMPL0, a guest at a less
+// privileged VMPL cannot forge a VMPL0 report, so the manifest's contents are as trustworthy as
+// the report's MEASUREMENT. The report's signature must still be verified with the verify package.
+package svsm
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+
+	"github.com/google/go-sev-guest/abi"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/pborman/uuid"
+)
+
+// Options represents validation options for an SVSM attestation.
+type Options struct {
+	// Nonce is the nonce that the attestation was requested with.
+	Nonce []byte
+	// ServiceGUID is the single service that the attestation covers. If empty, the manifest must be
+	// the services manifest of an attestation of all services.
+	ServiceGUID string
+	// ValidateOptions are further checks of the attestation report. Its ReportData and VMPL are
+	// replaced with the expected values for the manifest. May be nil.
+	ValidateOptions *validate.Options
+}
+
+// reportOptions returns opts.ValidateOptions with the REPORT_DATA and VMPL that an SVSM
+// attestation of the manifest must have.
+func reportOptions(manifest []byte, opts *Options) *validate.Options {
+	var result validate.Options
+	if opts.ValidateOptions != nil {
+		result = *opts.ValidateOptions
+	}
+	reportData := abi.SvsmReportData(opts.Nonce, manifest)
+	result.ReportData = reportData[:]
+	vmpl := 0
+	result.VMPL = &vmpl
+	return &result
+}
+
+// SnpAttestation checks that the attestation report was produced at VMPL0 for the nonce and
+// manifest, and that it satisfies opts.ValidateOptions.
+func SnpAttestation(attestation *spb.Attestation, manifest []byte, opts *Options) error {
+	if opts == nil {
+		return errors.New("options cannot be nil")
+	}
+	return validate.SnpAttestation(attestation, reportOptions(manifest, opts))
+}
+
+// ServiceManifest returns the manifest of the service identified by the given GUID string from
+// the manifest of an attestation with the given options.
+func ServiceManifest(manifest []byte, guid string, opts *Options) ([]byte, error) {
+	if opts.ServiceGUID != "" {
+		if !uuid.Equal(uuid.Parse(opts.ServiceGUID), uuid.Parse(guid)) {
+			return nil, fmt.Errorf("attestation covers service %s, not %s", opts.ServiceGUID, guid)
+		}
+		return manifest, nil
+	}
+	services, err := abi.ParseSvsmServicesManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return services.Service(guid)
+}
+
+// VtpmEndorsementKey validates an SVSM attestation as SnpAttestation does and returns the public
+// endorsement key of the SVSM vTPM from its version 0 manifest. Quotes that the vTPM signs with
+// keys certified by this endorsement key may then be trusted as much as the attestation report.
+func VtpmEndorsementKey(attestation *spb.Attestation, manifest []byte, opts *Options) (crypto.PublicKey, error) {
+	if err := SnpAttestation(attestation, manifest, opts); err != nil {
+		return nil, err
+	}
+	ek, err := ServiceManifest(manifest, abi.SvsmVtpmServiceGUID, opts)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ParseTpmtPublic(ek)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse the vTPM endorsement key: %v", err)
+	}
+	return key, nil
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svsm
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	cpb "github.com/google/go-sev-guest/proto/check"
+	spb "github.com/google/go-sev-guest/proto/sevsnp"
+	test "github.com/google/go-sev-guest/testing"
+	"github.com/google/go-sev-guest/validate"
+	"github.com/pborman/uuid"
+)
+
+const debugPolicy = 0xa0000
+
+// ekPolicy is the authPolicy of the TCG default EK templates.
+var ekPolicy = bytes.Repeat([]byte{0xee}, 32)
+
+func sized(b []byte) []byte {
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(b))), b...)
+}
+
+// rsaEK returns the TPMT_PUBLIC of an RSA 2048 EK with the default exponent and AES-128-CFB
+// symmetric parameters.
+func rsaEK(key *rsa.PublicKey) []byte {
+	var b []byte
+	b = binary.BigEndian.AppendUint16(b, tpmAlgRSA)
+	b = binary.BigEndian.AppendUint16(b, 0x000b) // SHA-256
+	b = binary.BigEndian.AppendUint32(b, 0x000300b2)
+	b = append(b, sized(ekPolicy)...)
+	b = append(b, 0x00, 0x06, 0x00, 0x80, 0x00, 0x43) // AES, 128 bits, CFB
+	b = binary.BigEndian.AppendUint16(b, tpmAlgNull)
+	b = binary.BigEndian.AppendUint16(b, uint16(key.N.BitLen()))
+	b = binary.BigEndian.AppendUint32(b, 0)
+	return append(b, sized(key.N.Bytes())...)
+}
+
+// eccEK returns the TPMT_PUBLIC of a NIST P-256 EK with AES-128-CFB symmetric parameters.
+func eccEK(key *ecdsa.PublicKey) []byte {
+	var b []byte
+	b = binary.BigEndian.AppendUint16(b, tpmAlgECC)
+	b = binary.BigEndian.AppendUint16(b, 0x000b)
+	b = binary.BigEndian.AppendUint32(b, 0x000300b2)
+	b = append(b, sized(ekPolicy)...)
+	b = append(b, 0x00, 0x06, 0x00, 0x80, 0x00, 0x43)
+	b = binary.BigEndian.AppendUint16(b, tpmAlgNull)
+	b = binary.BigEndian.AppendUint16(b, tpmEccNistP256)
+	b = binary.BigEndian.AppendUint16(b, tpmAlgNull)
+	b = append(b, sized(key.X.FillBytes(make([]byte, 32)))...)
+	return append(b, sized(key.Y.FillBytes(make([]byte, 32)))...)
+}
+
+func TestParseTpmtPublic(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offCurve := eccEK(&ecdsa.PublicKey{Curve: elliptic.P256(), X: ecKey.X, Y: ecKey.X})
+	unknownCurve := eccEK(&ecKey.PublicKey)
+	binary.BigEndian.PutUint16(unknownCurve[len(unknownCurve)-4-64-4:], 0x0010)
+	tcs := []struct {
+		name    string
+		data    []byte
+		want    any
+		wantErr string
+	}{
+		{name: "rsa", data: rsaEK(&rsaKey.PublicKey), want: &rsaKey.PublicKey},
+		{name: "ecc", data: eccEK(&ecKey.PublicKey), want: &ecKey.PublicKey},
+		{name: "truncated", data: rsaEK(&rsaKey.PublicKey)[:100], wantErr: "unexpected end of TPMT_PUBLIC"},
+		{name: "trailing", data: append(eccEK(&ecKey.PublicKey), 0), wantErr: "1 trailing bytes"},
+		{name: "off curve", data: offCurve, wantErr: "not on its curve"},
+		{name: "unknown curve", data: unknownCurve, wantErr: "curve 0x0010 is not supported"},
+		{name: "keyedhash", data: []byte{0x00, 0x08, 0, 0, 0, 0, 0, 0, 0, 0}, wantErr: "key type 0x0008 is not supported"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := ParseTpmtPublic(tc.data)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("ParseTpmtPublic() = _, %v. Want error %q", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if key, ok := got.(interface{ Equal(crypto.PublicKey) bool }); !ok || !key.Equal(tc.want) {
+				t.Errorf("ParseTpmtPublic() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestVtpmEndorsementKey(t *testing.T) {
+	sign, err := test.DefaultCertChain("Milan", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ek := eccEK(&ecKey.PublicKey)
+	all, err := (&abi.SvsmServicesManifest{Services: []abi.SvsmService{
+		{GUID: uuid.Parse(abi.SvsmVtpmServiceGUID), Manifest: ek},
+	}}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	noVtpm, err := (&abi.SvsmServicesManifest{}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	validateOptions, err := validate.PolicyToOptions(&cpb.Policy{Policy: debugPolicy, MinimumVersion: "0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := []byte("nonce")
+	attestation := func(manifest []byte, vmpl uint32) *spb.Attestation {
+		reportData := abi.SvsmReportData(nonce, manifest)
+		return &spb.Attestation{
+			Report: &spb.Report{
+				Version:    2,
+				Policy:     debugPolicy,
+				Vmpl:       vmpl,
+				ReportData: reportData[:],
+				ChipId:     make([]byte, abi.ChipIDSize),
+			},
+			CertificateChain: &spb.CertificateChain{VcekCert: sign.Vcek.Raw},
+		}
+	}
+	tcs := []struct {
+		name        string
+		attestation *spb.Attestation
+		manifest    []byte
+		opts        *Options
+		wantErr     string
+	}{
+		{
+			name:        "all services",
+			attestation: attestation(all, 0),
+			manifest:    all,
+			opts:        &Options{Nonce: nonce, ValidateOptions: validateOptions},
+		},
+		{
+			name:        "single service",
+			attestation: attestation(ek, 0),
+			manifest:    ek,
+			opts:        &Options{Nonce: nonce, ServiceGUID: abi.SvsmVtpmServiceGUID, ValidateOptions: validateOptions},
+		},
+		{
+			name:        "other nonce",
+			attestation: attestation(all, 0),
+			manifest:    all,
+			opts:        &Options{Nonce: []byte("replay"), ValidateOptions: validateOptions},
+			wantErr:     "REPORT_DATA",
+		},
+		{
+			name:        "substituted manifest",
+			attestation: attestation(all, 0),
+			manifest:    ek,
+			opts:        &Options{Nonce: nonce, ServiceGUID: abi.SvsmVtpmServiceGUID, ValidateOptions: validateOptions},
+			wantErr:     "REPORT_DATA",
+		},
+		{
+			name:        "guest vmpl",
+			attestation: attestation(all, 2),
+			manifest:    all,
+			opts:        &Options{Nonce: nonce, ValidateOptions: validateOptions},
+			wantErr:     "VMPL",
+		},
+		{
+			name:        "no vTPM",
+			attestation: attestation(noVtpm, 0),
+			manifest:    noVtpm,
+			opts:        &Options{Nonce: nonce, ValidateOptions: validateOptions},
+			wantErr:     "services manifest has no service " + abi.SvsmVtpmServiceGUID,
+		},
+		{
+			name:        "other service",
+			attestation: attestation(ek, 0),
+			manifest:    ek,
+			opts:        &Options{Nonce: nonce, ServiceGUID: abi.SvsmServicesManifestGUID, ValidateOptions: validateOptions},
+			wantErr:     "attestation covers service " + abi.SvsmServicesManifestGUID,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := VtpmEndorsementKey(tc.attestation, tc.manifest, tc.opts)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Fatalf("VtpmEndorsementKey() = _, %v. Want error %q", err, tc.wantErr)
+			}
+			if err == nil && !ecKey.PublicKey.Equal(got) {
+				t.Errorf("VtpmEndorsementKey() = %v, want %v", got, &ecKey.PublicKey)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package svsm
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// TPM 2.0 algorithm and curve identifiers from the TCG Algorithm Registry.
+const (
+	tpmAlgRSA      = 0x0001
+	tpmAlgNull     = 0x0010
+	tpmAlgRSAES    = 0x0015
+	tpmAlgECDAA    = 0x001a
+	tpmAlgECC      = 0x0023
+	tpmEccNistP256 = 0x0003
+	tpmEccNistP384 = 0x0004
+	tpmEccNistP521 = 0x0005
+
+	// rsaDefaultExponent is the RSA public exponent that an exponent field of 0 stands for.
+	rsaDefaultExponent = 65537
+)
+
+// tpmReader reads the big-endian TPM 2.0 structure encoding.
+type tpmReader struct {
+	data []byte
+	err  error
+}
+
+func (r *tpmReader) bytes(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = errors.New("unexpected end of TPMT_PUBLIC")
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *tpmReader) uint16() uint16 {
+	if b := r.bytes(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *tpmReader) uint32() uint32 {
+	if b := r.bytes(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+// sized reads a TPM2B structure's contents.
+func (r *tpmReader) sized() []byte {
+	return r.bytes(int(r.uint16()))
+}
+
+// symmetric reads a TPMT_SYM_DEF_OBJECT.
+func (r *tpmReader) symmetric() {
+	if r.uint16() != tpmAlgNull {
+		r.uint16() // keyBits
+		r.uint16() // mode
+	}
+}
+
+// ParseTpmtPublic returns the public key that a TPMT_PUBLIC structure of an RSA or a NIST P-curve
+// ECC key holds, as an *rsa.PublicKey or an *ecdsa.PublicKey.
+func ParseTpmtPublic(data []byte) (crypto.PublicKey, error) {
+	r := &tpmReader{data: data}
+	keyType := r.uint16()
+	r.uint16() // nameAlg
+	r.uint32() // objectAttributes
+	r.sized()  // authPolicy
+	var key crypto.PublicKey
+	switch keyType {
+	case tpmAlgRSA:
+		r.symmetric()
+		if scheme := r.uint16(); scheme != tpmAlgNull && scheme != tpmAlgRSAES {
+			r.uint16() // hashAlg
+		}
+		keyBits := r.uint16()
+		exponent := r.uint32()
+		modulus := r.sized()
+		if r.err != nil {
+			return nil, r.err
+		}
+		if exponent == 0 {
+			exponent = rsaDefaultExponent
+		}
+		if int(keyBits) != len(modulus)*8 {
+			return nil, fmt.Errorf("TPMT_PUBLIC RSA modulus is %d bits, expected %d", len(modulus)*8, keyBits)
+		}
+		if exponent > 1<<31-1 {
+			return nil, fmt.Errorf("TPMT_PUBLIC RSA exponent %d is too large", exponent)
+		}
+		key = &rsa.PublicKey{N: new(big.Int).SetBytes(modulus), E: int(exponent)}
+	case tpmAlgECC:
+		r.symmetric()
+		if scheme := r.uint16(); scheme != tpmAlgNull {
+			r.uint16() // hashAlg
+			if scheme == tpmAlgECDAA {
+				r.uint16() // count
+			}
+		}
+		curveID := r.uint16()
+		if kdf := r.uint16(); kdf != tpmAlgNull {
+			r.uint16() // hashAlg
+		}
+		x := r.sized()
+		y := r.sized()
+		if r.err != nil {
+			return nil, r.err
+		}
+		var curve elliptic.Curve
+		switch curveID {
+		case tpmEccNistP256:
+			curve = elliptic.P256()
+		case tpmEccNistP384:
+			curve = elliptic.P384()
+		case tpmEccNistP521:
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("TPMT_PUBLIC ECC curve 0x%04x is not supported", curveID)
+		}
+		ecKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(ecKey.X, ecKey.Y) {
+			return nil, errors.New("TPMT_PUBLIC ECC point is not on its curve")
+		}
+		key = ecKey
+	default:
+		if r.err != nil {
+			return nil, r.err
+		}
+		return nil, fmt.Errorf("TPMT_PUBLIC key type 0x%04x is not supported", keyType)
+	}
+	if len(r.data) != 0 {
+		return nil, fmt.Errorf("%d trailing bytes after TPMT_PUBLIC", len(r.data))
+	}
+	return key, nil
+}
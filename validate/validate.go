@@ -17,6 +17,7 @@ package validate
 
 import (
 	"bytes"
+	"context"
 	"crypto"
 	"crypto/ecdsa"
 	"crypto/x509"
@@ -25,9 +26,12 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
 	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/observe"
 	cpb "github.com/google/go-sev-guest/proto/check"
 	spb "github.com/google/go-sev-guest/proto/sevsnp"
 	"go.uber.org/multierr"
@@ -75,6 +79,21 @@ type Options struct {
 	RequireAuthorKey bool
 	// VMPL is the expected VMPL value, 0-3. Unchecked if nil.
 	VMPL *int
+	// CPUIDFamily is the expected CPUID_FAM_ID field. Requires a report version of at least 3.
+	// Unchecked if nil.
+	CPUIDFamily *uint8
+	// CPUIDModel is the expected CPUID_MOD_ID field. Requires a report version of at least 3.
+	// Unchecked if nil.
+	CPUIDModel *uint8
+	// CPUIDStepping is the expected CPUID_STEP field. Requires a report version of at least 3.
+	// Unchecked if nil.
+	CPUIDStepping *uint8
+	// RequiredLaunchMitVector is the mask of mitigations that must all be set in the report's
+	// LAUNCH_MIT_VECTOR. Requires a report version of at least 5 if non-zero.
+	RequiredLaunchMitVector uint64
+	// RequiredCurrentMitVector is the mask of mitigations that must all be set in the report's
+	// CURRENT_MIT_VECTOR. Requires a report version of at least 5 if non-zero.
+	RequiredCurrentMitVector uint64
 	// RequireIDBlock if true, will not validate a report if it does not have an ID_KEY_DIGEST that
 	// is trusted through all keys in TrustedIDKeys or TrustedIDKeyHashes, or any ID key whose hash
 	// was signed by a key in TrustedAuthorKeys or TrustedIDKeyHashes. No signatures are checked,
@@ -93,6 +112,64 @@ type Options struct {
 	// TrustedIDKeyHashes is an array of SHA-384 hashes of trusted ID signer keys's public key in
 	// SEV-SNP API format. Not required if TrustedKeyKeys is provided.
 	TrustedIDKeyHashes [][]byte
+	// IDBlock is the ID block the guest was launched with. If not nil, IDAuthInfo must also be set,
+	// and the report must match them as checked by the IDBlock function. Unlike RequireIDBlock, this
+	// checks the ID block's signatures, so it does not rely on the trusted key options.
+	IDBlock *abi.IDBlock
+	// IDAuthInfo is the ID authentication information the guest was launched with.
+	IDAuthInfo *abi.IDAuthInfo
+	// AllowedMeasurements, if not empty, are the acceptable MEASUREMENT values. Checked in addition
+	// to Measurement.
+	AllowedMeasurements []*AllowedValue
+	// AllowedHostData, if not empty, are the acceptable HOST_DATA values. Checked in addition to
+	// HostData.
+	AllowedHostData []*AllowedValue
+	// AllowedImageIDs, if not empty, are the acceptable IMAGE_ID values. Checked in addition to
+	// ImageID.
+	AllowedImageIDs []*AllowedValue
+	// AllowedFamilyIDs, if not empty, are the acceptable FAMILY_ID values. Checked in addition to
+	// FamilyID.
+	AllowedFamilyIDs []*AllowedValue
+	// AllowedChipIDs, if not empty, are the acceptable CHIP_ID values. Checked in addition to ChipID.
+	AllowedChipIDs []*AllowedValue
+	// ProductTCBs maps an AMD product line, e.g., "Milan", to the minimum TCBs that replace
+	// MinimumTCB and MinimumLaunchTCB for attestations from that product. If set, attestations
+	// whose VCEK or VLEK does not name a known product fail the TCB checks.
+	ProductTCBs map[string]*ProductTCB
+	// Now is the time at which the time bounds of allowed values are checked. If zero, uses
+	// time.Now().
+	Now time.Time
+	// Observer receives the outcome of each validation. If nil, uses observe.Default().
+	Observer observe.Observer
+}
+
+// AllowedValue is an acceptable value of a report field. If not zero, NotBefore and NotAfter bound
+// the times at which the value is acceptable.
+type AllowedValue struct {
+	Value     []byte
+	NotBefore time.Time
+	NotAfter  time.Time
+}
+
+// ProductTCB is the minimum TCB of attestations from one AMD product line.
+type ProductTCB struct {
+	MinimumTCB       kds.TCBParts
+	MinimumLaunchTCB kds.TCBParts
+}
+
+// minimumTCBs returns the minimum current and launch TCBs of attestations from the given product.
+func (o *Options) minimumTCBs(product string) (kds.TCBParts, kds.TCBParts) {
+	if p, ok := o.ProductTCBs[product]; ok {
+		return p.MinimumTCB, p.MinimumLaunchTCB
+	}
+	return o.MinimumTCB, o.MinimumLaunchTCB
+}
+
+func (o *Options) now() time.Time {
+	if o.Now.IsZero() {
+		return time.Now()
+	}
+	return o.Now
 }
 
 func lengthCheck(name string, length int, value []byte) error {
@@ -102,8 +179,22 @@ func lengthCheck(name string, length int, value []byte) error {
 	return nil
 }
 
+func allowedLengthCheck(name string, length int, values []*AllowedValue) error {
+	for _, v := range values {
+		if len(v.Value) != length {
+			return fmt.Errorf("option %q has a value of length %d. Want %d", name, len(v.Value), length)
+		}
+	}
+	return nil
+}
+
 func checkOptionsLengths(opts *Options) error {
 	return multierr.Combine(
+		allowedLengthCheck("allowed_measurements", abi.MeasurementSize, opts.AllowedMeasurements),
+		allowedLengthCheck("allowed_host_data", abi.HostDataSize, opts.AllowedHostData),
+		allowedLengthCheck("allowed_image_ids", abi.ImageIDSize, opts.AllowedImageIDs),
+		allowedLengthCheck("allowed_family_ids", abi.FamilyIDSize, opts.AllowedFamilyIDs),
+		allowedLengthCheck("allowed_chip_ids", abi.ChipIDSize, opts.AllowedChipIDs),
 		lengthCheck("family_id", abi.FamilyIDSize, opts.FamilyID),
 		lengthCheck("image_id", abi.ImageIDSize, opts.ImageID),
 		lengthCheck("report_data", abi.ReportDataSize, opts.ReportData),
@@ -141,6 +232,65 @@ func parseVersion(v string) (uint16, error) {
 	return (uint16(maj) << 8) | uint16(min), nil
 }
 
+// ParsePolicyTime parses an RFC 3339 time or a "YYYY-MM-DD" date in UTC. A date is its first
+// instant, or its last instant if endOfDay is true. The empty string is the zero time.
+func ParsePolicyTime(s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an RFC 3339 time nor a YYYY-MM-DD date", s)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return t, nil
+}
+
+func allowedValues(name string, values []*cpb.AllowedValue) ([]*AllowedValue, error) {
+	var result []*AllowedValue
+	for i, v := range values {
+		notBefore, err := ParsePolicyTime(v.GetNotBefore(), false)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s[%d].not_before: %v", name, i, err)
+		}
+		notAfter, err := ParsePolicyTime(v.GetNotAfter(), true)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s[%d].not_after: %v", name, i, err)
+		}
+		if !notBefore.IsZero() && !notAfter.IsZero() && notAfter.Before(notBefore) {
+			return nil, fmt.Errorf("%s[%d] has not_after %s before not_before %s", name, i,
+				v.GetNotAfter(), v.GetNotBefore())
+		}
+		result = append(result, &AllowedValue{Value: v.GetValue(), NotBefore: notBefore, NotAfter: notAfter})
+	}
+	return result, nil
+}
+
+func productTCBs(policy *cpb.Policy) (map[string]*ProductTCB, error) {
+	if len(policy.GetProductTcbs()) == 0 {
+		return nil, nil
+	}
+	result := make(map[string]*ProductTCB)
+	for _, p := range policy.GetProductTcbs() {
+		if _, err := kds.ProductLine(p.GetProduct()); err != nil {
+			return nil, fmt.Errorf("invalid product_tcbs product: %v", err)
+		}
+		if _, ok := result[p.GetProduct()]; ok {
+			return nil, fmt.Errorf("product_tcbs has product %q more than once", p.GetProduct())
+		}
+		result[p.GetProduct()] = &ProductTCB{
+			MinimumTCB:       kds.DecomposeTCBVersion(kds.TCBVersion(p.GetMinimumTcb())),
+			MinimumLaunchTCB: kds.DecomposeTCBVersion(kds.TCBVersion(p.GetMinimumLaunchTcb())),
+		}
+	}
+	return result, nil
+}
+
 // PolicyToOptions returns an Options object that is represented by a Policy message.
 func PolicyToOptions(policy *cpb.Policy) (*Options, error) {
 	guestPolicy, err := abi.ParseSnpPolicy(policy.GetPolicy())
@@ -199,6 +349,26 @@ func PolicyToOptions(policy *cpb.Policy) (*Options, error) {
 	if err != nil {
 		return nil, err
 	}
+	var measurements, hostData, imageIDs, familyIDs, chipIDs []*AllowedValue
+	for _, a := range []struct {
+		name   string
+		values []*cpb.AllowedValue
+		dest   *[]*AllowedValue
+	}{
+		{"allowed_measurements", policy.GetAllowedMeasurements(), &measurements},
+		{"allowed_host_data", policy.GetAllowedHostData(), &hostData},
+		{"allowed_image_ids", policy.GetAllowedImageIds(), &imageIDs},
+		{"allowed_family_ids", policy.GetAllowedFamilyIds(), &familyIDs},
+		{"allowed_chip_ids", policy.GetAllowedChipIds(), &chipIDs},
+	} {
+		if *a.dest, err = allowedValues(a.name, a.values); err != nil {
+			return nil, err
+		}
+	}
+	productMinimums, err := productTCBs(policy)
+	if err != nil {
+		return nil, err
+	}
 	opts := &Options{
 		MinimumGuestSvn:           policy.GetMinimumGuestSvn(),
 		GuestPolicy:               guestPolicy,
@@ -223,6 +393,12 @@ func PolicyToOptions(policy *cpb.Policy) (*Options, error) {
 		TrustedIDKeys:             idKeys,
 		TrustedIDKeyHashes:        policy.GetTrustedIdKeyHashes(),
 		VMPL:                      vmpl,
+		AllowedMeasurements:       measurements,
+		AllowedHostData:           hostData,
+		AllowedImageIDs:           imageIDs,
+		AllowedFamilyIDs:          familyIDs,
+		AllowedChipIDs:            chipIDs,
+		ProductTCBs:               productMinimums,
 	}
 	if err := checkOptionsLengths(opts); err != nil {
 		return nil, err
@@ -266,6 +442,36 @@ func validatePolicy(reportPolicy uint64, required abi.SnpPolicy) error {
 	return nil
 }
 
+// GuestPolicy returns an error if the guest policy allows what required does not, i.e., if an
+// attestation with that policy would fail a check against required.
+func GuestPolicy(policy uint64, required abi.SnpPolicy) error {
+	return validatePolicy(policy, required)
+}
+
+// policyExpectation describes the guest policies that validatePolicy accepts.
+func policyExpectation(required abi.SnpPolicy) string {
+	var allowed []string
+	if required.SMT {
+		allowed = append(allowed, "SMT")
+	}
+	if required.MigrateMA {
+		allowed = append(allowed, "migration agent")
+	}
+	if required.Debug {
+		allowed = append(allowed, "debug")
+	}
+	result := fmt.Sprintf("minimum ABI %d.%d", required.ABIMajor, required.ABIMinor)
+	if len(allowed) == 0 {
+		result += ", allows none of SMT, migration agent, debug"
+	} else {
+		result += ", allows " + strings.Join(allowed, ", ")
+	}
+	if required.SingleSocket {
+		result += ", requires single socket"
+	}
+	return result
+}
+
 func validateByteField(option, field string, size int, given, required []byte) error {
 	if len(required) == 0 {
 		return nil
@@ -280,43 +486,114 @@ func validateByteField(option, field string, size int, given, required []byte) e
 	return nil
 }
 
-func validateVerbatimFields(report *spb.Report, options *Options) error {
-	return multierr.Combine(
-		validateByteField("ReportData", "REPORT_DATA", abi.ReportDataSize, report.GetReportData(), options.ReportData),
-		validateByteField("HostData", "HOST_DATA", abi.HostDataSize, report.GetHostData(), options.HostData),
-		validateByteField("FamilyID", "FAMILY_ID", abi.FamilyIDSize, report.GetFamilyId(), options.FamilyID),
-		validateByteField("ImageID", "IMAGE_ID", abi.ImageIDSize, report.GetImageId(), options.ImageID),
-		validateByteField("ReportID", "REPORT_ID", abi.ReportIDSize, report.GetReportId(), options.ReportID),
-		validateByteField("ReportIDMA", "REPORT_ID_MA", abi.ReportIDMASize, report.GetReportIdMa(), options.ReportIDMA),
-		validateByteField("Measurement", "MEASUREMENT", abi.MeasurementSize, report.GetMeasurement(), options.Measurement),
-		validateByteField("ChipID", "CHIP_ID", abi.ChipIDSize, report.GetChipId(), options.ChipID),
-	)
-}
-
-func validateTcb(report *spb.Report, vcekTcb kds.TCBVersion, options *Options) error {
+// validateAllowedField checks that a report field is one of the allowed values at time now.
+func validateAllowedField(option, field string, size int, given []byte, allowed []*AllowedValue, now time.Time) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	var outOfTime error
+	for _, a := range allowed {
+		if len(a.Value) != size {
+			return fmt.Errorf("option %s values must be %d bytes", option, size)
+		}
+		if !bytes.Equal(a.Value, given) {
+			continue
+		}
+		if !a.NotBefore.IsZero() && now.Before(a.NotBefore) {
+			outOfTime = fmt.Errorf("report field %s is %s, which is not allowed before %s",
+				field, hex.EncodeToString(given), a.NotBefore.Format(time.RFC3339))
+			continue
+		}
+		if !a.NotAfter.IsZero() && now.After(a.NotAfter) {
+			outOfTime = fmt.Errorf("report field %s is %s, which was allowed only until %s",
+				field, hex.EncodeToString(given), a.NotAfter.Format(time.RFC3339))
+			continue
+		}
+		return nil
+	}
+	if outOfTime != nil {
+		return outOfTime
+	}
+	return fmt.Errorf("report field %s is %s, which is not an allowed value", field, hex.EncodeToString(given))
+}
+
+// verbatimField is a report field that is checked for exact equality with an option, and for
+// membership in a list of allowed values.
+type verbatimField struct {
+	check    string
+	option   string
+	field    string
+	size     int
+	given    []byte
+	required []byte
+	allowed  []*AllowedValue
+}
+
+func verbatimFields(report *spb.Report, options *Options) []verbatimField {
+	return []verbatimField{
+		{ReportDataCheck, "ReportData", "REPORT_DATA", abi.ReportDataSize, report.GetReportData(), options.ReportData, nil},
+		{HostDataCheck, "HostData", "HOST_DATA", abi.HostDataSize, report.GetHostData(), options.HostData, options.AllowedHostData},
+		{FamilyIDCheck, "FamilyID", "FAMILY_ID", abi.FamilyIDSize, report.GetFamilyId(), options.FamilyID, options.AllowedFamilyIDs},
+		{ImageIDCheck, "ImageID", "IMAGE_ID", abi.ImageIDSize, report.GetImageId(), options.ImageID, options.AllowedImageIDs},
+		{ReportIDCheck, "ReportID", "REPORT_ID", abi.ReportIDSize, report.GetReportId(), options.ReportID, nil},
+		{ReportIDMACheck, "ReportIDMA", "REPORT_ID_MA", abi.ReportIDMASize, report.GetReportIdMa(), options.ReportIDMA, nil},
+		{MeasurementCheck, "Measurement", "MEASUREMENT", abi.MeasurementSize, report.GetMeasurement(), options.Measurement, options.AllowedMeasurements},
+		{ChipIDCheck, "ChipID", "CHIP_ID", abi.ChipIDSize, report.GetChipId(), options.ChipID, options.AllowedChipIDs},
+	}
+}
+
+// expected returns a human-readable description of the values the field may have.
+func (f *verbatimField) expected() string {
+	var alternatives []string
+	for _, a := range f.allowed {
+		alternatives = append(alternatives, hex.EncodeToString(a.Value))
+	}
+	switch {
+	case len(f.allowed) == 0:
+		return hex.EncodeToString(f.required)
+	case len(f.required) == 0:
+		return fmt.Sprintf("one of [%s]", strings.Join(alternatives, ", "))
+	}
+	return fmt.Sprintf("%s and one of [%s]", hex.EncodeToString(f.required), strings.Join(alternatives, ", "))
+}
+
+func recordVerbatimFields(result *checks.Result, report *spb.Report, options *Options) {
+	now := options.now()
+	for _, f := range verbatimFields(report, options) {
+		if len(f.required) == 0 && len(f.allowed) == 0 {
+			result.Skip(f.check)
+			continue
+		}
+		result.Record(f.check, f.expected(), hex.EncodeToString(f.given), multierr.Append(
+			validateByteField(f.option, f.field, f.size, f.given, f.required),
+			validateAllowedField(f.option, f.field, f.size, f.given, f.allowed, now)))
+	}
+}
+
+func validateReportedTcb(report *spb.Report, vcekTcb kds.TCBVersion, key abi.ReportSigner) error {
 	// Any change to the TCB means that the VCEK certificate at an earlier TCB is no longer valid. The
 	// host must make sure that the up-to-date certificate is provisioned and delivered alongside the
 	// report that contains the new reported TCB value.
 	// If the certificate's TCB is greater than the report's TCB, then the host has not provisioned
 	// a certificate for the machine's actual state and should also not be accepted.
 	if kds.TCBVersion(report.GetReportedTcb()) != vcekTcb {
-		return fmt.Errorf("chip's VCEK TCB %x does not match the REPORTED_TCB %x",
-			vcekTcb, report.GetReportedTcb())
+		return fmt.Errorf("chip's %v TCB %x does not match the REPORTED_TCB %x",
+			key, vcekTcb, report.GetReportedTcb())
 	}
+	return nil
+}
+
+func validateCurrentTcb(report *spb.Report, vcekTcb kds.TCBVersion, key abi.ReportSigner, minimum kds.TCBParts, options *Options) error {
 	if !options.PermitProvisionalFirmware {
 		if kds.TCBVersion(report.GetCurrentTcb()) != vcekTcb {
-			return fmt.Errorf("chip's VCEK TCB %x does not match the CURRENT_TCB %x",
-				vcekTcb, report.GetReportedTcb())
-		}
-		if report.GetCurrentTcb() != report.GetCommittedTcb() {
-			return fmt.Errorf("firmware's committed TCB %x does not match the current TCB %x",
-				report.GetCommittedTcb(), report.GetCurrentTcb())
+			return fmt.Errorf("chip's %v TCB %x does not match the CURRENT_TCB %x",
+				key, vcekTcb, report.GetReportedTcb())
 		}
 	} else if kds.TCBVersion(report.GetCurrentTcb()) < vcekTcb {
-		return fmt.Errorf("firmware's current TCB %x is less than the TCB the VCEK is certified for %x",
-			report.GetCurrentTcb(), vcekTcb)
+		return fmt.Errorf("firmware's current TCB %x is less than the TCB the %v is certified for %x",
+			report.GetCurrentTcb(), key, vcekTcb)
 	}
-	min, err := kds.ComposeTCBParts(options.MinimumTCB)
+	min, err := kds.ComposeTCBParts(minimum)
 	if err != nil {
 		return fmt.Errorf("option MinimumTCB error: %v", err)
 	}
@@ -324,7 +601,24 @@ func validateTcb(report *spb.Report, vcekTcb kds.TCBVersion, options *Options) e
 		return fmt.Errorf("firmware's current TCB %x is less than required %x",
 			report.GetCurrentTcb(), min)
 	}
-	minLaunch, err := kds.ComposeTCBParts(options.MinimumLaunchTCB)
+	return nil
+}
+
+func validateCommittedTcb(report *spb.Report, options *Options) error {
+	if !options.PermitProvisionalFirmware && report.GetCurrentTcb() != report.GetCommittedTcb() {
+		return fmt.Errorf("firmware's committed TCB %x does not match the current TCB %x",
+			report.GetCommittedTcb(), report.GetCurrentTcb())
+	}
+	// The committed TCB means that a firmware installation cannot backslide before that number.
+	if report.GetCommittedTcb() > report.GetReportedTcb() {
+		return fmt.Errorf("report field COMMITTED_TCB %x is greater than its REPORTED_TCB %x",
+			report.GetLaunchTcb(), report.GetReportedTcb())
+	}
+	return nil
+}
+
+func validateLaunchTcb(report *spb.Report, minimum kds.TCBParts) error {
+	minLaunch, err := kds.ComposeTCBParts(minimum)
 	if err != nil {
 		return fmt.Errorf("option MinimumLaunchTCB error: %v", err)
 	}
@@ -343,11 +637,6 @@ func validateTcb(report *spb.Report, vcekTcb kds.TCBVersion, options *Options) e
 		return fmt.Errorf("report field LAUNCH_TCB %x is greater than its COMMITTED_TCB %x",
 			report.GetLaunchTcb(), report.GetCommittedTcb())
 	}
-	// The committed TCB means that a firmware installation cannot backslide before that number.
-	if report.GetCommittedTcb() > report.GetReportedTcb() {
-		return fmt.Errorf("report field COMMITTED_TCB %x is greater than its REPORTED_TCB %x",
-			report.GetLaunchTcb(), report.GetReportedTcb())
-	}
 	return nil
 }
 
@@ -416,22 +705,61 @@ func validatePlatformInfo(platformInfo uint64, required *abi.SnpPlatformInfo) er
 	return nil
 }
 
-func addKeyHashesFromCerts(hashes [][]byte, certs []*x509.Certificate) [][]byte {
+func validateCPUID(report *spb.Report, options *Options) error {
+	if options.CPUIDFamily == nil && options.CPUIDModel == nil && options.CPUIDStepping == nil {
+		return nil
+	}
+	if report.GetVersion() < abi.CPUIDReportVersion {
+		return fmt.Errorf("report version %d does not contain CPUID fields. Expect at least version %d",
+			report.GetVersion(), abi.CPUIDReportVersion)
+	}
+	check := func(field string, got uint32, want *uint8) error {
+		if want != nil && got != uint32(*want) {
+			return fmt.Errorf("report field %s is 0x%x. Expect 0x%x", field, got, *want)
+		}
+		return nil
+	}
+	return multierr.Combine(
+		check("CPUID_FAM_ID", report.GetCpuidFamId(), options.CPUIDFamily),
+		check("CPUID_MOD_ID", report.GetCpuidModId(), options.CPUIDModel),
+		check("CPUID_STEP", report.GetCpuidStep(), options.CPUIDStepping))
+}
+
+func validateMitigations(report *spb.Report, options *Options) error {
+	if options.RequiredLaunchMitVector == 0 && options.RequiredCurrentMitVector == 0 {
+		return nil
+	}
+	if report.GetVersion() < abi.MitigationReportVersion {
+		return fmt.Errorf("report version %d does not contain mitigation vectors. Expect at least version %d",
+			report.GetVersion(), abi.MitigationReportVersion)
+	}
+	check := func(field string, got, want uint64) error {
+		if missing := want &^ got; missing != 0 {
+			return fmt.Errorf("report field %s 0x%x is missing required mitigations 0x%x", field, got, missing)
+		}
+		return nil
+	}
+	return multierr.Combine(
+		check("LAUNCH_MIT_VECTOR", report.GetLaunchMitVector(), options.RequiredLaunchMitVector),
+		check("CURRENT_MIT_VECTOR", report.GetCurrentMitVector(), options.RequiredCurrentMitVector))
+}
+
+func addKeyHashesFromCerts(hashes [][]byte, certs []*x509.Certificate) ([][]byte, error) {
 	for _, c := range certs {
-		// Only add ECDSA P-384 keys
-		switch key := c.PublicKey.(type) {
-		case *ecdsa.PublicKey:
-			pubkey, err := abi.EcdsaPublicKeyToBytes(key)
-			if err != nil {
-				// Wrong key type.
-				continue
-			}
-			h := crypto.SHA384.New()
-			h.Write(pubkey)
-			hashes = append(hashes, h.Sum(nil))
+		// Only ECDSA P-384 keys can sign ID blocks.
+		key, ok := c.PublicKey.(*ecdsa.PublicKey)
+		if !ok {
+			return nil, fmt.Errorf("key of %q is a %T, not an ECDSA P-384 key", c.Subject, c.PublicKey)
+		}
+		pubkey, err := abi.EcdsaPublicKeyToBytes(key)
+		if err != nil {
+			return nil, fmt.Errorf("key of %q: %v", c.Subject, err)
 		}
+		h := crypto.SHA384.New()
+		h.Write(pubkey)
+		hashes = append(hashes, h.Sum(nil))
 	}
-	return hashes
+	return hashes, nil
 }
 
 func consolidateKeyHashes(options *Options) error {
@@ -452,15 +780,25 @@ func consolidateKeyHashes(options *Options) error {
 		return fmt.Errorf("bad hash size in TrustedAuthorKeyHashes: %v", err)
 	}
 
-	options.TrustedIDKeyHashes = addKeyHashesFromCerts(options.TrustedIDKeyHashes,
-		options.TrustedIDKeys)
-	options.TrustedAuthorKeyHashes = addKeyHashesFromCerts(options.TrustedAuthorKeyHashes,
-		options.TrustedAuthorKeys)
+	idKeyHashes, err := addKeyHashesFromCerts(options.TrustedIDKeyHashes, options.TrustedIDKeys)
+	if err != nil {
+		return fmt.Errorf("bad key in TrustedIDKeys: %v", err)
+	}
+	authorKeyHashes, err := addKeyHashesFromCerts(options.TrustedAuthorKeyHashes, options.TrustedAuthorKeys)
+	if err != nil {
+		return fmt.Errorf("bad key in TrustedAuthorKeys: %v", err)
+	}
+	options.TrustedIDKeyHashes = idKeyHashes
+	options.TrustedAuthorKeyHashes = authorKeyHashes
 	return nil
 }
 
 func validateKeys(report *spb.Report, options *Options) error {
-	if options.RequireAuthorKey && report.GetAuthorKeyEn() == 0 {
+	info, err := abi.ParseSignerInfo(report.GetSignerInfo())
+	if err != nil {
+		return err
+	}
+	if options.RequireAuthorKey && !info.AuthorKeyEn {
 		return errors.New("author key missing when required")
 	}
 
@@ -483,7 +821,7 @@ func validateKeys(report *spb.Report, options *Options) error {
 		return false
 	}
 
-	authorKeyTrusted := report.GetAuthorKeyEn() != 0 && bytesContained(options.TrustedAuthorKeyHashes,
+	authorKeyTrusted := info.AuthorKeyEn && bytesContained(options.TrustedAuthorKeyHashes,
 		report.GetAuthorKeyDigest())
 
 	if options.RequireAuthorKey && !authorKeyTrusted {
@@ -498,49 +836,229 @@ func validateKeys(report *spb.Report, options *Options) error {
 	return nil
 }
 
-func validateSnpAttestation(report *spb.Report, vcek []byte, options *Options) error {
-	vcekCert, err := x509.ParseCertificate(vcek)
+// IDBlock checks that the report is of a guest launched with the given ID block and ID
+// authentication information. The ID block's signatures must be valid, its launch digest,
+// FAMILY_ID, IMAGE_ID, GUEST_SVN, and policy must equal the report's, and the report's ID key and
+// author key digests must be of the keys in auth.
+func IDBlock(report *spb.Report, block *abi.IDBlock, auth *abi.IDAuthInfo) error {
+	if block == nil || auth == nil {
+		return errors.New("ID block and ID authentication information must both be provided")
+	}
+	info, err := abi.ParseSignerInfo(report.GetSignerInfo())
+	if err != nil {
+		return err
+	}
+	var errs error
+	mismatch := func(name string, got, want []byte) {
+		if !bytes.Equal(got, want) {
+			errs = multierr.Append(errs, fmt.Errorf("report field %s is %s. ID block expects %s", name,
+				hex.EncodeToString(got), hex.EncodeToString(want)))
+		}
+	}
+	mismatch("MEASUREMENT", report.GetMeasurement(), block.LD[:])
+	mismatch("FAMILY_ID", report.GetFamilyId(), block.FamilyID[:])
+	mismatch("IMAGE_ID", report.GetImageId(), block.ImageID[:])
+	if report.GetGuestSvn() != block.GuestSvn {
+		errs = multierr.Append(errs, fmt.Errorf("report GUEST_SVN is %d. ID block expects %d",
+			report.GetGuestSvn(), block.GuestSvn))
+	}
+	if report.GetPolicy() != block.Policy {
+		errs = multierr.Append(errs, fmt.Errorf("report POLICY is 0x%x. ID block expects 0x%x",
+			report.GetPolicy(), block.Policy))
+	}
+	mismatch("ID_KEY_DIGEST", report.GetIdKeyDigest(), auth.IDKeyDigest())
+	if info.AuthorKeyEn != auth.HasAuthorKey() {
+		errs = multierr.Append(errs, fmt.Errorf("report AUTHOR_KEY_EN is %v, but ID authentication information has an author key: %v",
+			info.AuthorKeyEn, auth.HasAuthorKey()))
+	} else if info.AuthorKeyEn {
+		mismatch("AUTHOR_KEY_DIGEST", report.GetAuthorKeyDigest(), auth.AuthorKeyDigest())
+	}
+	return multierr.Append(errs, abi.VerifyIDBlock(block, auth))
+}
+
+// Names of the checks that SnpAttestationResult records.
+const (
+	SignerInfoCheck      = "signer_info"
+	EndorsementKeyCheck  = "endorsement_key_certificate"
+	GuestSvnCheck        = "guest_svn"
+	PolicyCheck          = "policy"
+	ReportDataCheck      = "report_data"
+	HostDataCheck        = "host_data"
+	FamilyIDCheck        = "family_id"
+	ImageIDCheck         = "image_id"
+	ReportIDCheck        = "report_id"
+	ReportIDMACheck      = "report_id_ma"
+	MeasurementCheck     = "measurement"
+	ChipIDCheck          = "chip_id"
+	ReportedTcbCheck     = "reported_tcb"
+	CurrentTcbCheck      = "current_tcb"
+	CommittedTcbCheck    = "committed_tcb"
+	LaunchTcbCheck       = "launch_tcb"
+	FirmwareVersionCheck = "firmware_version"
+	PlatformInfoCheck    = "platform_info"
+	CPUIDCheck           = "cpuid"
+	MitigationsCheck     = "mitigations"
+	IDBlockCheck         = "id_block"
+	VmplCheck            = "vmpl"
+	EndorsementHWIDCheck = "endorsement_key_hwid"
+)
+
+// reportChecks are the checks that run once the endorsement key certificate is parsed.
+var reportChecks = []string{
+	GuestSvnCheck, PolicyCheck, ReportDataCheck, HostDataCheck, FamilyIDCheck, ImageIDCheck,
+	ReportIDCheck, ReportIDMACheck, MeasurementCheck, ChipIDCheck, ReportedTcbCheck,
+	CurrentTcbCheck, CommittedTcbCheck, LaunchTcbCheck, FirmwareVersionCheck, PlatformInfoCheck,
+	CPUIDCheck, MitigationsCheck, IDBlockCheck, VmplCheck, EndorsementHWIDCheck,
+}
+
+func validateSnpAttestation(report *spb.Report, ek []byte, options *Options) *checks.Result {
+	start := time.Now()
+	result := snpAttestationResult(report, ek, options)
+	observe.OrDefault(options.Observer).Outcome(context.Background(), &observe.Outcome{
+		Stage:    observe.StageValidate,
+		Result:   result,
+		Duration: time.Since(start),
+	})
+	return result
+}
+
+func snpAttestationResult(report *spb.Report, ek []byte, options *Options) *checks.Result {
+	result := &checks.Result{}
+	skip := func(names ...string) *checks.Result {
+		for _, name := range names {
+			result.Skip(name)
+		}
+		return result
+	}
+	info, err := abi.ParseSignerInfo(report.GetSignerInfo())
+	result.Record(SignerInfoCheck, "", fmt.Sprintf("0x%x", report.GetSignerInfo()), err)
+	if err != nil {
+		skip(EndorsementKeyCheck)
+		return skip(reportChecks...)
+	}
+	ekCert, err := x509.ParseCertificate(ek)
 	if err != nil {
-		return fmt.Errorf("could not parse VCEK certificate: %v", err)
+		result.Record(EndorsementKeyCheck, "", "", fmt.Errorf("could not parse %v certificate: %v", info.SigningKey, err))
+		return skip(reportChecks...)
 	}
-	// Get the TCB values of the VCEK
-	exts, err := kds.VcekCertificateExtensions(vcekCert)
+	// Get the TCB values of the VCEK or VLEK
+	exts, err := kds.CertificateExtensions(ekCert, info.SigningKey)
 	if err != nil {
-		return fmt.Errorf("could not get VCEK certificate extensions: %v", err)
+		result.Record(EndorsementKeyCheck, "", "", fmt.Errorf("could not get %v certificate extensions: %v", info.SigningKey, err))
+		return skip(reportChecks...)
 	}
+	result.Record(EndorsementKeyCheck, "", info.SigningKey.String(), nil)
 
+	var svnErr error
 	if report.GetGuestSvn() < options.MinimumGuestSvn {
-		return fmt.Errorf("report's GUEST_SVN %d is less than the required minimum %d",
+		svnErr = fmt.Errorf("report's GUEST_SVN %d is less than the required minimum %d",
 			report.GetGuestSvn(), options.MinimumGuestSvn)
 	}
+	result.Record(GuestSvnCheck, fmt.Sprintf(">= %d", options.MinimumGuestSvn), fmt.Sprintf("%d", report.GetGuestSvn()), svnErr)
+	result.Record(PolicyCheck, policyExpectation(options.GuestPolicy),
+		fmt.Sprintf("0x%x", report.GetPolicy()), validatePolicy(report.GetPolicy(), options.GuestPolicy))
+	recordVerbatimFields(result, report, options)
 
-	if err := multierr.Combine(
-		validatePolicy(report.GetPolicy(), options.GuestPolicy),
-		validateVerbatimFields(report, options),
-		validateTcb(report, exts.TCBVersion, options),
-		validateVersion(report, options),
-		validatePlatformInfo(report.GetPlatformInfo(), options.PlatformInfo),
-		validateKeys(report, options)); err != nil {
-		return err
+	tcb := func(v uint64) string { return fmt.Sprintf("0x%x", v) }
+	// The product is only needed to choose product-specific minimum TCBs.
+	product, productErr := kds.ProductLine(exts.ProductName)
+	if productErr != nil && len(options.ProductTCBs) > 0 {
+		productErr = fmt.Errorf("could not choose the product's minimum TCBs: %v", productErr)
+	} else {
+		productErr = nil
 	}
+	minTcbParts, minLaunchTcbParts := options.minimumTCBs(product)
+	minTcb, _ := kds.ComposeTCBParts(minTcbParts)
+	minLaunchTcb, _ := kds.ComposeTCBParts(minLaunchTcbParts)
+	result.Record(ReportedTcbCheck, tcb(uint64(exts.TCBVersion)), tcb(report.GetReportedTcb()),
+		validateReportedTcb(report, exts.TCBVersion, info.SigningKey))
+	result.Record(CurrentTcbCheck, fmt.Sprintf(">= %s", tcb(uint64(minTcb))), tcb(report.GetCurrentTcb()),
+		multierr.Append(productErr, validateCurrentTcb(report, exts.TCBVersion, info.SigningKey, minTcbParts, options)))
+	result.Record(CommittedTcbCheck, "", tcb(report.GetCommittedTcb()), validateCommittedTcb(report, options))
+	result.Record(LaunchTcbCheck, fmt.Sprintf(">= %s", tcb(uint64(minLaunchTcb))), tcb(report.GetLaunchTcb()),
+		multierr.Append(productErr, validateLaunchTcb(report, minLaunchTcbParts)))
+	result.Record(FirmwareVersionCheck,
+		fmt.Sprintf(">= %d.%d build %d", options.MinimumVersion>>8, options.MinimumVersion&0xff, options.MinimumBuild),
+		fmt.Sprintf("%d.%d build %d", report.GetCurrentMajor(), report.GetCurrentMinor(), report.GetCurrentBuild()),
+		validateVersion(report, options))
 
-	if options.VMPL != nil && uint32(*options.VMPL) != report.GetVmpl() {
-		return fmt.Errorf("report VMPL %d is not %d", report.GetVmpl(), *options.VMPL)
+	if options.PlatformInfo == nil {
+		result.Skip(PlatformInfoCheck)
+	} else {
+		result.Record(PlatformInfoCheck, fmt.Sprintf("<= %+v", *options.PlatformInfo), fmt.Sprintf("0x%x", report.GetPlatformInfo()),
+			validatePlatformInfo(report.GetPlatformInfo(), options.PlatformInfo))
+	}
+	if options.CPUIDFamily == nil && options.CPUIDModel == nil && options.CPUIDStepping == nil {
+		result.Skip(CPUIDCheck)
+	} else {
+		result.Record(CPUIDCheck, "",
+			fmt.Sprintf("family 0x%x model 0x%x stepping 0x%x", report.GetCpuidFamId(), report.GetCpuidModId(), report.GetCpuidStep()),
+			validateCPUID(report, options))
+	}
+	if options.RequiredLaunchMitVector == 0 && options.RequiredCurrentMitVector == 0 {
+		result.Skip(MitigationsCheck)
+	} else {
+		result.Record(MitigationsCheck,
+			fmt.Sprintf("launch 0x%x current 0x%x", options.RequiredLaunchMitVector, options.RequiredCurrentMitVector),
+			fmt.Sprintf("launch 0x%x current 0x%x", report.GetLaunchMitVector(), report.GetCurrentMitVector()),
+			validateMitigations(report, options))
+	}
+	if !options.RequireAuthorKey && !options.RequireIDBlock && options.IDBlock == nil {
+		// The trusted keys are unused, but must still be well-formed.
+		if err := consolidateKeyHashes(options); err != nil {
+			result.Record(IDBlockCheck, "", "", err)
+		} else {
+			result.Skip(IDBlockCheck)
+		}
+	} else {
+		err := validateKeys(report, options)
+		if options.IDBlock != nil {
+			err = multierr.Append(err, IDBlock(report, options.IDBlock, options.IDAuthInfo))
+		}
+		result.Record(IDBlockCheck, "", fmt.Sprintf("id key %s author key %s",
+			hex.EncodeToString(report.GetIdKeyDigest()), hex.EncodeToString(report.GetAuthorKeyDigest())), err)
+	}
+
+	if options.VMPL == nil {
+		result.Skip(VmplCheck)
+	} else {
+		var vmplErr error
+		if uint32(*options.VMPL) != report.GetVmpl() {
+			vmplErr = fmt.Errorf("report VMPL %d is not %d", report.GetVmpl(), *options.VMPL)
+		}
+		result.Record(VmplCheck, fmt.Sprintf("%d", *options.VMPL), fmt.Sprintf("%d", report.GetVmpl()), vmplErr)
 	}
 
 	// MaskChipId might be 1 for the host, so only check if the the CHIP_ID is not all zeros.
-	if !allZero(report.GetChipId()) && !bytes.Equal(report.GetChipId(), exts.HWID[:]) {
-		return fmt.Errorf("report field CHIP_ID %s is not the same as the VCEK certificate's HWID %s",
-			hex.EncodeToString(report.GetChipId()), hex.EncodeToString(exts.HWID[:]))
+	// The VLEK is not specific to a chip, so it has no HWID to compare against.
+	if info.SigningKey != abi.VcekReportSigner || allZero(report.GetChipId()) {
+		result.Skip(EndorsementHWIDCheck)
+	} else {
+		var hwidErr error
+		if !bytes.Equal(report.GetChipId(), exts.HWID[:]) {
+			hwidErr = fmt.Errorf("report field CHIP_ID %s is not the same as the VCEK certificate's HWID %s",
+				hex.EncodeToString(report.GetChipId()), hex.EncodeToString(exts.HWID[:]))
+		}
+		result.Record(EndorsementHWIDCheck, hex.EncodeToString(exts.HWID[:]), hex.EncodeToString(report.GetChipId()), hwidErr)
 	}
-	return nil
+	return result
+}
+
+// SnpAttestationResult validates fields of the protobuf representation of an attestation report
+// against expectations and records the outcome of each check. Does not check the attestation
+// certificates or signature.
+func SnpAttestationResult(attestation *spb.Attestation, options *Options) *checks.Result {
+	ek := attestation.GetCertificateChain().GetVcekCert()
+	if info, err := abi.ParseSignerInfo(attestation.GetReport().GetSignerInfo()); err == nil && info.SigningKey == abi.VlekReportSigner {
+		ek = attestation.GetCertificateChain().GetVlekCert()
+	}
+	return validateSnpAttestation(attestation.GetReport(), ek, options)
 }
 
 // SnpAttestation validates fields of the protobuf representation of an attestation report against
 // expectations. Does not check the attestation certificates or signature.
 func SnpAttestation(attestation *spb.Attestation, options *Options) error {
-	return validateSnpAttestation(attestation.GetReport(),
-		attestation.GetCertificateChain().GetVcekCert(), options)
+	return SnpAttestationResult(attestation, options).Err()
 }
 
 // RawSnpAttestation validates fields of a raw attestation report against expectations. Does not
@@ -551,14 +1069,21 @@ func RawSnpAttestation(report []byte, certTable []byte, options *Options) error
 		return fmt.Errorf("could not unmarshal SNP certificate table: %v", err)
 	}
 
-	vcek, err := certs.GetByGUIDString(abi.VcekGUID)
-	if err != nil {
-		return fmt.Errorf("could not get VCEK certificate: %v", err)
-	}
-
 	proto, err := abi.ReportToProto(report)
 	if err != nil {
 		return fmt.Errorf("could not parse attestation report: %v", err)
 	}
-	return validateSnpAttestation(proto, vcek, options)
+	info, err := abi.ParseSignerInfo(proto.GetSignerInfo())
+	if err != nil {
+		return err
+	}
+	guid := abi.VcekGUID
+	if info.SigningKey == abi.VlekReportSigner {
+		guid = abi.VlekGUID
+	}
+	ek, err := certs.GetByGUIDString(guid)
+	if err != nil {
+		return fmt.Errorf("could not get %v certificate: %v", info.SigningKey, err)
+	}
+	return validateSnpAttestation(proto, ek, options).Err()
 }
@@ -16,21 +16,31 @@
 package validate
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	_ "embed"
-	"encoding/pem"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
 	sg "github.com/google/go-sev-guest/client"
 	labi "github.com/google/go-sev-guest/client/linuxabi"
 	"github.com/google/go-sev-guest/kds"
 	test "github.com/google/go-sev-guest/testing"
 	"github.com/google/go-sev-guest/verify"
 	"go.uber.org/multierr"
+	"google.golang.org/protobuf/encoding/prototext"
 
+	cpb "github.com/google/go-sev-guest/proto/check"
 	spb "github.com/google/go-sev-guest/proto/sevsnp"
 )
 
@@ -100,7 +110,7 @@ func TestValidateSnpAttestation(t *testing.T) {
 			ReportId:        reportID,
 			ReportIdMa:      reportIDMA,
 			ChipId:          chipID[:],
-			AuthorKeyEn:     opts.authorKeyEn,
+			SignerInfo:      opts.authorKeyEn,
 			CommittedBuild:  uint32(opts.committedBuild),
 			CommittedMajor:  uint32(opts.committedMajor),
 			CommittedMinor:  uint32(opts.committedMinor),
@@ -155,9 +165,7 @@ func TestValidateSnpAttestation(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	rootBytes := append(
-		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sign.Ask.Raw}),
-		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: sign.Ark.Raw})...)
+	rootBytes := test.ProductRootsPEM(sign)
 
 	opts := &test.DeviceOptions{
 		Signer: sign,
@@ -246,7 +254,7 @@ func TestValidateSnpAttestation(t *testing.T) {
 		if err != nil {
 			t.Fatal(err)
 		}
-		attestation, err := verify.GetAttestationFromReport(report, &verify.Options{Getter: getter})
+		attestation, err := verify.GetAttestationFromReport(report, &verify.Options{Getter: getter, Product: "Milan"})
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -465,3 +473,395 @@ func TestValidateSnpAttestation(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateCPUIDAndMitigations(t *testing.T) {
+	family := uint8(0x19)
+	model := uint8(0x11)
+	v2 := &spb.Report{Version: 2}
+	v5 := &spb.Report{Version: 5, CpuidFamId: 0x19, CpuidModId: 0x10, LaunchMitVector: 0x3, CurrentMitVector: 0x1}
+	tests := []struct {
+		name    string
+		report  *spb.Report
+		opts    *Options
+		wantErr string
+	}{
+		{
+			name:   "no expectations",
+			report: v2,
+			opts:   &Options{},
+		},
+		{
+			name:    "v2 CPUID",
+			report:  v2,
+			opts:    &Options{CPUIDFamily: &family},
+			wantErr: "report version 2 does not contain CPUID fields. Expect at least version 3",
+		},
+		{
+			name:   "family match",
+			report: v5,
+			opts:   &Options{CPUIDFamily: &family},
+		},
+		{
+			name:    "model mismatch",
+			report:  v5,
+			opts:    &Options{CPUIDFamily: &family, CPUIDModel: &model},
+			wantErr: "report field CPUID_MOD_ID is 0x10. Expect 0x11",
+		},
+		{
+			name:    "v2 mitigations",
+			report:  v2,
+			opts:    &Options{RequiredCurrentMitVector: 1},
+			wantErr: "report version 2 does not contain mitigation vectors. Expect at least version 5",
+		},
+		{
+			name:   "mitigations present",
+			report: v5,
+			opts:   &Options{RequiredLaunchMitVector: 0x2, RequiredCurrentMitVector: 0x1},
+		},
+		{
+			name:    "mitigations missing",
+			report:  v5,
+			opts:    &Options{RequiredCurrentMitVector: 0x3},
+			wantErr: "report field CURRENT_MIT_VECTOR 0x1 is missing required mitigations 0x2",
+		},
+	}
+	for _, tc := range tests {
+		err := multierr.Combine(validateCPUID(tc.report, tc.opts), validateMitigations(tc.report, tc.opts))
+		if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+			t.Errorf("%s: validate(%v) = %v. Want error %q", tc.name, tc.report, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSnpAttestationResult(t *testing.T) {
+	sign, err := test.DefaultCertChain("Milan", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	measurement := make([]byte, abi.MeasurementSize)
+	measurement[0] = 1
+	attestation := &spb.Attestation{
+		Report: &spb.Report{
+			Version:     snpReportVersion,
+			Policy:      debugPolicy,
+			Measurement: make([]byte, abi.MeasurementSize),
+			ChipId:      make([]byte, abi.ChipIDSize),
+			Vmpl:        1,
+		},
+		CertificateChain: &spb.CertificateChain{VcekCert: sign.Vcek.Raw},
+	}
+	vmpl := 1
+	opts := &Options{
+		GuestPolicy: abi.SnpPolicy{Debug: true},
+		Measurement: measurement,
+		VMPL:        &vmpl,
+	}
+	result := SnpAttestationResult(attestation, opts)
+	wantStatus := map[string]checks.Status{
+		SignerInfoCheck:      checks.Passed,
+		EndorsementKeyCheck:  checks.Passed,
+		PolicyCheck:          checks.Passed,
+		ReportDataCheck:      checks.Skipped,
+		MeasurementCheck:     checks.Failed,
+		CurrentTcbCheck:      checks.Passed,
+		IDBlockCheck:         checks.Skipped,
+		VmplCheck:            checks.Passed,
+		EndorsementHWIDCheck: checks.Skipped,
+	}
+	for name, want := range wantStatus {
+		got := result.Get(name)
+		if got == nil {
+			t.Errorf("SnpAttestationResult() has no %q check", name)
+			continue
+		}
+		if got.Status != want {
+			t.Errorf("SnpAttestationResult() check %q status = %v, want %v (error %v)", name, got.Status, want, got.Err)
+		}
+	}
+	if got := result.Get(MeasurementCheck); got != nil && (got.Expected != hex.EncodeToString(measurement) ||
+		got.Actual != hex.EncodeToString(attestation.Report.Measurement)) {
+		t.Errorf("measurement check = %+v, want expected %x and actual %x", got, measurement, attestation.Report.Measurement)
+	}
+	if len(result.Failures()) != 1 {
+		t.Errorf("SnpAttestationResult() failures = %v, want only %q", result.Failures(), MeasurementCheck)
+	}
+	wantErr := "report field MEASUREMENT"
+	if err := SnpAttestation(attestation, opts); err == nil || !strings.Contains(err.Error(), wantErr) {
+		t.Errorf("SnpAttestation() = %v, want error %q", err, wantErr)
+	}
+	if got, want := result.Get(PolicyCheck).Expected, "minimum ABI 0.0, allows debug"; got != want {
+		t.Errorf("policy check expected %q, want %q", got, want)
+	}
+
+	// Trusted keys are checked even when no ID block is required.
+	for _, badKeys := range []*Options{
+		{TrustedAuthorKeys: []*x509.Certificate{sign.Ark}},
+		{TrustedIDKeyHashes: [][]byte{{1, 2, 3}}},
+	} {
+		badKeys.GuestPolicy = opts.GuestPolicy
+		if got := SnpAttestationResult(attestation, badKeys).Get(IDBlockCheck); got == nil || got.Status != checks.Failed {
+			t.Errorf("SnpAttestationResult(%+v) ID block check = %+v, want failed", badKeys, got)
+		}
+	}
+}
+
+func TestIDBlock(t *testing.T) {
+	idKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	authorKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := &abi.IDBlock{Version: abi.IDBlockVersion, GuestSvn: 2, Policy: debugPolicy}
+	block.LD[0] = 0x11
+	block.FamilyID[0] = 0x22
+	block.ImageID[0] = 0x33
+	idOnly, err := abi.SignIDBlock(block, idKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withAuthor, err := abi.SignIDBlock(block, idKey, authorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newReport := func(auth *abi.IDAuthInfo) *spb.Report {
+		report := &spb.Report{
+			Measurement:     block.LD[:],
+			FamilyId:        block.FamilyID[:],
+			ImageId:         block.ImageID[:],
+			GuestSvn:        block.GuestSvn,
+			Policy:          block.Policy,
+			IdKeyDigest:     auth.IDKeyDigest(),
+			AuthorKeyDigest: make([]byte, abi.AuthorKeyDigestSize),
+		}
+		if auth.HasAuthorKey() {
+			report.SignerInfo = abi.ComposeSignerInfo(abi.SignerInfo{AuthorKeyEn: true})
+			report.AuthorKeyDigest = auth.AuthorKeyDigest()
+		}
+		return report
+	}
+	tcs := []struct {
+		name    string
+		report  func() *spb.Report
+		auth    *abi.IDAuthInfo
+		wantErr string
+	}{
+		{name: "id key", report: func() *spb.Report { return newReport(idOnly) }, auth: idOnly},
+		{name: "author key", report: func() *spb.Report { return newReport(withAuthor) }, auth: withAuthor},
+		{
+			name: "measurement",
+			report: func() *spb.Report {
+				r := newReport(idOnly)
+				r.Measurement = make([]byte, abi.MeasurementSize)
+				return r
+			},
+			auth:    idOnly,
+			wantErr: "report field MEASUREMENT",
+		},
+		{
+			name: "policy and svn",
+			report: func() *spb.Report {
+				r := newReport(idOnly)
+				r.Policy = 0x30000
+				r.GuestSvn = 1
+				return r
+			},
+			auth:    idOnly,
+			wantErr: "report GUEST_SVN is 1. ID block expects 2; report POLICY is 0x30000",
+		},
+		{
+			name:    "other id key",
+			report:  func() *spb.Report { return newReport(idOnly) },
+			auth:    withAuthor,
+			wantErr: "AUTHOR_KEY_EN is false",
+		},
+		{
+			name:    "missing auth",
+			report:  func() *spb.Report { return newReport(idOnly) },
+			wantErr: "must both be provided",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			err := IDBlock(tc.report(), block, tc.auth)
+			if (err == nil) != (tc.wantErr == "") || (err != nil && !strings.Contains(err.Error(), tc.wantErr)) {
+				t.Errorf("IDBlock() = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func readPolicy(t *testing.T, name string) *Options {
+	t.Helper()
+	text, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	policy := &cpb.Policy{}
+	if err := prototext.Unmarshal(text, policy); err != nil {
+		t.Fatalf("could not parse %s: %v", name, err)
+	}
+	opts, err := PolicyToOptions(policy)
+	if err != nil {
+		t.Fatalf("PolicyToOptions(%s) = _, %v", name, err)
+	}
+	return opts
+}
+
+func TestAllowedValues(t *testing.T) {
+	sign, err := test.DefaultCertChain("Milan", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bytesOf := func(b byte, size int) []byte { return bytes.Repeat([]byte{b}, size) }
+	hostData := append([]byte{0x0a}, make([]byte, abi.HostDataSize-1)...)
+	day := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 12, 0, 0, 0, time.UTC)
+	}
+	tcs := []struct {
+		name        string
+		measurement []byte
+		hostData    []byte
+		now         time.Time
+		wantErr     string
+	}{
+		{name: "old image", measurement: bytesOf(1, 48), hostData: hostData, now: day(2026, time.July, 1)},
+		{name: "old image last day", measurement: bytesOf(1, 48), hostData: hostData, now: day(2026, time.December, 31)},
+		{
+			name:        "old image expired",
+			measurement: bytesOf(1, 48),
+			hostData:    hostData,
+			now:         day(2027, time.January, 1),
+			wantErr:     "which was allowed only until 2026-12-31T23:59:59Z",
+		},
+		{
+			name:        "new image too early",
+			measurement: bytesOf(2, 48),
+			hostData:    hostData,
+			now:         day(2026, time.May, 31),
+			wantErr:     "which is not allowed before 2026-06-01T00:00:00Z",
+		},
+		{name: "new image", measurement: bytesOf(2, 48), hostData: hostData, now: day(2027, time.January, 1)},
+		{
+			name:        "unknown image",
+			measurement: bytesOf(3, 48),
+			hostData:    hostData,
+			now:         day(2026, time.July, 1),
+			wantErr:     "report field MEASUREMENT is 030303",
+		},
+		{
+			name:        "unknown host data",
+			measurement: bytesOf(2, 48),
+			hostData:    bytesOf(0xb, abi.HostDataSize),
+			now:         day(2026, time.July, 1),
+			wantErr:     "report field HOST_DATA is 0b0b",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := readPolicy(t, "rollout.textproto")
+			opts.Now = tc.now
+			attestation := &spb.Attestation{
+				Report: &spb.Report{
+					Version:     snpReportVersion,
+					Policy:      debugPolicy,
+					Measurement: tc.measurement,
+					HostData:    tc.hostData,
+					ChipId:      make([]byte, abi.ChipIDSize),
+				},
+				CertificateChain: &spb.CertificateChain{VcekCert: sign.Vcek.Raw},
+			}
+			err := SnpAttestation(attestation, opts)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Errorf("SnpAttestation() = %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestProductTCBs(t *testing.T) {
+	tcs := []struct {
+		product string
+		wantErr string
+	}{
+		{product: "Milan"},
+		{product: "Genoa", wantErr: "firmware's current TCB 0 is less than required ff00000000000000"},
+		{product: "Naples", wantErr: "could not choose the product's minimum TCBs: unknown product name: Naples"},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.product, func(t *testing.T) {
+			sign, err := test.DefaultCertChain(tc.product, time.Now())
+			if err != nil {
+				t.Fatal(err)
+			}
+			attestation := &spb.Attestation{
+				Report: &spb.Report{
+					Version:     snpReportVersion,
+					Policy:      debugPolicy,
+					Measurement: make([]byte, abi.MeasurementSize),
+					ChipId:      make([]byte, abi.ChipIDSize),
+				},
+				CertificateChain: &spb.CertificateChain{VcekCert: sign.Vcek.Raw},
+			}
+			err = SnpAttestation(attestation, readPolicy(t, "product_tcbs.textproto"))
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Errorf("SnpAttestation() = %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPolicyToOptionsAllowlists(t *testing.T) {
+	measurement := make([]byte, abi.MeasurementSize)
+	tcs := []struct {
+		name    string
+		policy  *cpb.Policy
+		wantErr string
+	}{
+		{
+			name: "dates",
+			policy: &cpb.Policy{AllowedMeasurements: []*cpb.AllowedValue{
+				{Value: measurement, NotBefore: "2026-01-01", NotAfter: "2026-12-31T00:00:00Z"},
+			}},
+		},
+		{
+			name:    "bad date",
+			policy:  &cpb.Policy{AllowedMeasurements: []*cpb.AllowedValue{{Value: measurement, NotAfter: "31/12/2026"}}},
+			wantErr: "invalid allowed_measurements[0].not_after",
+		},
+		{
+			name: "inverted dates",
+			policy: &cpb.Policy{AllowedHostData: []*cpb.AllowedValue{
+				{Value: make([]byte, abi.HostDataSize), NotBefore: "2026-12-31", NotAfter: "2026-01-01"},
+			}},
+			wantErr: "allowed_host_data[0] has not_after 2026-01-01 before not_before 2026-12-31",
+		},
+		{
+			name:    "bad length",
+			policy:  &cpb.Policy{AllowedChipIds: []*cpb.AllowedValue{{Value: measurement}}},
+			wantErr: `option "allowed_chip_ids" has a value of length 48. Want 64`,
+		},
+		{
+			name:    "unknown product",
+			policy:  &cpb.Policy{ProductTcbs: []*cpb.ProductTcb{{Product: "Naples"}}},
+			wantErr: "invalid product_tcbs product",
+		},
+		{
+			name:    "duplicate product",
+			policy:  &cpb.Policy{ProductTcbs: []*cpb.ProductTcb{{Product: "Milan"}, {Product: "Milan"}}},
+			wantErr: `product_tcbs has product "Milan" more than once`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.policy.Policy = debugPolicy
+			tc.policy.MinimumVersion = "0.0"
+			_, err := PolicyToOptions(tc.policy)
+			if (err == nil && tc.wantErr != "") || (err != nil && (tc.wantErr == "" || !strings.Contains(err.Error(), tc.wantErr))) {
+				t.Errorf("PolicyToOptions() = _, %v. Want error %q", err, tc.wantErr)
+			}
+		})
+	}
+}
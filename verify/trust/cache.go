@@ -0,0 +1,306 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trust
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/observe"
+	kpb "github.com/google/go-sev-guest/proto/fakekds"
+	"github.com/google/logger"
+)
+
+// DefaultCacheTTL is how long a cached response without an expiry of its own is served when
+// CachingHTTPSGetter.TTL is zero.
+const DefaultCacheTTL = 24 * time.Hour
+
+// ErrCacheMiss is returned by an offline CachingHTTPSGetter when it has no unexpired entry for a
+// URL.
+var ErrCacheMiss = errors.New("no unexpired KDS cache entry")
+
+// entryKind is the kind of KDS response a cache entry holds, which determines its expiry.
+type entryKind int
+
+const (
+	otherEntry entryKind = iota
+	vcekEntry
+	certChainEntry
+	crlEntry
+)
+
+// CachingHTTPSGetter is an HTTPSGetter that keeps AMD KDS responses in a directory so that they
+// survive process restarts. VCEK certificates are keyed by product, chip ID, and TCB version,
+// certificate chains and CRLs by product and endorsement key kind. A certificate entry expires with
+// the earliest NotAfter of its certificates, and a CRL entry at its NextUpdate time.
+type CachingHTTPSGetter struct {
+	// Dir is the directory the cache is stored in. It is created if it does not exist.
+	Dir string
+	// Getter fetches missing or expired entries. If nil, uses DefaultHTTPSGetter().
+	Getter HTTPSGetter
+	// Offline disallows fetching. A missing or expired entry is an error wrapping ErrCacheMiss.
+	Offline bool
+	// TTL bounds how long after it was stored an entry is served, in addition to the entry's own
+	// expiry. If zero, entries with an expiry of their own are served until it, and other entries
+	// are served for DefaultCacheTTL.
+	TTL time.Duration
+	// Now returns the current time. If nil, uses time.Now.
+	Now func() time.Time
+	// Observer receives an event for each lookup. If nil, uses observe.Default().
+	Observer observe.Observer
+}
+
+func (c *CachingHTTPSGetter) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+func (c *CachingHTTPSGetter) getter() HTTPSGetter {
+	if c.Getter == nil {
+		return DefaultHTTPSGetter()
+	}
+	return c.Getter
+}
+
+// safePathElement returns whether s can be used as a single path element within the cache.
+func safePathElement(s string) bool {
+	if s == "" || s == "." || s == ".." {
+		return false
+	}
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+func keyDir(key abi.ReportSigner) string {
+	if key == abi.VlekReportSigner {
+		return "vlek"
+	}
+	return "vcek"
+}
+
+// urlClass returns the class of the URLs whose responses are entries of kind k.
+func (k entryKind) urlClass() observe.URLClass {
+	switch k {
+	case vcekEntry:
+		return observe.VcekURL
+	case certChainEntry:
+		return observe.CertChainURL
+	case crlEntry:
+		return observe.CrlURL
+	}
+	return observe.OtherURL
+}
+
+// kdsURLClass returns the class of the AMD KDS resource that url names.
+func kdsURLClass(url string) observe.URLClass {
+	_, kind := cacheEntry(url)
+	return kind.urlClass()
+}
+
+// cacheEntry returns the path relative to the cache directory that holds the response for url,
+// and the kind of response it is.
+func cacheEntry(url string) (string, entryKind) {
+	if vcek, err := kds.ParseVCEKCertURL(url); err == nil && safePathElement(vcek.Product) {
+		return filepath.Join("vcek", vcek.Product, hex.EncodeToString(vcek.HWID), fmt.Sprintf("%016x.der", vcek.TCB)), vcekEntry
+	}
+	if product, err := kds.ParseProductCertChainURL(url); err == nil && safePathElement(product) {
+		return filepath.Join(keyDir(abi.VcekReportSigner), product, "cert_chain.pem"), certChainEntry
+	}
+	if product, err := kds.ParseVlekProductCertChainURL(url); err == nil && safePathElement(product) {
+		return filepath.Join(keyDir(abi.VlekReportSigner), product, "cert_chain.pem"), certChainEntry
+	}
+	if product, key, err := kds.ParseCrlURL(url); err == nil && safePathElement(product) {
+		return filepath.Join(keyDir(key), product, "crl.der"), crlEntry
+	}
+	digest := sha256.Sum256([]byte(url))
+	return filepath.Join("other", hex.EncodeToString(digest[:])), otherEntry
+}
+
+// entryExpiry returns the time at which a response of the given kind stops being valid, or the
+// zero time if the response has no expiry of its own.
+func entryExpiry(kind entryKind, body []byte) (time.Time, error) {
+	switch kind {
+	case vcekEntry:
+		cert, err := x509.ParseCertificate(body)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not parse VCEK certificate: %v", err)
+		}
+		return cert.NotAfter, nil
+	case certChainEntry:
+		ask, ark, err := kds.ParseProductCertChain(body)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not parse cert_chain: %v", err)
+		}
+		var expiry time.Time
+		for _, der := range [][]byte{ask, ark} {
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return time.Time{}, fmt.Errorf("could not parse cert_chain certificate: %v", err)
+			}
+			if expiry.IsZero() || cert.NotAfter.Before(expiry) {
+				expiry = cert.NotAfter
+			}
+		}
+		return expiry, nil
+	case crlEntry:
+		crl, err := x509.ParseRevocationList(body)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("could not parse CRL: %v", err)
+		}
+		return crl.NextUpdate, nil
+	}
+	return time.Time{}, nil
+}
+
+// fresh returns an error if a response of the given kind stored at the given time should no
+// longer be served.
+func (c *CachingHTTPSGetter) fresh(kind entryKind, body []byte, stored time.Time) error {
+	now := c.now()
+	expiry, err := entryExpiry(kind, body)
+	if err != nil {
+		return err
+	}
+	if !expiry.IsZero() && !now.Before(expiry) {
+		return fmt.Errorf("entry expired at %v", expiry)
+	}
+	ttl := c.TTL
+	if ttl == 0 && expiry.IsZero() {
+		ttl = DefaultCacheTTL
+	}
+	if ttl != 0 && !now.Before(stored.Add(ttl)) {
+		return fmt.Errorf("entry stored at %v is older than %v", stored, ttl)
+	}
+	return nil
+}
+
+// read returns the cached response at the given relative path if it should still be served.
+func (c *CachingHTTPSGetter) read(name string, kind entryKind) ([]byte, error) {
+	path := filepath.Join(c.Dir, name)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	body, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.fresh(kind, body, info.ModTime()); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// write atomically stores body at the given relative path with the current time as its
+// modification time.
+func (c *CachingHTTPSGetter) write(name string, body []byte) error {
+	path := filepath.Join(c.Dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	tmp := f.Name()
+	_, err = f.Write(body)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		now := c.now()
+		err = os.Chtimes(tmp, now, now)
+	}
+	if err == nil {
+		err = os.Rename(tmp, path)
+	}
+	if err != nil {
+		os.Remove(tmp)
+	}
+	return err
+}
+
+// Put stores body in the cache as the response for url. It is an error to store a response that
+// cannot be parsed as the kind of response url refers to or that has already expired.
+func (c *CachingHTTPSGetter) Put(url string, body []byte) error {
+	name, kind := cacheEntry(url)
+	if err := c.fresh(kind, body, c.now()); err != nil {
+		return fmt.Errorf("will not cache response for %q: %v", url, err)
+	}
+	if err := c.write(name, body); err != nil {
+		return fmt.Errorf("could not cache response for %q: %v", url, err)
+	}
+	return nil
+}
+
+// Seed stores every VCEK certificate in the database in the cache as certificates of the given
+// product.
+func (c *CachingHTTPSGetter) Seed(product string, database *kpb.Certificates) error {
+	for _, chip := range database.GetChipCerts() {
+		for tcb, cert := range chip.GetTcbCerts() {
+			if err := c.Put(kds.VCEKCertURL(product, chip.GetChipId(), kds.TCBVersion(tcb)), cert); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Get returns the cached response for url if it has not expired. Otherwise, unless the getter is
+// offline, it fetches the response and caches it if it can be parsed and has not expired.
+func (c *CachingHTTPSGetter) Get(url string) ([]byte, error) {
+	return c.GetContext(context.Background(), url)
+}
+
+// GetContext is Get, but a fetch on a cache miss is bound to ctx.
+func (c *CachingHTTPSGetter) GetContext(ctx context.Context, url string) ([]byte, error) {
+	name, kind := cacheEntry(url)
+	body, err := c.read(name, kind)
+	observe.OrDefault(c.Observer).CacheLookup(ctx, &observe.CacheLookup{Class: kind.urlClass(), Hit: err == nil})
+	if err == nil {
+		return body, nil
+	}
+	if c.Offline {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w for %q", ErrCacheMiss, url)
+		}
+		return nil, fmt.Errorf("%w for %q: %v", ErrCacheMiss, url, err)
+	}
+	body, err = GetWithContext(ctx, c.getter(), url)
+	if err != nil {
+		return nil, err
+	}
+	// A response that cannot be cached is still returned so that verification reports the problem.
+	if err := c.fresh(kind, body, c.now()); err == nil {
+		if err := c.write(name, body); err != nil {
+			logger.Warningf("could not cache response for %q: %v", url, err)
+		}
+	}
+	return body, nil
+}
@@ -0,0 +1,212 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trust
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/kds"
+	kpb "github.com/google/go-sev-guest/proto/fakekds"
+)
+
+// countingGetter serves fixed responses and counts requests per URL.
+type countingGetter struct {
+	responses map[string][]byte
+	calls     map[string]int
+}
+
+func (g *countingGetter) Get(url string) ([]byte, error) {
+	g.calls[url]++
+	body, ok := g.responses[url]
+	if !ok {
+		return nil, fmt.Errorf("not found: %q", url)
+	}
+	return body, nil
+}
+
+func selfSigned(t *testing.T, name string, notAfter time.Time) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:              notAfter,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+func TestCachingHTTPSGetter(t *testing.T) {
+	now := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	chipID := make([]byte, abi.ChipIDSize)
+	chipID[0] = 0xc0
+	tcb := kds.TCBVersion(0x0300000000000115)
+	vcekURL := kds.VCEKCertURL("Milan", chipID, tcb)
+	chainURL := kds.ProductCertChainURL("Milan")
+	crlURL := kds.CrlURL("Milan", abi.VcekReportSigner)
+	otherURL := "https://example.com/other"
+
+	vcek, _ := selfSigned(t, "SEV-VCEK", now.Add(48*time.Hour))
+	ask, _ := selfSigned(t, "SEV-Milan", now.Add(72*time.Hour))
+	ark, arkKey := selfSigned(t, "ARK-Milan", now.Add(24*365*time.Hour))
+	chain := append(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ask.Raw}),
+		pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ark.Raw})...)
+	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: now,
+		NextUpdate: now.Add(12 * time.Hour),
+	}, ark, arkKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	responses := map[string][]byte{
+		vcekURL:  vcek.Raw,
+		chainURL: chain,
+		crlURL:   crl,
+		otherURL: []byte("other"),
+	}
+
+	tcs := []struct {
+		name string
+		// age is how long after the first fetch the second Get happens.
+		age       time.Duration
+		ttl       time.Duration
+		wantCalls map[string]int
+	}{
+		{
+			name:      "fresh",
+			age:       time.Hour,
+			wantCalls: map[string]int{vcekURL: 1, chainURL: 1, crlURL: 1, otherURL: 1},
+		},
+		{
+			name:      "crl next update",
+			age:       13 * time.Hour,
+			wantCalls: map[string]int{vcekURL: 1, chainURL: 1, crlURL: 2, otherURL: 1},
+		},
+		{
+			name:      "default ttl",
+			age:       25 * time.Hour,
+			wantCalls: map[string]int{vcekURL: 1, chainURL: 1, crlURL: 2, otherURL: 2},
+		},
+		{
+			name:      "vcek expiry",
+			age:       49 * time.Hour,
+			wantCalls: map[string]int{vcekURL: 2, chainURL: 1, crlURL: 2, otherURL: 2},
+		},
+		{
+			name:      "ask expiry",
+			age:       73 * time.Hour,
+			wantCalls: map[string]int{vcekURL: 2, chainURL: 2, crlURL: 2, otherURL: 2},
+		},
+		{
+			name:      "ttl",
+			age:       2 * time.Hour,
+			ttl:       time.Hour,
+			wantCalls: map[string]int{vcekURL: 2, chainURL: 2, crlURL: 2, otherURL: 2},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			getter := &countingGetter{responses: responses, calls: map[string]int{}}
+			clock := now
+			c := &CachingHTTPSGetter{
+				Dir:    t.TempDir(),
+				Getter: getter,
+				TTL:    tc.ttl,
+				Now:    func() time.Time { return clock },
+			}
+			for _, step := range []time.Duration{0, tc.age} {
+				clock = now.Add(step)
+				for url, want := range responses {
+					// A new getter on the same directory must see the previous one's entries.
+					c2 := *c
+					got, err := c2.Get(url)
+					if err != nil {
+						t.Fatalf("Get(%q) = _, %v, want nil", url, err)
+					}
+					if !bytes.Equal(got, want) {
+						t.Errorf("Get(%q) = %v, want %v", url, got, want)
+					}
+				}
+			}
+			for url, want := range tc.wantCalls {
+				if got := getter.calls[url]; got != want {
+					t.Errorf("%q fetched %d times, want %d", url, got, want)
+				}
+			}
+		})
+	}
+
+	t.Run("offline", func(t *testing.T) {
+		clock := now
+		c := &CachingHTTPSGetter{
+			Dir:     t.TempDir(),
+			Getter:  &countingGetter{responses: responses, calls: map[string]int{}},
+			Offline: true,
+			Now:     func() time.Time { return clock },
+		}
+		if _, err := c.Get(vcekURL); !errors.Is(err, ErrCacheMiss) {
+			t.Fatalf("Get(%q) = _, %v, want %v", vcekURL, err, ErrCacheMiss)
+		}
+		if err := c.Seed("Milan", &kpb.Certificates{ChipCerts: []*kpb.Certificates_ChipTCBCerts{
+			{ChipId: chipID, TcbCerts: map[uint64][]byte{uint64(tcb): vcek.Raw}},
+		}}); err != nil {
+			t.Fatalf("Seed() = %v, want nil", err)
+		}
+		if err := c.Put(crlURL, crl); err != nil {
+			t.Fatalf("Put(%q) = %v, want nil", crlURL, err)
+		}
+		if got, err := c.Get(vcekURL); err != nil || !bytes.Equal(got, vcek.Raw) {
+			t.Errorf("Get(%q) = %v, %v, want seeded VCEK", vcekURL, got, err)
+		}
+		clock = now.Add(13 * time.Hour)
+		if _, err := c.Get(crlURL); !errors.Is(err, ErrCacheMiss) || !strings.Contains(err.Error(), "expired") {
+			t.Errorf("Get(%q) = _, %v, want expired %v", crlURL, err, ErrCacheMiss)
+		}
+		if err := c.Put(crlURL, crl); err == nil || !strings.Contains(err.Error(), "will not cache") {
+			t.Errorf("Put(%q) of expired CRL = %v, want error", crlURL, err)
+		}
+		if err := c.Put(vcekURL, []byte("garbage")); err == nil {
+			t.Errorf("Put(%q) of garbage = nil, want error", vcekURL)
+		}
+	})
+}
@@ -18,44 +18,57 @@ package trust
 import (
 	"context"
 	"crypto/x509"
-	_ "embed"
+	"embed"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/google/go-sev-guest/abi"
 	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/observe"
 	"github.com/google/logger"
 )
 
 var (
 	// DefaultRootCerts holds AMD's SEV API certificate format for ASK and ARK keys as published here
 	// https://developer.amd.com/wp-content/resources/ask_ark_milan.cert
+	// keyed by product name. Each product's certificates are embedded from the file
+	// ask_ark_<product>.sevcert, e.g., ask_ark_genoa.sevcert for Genoa.
 	DefaultRootCerts map[string]*AMDRootCerts
 
 	// The ASK and ARK certificates are embedded since they do not have an expiration date. The KDS
 	// documents them having a lifetime of 25 years. The X.509 certificate that this cert's signature
 	// is over cannot be reconstructed from the SEV certificate format. The X.509 certificate with its
-	// expiration dates is at https://kdsintf.amd.com/vcek/v1/Milan/cert_chain
-	//go:embed ask_ark_milan.sevcert
-	askArkMilanBytes []byte
+	// expiration dates is at https://kdsintf.amd.com/vcek/v1/{product}/cert_chain
+	//go:embed ask_ark_*.sevcert
+	askArkFiles embed.FS
 
-	// A cache of product certificate KDS results per product.
+	// A cache of product certificate KDS results per product and endorsement key kind.
 	prodCacheMu      sync.Mutex
-	productCertCache map[string]*ProductCerts
+	productCertCache map[productCacheKey]*ProductCerts
 )
 
+type productCacheKey struct {
+	product string
+	key     abi.ReportSigner
+}
+
 // Communication with AMD suggests repeat requests of the same arguments will
 // be throttled to once per 10 seconds.
 const initialDelay = 10 * time.Second
 
-// ProductCerts contains the root key and signing key devoted to a given product line.
+// ProductCerts contains the root key and signing keys devoted to a given product line.
 type ProductCerts struct {
 	Ask *x509.Certificate
-	Ark *x509.Certificate
+	// Asvk is the AMD SEV VLEK signing key certificate, which certifies VLEKs.
+	Asvk *x509.Certificate
+	Ark  *x509.Certificate
 }
 
 // AMDRootCerts encapsulates the certificates that represent root of trust in AMD.
@@ -75,6 +88,9 @@ type AMDRootCerts struct {
 	// CRL is the certificate revocation list for this AMD product. Populated once, only when a
 	// revocation is checked.
 	CRL *x509.RevocationList
+	// VlekCRL is the certificate revocation list for this AMD product's VLEK signing key.
+	// Populated once, only when a VLEK revocation is checked.
+	VlekCRL *x509.RevocationList
 }
 
 // HTTPSGetter represents the ability to fetch data from the internet from an HTTP URL.
@@ -83,6 +99,28 @@ type HTTPSGetter interface {
 	Get(url string) ([]byte, error)
 }
 
+// ContextHTTPSGetter is an HTTPSGetter whose fetches can be cancelled or given a deadline.
+type ContextHTTPSGetter interface {
+	HTTPSGetter
+	GetContext(ctx context.Context, url string) ([]byte, error)
+}
+
+// GetWithContext fetches url with getter's GetContext if it has one. Otherwise it falls back to Get
+// and only checks ctx before and after the fetch.
+func GetWithContext(ctx context.Context, getter HTTPSGetter, url string) ([]byte, error) {
+	if cgetter, ok := getter.(ContextHTTPSGetter); ok {
+		return cgetter.GetContext(ctx, url)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	body, err := getter.Get(url)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return nil, ctxErr
+	}
+	return body, err
+}
+
 // AttestationRecreationErr represents a problem with fetching or interpreting associated
 // certificates for a given attestation report. This is typically due to network unreliability.
 type AttestationRecreationErr struct {
@@ -93,16 +131,36 @@ func (e *AttestationRecreationErr) Error() string {
 	return e.Msg
 }
 
+// HTTPStatusError is the error of a fetch whose response had an unsuccessful HTTP status code.
+type HTTPStatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *HTTPStatusError) Error() string {
+	return fmt.Sprintf("failed to retrieve %s: HTTP status %d", e.URL, e.StatusCode)
+}
+
 // SimpleHTTPSGetter implements the HTTPSGetter interface with http.Get.
 type SimpleHTTPSGetter struct{}
 
 // Get uses http.Get to return the HTTPS response body as a byte array.
 func (n *SimpleHTTPSGetter) Get(url string) ([]byte, error) {
-	resp, err := http.Get(url)
+	return n.GetContext(context.Background(), url)
+}
+
+// GetContext is Get, but the request is bound to ctx.
+func (n *SimpleHTTPSGetter) GetContext(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, err
 	} else if resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("failed to retrieve %s", url)
+		resp.Body.Close()
+		return nil, &HTTPStatusError{URL: url, StatusCode: resp.StatusCode}
 	}
 
 	body, err := io.ReadAll(resp.Body)
@@ -121,29 +179,59 @@ type RetryHTTPSGetter struct {
 	MaxRetryDelay time.Duration
 	// Getter is the non-retrying way of getting a URL.
 	Getter HTTPSGetter
+	// Observer receives an event for each URL fetched. If nil, uses observe.Default().
+	Observer observe.Observer
 }
 
 // Get fetches the body of the URL, retrying a given amount of times on failure.
 func (n *RetryHTTPSGetter) Get(url string) ([]byte, error) {
+	return n.GetContext(context.Background(), url)
+}
+
+// GetContext is Get, but stops retrying when ctx is done. The Timeout still bounds the retries.
+func (n *RetryHTTPSGetter) GetContext(parent context.Context, url string) ([]byte, error) {
+	event := &observe.KDSRequest{Class: kdsURLClass(url)}
+	start := time.Now()
+	body, err := n.getContext(parent, url, event)
+	event.Latency = time.Since(start)
+	event.Err = err
+	observe.OrDefault(n.Observer).KDSRequest(parent, event)
+	return body, err
+}
+
+func (n *RetryHTTPSGetter) getContext(parent context.Context, url string, event *observe.KDSRequest) ([]byte, error) {
 	delay := initialDelay
-	ctx, cancel := context.WithTimeout(context.Background(), n.Timeout)
-	for {
-		body, err := n.Getter.Get(url)
+	ctx, cancel := context.WithTimeout(parent, n.Timeout)
+	defer cancel()
+	for attempt := 0; ; attempt++ {
+		if ctx.Err() != nil {
+			break
+		}
+		event.Retries = attempt
+		body, err := GetWithContext(ctx, n.Getter, url)
 		if err == nil {
-			cancel()
+			event.StatusCode = 0
 			return body, nil
 		}
+		// Keep the last HTTP status if the attempt instead failed for the deadline.
+		var statusErr *HTTPStatusError
+		if errors.As(err, &statusErr) {
+			event.StatusCode = statusErr.StatusCode
+		}
 		delay = delay + delay
 		if delay > n.MaxRetryDelay {
 			delay = n.MaxRetryDelay
 		}
 		select {
 		case <-ctx.Done():
-			cancel()
-			return nil, fmt.Errorf("timeout") // context cancelled
 		case <-time.After(delay): // wait to retry
 		}
 	}
+	// Report the caller's cancellation or deadline as such.
+	if err := parent.Err(); err != nil {
+		return nil, err
+	}
+	return nil, fmt.Errorf("timeout") // context cancelled
 }
 
 // DefaultHTTPSGetter returns the library's default getter implementation. It will
@@ -172,12 +260,17 @@ func (r *AMDRootCerts) Unmarshal(data []byte) error {
 }
 
 // FromDER populates the ProductCerts from DER-formatted certificates for both the ASK and the ARK.
+// If the intermediate certificate is an ASVK, then it populates Asvk instead of Ask.
 func (r *ProductCerts) FromDER(ask []byte, ark []byte) error {
 	askCert, err := x509.ParseCertificate(ask)
 	if err != nil {
 		return fmt.Errorf("could not parse ASK certificate: %v", err)
 	}
-	r.Ask = askCert
+	if strings.HasPrefix(askCert.Subject.CommonName, "SEV-VLEK-") {
+		r.Asvk = askCert
+	} else {
+		r.Ask = askCert
+	}
 
 	arkCert, err := x509.ParseCertificate(ark)
 	if err != nil {
@@ -208,16 +301,36 @@ func (r *ProductCerts) FromKDSCert(path string) error {
 	return r.FromKDSCertBytes(certBytes)
 }
 
+// Intermediate returns the signing key certificate that certifies the given kind of endorsement
+// key, i.e., the ASK for the VCEK and the ASVK for the VLEK.
+func (r *ProductCerts) Intermediate(key abi.ReportSigner) *x509.Certificate {
+	switch key {
+	case abi.VcekReportSigner:
+		return r.Ask
+	case abi.VlekReportSigner:
+		return r.Asvk
+	}
+	return nil
+}
+
 // X509Options returns the ASK and ARK as the only intermediate and root certificates of an x509
 // verification options object, or nil if either key's x509 certificate is not present in r.
 func (r *ProductCerts) X509Options(now time.Time) *x509.VerifyOptions {
-	if r.Ask == nil || r.Ark == nil {
+	return r.X509OptionsForKey(now, abi.VcekReportSigner)
+}
+
+// X509OptionsForKey returns the signing key for the given endorsement key kind and ARK as the only
+// intermediate and root certificates of an x509 verification options object, or nil if either
+// key's x509 certificate is not present in r.
+func (r *ProductCerts) X509OptionsForKey(now time.Time, key abi.ReportSigner) *x509.VerifyOptions {
+	intermediate := r.Intermediate(key)
+	if intermediate == nil || r.Ark == nil {
 		return nil
 	}
 	roots := x509.NewCertPool()
 	roots.AddCert(r.Ark)
 	intermediates := x509.NewCertPool()
-	intermediates.AddCert(r.Ask)
+	intermediates.AddCert(intermediate)
 	return &x509.VerifyOptions{Roots: roots, Intermediates: intermediates, CurrentTime: now}
 }
 
@@ -232,17 +345,44 @@ func ClearProductCertCache() {
 // GetProductChain returns the ASK and ARK certificates of the given product, either from getter
 // or from a cache of the results from the last successful call.
 func GetProductChain(product string, getter HTTPSGetter) (*ProductCerts, error) {
+	return GetProductChainForKey(product, abi.VcekReportSigner, getter)
+}
+
+// GetProductChainForKey returns the ASK (or ASVK for the VLEK) and ARK certificates of the given
+// product, either from getter or from a cache of the results from the last successful call.
+func GetProductChainForKey(product string, key abi.ReportSigner, getter HTTPSGetter) (*ProductCerts, error) {
+	return GetProductChainContext(context.Background(), product, key, getter)
+}
+
+// GetProductChainContext is GetProductChainForKey, but gives up downloading the chain when ctx is
+// done.
+func GetProductChainContext(ctx context.Context, product string, key abi.ReportSigner, getter HTTPSGetter) (*ProductCerts, error) {
+	var url, intermediate string
+	switch key {
+	case abi.VcekReportSigner:
+		url = kds.ProductCertChainURL(product)
+		intermediate = "ASK"
+	case abi.VlekReportSigner:
+		url = kds.VlekProductCertChainURL(product)
+		intermediate = "ASVK"
+	default:
+		return nil, fmt.Errorf("no product certificate chain for endorsement key kind %v", key)
+	}
+	cacheKey := productCacheKey{product: product, key: key}
+	prodCacheMu.Lock()
 	if productCertCache == nil {
-		prodCacheMu.Lock()
-		productCertCache = make(map[string]*ProductCerts)
-		prodCacheMu.Unlock()
+		productCertCache = make(map[productCacheKey]*ProductCerts)
 	}
-	result, ok := productCertCache[product]
+	result, ok := productCertCache[cacheKey]
+	prodCacheMu.Unlock()
 	if !ok {
-		askark, err := getter.Get(kds.ProductCertChainURL(product))
+		askark, err := GetWithContext(ctx, getter, url)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("could not download %s and ARK certificates: %w", intermediate, ctxErr)
+		}
 		if err != nil {
 			return nil, &AttestationRecreationErr{
-				Msg: fmt.Sprintf("could not download ASK and ARK certificates: %v", err),
+				Msg: fmt.Sprintf("could not download %s and ARK certificates: %v", intermediate, err),
 			}
 		}
 
@@ -253,15 +393,20 @@ func GetProductChain(product string, getter HTTPSGetter) (*ProductCerts, error)
 		}
 		askCert, err := x509.ParseCertificate(ask)
 		if err != nil {
-			return nil, &AttestationRecreationErr{Msg: fmt.Sprintf("could not parse ASK cert: %v", err)}
+			return nil, &AttestationRecreationErr{Msg: fmt.Sprintf("could not parse %s cert: %v", intermediate, err)}
 		}
 		arkCert, err := x509.ParseCertificate(ark)
 		if err != nil {
 			return nil, &AttestationRecreationErr{Msg: fmt.Sprintf("could not parse ARK cert: %v", err)}
 		}
-		result = &ProductCerts{Ask: askCert, Ark: arkCert}
+		result = &ProductCerts{Ark: arkCert}
+		if key == abi.VlekReportSigner {
+			result.Asvk = askCert
+		} else {
+			result.Ask = askCert
+		}
 		prodCacheMu.Lock()
-		productCertCache[product] = result
+		productCertCache[cacheKey] = result
 		prodCacheMu.Unlock()
 	}
 	return result, nil
@@ -294,17 +439,44 @@ func (r *AMDRootCerts) FromKDSCert(path string) error {
 // X509Options returns the ASK and ARK as the only intermediate and root certificates of an x509
 // verification options object, or nil if either key's x509 certificate is not present in r.
 func (r *AMDRootCerts) X509Options(now time.Time) *x509.VerifyOptions {
+	return r.X509OptionsForKey(now, abi.VcekReportSigner)
+}
+
+// X509OptionsForKey returns the signing key for the given endorsement key kind and ARK as the only
+// intermediate and root certificates of an x509 verification options object, or nil if either
+// key's x509 certificate is not present in r.
+func (r *AMDRootCerts) X509OptionsForKey(now time.Time, key abi.ReportSigner) *x509.VerifyOptions {
 	if r.ProductCerts == nil {
 		return nil
 	}
-	return r.ProductCerts.X509Options(now)
+	return r.ProductCerts.X509OptionsForKey(now, key)
 }
 
-// Parse ASK, ARK certificates from the embedded AMD certificate file.
+// Parse ASK, ARK certificates from the embedded AMD certificate files.
 func init() {
-	milanCerts := new(AMDRootCerts)
-	milanCerts.Unmarshal(askArkMilanBytes)
-	DefaultRootCerts = map[string]*AMDRootCerts{
-		"Milan": milanCerts,
+	DefaultRootCerts = make(map[string]*AMDRootCerts)
+	files, err := askArkFiles.ReadDir(".")
+	if err != nil {
+		logger.Errorf("could not read embedded AMD root certificates: %v", err)
+		return
+	}
+	for _, file := range files {
+		name := strings.TrimSuffix(strings.TrimPrefix(file.Name(), "ask_ark_"), path.Ext(file.Name()))
+		if name == "" {
+			logger.Errorf("embedded AMD root certificate %q does not name a product", file.Name())
+			continue
+		}
+		product := strings.ToUpper(name[:1]) + name[1:]
+		data, err := askArkFiles.ReadFile(file.Name())
+		if err != nil {
+			logger.Errorf("could not read embedded AMD root certificate %q: %v", file.Name(), err)
+			continue
+		}
+		certs := &AMDRootCerts{Product: product}
+		if err := certs.Unmarshal(data); err != nil {
+			logger.Errorf("could not parse embedded AMD root certificate %q: %v", file.Name(), err)
+			continue
+		}
+		DefaultRootCerts[product] = certs
 	}
 }
@@ -0,0 +1,228 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package trust
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/observe"
+)
+
+func TestSimpleHTTPSGetterContext(t *testing.T) {
+	block := make(chan struct{})
+	defer close(block)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			select {
+			case <-block:
+			case <-r.Context().Done():
+			}
+		}
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	getter := &SimpleHTTPSGetter{}
+	body, err := getter.GetContext(context.Background(), server.URL+"/fast")
+	if err != nil || string(body) != "body" {
+		t.Errorf("GetContext(fast) = %q, %v, want %q, nil", body, err, "body")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := getter.GetContext(ctx, server.URL+"/slow"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("GetContext(slow) = _, %v, want DeadlineExceeded", err)
+	}
+}
+
+func TestRetryHTTPSGetterContext(t *testing.T) {
+	tcs := []struct {
+		name    string
+		timeout time.Duration
+		// ctxTimeout is the caller's deadline, or zero for none.
+		ctxTimeout time.Duration
+		wantErr    error
+	}{
+		{name: "caller deadline", timeout: time.Minute, ctxTimeout: 10 * time.Millisecond, wantErr: context.DeadlineExceeded},
+		{name: "own timeout", timeout: 10 * time.Millisecond},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.ctxTimeout != 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.ctxTimeout)
+				defer cancel()
+			}
+			g := &countingGetter{calls: make(map[string]int)}
+			r := &RetryHTTPSGetter{Timeout: tc.timeout, MaxRetryDelay: time.Millisecond, Getter: g}
+			_, err := r.GetContext(ctx, "https://example.com")
+			if err == nil {
+				t.Fatal("GetContext() = _, nil, want error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("GetContext() = _, %v, want %v", err, tc.wantErr)
+			}
+			if tc.wantErr == nil && errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("GetContext() = _, %v, want the getter's own timeout", err)
+			}
+			if g.calls["https://example.com"] == 0 {
+				t.Error("GetContext() never called its getter")
+			}
+		})
+	}
+}
+
+func TestGetProductChainContext(t *testing.T) {
+	ClearProductCertCache()
+	defer ClearProductCertCache()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g := &countingGetter{calls: make(map[string]int)}
+	if _, err := GetProductChainContext(ctx, "Milan", abi.VcekReportSigner, g); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetProductChainContext(cancelled) = _, %v, want Canceled", err)
+	}
+	if len(g.calls) != 0 {
+		t.Errorf("GetProductChainContext(cancelled) fetched %v, want no fetches", g.calls)
+	}
+}
+
+// recordingObserver records KDS request and cache lookup events.
+type recordingObserver struct {
+	observe.Nop
+	requests []*observe.KDSRequest
+	lookups  []*observe.CacheLookup
+}
+
+func (o *recordingObserver) KDSRequest(_ context.Context, e *observe.KDSRequest) {
+	o.requests = append(o.requests, e)
+}
+
+func (o *recordingObserver) CacheLookup(_ context.Context, e *observe.CacheLookup) {
+	o.lookups = append(o.lookups, e)
+}
+
+// flakyGetter fails its first `failures` fetches with an HTTP status error. If cancel is set, it
+// calls cancel during fetch number cancelAt, as if the caller's deadline passed.
+type flakyGetter struct {
+	failures int
+	calls    int
+	cancelAt int
+	cancel   context.CancelFunc
+}
+
+func (g *flakyGetter) Get(url string) ([]byte, error) {
+	g.calls++
+	if g.cancel != nil && g.calls == g.cancelAt {
+		g.cancel()
+	}
+	if g.failures > 0 {
+		g.failures--
+		return nil, &HTTPStatusError{URL: url, StatusCode: http.StatusTooManyRequests}
+	}
+	return []byte("body"), nil
+}
+
+func TestHTTPStatusError(t *testing.T) {
+	err := &HTTPStatusError{URL: "https://example.com/vcek", StatusCode: http.StatusNotFound}
+	want := "failed to retrieve https://example.com/vcek: HTTP status 404"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestRetryHTTPSGetterObserver(t *testing.T) {
+	url := kds.ProductCertChainURL("Milan")
+	tcs := []struct {
+		name           string
+		failures       int
+		cancelAt       int
+		wantRetries    int
+		wantStatusCode int
+		wantErr        bool
+	}{
+		{name: "success"},
+		{name: "retried", failures: 2, wantRetries: 2},
+		{name: "throttled", failures: 1000, cancelAt: 3, wantRetries: 2, wantStatusCode: http.StatusTooManyRequests, wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			getter := &flakyGetter{failures: tc.failures}
+			if tc.cancelAt > 0 {
+				getter.cancelAt = tc.cancelAt
+				getter.cancel = cancel
+			}
+			o := &recordingObserver{}
+			r := &RetryHTTPSGetter{
+				Timeout:       time.Minute,
+				MaxRetryDelay: time.Millisecond,
+				Getter:        getter,
+				Observer:      o,
+			}
+			_, err := r.GetContext(ctx, url)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Get() = _, %v, want error %v", err, tc.wantErr)
+			}
+			if len(o.requests) != 1 {
+				t.Fatalf("observed %d KDS requests, want 1", len(o.requests))
+			}
+			e := o.requests[0]
+			if e.Class != observe.CertChainURL {
+				t.Errorf("observed class %q, want %q", e.Class, observe.CertChainURL)
+			}
+			if e.Retries != tc.wantRetries {
+				t.Errorf("observed %d retries, want %d", e.Retries, tc.wantRetries)
+			}
+			if e.StatusCode != tc.wantStatusCode {
+				t.Errorf("observed status code %d, want %d", e.StatusCode, tc.wantStatusCode)
+			}
+			if (e.Err != nil) != tc.wantErr {
+				t.Errorf("observed error %v, want error %v", e.Err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCachingHTTPSGetterObserver(t *testing.T) {
+	url := "https://example.com/other"
+	o := &recordingObserver{}
+	c := &CachingHTTPSGetter{
+		Dir:      t.TempDir(),
+		Getter:   &countingGetter{responses: map[string][]byte{url: []byte("other")}, calls: make(map[string]int)},
+		Observer: o,
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(url); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []*observe.CacheLookup{{Class: observe.OtherURL}, {Class: observe.OtherURL, Hit: true}}
+	if len(o.lookups) != len(want) {
+		t.Fatalf("observed %d cache lookups, want %d", len(o.lookups), len(want))
+	}
+	for i, e := range o.lookups {
+		if *e != *want[i] {
+			t.Errorf("cache lookup %d = %+v, want %+v", i, *e, *want[i])
+		}
+	}
+}
@@ -16,6 +16,7 @@
 package verify
 
 import (
+	"context"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
@@ -24,7 +25,9 @@ import (
 	"time"
 
 	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
 	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/observe"
 	cpb "github.com/google/go-sev-guest/proto/check"
 	spb "github.com/google/go-sev-guest/proto/sevsnp"
 	"github.com/google/go-sev-guest/verify/trust"
@@ -35,21 +38,15 @@ import (
 )
 
 const (
-	askVersion     = 1
-	askKeyUsage    = 0x13
-	arkVersion     = 1
-	arkKeyUsage    = 0x0
-	askX509Version = 3
-	arkX509Version = 3
+	askVersion      = 1
+	askKeyUsage     = 0x13
+	arkVersion      = 1
+	arkKeyUsage     = 0x0
+	askX509Version  = 3
+	asvkX509Version = 3
+	arkX509Version  = 3
 )
 
-// The VCEK productName in includes the specific silicon stepping
-// corresponding to the supplied hwID. For example, “Milan-B0”.
-// The product should inform what product keys we expect the key to be certified by.
-var vcekProductMap = map[string]string{
-	"Milan-B0": "Milan",
-}
-
 func askVerifiedBy(signee, signer *abi.AskCert, signeeName, signerName string) error {
 	if !uuid.Equal(signee.CertifyingID[:], signer.KeyID[:]) {
 		return fmt.Errorf("%s's certifying ID (%s) is not %s's key ID (%s) ",
@@ -119,7 +116,7 @@ func validateAmdLocation(name pkix.Name, role string) error {
 	return checkSingletonList(name.OrganizationalUnit, "organizational unit", "organizational uints", "Engineering")
 }
 
-func validateRootX509(product string, x *x509.Certificate, version int, role, cn string) error {
+func validateRootX509(product string, x *x509.Certificate, version int, key abi.ReportSigner, role, cn string) error {
 	// Additionally check that the X.509 cert's public key matches the SEV format cert.
 	if x == nil {
 		return fmt.Errorf("no X.509 certificate for %s", role)
@@ -137,7 +134,7 @@ func validateRootX509(product string, x *x509.Certificate, version int, role, cn
 	if cn != "" && x.Subject.CommonName != cn {
 		return fmt.Errorf("%s common-name is %s. Expected %s", role, x.Subject.CommonName, cn)
 	}
-	return validateCRLlink(x, product, role)
+	return validateCRLlink(x, product, key, role)
 }
 
 // ValidateAskX509 checks expected metadata about the ASK X.509 certificate. It does not verify the
@@ -150,7 +147,7 @@ func ValidateAskX509(r *trust.AMDRootCerts) error {
 	if r.Product != "" {
 		cn = fmt.Sprintf("SEV-%s", r.Product)
 	}
-	if err := validateRootX509(r.Product, r.ProductCerts.Ask, askX509Version, "ASK", cn); err != nil {
+	if err := validateRootX509(r.Product, r.ProductCerts.Ask, askX509Version, abi.VcekReportSigner, "ASK", cn); err != nil {
 		return err
 	}
 	if r.AskSev != nil {
@@ -159,6 +156,20 @@ func ValidateAskX509(r *trust.AMDRootCerts) error {
 	return nil
 }
 
+// ValidateAsvkX509 checks expected metadata about the ASVK X.509 certificate. It does not verify the
+// cryptographic signatures.
+func ValidateAsvkX509(r *trust.AMDRootCerts) error {
+	if r == nil {
+		r = trust.DefaultRootCerts["Milan"]
+	}
+	var cn string
+	if r.Product != "" {
+		cn = fmt.Sprintf("SEV-VLEK-%s", r.Product)
+	}
+	// There is no AMD SEV format certificate for the ASVK to cross-check.
+	return validateRootX509(r.Product, r.ProductCerts.Asvk, asvkX509Version, abi.VlekReportSigner, "ASVK", cn)
+}
+
 // ValidateArkX509 checks expected metadata about the ARK X.509 certificate. It does not verify the
 // cryptographic signatures.
 func ValidateArkX509(r *trust.AMDRootCerts) error {
@@ -169,7 +180,7 @@ func ValidateArkX509(r *trust.AMDRootCerts) error {
 	if r.Product != "" {
 		cn = fmt.Sprintf("ARK-%s", r.Product)
 	}
-	if err := validateRootX509(r.Product, r.ProductCerts.Ark, arkX509Version, "ARK", cn); err != nil {
+	if err := validateRootX509(r.Product, r.ProductCerts.Ark, arkX509Version, abi.VcekReportSigner, "ARK", cn); err != nil {
 		return err
 	}
 	if r.ArkSev != nil {
@@ -209,40 +220,67 @@ func ValidateArkSev(r *trust.AMDRootCerts) error {
 	return validateRootSev(r.ArkSev, r.ArkSev, arkVersion, arkKeyUsage, "ARK", "ARK")
 }
 
-// ValidateX509 will validate the x509 certificates of the ASK and ARK.
+// ValidateX509 will validate the x509 certificates of the ARK and whichever of the ASK and ASVK
+// are present.
 func ValidateX509(r *trust.AMDRootCerts) error {
 	if err := ValidateArkX509(r); err != nil {
 		return fmt.Errorf("ARK validation error: %v", err)
 	}
-	if err := ValidateAskX509(r); err != nil {
-		return fmt.Errorf("ASK validation error: %v", err)
+	hasAsvk := r != nil && r.ProductCerts != nil && r.ProductCerts.Asvk != nil
+	hasAsk := r == nil || r.ProductCerts == nil || r.ProductCerts.Ask != nil
+	// The ASK is required unless only the VLEK signing key is being trusted.
+	if hasAsk || !hasAsvk {
+		if err := ValidateAskX509(r); err != nil {
+			return fmt.Errorf("ASK validation error: %v", err)
+		}
+	}
+	if hasAsvk {
+		if err := ValidateAsvkX509(r); err != nil {
+			return fmt.Errorf("ASVK validation error: %v", err)
+		}
 	}
 	return nil
 }
 
-// ValidateVcekCertSubject checks KDS-specified values of the subject metadata of the AMD certificate.
-func ValidateVcekCertSubject(subject pkix.Name) error {
-	if err := validateAmdLocation(subject, "VCEK subject"); err != nil {
+// ValidateKDSCertSubject checks KDS-specified values of the subject metadata of the AMD certificate
+// for the given kind of endorsement key.
+func ValidateKDSCertSubject(subject pkix.Name, key abi.ReportSigner) error {
+	if err := validateAmdLocation(subject, fmt.Sprintf("%v subject", key)); err != nil {
 		return err
 	}
-	if subject.CommonName != "SEV-VCEK" {
-		return fmt.Errorf("VCEK certificate subject common name %s not expected. Expected SEV-VCEK", subject.CommonName)
+	cn := fmt.Sprintf("SEV-%v", key)
+	if subject.CommonName != cn {
+		return fmt.Errorf("%v certificate subject common name %s not expected. Expected %s", key, subject.CommonName, cn)
 	}
 	return nil
 }
 
-// ValidateVcekCertIssuer checks KDS-specified values of the issuer metadata of the AMD certificate.
-func ValidateVcekCertIssuer(r *trust.AMDRootCerts, issuer pkix.Name) error {
-	if err := validateAmdLocation(issuer, "VCEK issuer"); err != nil {
+// ValidateKDSCertIssuer checks KDS-specified values of the issuer metadata of the AMD certificate
+// for the given kind of endorsement key.
+func ValidateKDSCertIssuer(r *trust.AMDRootCerts, issuer pkix.Name, key abi.ReportSigner) error {
+	if err := validateAmdLocation(issuer, fmt.Sprintf("%v issuer", key)); err != nil {
 		return err
 	}
 	cn := fmt.Sprintf("SEV-%s", r.Product)
+	if key == abi.VlekReportSigner {
+		cn = fmt.Sprintf("SEV-VLEK-%s", r.Product)
+	}
 	if issuer.CommonName != cn {
-		return fmt.Errorf("VCEK certificate issuer common name %s not expected. Expected %s", issuer.CommonName, cn)
+		return fmt.Errorf("%v certificate issuer common name %s not expected. Expected %s", key, issuer.CommonName, cn)
 	}
 	return nil
 }
 
+// ValidateVcekCertSubject checks KDS-specified values of the subject metadata of the AMD certificate.
+func ValidateVcekCertSubject(subject pkix.Name) error {
+	return ValidateKDSCertSubject(subject, abi.VcekReportSigner)
+}
+
+// ValidateVcekCertIssuer checks KDS-specified values of the issuer metadata of the AMD certificate.
+func ValidateVcekCertIssuer(r *trust.AMDRootCerts, issuer pkix.Name) error {
+	return ValidateKDSCertIssuer(r, issuer, abi.VcekReportSigner)
+}
+
 // CRLUnavailableErr represents a problem with fetching the CRL from the network.
 // This type is special to allow for easy "fail open" semantics for CRL unavailability. See
 // Adam Langley's write-up on CRLs and network unreliability
@@ -254,18 +292,54 @@ type CRLUnavailableErr struct {
 // GetCrlAndCheckRoot downloads the given cert's CRL from one of the distribution points and
 // verifies that the CRL is valid and doesn't revoke an intermediate key.
 func GetCrlAndCheckRoot(r *trust.AMDRootCerts, opts *Options) (*x509.RevocationList, error) {
+	return getCrlAndCheckRoot(context.Background(), r, abi.VcekReportSigner, opts)
+}
+
+// intermediateName returns the name of the AMD signing key that certifies the given kind of
+// endorsement key.
+func intermediateName(key abi.ReportSigner) string {
+	if key == abi.VlekReportSigner {
+		return "ASVK"
+	}
+	return "ASK"
+}
+
+func getCrlAndCheckRoot(ctx context.Context, r *trust.AMDRootCerts, key abi.ReportSigner, opts *Options) (*x509.RevocationList, error) {
 	r.Mu.Lock()
 	defer r.Mu.Unlock()
 	getter := opts.Getter
 	if getter == nil {
 		getter = trust.DefaultHTTPSGetter()
 	}
-	if r.CRL != nil && opts.Now.Before(r.CRL.NextUpdate) {
-		return r.CRL, nil
+	crlField := &r.CRL
+	if key == abi.VlekReportSigner {
+		crlField = &r.VlekCRL
+	}
+	if *crlField != nil && opts.Now.Before((*crlField).NextUpdate) {
+		return *crlField, nil
+	}
+	crl, err := refreshCrl(ctx, r, key, getter, crlField)
+	observe.OrDefault(opts.Observer).CRLRefresh(ctx, &observe.CRLRefresh{
+		Product: r.Product,
+		Signer:  key.String(),
+		Err:     err,
+	})
+	return crl, err
+}
+
+// refreshCrl downloads the CRL of the given kind of endorsement key into crlField and checks it.
+// Must be called while r.Mu is held.
+func refreshCrl(ctx context.Context, r *trust.AMDRootCerts, key abi.ReportSigner, getter trust.HTTPSGetter, crlField **x509.RevocationList) (*x509.RevocationList, error) {
+	intermediate := r.ProductCerts.Intermediate(key)
+	if intermediate == nil {
+		return nil, fmt.Errorf("missing %s x509 certificate to find the CRL", intermediateName(key))
 	}
 	var errs error
-	for _, url := range r.ProductCerts.Ask.CRLDistributionPoints {
-		bytes, err := getter.Get(url)
+	for _, url := range intermediate.CRLDistributionPoints {
+		bytes, err := trust.GetWithContext(ctx, getter, url)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
 		if err != nil {
 			errs = multierr.Append(errs, err)
 			continue
@@ -275,33 +349,34 @@ func GetCrlAndCheckRoot(r *trust.AMDRootCerts, opts *Options) (*x509.RevocationL
 			errs = multierr.Append(errs, err)
 			continue
 		}
-		r.CRL = crl
-		if err := verifyCRL(r); err != nil {
+		*crlField = crl
+		if err := verifyCRL(r, crl, key); err != nil {
 			return nil, err
 		}
-		return r.CRL, nil
+		return crl, nil
 	}
 	return nil, CRLUnavailableErr{multierr.Append(errs, errors.New("could not fetch product CRL"))}
 }
 
-// verifyCRL checks that the VCEK CRL is signed by the ARK. Must be called after r.CRL is set and while
-// r.Mu is held.
-func verifyCRL(r *trust.AMDRootCerts) error {
-	if r.CRL == nil {
+// verifyCRL checks that the VCEK or VLEK CRL is signed by the ARK and does not revoke the
+// intermediate signing key. Must be called while r.Mu is held.
+func verifyCRL(r *trust.AMDRootCerts, crl *x509.RevocationList, key abi.ReportSigner) error {
+	if crl == nil {
 		return errors.New("internal error: CRL not set")
 	}
 	if r.ProductCerts.Ark == nil {
 		return errors.New("missing ARK x509 certificate to check CRL validity")
 	}
-	if r.ProductCerts.Ark == nil {
-		return errors.New("missing ASK x509 certificate to check intermediate key validity")
+	intermediate := r.ProductCerts.Intermediate(key)
+	if intermediate == nil {
+		return fmt.Errorf("missing %s x509 certificate to check intermediate key validity", intermediateName(key))
 	}
-	if err := r.CRL.CheckSignatureFrom(r.ProductCerts.Ark); err != nil {
+	if err := crl.CheckSignatureFrom(r.ProductCerts.Ark); err != nil {
 		return fmt.Errorf("CRL is not signed by ARK: %v", err)
 	}
-	for _, bad := range r.CRL.RevokedCertificates {
-		if r.ProductCerts.Ask.SerialNumber.Cmp(bad.SerialNumber) == 0 {
-			return fmt.Errorf("ASK was revoked at %v", bad.RevocationTime)
+	for _, bad := range crl.RevokedCertificates {
+		if intermediate.SerialNumber.Cmp(bad.SerialNumber) == 0 {
+			return fmt.Errorf("%s was revoked at %v", intermediateName(key), bad.RevocationTime)
 		}
 		// From offline discussions with AMD, we don't expect them to ever explicitly revoke a VCEK
 		// since TCB numbers serve the purpose of superceding previous certificates.
@@ -316,8 +391,17 @@ func VcekNotRevoked(r *trust.AMDRootCerts, _ *x509.Certificate, options *Options
 	return err
 }
 
-func validateCRLlink(x *x509.Certificate, product, role string) error {
-	url := fmt.Sprintf("https://kdsintf.amd.com/vcek/v1/%s/crl", product)
+// VlekNotRevoked will consult the online CRL listed in the ASVK certificate for whether the VLEK's
+// signing key has been revoked. Returns nil if not revoked, error on any problem.
+func VlekNotRevoked(r *trust.AMDRootCerts, _ *x509.Certificate, options *Options) error {
+	_, err := getCrlAndCheckRoot(context.Background(), r, abi.VlekReportSigner, options)
+	return err
+}
+
+// validateCRLlink checks that the certificate points to the CRL of the given kind of endorsement
+// key's chain.
+func validateCRLlink(x *x509.Certificate, product string, key abi.ReportSigner, role string) error {
+	url := kds.CrlURL(product, key)
 	if len(x.CRLDistributionPoints) != 1 {
 		return fmt.Errorf("%s has %d CRL distribution points, want 1", role, len(x.CRLDistributionPoints))
 	}
@@ -329,89 +413,116 @@ func validateCRLlink(x *x509.Certificate, product, role string) error {
 
 // ValidateVcekExtensions checks if the certificate extensions match
 // wellformedness expectations.
-func ValidateVcekExtensions(exts *kds.VcekExtensions) error {
-	if _, ok := vcekProductMap[exts.ProductName]; !ok {
-		return fmt.Errorf("unknown VCEK product name: %v", exts.ProductName)
+func ValidateVcekExtensions(exts *kds.Extensions) error {
+	return validateExtensions(exts, abi.VcekReportSigner)
+}
+
+// ValidateVlekExtensions checks if the VLEK certificate extensions match
+// wellformedness expectations.
+func ValidateVlekExtensions(exts *kds.Extensions) error {
+	return validateExtensions(exts, abi.VlekReportSigner)
+}
+
+// The VCEK or VLEK productName includes the specific silicon stepping
+// corresponding to the supplied hwID. For example, “Milan-B0”.
+// The product should inform what product keys we expect the key to be certified by.
+func validateExtensions(exts *kds.Extensions, key abi.ReportSigner) error {
+	if _, err := kds.ProductLine(exts.ProductName); err != nil {
+		return fmt.Errorf("unknown %v product name: %v", key, exts.ProductName)
 	}
 	return nil
 }
 
-// validateVcekCertificateProductNonspecific returns an error if the given certificate doesn't have
-// the documented qualities of a VCEK certificate according to Key Distribution Service
+// validateKDSCertificateProductNonspecific returns an error if the given certificate doesn't have
+// the documented qualities of a VCEK or VLEK certificate according to Key Distribution Service
 // documentation:
 // https://www.amd.com/system/files/TechDocs/57230.pdf
 // This does not check the certificate revocation list since that requires internet access.
-// If valid, then returns the VCEK-specific certificate extensions in the VcekExtensions type.
-func validateVcekCertificateProductNonspecific(cert *x509.Certificate) (*kds.VcekExtensions, error) {
+// If valid, then returns the key-specific certificate extensions in the Extensions type.
+func validateKDSCertificateProductNonspecific(cert *x509.Certificate, key abi.ReportSigner) (*kds.Extensions, error) {
 	if cert.Version != 3 {
-		return nil, fmt.Errorf("VCEK certificate version is %v, expected 3", cert.Version)
+		return nil, fmt.Errorf("%v certificate version is %v, expected 3", key, cert.Version)
 	}
 	// Signature algorithm: RSASSA-PSS
 	// Signature hash algorithm sha384
 	if cert.SignatureAlgorithm != x509.SHA384WithRSAPSS {
-		return nil, fmt.Errorf("VCEK certificate signature algorithm is %v, expected SHA-384 with RSASSA-PSS", cert.SignatureAlgorithm)
+		return nil, fmt.Errorf("%v certificate signature algorithm is %v, expected SHA-384 with RSASSA-PSS", key, cert.SignatureAlgorithm)
 	}
 	// Subject Public Key Info ECDSA on curve P-384
 	if cert.PublicKeyAlgorithm != x509.ECDSA {
-		return nil, fmt.Errorf("VCEK certificate public key type is %v, expected ECDSA", cert.PublicKeyAlgorithm)
+		return nil, fmt.Errorf("%v certificate public key type is %v, expected ECDSA", key, cert.PublicKeyAlgorithm)
 	}
 	// Locally bind the public key any type to allow for occurrence typing in the switch statement.
 	switch pub := cert.PublicKey.(type) {
 	case *ecdsa.PublicKey:
 		if pub.Curve.Params().Name != "P-384" {
-			return nil, fmt.Errorf("VCEK certificate public key curve is %s, expected P-384", pub.Curve.Params().Name)
+			return nil, fmt.Errorf("%v certificate public key curve is %s, expected P-384", key, pub.Curve.Params().Name)
 		}
 	default:
-		return nil, fmt.Errorf("VCEK certificate public key not ecdsa PublicKey type %v", pub)
+		return nil, fmt.Errorf("%v certificate public key not ecdsa PublicKey type %v", key, pub)
 	}
 
-	if err := ValidateVcekCertSubject(cert.Subject); err != nil {
+	if err := ValidateKDSCertSubject(cert.Subject, key); err != nil {
 		return nil, err
 	}
-	exts, err := kds.VcekCertificateExtensions(cert)
+	exts, err := kds.CertificateExtensions(cert, key)
 	if err != nil {
 		return nil, err
 	}
-	if err := ValidateVcekExtensions(exts); err != nil {
+	if err := validateExtensions(exts, key); err != nil {
 		return nil, err
 	}
 	return exts, nil
 }
 
-func validateVcekCertificateProductSpecifics(r *trust.AMDRootCerts, cert *x509.Certificate, opts *Options) error {
-	if err := ValidateVcekCertIssuer(r, cert.Issuer); err != nil {
+func validateKDSCertificateProductSpecifics(r *trust.AMDRootCerts, cert *x509.Certificate, key abi.ReportSigner, opts *Options) error {
+	if err := ValidateKDSCertIssuer(r, cert.Issuer, key); err != nil {
 		return err
 	}
-	if _, err := cert.Verify(*r.X509Options(opts.Now)); err != nil {
-		return fmt.Errorf("error verifying VCEK certificate: %v (%v)", err, r.ProductCerts.Ask.IsCA)
+	x509Opts := r.X509OptionsForKey(opts.Now, key)
+	if x509Opts == nil {
+		return fmt.Errorf("root of trust is missing the %s or ARK certificate to verify the %v certificate", intermediateName(key), key)
+	}
+	if _, err := cert.Verify(*x509Opts); err != nil {
+		return fmt.Errorf("error verifying %v certificate: %v (%v)", key, err, r.ProductCerts.Intermediate(key).IsCA)
 	}
-	// VCEK is not expected to have a CRL link.
+	// VCEK and VLEK are not expected to have a CRL link.
 	return nil
 }
 
-// VcekDER checks that the VCEK certificate matches expected fields
+// decodeCerts checks that the VCEK or VLEK certificate matches expected fields
 // from the KDS specification and also that its certificate chain matches
 // hardcoded trusted root certificates from AMD.
-func VcekDER(vcek []byte, ask []byte, ark []byte, options *Options) (*x509.Certificate, *trust.AMDRootCerts, error) {
-	vcekCert, err := x509.ParseCertificate(vcek)
+func decodeCerts(ek []byte, intermediate []byte, ark []byte, key abi.ReportSigner, options *Options) (*x509.Certificate, *trust.AMDRootCerts, error) {
+	ekCert, err := x509.ParseCertificate(ek)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not interpret VCEK DER bytes: %v", err)
+		return nil, nil, fmt.Errorf("could not interpret %v DER bytes: %v", key, err)
 	}
-	exts, err := validateVcekCertificateProductNonspecific(vcekCert)
+	exts, err := validateKDSCertificateProductNonspecific(ekCert, key)
 	if err != nil {
 		return nil, nil, err
 	}
 	roots := options.TrustedRoots
-	product := vcekProductMap[exts.ProductName]
+	product, err := kds.ProductLine(exts.ProductName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if options.Product != "" && options.Product != product {
+		return nil, nil, fmt.Errorf("%v certificate product %q is not the expected product %q", key, product, options.Product)
+	}
 	if len(roots) == 0 {
+		embedded, ok := trust.DefaultRootCerts[product]
+		if !ok {
+			return nil, nil, fmt.Errorf("no embedded AMD root certificates for product %q. Provide TrustedRoots", product)
+		}
 		logger.Warning("Using embedded AMD certificates for SEV-SNP attestation root of trust")
 		root := &trust.AMDRootCerts{
 			Product: product,
 			// Require that the root matches embedded root certs.
-			AskSev: trust.DefaultRootCerts[product].AskSev,
-			ArkSev: trust.DefaultRootCerts[product].ArkSev,
+			AskSev: embedded.AskSev,
+			ArkSev: embedded.ArkSev,
 		}
-		if err := root.FromDER(ask, ark); err != nil {
+		if err := root.FromDER(intermediate, ark); err != nil {
 			return nil, nil, err
 		}
 		if err := ValidateX509(root); err != nil {
@@ -423,13 +534,27 @@ func VcekDER(vcek []byte, ask []byte, ark []byte, options *Options) (*x509.Certi
 	}
 	var lastErr error
 	for _, productRoot := range roots[product] {
-		if err := validateVcekCertificateProductSpecifics(productRoot, vcekCert, options); err != nil {
+		if err := validateKDSCertificateProductSpecifics(productRoot, ekCert, key, options); err != nil {
 			lastErr = err
 			continue
 		}
-		return vcekCert, productRoot, nil
+		return ekCert, productRoot, nil
 	}
-	return nil, nil, fmt.Errorf("VCEK could not be verified by any trusted roots. Last error: %v", lastErr)
+	return nil, nil, fmt.Errorf("%v could not be verified by any trusted roots. Last error: %v", key, lastErr)
+}
+
+// VcekDER checks that the VCEK certificate matches expected fields
+// from the KDS specification and also that its certificate chain matches
+// hardcoded trusted root certificates from AMD.
+func VcekDER(vcek []byte, ask []byte, ark []byte, options *Options) (*x509.Certificate, *trust.AMDRootCerts, error) {
+	return decodeCerts(vcek, ask, ark, abi.VcekReportSigner, options)
+}
+
+// VlekDER checks that the VLEK certificate matches expected fields
+// from the KDS specification and also that its certificate chain matches
+// hardcoded trusted root certificates from AMD.
+func VlekDER(vlek []byte, asvk []byte, ark []byte, options *Options) (*x509.Certificate, *trust.AMDRootCerts, error) {
+	return decodeCerts(vlek, asvk, ark, abi.VlekReportSigner, options)
 }
 
 // SnpReportSignature verifies the attestation report's signature based on the report's
@@ -479,6 +604,13 @@ type Options struct {
 	KDSClockSkewThreshold time.Duration
 	// Now is the time at which to verify the validity of certificates. If unset, uses time.Now().
 	Now time.Time
+	// Observer receives CRL refresh and verification outcome events. If nil, uses
+	// observe.Default().
+	Observer observe.Observer
+	// Product is the AMD product line, e.g., "Milan", that the attestation is expected to come from.
+	// If empty, the product is detected from the attestation's certificates or CPUID fields, and
+	// verification fails if neither identifies it.
+	Product string
 	// TrustedRoots specifies the ARK and ASK certificates to trust when checking the VCEK. If nil,
 	// then verification will fall back on embedded AMD-published root certificates.
 	// Maps the product name to an array of allowed roots.
@@ -494,21 +626,47 @@ func DefaultOptions() *Options {
 	}
 }
 
+// rootProduct returns the given product if not empty, or else the product of the root's signing
+// key certificate.
+func rootProduct(product string, root *trust.AMDRootCerts) (string, error) {
+	if product != "" {
+		return product, nil
+	}
+	intermediate := root.ProductCerts.Ask
+	if intermediate == nil {
+		intermediate = root.ProductCerts.Asvk
+	}
+	return kds.ProductFromSigningKey(intermediate)
+}
+
 func getTrustedRoots(rot *cpb.RootOfTrust) (map[string][]*trust.AMDRootCerts, error) {
 	result := map[string][]*trust.AMDRootCerts{}
+	addRoot := func(root *trust.AMDRootCerts) error {
+		product, err := rootProduct(rot.Product, root)
+		if err != nil {
+			return fmt.Errorf("could not determine CA bundle product: %v", err)
+		}
+		root.Product = product
+		result[product] = append(result[product], root)
+		return nil
+	}
 	for _, path := range rot.CabundlePaths {
-		root := &trust.AMDRootCerts{Product: rot.Product}
+		root := &trust.AMDRootCerts{}
 		if err := root.FromKDSCert(path); err != nil {
 			return nil, fmt.Errorf("could not parse CA bundle %q: %v", path, err)
 		}
-		result[rot.Product] = append(result[rot.Product], root)
+		if err := addRoot(root); err != nil {
+			return nil, err
+		}
 	}
 	for _, cabundle := range rot.Cabundles {
-		root := &trust.AMDRootCerts{Product: rot.Product}
+		root := &trust.AMDRootCerts{}
 		if err := root.FromKDSCertBytes([]byte(cabundle)); err != nil {
 			return nil, fmt.Errorf("could not parse CA bundle bytes: %v", err)
 		}
-		result[rot.Product] = append(result[rot.Product], root)
+		if err := addRoot(root); err != nil {
+			return nil, err
+		}
 	}
 	return result, nil
 }
@@ -523,38 +681,113 @@ func RootOfTrustToOptions(rot *cpb.RootOfTrust) (*Options, error) {
 	return &Options{
 		CheckRevocations:    rot.CheckCrl,
 		DisableCertFetching: rot.DisallowNetwork,
+		Product:             rot.Product,
 		TrustedRoots:        trustedRoots,
 	}, nil
 }
 
-// SnpAttestation verifies the protobuf representation of an attestation report's signature based
-// on the report's SignatureAlgo, provided the certificate chain is valid.
-func SnpAttestation(attestation *spb.Attestation, options *Options) error {
+// Names of the checks that SnpAttestationResult records.
+const (
+	// CertificateChainCheck is the check that the endorsement key certificate chains to a trusted
+	// AMD root.
+	CertificateChainCheck = "certificate_chain"
+	// CRLCheck is the check that no certificate in the chain is revoked.
+	CRLCheck = "crl"
+	// SignatureCheck is the check of the report signature by the endorsement key.
+	SignatureCheck = "signature"
+)
+
+// SnpAttestationResult verifies the protobuf representation of an attestation report's signature
+// based on the report's SignatureAlgo, provided the certificate chain is valid, and records the
+// outcome of each check. Checks after a failed check are skipped.
+func SnpAttestationResult(attestation *spb.Attestation, options *Options) *checks.Result {
+	return SnpAttestationResultContext(context.Background(), attestation, options)
+}
+
+// SnpAttestationResultContext is SnpAttestationResult, but certificate and CRL downloads and waits
+// for clock skew stop when ctx is done.
+func SnpAttestationResultContext(ctx context.Context, attestation *spb.Attestation, options *Options) *checks.Result {
+	start := time.Now()
+	result := snpAttestationResult(ctx, attestation, options)
+	var observer observe.Observer
+	if options != nil {
+		observer = options.Observer
+	}
+	observe.OrDefault(observer).Outcome(ctx, &observe.Outcome{
+		Stage:    observe.StageVerify,
+		Result:   result,
+		Duration: time.Since(start),
+	})
+	return result
+}
+
+func snpAttestationResult(ctx context.Context, attestation *spb.Attestation, options *Options) *checks.Result {
+	result := &checks.Result{}
+	skip := func(names ...string) *checks.Result {
+		for _, name := range names {
+			result.Skip(name)
+		}
+		return result
+	}
 	if options == nil {
-		return fmt.Errorf("options cannot be nil")
+		result.Record(CertificateChainCheck, "", "", fmt.Errorf("options cannot be nil"))
+		return skip(CRLCheck, SignatureCheck)
 	}
 	// Make sure we have the whole certificate chain if we're allowed.
 	if !options.DisableCertFetching {
-		if err := fillInAttestation(attestation, options); err != nil {
-			return err
+		if err := fillInAttestation(ctx, attestation, options); err != nil {
+			result.Record(CertificateChainCheck, "", "", err)
+			return skip(CRLCheck, SignatureCheck)
 		}
 	}
+	info, err := abi.ParseSignerInfo(attestation.GetReport().GetSignerInfo())
+	if err != nil {
+		result.Record(CertificateChainCheck, "", "", err)
+		return skip(CRLCheck, SignatureCheck)
+	}
 	chain := attestation.GetCertificateChain()
-	vcek, root, err := VcekDER(chain.GetVcekCert(), chain.GetAskCert(), chain.GetArkCert(), options)
+	var endorsementKeyCert *x509.Certificate
+	var root *trust.AMDRootCerts
+	switch info.SigningKey {
+	case abi.VcekReportSigner:
+		endorsementKeyCert, root, err = VcekDER(chain.GetVcekCert(), chain.GetAskCert(), chain.GetArkCert(), options)
+	case abi.VlekReportSigner:
+		endorsementKeyCert, root, err = VlekDER(chain.GetVlekCert(), chain.GetAskCert(), chain.GetArkCert(), options)
+	default:
+		err = fmt.Errorf("report signing key %v is not supported", info.SigningKey)
+	}
+	result.Record(CertificateChainCheck, "", info.SigningKey.String(), err)
 	if err != nil {
-		return err
+		return skip(CRLCheck, SignatureCheck)
 	}
-	if options != nil && options.CheckRevocations {
-		if err := VcekNotRevoked(root, vcek, options); err != nil {
-			return err
+	if options.CheckRevocations {
+		if _, err := getCrlAndCheckRoot(ctx, root, info.SigningKey, options); err != nil {
+			result.Record(CRLCheck, "", "", err)
+			return skip(SignatureCheck)
 		}
+		result.Record(CRLCheck, "", "", nil)
+	} else {
+		result.Skip(CRLCheck)
 	}
-	return SnpProtoReportSignature(attestation.GetReport(), vcek)
+	result.Record(SignatureCheck, "", "", SnpProtoReportSignature(attestation.GetReport(), endorsementKeyCert))
+	return result
+}
+
+// SnpAttestation verifies the protobuf representation of an attestation report's signature based
+// on the report's SignatureAlgo, provided the certificate chain is valid.
+func SnpAttestation(attestation *spb.Attestation, options *Options) error {
+	return SnpAttestationResult(attestation, options).Err()
+}
+
+// SnpAttestationContext is SnpAttestation, but certificate and CRL downloads and waits for clock
+// skew stop when ctx is done.
+func SnpAttestationContext(ctx context.Context, attestation *spb.Attestation, options *Options) error {
+	return SnpAttestationResultContext(ctx, attestation, options).Err()
 }
 
 // waitForClockSkew allows a fresh certificate to be NotBefore a future time if that time is within
 // a threshold of acceptable clock skew between the host and KDS.
-func waitForClockSkew(certRaw []byte, opts *Options) error {
+func waitForClockSkew(ctx context.Context, certRaw []byte, opts *Options) error {
 	cert, err := x509.ParseCertificate(certRaw)
 	if err != nil {
 		return err
@@ -580,7 +813,11 @@ func waitForClockSkew(certRaw []byte, opts *Options) error {
 		if now.IsZero() {
 			// The system time is used for verification, so wait until the future
 			// time of NotBefore before continuing.
-			time.Sleep(skew)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(skew):
+			}
 		} else {
 			// The Now value won't be interpreted as time.Now() since it's not zero, but
 			// the threshold is acceptable to bump up the Now option for verification.
@@ -590,44 +827,119 @@ func waitForClockSkew(certRaw []byte, opts *Options) error {
 	return nil
 }
 
+// productFromCert returns the product line of the DER-formatted certificate if it is a well-formed
+// VCEK or VLEK certificate.
+func productFromCert(der []byte, key abi.ReportSigner) (string, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return "", err
+	}
+	exts, err := kds.CertificateExtensions(cert, key)
+	if err != nil {
+		return "", err
+	}
+	return kds.ProductLine(exts.ProductName)
+}
+
+// productFromSigningKey returns the product line of the DER-formatted ASK or ASVK certificate.
+func productFromSigningKey(der []byte) (string, error) {
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return "", err
+	}
+	return kds.ProductFromSigningKey(cert)
+}
+
+// detectProduct returns the AMD product line that produced the attestation. The product is given
+// by the options if set, or else by the endorsement key certificate's product name extension, or
+// else by the signing key certificate's common name, or else by the report's CPUID fields. Returns
+// an error if none of these identify the product.
+func detectProduct(attestation *spb.Attestation, key abi.ReportSigner, options *Options) (string, error) {
+	if options.Product != "" {
+		return options.Product, nil
+	}
+	chain := attestation.GetCertificateChain()
+	ek := chain.GetVcekCert()
+	if key == abi.VlekReportSigner {
+		ek = chain.GetVlekCert()
+	}
+	if len(ek) != 0 {
+		if product, err := productFromCert(ek, key); err == nil {
+			return product, nil
+		}
+	}
+	if len(chain.GetAskCert()) != 0 {
+		if product, err := productFromSigningKey(chain.GetAskCert()); err == nil {
+			return product, nil
+		}
+	}
+	report := attestation.GetReport()
+	if report.GetVersion() >= abi.CPUIDReportVersion {
+		if product, err := kds.ProductFromCPUID(uint8(report.GetCpuidFamId()), uint8(report.GetCpuidModId())); err == nil {
+			return product, nil
+		}
+	}
+	// A version 2 attestation report alone does not identify the product it was produced on.
+	return "", errors.New("could not determine the attestation's AMD product. Set Options.Product")
+}
+
 // fillInAttestation uses AMD's KDS to populate any empty certificate field in the attestation's
 // certificate chain.
-func fillInAttestation(attestation *spb.Attestation, options *Options) error {
-	// TODO(Issue #11): Determine the product a report was fetched from, or make this an option.
-	product := "Milan"
+func fillInAttestation(ctx context.Context, attestation *spb.Attestation, options *Options) error {
 	getter := options.Getter
 	if getter == nil {
 		getter = trust.DefaultHTTPSGetter()
 	}
 	report := attestation.GetReport()
+	info, err := abi.ParseSignerInfo(report.GetSignerInfo())
+	if err != nil {
+		return err
+	}
 	chain := attestation.GetCertificateChain()
 	if chain == nil {
 		chain = &spb.CertificateChain{}
 		attestation.CertificateChain = chain
 	}
+	if info.SigningKey == abi.NoneReportSigner {
+		return fmt.Errorf("report signing key %v has no certificate chain", info.SigningKey)
+	}
+	product, err := detectProduct(attestation, info.SigningKey, options)
+	if err != nil {
+		return err
+	}
 	if len(chain.GetAskCert()) == 0 || len(chain.GetArkCert()) == 0 {
-		askark, err := trust.GetProductChain(product, getter)
+		askark, err := trust.GetProductChainContext(ctx, product, info.SigningKey, getter)
 		if err != nil {
 			return err
 		}
 
 		if len(chain.GetAskCert()) == 0 {
-			chain.AskCert = askark.Ask.Raw
+			chain.AskCert = askark.Intermediate(info.SigningKey).Raw
 		}
 		if len(chain.GetArkCert()) == 0 {
 			chain.ArkCert = askark.Ark.Raw
 		}
 	}
+	if info.SigningKey == abi.VlekReportSigner {
+		// The VLEK is provisioned by the cloud service provider, so the KDS cannot provide it.
+		if len(chain.GetVlekCert()) == 0 {
+			return errors.New("VLEK certificate is missing and cannot be fetched from the AMD KDS")
+		}
+		return nil
+	}
 	if len(chain.GetVcekCert()) == 0 {
 		vcekURL := kds.VCEKCertURL(product, report.GetChipId(), kds.TCBVersion(report.GetCurrentTcb()))
-		vcek, err := getter.Get(vcekURL)
+		vcek, err := trust.GetWithContext(ctx, getter, vcekURL)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("could not download VCEK certificate: %w", ctxErr)
+		}
 		if err != nil {
 			return &trust.AttestationRecreationErr{
 				Msg: fmt.Sprintf("could not download VCEK certificate: %v", err),
 			}
 		}
 		chain.VcekCert = vcek
-		return waitForClockSkew(vcek, options)
+		return waitForClockSkew(ctx, vcek, options)
 	}
 	return nil
 }
@@ -636,11 +948,17 @@ func fillInAttestation(attestation *spb.Attestation, options *Options) error {
 // chain for the VCEK that supposedly signed the given report, and returns the Attestation
 // representation of their combination. If getter is nil, uses Golang's http.Get.
 func GetAttestationFromReport(report *spb.Report, options *Options) (*spb.Attestation, error) {
+	return GetAttestationFromReportContext(context.Background(), report, options)
+}
+
+// GetAttestationFromReportContext is GetAttestationFromReport, but gives up downloading the
+// certificate chain when ctx is done.
+func GetAttestationFromReportContext(ctx context.Context, report *spb.Report, options *Options) (*spb.Attestation, error) {
 	result := &spb.Attestation{
 		Report:           report,
 		CertificateChain: &spb.CertificateChain{},
 	}
-	if err := fillInAttestation(result, options); err != nil {
+	if err := fillInAttestation(ctx, result, options); err != nil {
 		return nil, err
 	}
 	return result, nil
@@ -650,14 +968,20 @@ func GetAttestationFromReport(report *spb.Report, options *Options) (*spb.Attest
 // on the report's SignatureAlgo and uses the AMD Key Distribution Service to download the
 // report's corresponding VCEK certificate.
 func SnpReport(report *spb.Report, options *Options) error {
+	return SnpReportContext(context.Background(), report, options)
+}
+
+// SnpReportContext is SnpReport, but certificate and CRL downloads and waits for clock skew stop
+// when ctx is done.
+func SnpReportContext(ctx context.Context, report *spb.Report, options *Options) error {
 	if options.DisableCertFetching {
 		return errors.New("cannot verify attestation report without fetching certificates")
 	}
-	attestation, err := GetAttestationFromReport(report, options)
+	attestation, err := GetAttestationFromReportContext(ctx, report, options)
 	if err != nil {
 		return fmt.Errorf("could not recreate attestation from report: %w", err)
 	}
-	return SnpAttestation(attestation, options)
+	return SnpAttestationContext(ctx, attestation, options)
 }
 
 // RawSnpReport verifies the raw bytes representation of an attestation report's signature
@@ -16,10 +16,12 @@ package verify
 
 import (
 	"bytes"
+	"context"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	_ "embed"
 	"encoding/asn1"
+	"errors"
 	"fmt"
 	"math/big"
 	"math/rand"
@@ -29,8 +31,10 @@ import (
 	"time"
 
 	"github.com/google/go-sev-guest/abi"
+	"github.com/google/go-sev-guest/checks"
 	sg "github.com/google/go-sev-guest/client"
 	"github.com/google/go-sev-guest/kds"
+	"github.com/google/go-sev-guest/observe"
 	pb "github.com/google/go-sev-guest/proto/sevsnp"
 	test "github.com/google/go-sev-guest/testing"
 	testclient "github.com/google/go-sev-guest/testing/client"
@@ -70,6 +74,36 @@ func TestEmbeddedCertsAppendixB3Expectations(t *testing.T) {
 	}
 }
 
+// embeddedProducts lists the products whose AMD root certificates are embedded. Genoa's
+// ask_ark_genoa.sevcert is not embedded yet, so verifying Genoa attestations needs TrustedRoots.
+var embeddedProducts = map[string]bool{"Milan": true}
+
+func TestEmbeddedCertsPerProduct(t *testing.T) {
+	for _, product := range kds.Products {
+		t.Run(product, func(t *testing.T) {
+			root, ok := trust.DefaultRootCerts[product]
+			if ok != embeddedProducts[product] {
+				t.Fatalf("embedded %s root certificates present: %v, want %v", product, ok, embeddedProducts[product])
+			}
+			if !ok {
+				return
+			}
+			if root.Product != product {
+				t.Errorf("embedded %s root certificates are for product %q", product, root.Product)
+			}
+			if root.AskSev == nil || root.ArkSev == nil {
+				t.Fatalf("embedded %s root certificates are missing the ASK or ARK", product)
+			}
+			if err := ValidateArkSev(root); err != nil {
+				t.Errorf("embedded %s ARK is not self-certified: %v", product, err)
+			}
+			if err := ValidateAskSev(root); err != nil {
+				t.Errorf("embedded %s ASK is not certified by its ARK: %v", product, err)
+			}
+		})
+	}
+}
+
 func TestFakeCertsKDSExpectations(t *testing.T) {
 	signMu.Do(initSigner)
 	trust.ClearProductCertCache()
@@ -94,7 +128,7 @@ func TestParseVcekCert(t *testing.T) {
 	if err != nil {
 		t.Errorf("could not parse valid VCEK certificate: %v", err)
 	}
-	if _, err := validateVcekCertificateProductNonspecific(cert); err != nil {
+	if _, err := validateKDSCertificateProductNonspecific(cert, abi.VcekReportSigner); err != nil {
 		t.Errorf("could not validate valid VCEK certificate: %v", err)
 	}
 }
@@ -477,6 +511,7 @@ func TestClockSkew(t *testing.T) {
 				Getter:                getter,
 				KDSClockSkewThreshold: tc.threshold,
 				TrustedRoots:          goodRoots,
+				Product:               "Milan",
 				Now:                   tc.now,
 			})
 			if !test.Match(err, tc.wantErr) {
@@ -486,6 +521,29 @@ func TestClockSkew(t *testing.T) {
 	}
 }
 
+func TestWaitForClockSkewContext(t *testing.T) {
+	sb := &test.AmdSignerBuilder{
+		Product:          "Milan",
+		ArkCreationTime:  time.Now().Add(10 * time.Second),
+		AskCreationTime:  time.Now().Add(10 * time.Second),
+		VcekCreationTime: time.Now(),
+	}
+	future, err := sb.CertChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err = waitForClockSkew(ctx, future.Ask.Raw, &Options{KDSClockSkewThreshold: time.Minute})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("waitForClockSkew() = %v, want DeadlineExceeded", err)
+	}
+	if waited := time.Since(start); waited > 5*time.Second {
+		t.Errorf("waitForClockSkew() waited %v after its context's deadline", waited)
+	}
+}
+
 func TestOpenGetExtendedReportVerifyClose(t *testing.T) {
 	trust.ClearProductCertCache()
 	tests := test.TestCases()
@@ -512,8 +570,8 @@ func TestOpenGetExtendedReportVerifyClose(t *testing.T) {
 		},
 	}
 	// Trust the test device's root certs.
-	options := &Options{TrustedRoots: goodRoots, Getter: kds}
-	badOptions := &Options{TrustedRoots: badRoots, Getter: kds}
+	options := &Options{TrustedRoots: goodRoots, Getter: kds, Product: "Milan"}
+	badOptions := &Options{TrustedRoots: badRoots, Getter: kds, Product: "Milan"}
 	for _, tc := range tests {
 		if testclient.SkipUnmockableTestCase(&tc) {
 			continue
@@ -536,6 +594,39 @@ func TestOpenGetExtendedReportVerifyClose(t *testing.T) {
 	}
 }
 
+func TestObserver(t *testing.T) {
+	trust.ClearProductCertCache()
+	tests := test.TestCases()
+	d, goodRoots, _, kds := testclient.GetSevGuest(tests, &test.DeviceOptions{Now: time.Now()}, t)
+	defer d.Close()
+	attestation, err := sg.GetExtendedReport(d, tests[0].Input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	observer := &test.Observer{}
+	options := &Options{TrustedRoots: goodRoots, Getter: kds, CheckRevocations: true, Observer: observer}
+	// The fake KDS does not serve CRLs.
+	if err := SnpAttestation(attestation, options); err == nil {
+		t.Fatal("SnpAttestation() = nil, want CRL error")
+	}
+	if len(observer.CRLRefreshes) != 1 {
+		t.Fatalf("observed %d CRL refreshes, want 1", len(observer.CRLRefreshes))
+	}
+	if refresh := observer.CRLRefreshes[0]; refresh.Signer != "VCEK" || refresh.Err == nil {
+		t.Errorf("observed CRL refresh %+v, want a failed VCEK refresh", refresh)
+	}
+	if len(observer.Outcomes) != 1 {
+		t.Fatalf("observed %d outcomes, want 1", len(observer.Outcomes))
+	}
+	outcome := observer.Outcomes[0]
+	if outcome.Stage != observe.StageVerify {
+		t.Errorf("observed outcome of stage %q, want %q", outcome.Stage, observe.StageVerify)
+	}
+	if c := outcome.Result.Get(CRLCheck); c == nil || c.Status != checks.Failed {
+		t.Errorf("observed CRL check %+v, want failed", c)
+	}
+}
+
 func TestRealAttestationVerification(t *testing.T) {
 	trust.ClearProductCertCache()
 	var nonce [64]byte
@@ -547,7 +638,168 @@ func TestRealAttestationVerification(t *testing.T) {
 			"https://kdsintf.amd.com/vcek/v1/Milan/3ac3fe21e13fb0990eb28a802e3fb6a29483a6b0753590c951bdd3b8e53786184ca39e359669a2b76a1936776b564ea464cdce40c05f63c9b610c5068b006b5d?blSPL=2&teeSPL=0&snpSPL=5&ucodeSPL=68": testdata.VcekBytes,
 		},
 	}
-	if err := RawSnpReport(testdata.AttestationBytes, &Options{Getter: getter}); err != nil {
+	if err := RawSnpReport(testdata.AttestationBytes, &Options{Getter: getter, Product: "Milan"}); err != nil {
 		t.Error(err)
 	}
 }
+
+func TestVlekSnpAttestation(t *testing.T) {
+	keys, err := test.DefaultAmdKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.Asvk, err = test.DefaultAsvk(); err != nil {
+		t.Fatal(err)
+	}
+	if keys.Vlek, err = test.DefaultVlek(); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2023, time.March, 1, 9, 0, 0, 0, time.UTC)
+	sb := &test.AmdSignerBuilder{
+		Keys:             keys,
+		Product:          product,
+		ArkCreationTime:  now,
+		AskCreationTime:  now,
+		VcekCreationTime: now,
+	}
+	vlekSigner, err := sb.CertChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	makeReport := func(signerInfo uint32, sign func([]byte) (*big.Int, *big.Int, error)) *pb.Report {
+		opts := &test.AttestationOptions{SignerInfo: signerInfo, Sign: sign}
+		return test.SignedAttestation(t, vlekSigner, opts).GetReport()
+	}
+	vlekInfo := abi.ComposeSignerInfo(abi.SignerInfo{SigningKey: abi.VlekReportSigner})
+	vlekReport := makeReport(vlekInfo, vlekSigner.SignVlek)
+	vcekClaimingVlek := makeReport(vlekInfo, vlekSigner.Sign)
+	goodRoots := map[string][]*trust.AMDRootCerts{
+		product: {{
+			Product: product,
+			ProductCerts: &trust.ProductCerts{
+				Asvk: vlekSigner.Asvk,
+				Ark:  vlekSigner.Ark,
+			},
+		}},
+	}
+	chain := &pb.CertificateChain{
+		VlekCert: vlekSigner.Vlek.Raw,
+		AskCert:  vlekSigner.Asvk.Raw,
+		ArkCert:  vlekSigner.Ark.Raw,
+	}
+	tcs := []struct {
+		name        string
+		attestation *pb.Attestation
+		wantErr     string
+	}{
+		{
+			name:        "happy path",
+			attestation: &pb.Attestation{Report: vlekReport, CertificateChain: chain},
+		},
+		{
+			name: "missing VLEK certificate",
+			attestation: &pb.Attestation{Report: vlekReport, CertificateChain: &pb.CertificateChain{
+				AskCert: vlekSigner.Asvk.Raw,
+				ArkCert: vlekSigner.Ark.Raw,
+			}},
+			wantErr: "VLEK certificate is missing and cannot be fetched from the AMD KDS",
+		},
+		{
+			name:        "VCEK signature with VLEK signing key",
+			attestation: &pb.Attestation{Report: vcekClaimingVlek, CertificateChain: chain},
+			wantErr:     "report signature verification error",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			options := &Options{TrustedRoots: goodRoots, Now: now.Add(time.Hour)}
+			if err := SnpAttestation(tc.attestation, options); !test.Match(err, tc.wantErr) {
+				t.Errorf("SnpAttestation(%v) = %v. Want err: %q", tc.attestation, err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestProductDetection(t *testing.T) {
+	if !sg.UseDefaultSevGuest() {
+		t.Skip("Skipping fake product detection test for hardware device testing")
+	}
+	trust.ClearProductCertCache()
+	defer trust.ClearProductCertCache()
+	now := time.Date(2023, time.March, 1, 9, 0, 0, 0, time.UTC)
+	sb := &test.AmdSignerBuilder{
+		Product:          "Genoa",
+		ArkCreationTime:  now,
+		AskCreationTime:  now,
+		VcekCreationTime: now,
+	}
+	genoaSigner, err := sb.CertChain()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := test.TestCases()
+	d, goodRoots, _, getter := testclient.GetSevGuest(tests, &test.DeviceOptions{Now: now, Signer: genoaSigner}, t)
+	defer d.Close()
+	if _, ok := goodRoots["Genoa"]; !ok {
+		t.Fatalf("test roots are not for Genoa: %v", goodRoots)
+	}
+	var nonce [64]byte
+	extended, err := sg.GetExtendedReport(d, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := sg.GetReport(d, nonce)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tcs := []struct {
+		name        string
+		attestation *pb.Attestation
+		product     string
+		embedded    bool
+		wantErr     string
+	}{
+		{
+			name:        "detected from VCEK",
+			attestation: extended,
+		},
+		{
+			name:        "no embedded roots",
+			attestation: extended,
+			embedded:    true,
+			wantErr:     "no embedded AMD root certificates for product \"Genoa\". Provide TrustedRoots",
+		},
+		{
+			name:        "expected product",
+			attestation: extended,
+			product:     "Genoa",
+		},
+		{
+			name:        "unexpected product",
+			attestation: extended,
+			product:     "Milan",
+			wantErr:     "VCEK certificate product \"Genoa\" is not the expected product \"Milan\"",
+		},
+		{
+			name:        "report only with product",
+			attestation: &pb.Attestation{Report: report},
+			product:     "Genoa",
+		},
+		{
+			name:        "report only without product",
+			attestation: &pb.Attestation{Report: report},
+			wantErr:     "could not determine the attestation's AMD product. Set Options.Product",
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			options := &Options{TrustedRoots: goodRoots, Getter: getter, Product: tc.product, Now: now.Add(time.Hour)}
+			if tc.embedded {
+				options.TrustedRoots = nil
+			}
+			if err := SnpAttestation(tc.attestation, options); !test.Match(err, tc.wantErr) {
+				t.Errorf("SnpAttestation(%v) = %v. Want err: %q", tc.attestation, err, tc.wantErr)
+			}
+		})
+	}
+}
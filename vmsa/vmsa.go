@@ -0,0 +1,296 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vmsa represents the SEV-ES and SEV-SNP virtual machine save area (VMSA), the encrypted
+// initial register state of each vCPU that the launch digest measures.
+package vmsa
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+const (
+	// Size is the size of a VMSA page.
+	Size = 0x1000
+	// BSPResetEIP is the x86 reset vector at which the bootstrap processor starts.
+	BSPResetEIP = 0xFFFFFFF0
+)
+
+// SEV_FEATURES bits as defined in the AMD64 Architecture Programmer's Manual Volume 2, Table 15-34.
+const (
+	// SevFeatureSNPActive is set for every SEV-SNP guest.
+	SevFeatureSNPActive = 1 << 0
+	// SevFeatureVTOM enables the virtual top of memory.
+	SevFeatureVTOM = 1 << 1
+	// SevFeatureReflectVC reflects #VC exceptions to the hypervisor.
+	SevFeatureReflectVC = 1 << 2
+	// SevFeatureRestrictedInjection enables restricted interrupt injection.
+	SevFeatureRestrictedInjection = 1 << 3
+	// SevFeatureAlternateInjection enables alternate interrupt injection.
+	SevFeatureAlternateInjection = 1 << 4
+	// SevFeatureDebugSwap enables swapping of debug registers on world switch.
+	SevFeatureDebugSwap = 1 << 5
+	// SevFeaturePreventHostIBS prevents the host from using IBS on the guest.
+	SevFeaturePreventHostIBS = 1 << 6
+	// SevFeatureBTBIsolation isolates the branch target buffer from the host.
+	SevFeatureBTBIsolation = 1 << 7
+	// SevFeatureVmplSSS enables VMPL supervisor shadow stacks.
+	SevFeatureVmplSSS = 1 << 8
+	// SevFeatureSecureTSC enables the secure TSC.
+	SevFeatureSecureTSC = 1 << 9
+)
+
+// Segment is a segment register in the VMSA.
+type Segment struct {
+	Selector uint16
+	Attrib   uint16
+	Limit    uint32
+	Base     uint64
+}
+
+// SaveArea is the VMSA as laid out in Table B-4 of the AMD64 Architecture Programmer's Manual
+// Volume 2. Reserved bytes are not represented and must be zero.
+type SaveArea struct {
+	ES   Segment
+	CS   Segment
+	SS   Segment
+	DS   Segment
+	FS   Segment
+	GS   Segment
+	GDTR Segment
+	LDTR Segment
+	IDTR Segment
+	TR   Segment
+
+	Vmpl0SSP uint64
+	Vmpl1SSP uint64
+	Vmpl2SSP uint64
+	Vmpl3SSP uint64
+	UCET     uint64
+	_        [2]byte
+	VMPL     uint8
+	CPL      uint8
+	_        [4]byte
+	EFER     uint64
+	_        [104]byte
+
+	XSS         uint64
+	CR4         uint64
+	CR3         uint64
+	CR0         uint64
+	DR7         uint64
+	DR6         uint64
+	RFLAGS      uint64
+	RIP         uint64
+	DR0         uint64
+	DR1         uint64
+	DR2         uint64
+	DR3         uint64
+	DR0AddrMask uint64
+	DR1AddrMask uint64
+	DR2AddrMask uint64
+	DR3AddrMask uint64
+	_           [24]byte
+
+	RSP          uint64
+	SCET         uint64
+	SSP          uint64
+	ISSTAddr     uint64
+	RAX          uint64
+	STAR         uint64
+	LSTAR        uint64
+	CSTAR        uint64
+	SFMASK       uint64
+	KernelGSBase uint64
+	SysenterCS   uint64
+	SysenterESP  uint64
+	SysenterEIP  uint64
+	CR2          uint64
+	_            [32]byte
+
+	GPAT         uint64
+	DbgCtl       uint64
+	BrFrom       uint64
+	BrTo         uint64
+	LastExcpFrom uint64
+	LastExcpTo   uint64
+	_            [80]byte
+	PKRU         uint32
+	_            [28]byte
+
+	RCX uint64
+	RDX uint64
+	RBX uint64
+	_   uint64
+	RBP uint64
+	RSI uint64
+	RDI uint64
+	R8  uint64
+	R9  uint64
+	R10 uint64
+	R11 uint64
+	R12 uint64
+	R13 uint64
+	R14 uint64
+	R15 uint64
+	_   [16]byte
+
+	GuestExitInfo1   uint64
+	GuestExitInfo2   uint64
+	GuestExitIntInfo uint64
+	GuestNRIP        uint64
+	SevFeatures      uint64
+	VintrCtrl        uint64
+	GuestExitCode    uint64
+	VirtualTOM       uint64
+	TLBID            uint64
+	PCPUID           uint64
+	EventInj         uint64
+	XCR0             uint64
+	_                [16]byte
+
+	X87DP    uint64
+	MXCSR    uint32
+	X87FTW   uint16
+	X87FSW   uint16
+	X87FCW   uint16
+	X87FOP   uint16
+	X87DS    uint16
+	X87CS    uint16
+	X87RIP   uint64
+	FPRegX87 [80]byte
+	FPRegXMM [256]byte
+	FPRegYMM [256]byte
+	_        [0x990]byte
+}
+
+// Marshal returns the VMSA page.
+func (s *SaveArea) Marshal() []byte {
+	var b bytes.Buffer
+	b.Grow(Size)
+	// Writes to a bytes.Buffer of a fixed-size struct cannot fail.
+	binary.Write(&b, binary.LittleEndian, s)
+	return b.Bytes()
+}
+
+// ParseSaveArea returns the save area of a VMSA page.
+func ParseSaveArea(data []byte) (*SaveArea, error) {
+	if len(data) != Size {
+		return nil, fmt.Errorf("VMSA size is 0x%x, expected 0x%x", len(data), Size)
+	}
+	s := &SaveArea{}
+	if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, s); err != nil {
+		return nil, err
+	}
+	// Reserved bytes are skipped when reading, so they are zero iff the page round trips.
+	if !bytes.Equal(s.Marshal(), data) {
+		return nil, errors.New("VMSA has non-zero reserved bytes")
+	}
+	return s, nil
+}
+
+// Digest returns the SHA-384 digest of the VMSA page that the launch digest includes.
+func (s *SaveArea) Digest() [sha512.Size384]byte {
+	return sha512.Sum384(s.Marshal())
+}
+
+// ResetSaveArea returns the VMSA that QEMU and KVM create for a vCPU with the given processor
+// signature that starts executing at eip in real mode.
+func ResetSaveArea(eip uint32, sevFeatures uint64, vcpuSig uint32) *SaveArea {
+	data := Segment{Attrib: 0x93, Limit: 0xffff}
+	return &SaveArea{
+		ES:   data,
+		CS:   Segment{Selector: 0xf000, Attrib: 0x9b, Limit: 0xffff, Base: uint64(eip & 0xffff0000)},
+		SS:   data,
+		DS:   data,
+		FS:   data,
+		GS:   data,
+		GDTR: Segment{Limit: 0xffff},
+		LDTR: Segment{Attrib: 0x82, Limit: 0xffff},
+		IDTR: Segment{Limit: 0xffff},
+		TR:   Segment{Attrib: 0x8b, Limit: 0xffff},
+		// KVM sets EFER.SVME and CR4.MCE.
+		EFER:   0x1000,
+		CR4:    0x40,
+		CR0:    0x10,
+		DR7:    0x400,
+		DR6:    0xffff0ff0,
+		RFLAGS: 0x2,
+		RIP:    uint64(eip & 0xffff),
+		// The PAT MSR reset value from Section A.3 of the AMD64 Architecture Programmer's Manual
+		// Volume 2.
+		GPAT: 0x0007040600070406,
+		// RDX holds the processor signature at reset, as CPUID function 1 reports it in EAX.
+		RDX:         uint64(vcpuSig),
+		SevFeatures: sevFeatures,
+		XCR0:        0x1,
+		MXCSR:       0x1f80,
+		X87FCW:      0x37f,
+	}
+}
+
+// BSPSaveArea returns the VMSA of the bootstrap processor, which starts at the reset vector.
+func BSPSaveArea(sevFeatures uint64, vcpuSig uint32) *SaveArea {
+	return ResetSaveArea(BSPResetEIP, sevFeatures, vcpuSig)
+}
+
+// CPUSig returns the processor signature that CPUID function 1 reports in EAX for the given
+// family, model, and stepping.
+func CPUSig(family, model, stepping uint32) uint32 {
+	familyLow := family
+	var familyHigh uint32
+	if family > 0xf {
+		familyLow = 0xf
+		familyHigh = (family - 0xf) & 0xff
+	}
+	return familyHigh<<20 | (model>>4&0xf)<<16 | familyLow<<8 | (model&0xf)<<4 | stepping&0xf
+}
+
+// CPUSigs maps QEMU -cpu model names to the processor signature the guest's vCPUs report.
+var CPUSigs = map[string]uint32{
+	"EPYC":          CPUSig(23, 1, 2),
+	"EPYC-v1":       CPUSig(23, 1, 2),
+	"EPYC-v2":       CPUSig(23, 1, 2),
+	"EPYC-IBPB":     CPUSig(23, 1, 2),
+	"EPYC-v3":       CPUSig(23, 1, 2),
+	"EPYC-v4":       CPUSig(23, 1, 2),
+	"EPYC-Rome":     CPUSig(23, 49, 0),
+	"EPYC-Rome-v1":  CPUSig(23, 49, 0),
+	"EPYC-Rome-v2":  CPUSig(23, 49, 0),
+	"EPYC-Rome-v3":  CPUSig(23, 49, 0),
+	"EPYC-Milan":    CPUSig(25, 1, 1),
+	"EPYC-Milan-v1": CPUSig(25, 1, 1),
+	"EPYC-Milan-v2": CPUSig(25, 1, 1),
+	"EPYC-Genoa":    CPUSig(25, 17, 0),
+	"EPYC-Genoa-v1": CPUSig(25, 17, 0),
+}
+
+// CPUSigFromName returns the processor signature of the given QEMU -cpu model name.
+func CPUSigFromName(name string) (uint32, error) {
+	sig, ok := CPUSigs[name]
+	if !ok {
+		names := make([]string, 0, len(CPUSigs))
+		for n := range CPUSigs {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return 0, fmt.Errorf("unknown CPU model %q. Expect one of %v", name, names)
+	}
+	return sig, nil
+}
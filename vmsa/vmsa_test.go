@@ -0,0 +1,141 @@
+// Copyright 2026 Google LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vmsa
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLayout(t *testing.T) {
+	if got := binary.Size(&SaveArea{}); got != Size {
+		t.Fatalf("binary.Size(SaveArea) = 0x%x, want 0x%x", got, Size)
+	}
+	// Each field's offset from Table B-4 of the AMD64 Architecture Programmer's Manual Volume 2.
+	tcs := []struct {
+		name   string
+		offset int
+		width  int
+		set    func(s *SaveArea)
+	}{
+		{name: "TR.Base", offset: 0x98, width: 8, set: func(s *SaveArea) { s.TR.Base = ^uint64(0) }},
+		{name: "VMPL", offset: 0xCA, width: 1, set: func(s *SaveArea) { s.VMPL = 0xff }},
+		{name: "EFER", offset: 0xD0, width: 8, set: func(s *SaveArea) { s.EFER = ^uint64(0) }},
+		{name: "XSS", offset: 0x140, width: 8, set: func(s *SaveArea) { s.XSS = ^uint64(0) }},
+		{name: "RIP", offset: 0x178, width: 8, set: func(s *SaveArea) { s.RIP = ^uint64(0) }},
+		{name: "RSP", offset: 0x1D8, width: 8, set: func(s *SaveArea) { s.RSP = ^uint64(0) }},
+		{name: "GPAT", offset: 0x268, width: 8, set: func(s *SaveArea) { s.GPAT = ^uint64(0) }},
+		{name: "PKRU", offset: 0x2E8, width: 4, set: func(s *SaveArea) { s.PKRU = ^uint32(0) }},
+		{name: "RDX", offset: 0x310, width: 8, set: func(s *SaveArea) { s.RDX = ^uint64(0) }},
+		{name: "RBP", offset: 0x328, width: 8, set: func(s *SaveArea) { s.RBP = ^uint64(0) }},
+		{name: "R15", offset: 0x378, width: 8, set: func(s *SaveArea) { s.R15 = ^uint64(0) }},
+		{name: "GuestExitInfo1", offset: 0x390, width: 8, set: func(s *SaveArea) { s.GuestExitInfo1 = ^uint64(0) }},
+		{name: "SevFeatures", offset: 0x3B0, width: 8, set: func(s *SaveArea) { s.SevFeatures = ^uint64(0) }},
+		{name: "XCR0", offset: 0x3E8, width: 8, set: func(s *SaveArea) { s.XCR0 = ^uint64(0) }},
+		{name: "X87DP", offset: 0x400, width: 8, set: func(s *SaveArea) { s.X87DP = ^uint64(0) }},
+		{name: "MXCSR", offset: 0x408, width: 4, set: func(s *SaveArea) { s.MXCSR = ^uint32(0) }},
+		{name: "X87FCW", offset: 0x410, width: 2, set: func(s *SaveArea) { s.X87FCW = ^uint16(0) }},
+		{name: "X87RIP", offset: 0x418, width: 8, set: func(s *SaveArea) { s.X87RIP = ^uint64(0) }},
+		{name: "FPRegX87", offset: 0x420, width: 1, set: func(s *SaveArea) { s.FPRegX87[0] = 0xff }},
+		{name: "FPRegXMM", offset: 0x470, width: 1, set: func(s *SaveArea) { s.FPRegXMM[0] = 0xff }},
+		{name: "FPRegYMM", offset: 0x570, width: 1, set: func(s *SaveArea) { s.FPRegYMM[0] = 0xff }},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &SaveArea{}
+			tc.set(s)
+			want := make([]byte, Size)
+			for i := 0; i < tc.width; i++ {
+				want[tc.offset+i] = 0xff
+			}
+			if got := s.Marshal(); !bytes.Equal(got, want) {
+				t.Errorf("Marshal() sets bytes at %v, want [0x%x, 0x%x)", setBytes(got), tc.offset, tc.offset+tc.width)
+			}
+		})
+	}
+}
+
+func setBytes(data []byte) []int {
+	var result []int
+	for i, b := range data {
+		if b != 0 {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+func TestResetSaveArea(t *testing.T) {
+	bsp := BSPSaveArea(SevFeatureSNPActive, 0x00A00F11)
+	if bsp.CS.Base != 0xFFFF0000 || bsp.RIP != 0xFFF0 {
+		t.Errorf("BSP starts at CS.Base 0x%x RIP 0x%x, want 0xffff0000 0xfff0", bsp.CS.Base, bsp.RIP)
+	}
+	ap := ResetSaveArea(0xFFFFF123, SevFeatureSNPActive, 0x00A00F11)
+	if ap.CS.Base != 0xFFFF0000 || ap.RIP != 0xF123 {
+		t.Errorf("AP starts at CS.Base 0x%x RIP 0x%x, want 0xffff0000 0xf123", ap.CS.Base, ap.RIP)
+	}
+	if ap.RDX != 0x00A00F11 || ap.SevFeatures != SevFeatureSNPActive {
+		t.Errorf("AP has RDX 0x%x SEV_FEATURES 0x%x, want 0xa00f11 0x1", ap.RDX, ap.SevFeatures)
+	}
+
+	page := bsp.Marshal()
+	got, err := ParseSaveArea(page)
+	if err != nil {
+		t.Fatalf("ParseSaveArea() = _, %v, want nil", err)
+	}
+	if diff := cmp.Diff(bsp, got); diff != "" {
+		t.Errorf("ParseSaveArea(Marshal()) returned unexpected diff (-want +got):\n%s", diff)
+	}
+	if digest := sha512.Sum384(page); bsp.Digest() != digest {
+		t.Errorf("Digest() = %x, want %x", bsp.Digest(), digest)
+	}
+	if bsp.Digest() == ap.Digest() {
+		t.Error("BSP and AP VMSAs have the same digest")
+	}
+
+	reserved := append([]byte{}, page...)
+	reserved[0xC8] = 1
+	if _, err := ParseSaveArea(reserved); err == nil || !strings.Contains(err.Error(), "reserved") {
+		t.Errorf("ParseSaveArea(non-zero reserved) = _, %v, want reserved error", err)
+	}
+	if _, err := ParseSaveArea(page[:Size-1]); err == nil {
+		t.Error("ParseSaveArea(short) = _, nil, want error")
+	}
+}
+
+func TestCPUSig(t *testing.T) {
+	tcs := []struct {
+		name string
+		want uint32
+	}{
+		{name: "EPYC", want: 0x00800F12},
+		{name: "EPYC-Rome", want: 0x00830F10},
+		{name: "EPYC-Milan", want: 0x00A00F11},
+		{name: "EPYC-Genoa", want: 0x00A10F10},
+	}
+	for _, tc := range tcs {
+		if got, err := CPUSigFromName(tc.name); err != nil || got != tc.want {
+			t.Errorf("CPUSigFromName(%q) = 0x%x, %v, want 0x%x, nil", tc.name, got, err, tc.want)
+		}
+	}
+	if _, err := CPUSigFromName("Skylake"); err == nil || !strings.Contains(err.Error(), "EPYC-Milan") {
+		t.Errorf("CPUSigFromName(\"Skylake\") = _, %v, want error listing known models", err)
+	}
+}